@@ -3,7 +3,6 @@ package cmd
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"math/rand/v2"
@@ -16,20 +15,29 @@ import (
 	"time"
 
 	paho_mqtt "github.com/eclipse/paho.mqtt.golang"
-	"github.com/jackc/pgx/v5/pgxpool"
-	go_ora "github.com/sijms/go-ora/v2"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/anicoll/winet-integration/internal/pkg/audit"
 	"github.com/anicoll/winet-integration/internal/pkg/auth"
 	"github.com/anicoll/winet-integration/internal/pkg/config"
-	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+	"github.com/anicoll/winet-integration/internal/pkg/control"
+	"github.com/anicoll/winet-integration/internal/pkg/filesink"
+	"github.com/anicoll/winet-integration/internal/pkg/influx"
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
 	"github.com/anicoll/winet-integration/internal/pkg/mqtt"
 	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/pvoutput"
+	"github.com/anicoll/winet-integration/internal/pkg/retention"
 	"github.com/anicoll/winet-integration/internal/pkg/server"
 	"github.com/anicoll/winet-integration/internal/pkg/store"
-	orastore "github.com/anicoll/winet-integration/internal/pkg/store/oracle"
-	pgstore "github.com/anicoll/winet-integration/internal/pkg/store/postgres"
+	"github.com/anicoll/winet-integration/internal/pkg/store/dualwrite"
+	memstore "github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	"github.com/anicoll/winet-integration/internal/pkg/store/open"
+	"github.com/anicoll/winet-integration/internal/pkg/stream"
+	"github.com/anicoll/winet-integration/internal/pkg/tariff"
+	"github.com/anicoll/winet-integration/internal/pkg/webhook"
 	"github.com/anicoll/winet-integration/internal/pkg/winet"
 	api "github.com/anicoll/winet-integration/pkg/server"
 )
@@ -45,9 +53,6 @@ const (
 	// Channel buffer sizes
 	errorChannelBuffer = 1000
 
-	// Cron schedules
-	// dbCleanupSchedule = "CRON_TZ=Australia/Adelaide 0 3 * * *"
-
 	// Reconnect backoff
 	backoffBase     = 5 * time.Second
 	backoffMax      = 5 * time.Minute
@@ -89,8 +94,14 @@ func Run(ctx context.Context, cfg *config.Config) error {
 		_ = logger.Sync() // flushes buffer, if any.
 	}()
 
+	// Local time for PVOutput statuses, retention day boundaries and the API.
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone: %w", err)
+	}
+
 	// Initialize database connection
-	db, cleanup, err := setupDatabase(ctx, cfg.DBDriver, cfg.DBDSN, cfg.MigrationsFolder, &cfg.OracleCfg)
+	db, cleanup, err := setupDatabase(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to setup database: %w", err)
 	}
@@ -98,32 +109,153 @@ func Run(ctx context.Context, cfg *config.Config) error {
 	defer cleanup()
 
 	publishers := []publisher.Publisher{db}
+	// seedSnapshot fills the MQTT snapshot state from the store once routes
+	// are known; nil unless snapshots are enabled.
+	var seedSnapshot func([]publisher.DataPoint)
 	if cfg.MqttCfg.Host != "" {
 		mqttOpts := paho_mqtt.NewClientOptions()
 		mqttOpts.SetPassword(cfg.MqttCfg.Password)
 		mqttOpts.SetUsername(cfg.MqttCfg.Username)
 		mqttOpts.AddBroker(cfg.MqttCfg.Host)
 
-		mqttPublisher := mqtt.New(paho_mqtt.NewClient(mqttOpts))
+		mqttPublisher := mqtt.New(paho_mqtt.NewClient(mqttOpts), mqtt.WithSnapshot(cfg.MqttCfg.Snapshot))
 		if err := mqttPublisher.Connect(); err != nil {
 			return fmt.Errorf("failed to connect to MQTT broker: %w", err)
 		}
 		publishers = append(publishers, mqttPublisher)
+		if cfg.MqttCfg.Snapshot {
+			seedSnapshot = mqttPublisher.Seed
+		}
 		logger.Info("Connected to MQTT broker", zap.String("host", cfg.MqttCfg.Host))
 	} else {
 		logger.Info("MQTT_HOST not set; MQTT publishing disabled")
 	}
 
+	// publisherLoops are background flush loops owned by buffering backends.
+	var publisherLoops []func(context.Context) error
+	stores := []store.Store{db}
+	if dw, ok := db.(*dualwrite.Store); ok {
+		// Replicates writes to the secondaries.
+		publisherLoops = append(publisherLoops, dw.Run)
+		stores = dw.Stores()
+	}
+	for _, st := range stores {
+		if mem, ok := st.(*memstore.Store); ok {
+			// Saves the snapshot periodically and on shutdown.
+			publisherLoops = append(publisherLoops, mem.Run)
+		}
+	}
+	if cfg.InfluxCfg.URL != "" {
+		influxPublisher, err := influx.New(&cfg.InfluxCfg)
+		if err != nil {
+			return fmt.Errorf("failed to setup influx publisher: %w", err)
+		}
+		publishers = append(publishers, influxPublisher)
+		publisherLoops = append(publisherLoops, influxPublisher.Run)
+		logger.Info("InfluxDB publishing enabled", zap.String("url", cfg.InfluxCfg.URL), zap.String("bucket", cfg.InfluxCfg.Bucket))
+	}
+
+	if cfg.FileSinkCfg.Dir != "" {
+		filePublisher, err := filesink.New(&cfg.FileSinkCfg)
+		if err != nil {
+			return fmt.Errorf("failed to setup file sink: %w", err)
+		}
+		publishers = append(publishers, filePublisher)
+		publisherLoops = append(publisherLoops, filePublisher.Run)
+		logger.Info("File sink enabled", zap.String("dir", cfg.FileSinkCfg.Dir), zap.String("format", cfg.FileSinkCfg.Format))
+	}
+
+	if cfg.PVOutputCfg.APIKey != "" {
+		pvoutputPublisher, err := pvoutput.New(&cfg.PVOutputCfg, loc)
+		if err != nil {
+			return fmt.Errorf("failed to setup pvoutput publisher: %w", err)
+		}
+		publishers = append(publishers, pvoutputPublisher)
+		publisherLoops = append(publisherLoops, pvoutputPublisher.Run)
+		logger.Info("PVOutput uploads enabled", zap.String("system_id", cfg.PVOutputCfg.SystemID), zap.Duration("interval", cfg.PVOutputCfg.Interval))
+	}
+
+	// Every inverter command is written to the audit log, and fanned out to
+	// the webhook when configured.
+	auditRecorder := audit.New(db)
+	publisherLoops = append(publisherLoops, auditRecorder.Run)
+	// The control tracker keeps the Inverter table at the state the last
+	// successful command left the inverter in, corrected by readings.
+	controlTracker := control.New(db)
+	if err := controlTracker.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load inverter state: %w", err)
+	}
+	publisherLoops = append(publisherLoops, controlTracker.Run)
+	commandHooks := []func(model.CommandEvent){auditRecorder.CommandHook, controlTracker.CommandHook}
+	if len(cfg.WebhookCfg.URLs) > 0 {
+		webhookPublisher, err := webhook.New(&cfg.WebhookCfg)
+		if err != nil {
+			return fmt.Errorf("failed to setup webhook publisher: %w", err)
+		}
+		publishers = append(publishers, webhookPublisher)
+		publisherLoops = append(publisherLoops, webhookPublisher.Run)
+		commandHooks = append(commandHooks, webhookPublisher.CommandHook)
+		logger.Info("Webhook publishing enabled", zap.Int("urls", len(cfg.WebhookCfg.URLs)), zap.Strings("events", cfg.WebhookCfg.Events))
+	}
+
+	if cfg.MetricsEnabled {
+		publishers = append(publishers, metrics.NewPublisher(metrics.Registry))
+	}
+
+	// Feeds GET /stream and /stream/ws with each published batch.
+	streamHub := stream.New()
+	publishers = append(publishers, streamHub)
+	publisherLoops = append(publisherLoops, streamHub.Run)
+
 	pub := publisher.NewMultiPublisher(publishers...)
+	pub.SetWriteErrorHook(func(backend string, _ error) {
+		metrics.PublishErrors.WithLabelValues(backend).Inc()
+	})
+	var routes publisher.Routes
+	if cfg.RoutesFile != "" {
+		routes, err = publisher.LoadRoutes(cfg.RoutesFile)
+		if err != nil {
+			return fmt.Errorf("failed to load publisher routes: %w", err)
+		}
+		if err := pub.SetRoutes(routes); err != nil {
+			return fmt.Errorf("failed to apply publisher routes: %w", err)
+		}
+		logger.Info("Publisher routes loaded", zap.String("file", cfg.RoutesFile), zap.Int("routes", len(routes)))
+	}
+	if seedSnapshot != nil {
+		latest, err := latestDataPoints(ctx, db)
+		if err != nil {
+			return fmt.Errorf("failed to seed MQTT snapshot: %w", err)
+		}
+		seedSnapshot(routes["mqtt"].Apply(latest))
+	}
 
 	authSvc := auth.NewService(cfg.AuthCfg.JWTSecret, cfg.AuthCfg.AccessTokenTTL, cfg.AuthCfg.RefreshTokenTTL, db, db)
 	authSvc.StartCleanup(ctx, time.Hour)
 
 	errorChan := make(chan error, errorChannelBuffer)
 	winetSvc := winet.New(&cfg.WinetCfg, pub)
+	winetSvc.SetCommandHook(func(event model.CommandEvent) {
+		for _, hook := range commandHooks {
+			hook(event)
+		}
+	})
+	winetSvc.SetDeviceStatusHook(controlTracker.DeviceStatusHook)
 
-	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
-		return fmt.Errorf("failed to load timezone: %w", err)
+	var retentionStatus server.Retention
+	if cfg.RetentionCfg.Enabled {
+		retentionSvc, err := retention.New(db, &cfg.RetentionCfg, loc)
+		if err != nil {
+			return fmt.Errorf("failed to setup retention: %w", err)
+		}
+		retentionStatus = retentionSvc
+		publisherLoops = append(publisherLoops, retentionSvc.Run)
+		logger.Info("Data retention enabled", zap.String("schedule", cfg.RetentionCfg.Schedule), zap.Duration("raw", cfg.RetentionCfg.Raw))
+	}
+
+	energyTariff, err := tariff.New(&cfg.TariffCfg, loc)
+	if err != nil {
+		return fmt.Errorf("failed to setup tariff: %w", err)
 	}
 
 	health := &healthState{}
@@ -131,10 +263,12 @@ func Run(ctx context.Context, cfg *config.Config) error {
 
 	eg, ctx := errgroup.WithContext(ctx)
 
-	// // Start database cleanup service
-	// eg.Go(func() error {
-	// 	return startDbCleanupService(ctx, db, errorChan, logger)
-	// })
+	// Start publisher flush loops and the retention schedule
+	for _, loop := range publisherLoops {
+		eg.Go(func() error {
+			return loop(ctx)
+		})
+	}
 
 	// Start winet service with retry logic
 	eg.Go(func() error {
@@ -147,7 +281,7 @@ func Run(ctx context.Context, cfg *config.Config) error {
 
 	// Start HTTP server
 	eg.Go(func() error {
-		return startHTTPServer(ctx, winetSvc, db, authSvc, health, cfg.AllowedOrigins, cfg.AuthCfg.SecureCookies, logger)
+		return startHTTPServer(ctx, winetSvc, db, authSvc, retentionStatus, controlTracker, streamHub, loc, &cfg.ReportCfg, energyTariff, health, cfg.AllowedOrigins, cfg.AuthCfg.SecureCookies, cfg.MetricsEnabled, logger)
 	})
 
 	// Start error handler
@@ -178,51 +312,63 @@ func setupLogger(logLevel string) (*zap.Logger, error) {
 	return logger, nil
 }
 
-func setupDatabase(ctx context.Context, driver, dsn, migrationsPath string, oracleCfg *config.OracleConfig) (store.Store, func(), error) {
-	if migrationsPath == "" {
-		migrationsPath = "migrations/" + driver
+// setupDatabase opens the primary database and, when DUAL_WRITE_SECONDARIES
+// is set, the secondaries, wrapping them all in a dual-write store.
+func setupDatabase(ctx context.Context, cfg *config.Config) (store.Store, func(), error) {
+	primary, cleanup, err := openDatabase(ctx, &cfg.DatabaseConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.DualWriteCfg.Secondaries) == 0 {
+		return primary, cleanup, nil
 	}
 
-	switch driver {
-	case "oracle":
-		if oracleCfg.Host == "" || oracleCfg.Service == "" || oracleCfg.User == "" || oracleCfg.Password == "" {
-			return nil, nil, fmt.Errorf("ORACLE_HOST, ORACLE_SERVICE, ORACLE_USER and ORACLE_PASSWORD are required when DB_DRIVER=oracle")
-		}
-		urlOptions := map[string]string{
-			"ssl": "true",
-		}
-		if !oracleCfg.SSLVerify {
-			urlOptions["ssl verify"] = "false"
-		}
-		if oracleCfg.WalletPath != "" {
-			urlOptions["wallet"] = oracleCfg.WalletPath
+	cleanups := []func(){cleanup}
+	closeAll := func() {
+		for _, c := range slices.Backward(cleanups) {
+			c()
 		}
-		connStr := go_ora.BuildUrl(oracleCfg.Host, oracleCfg.Port, oracleCfg.Service, oracleCfg.User, oracleCfg.Password, urlOptions)
-		db, err := sql.Open("oracle", connStr)
+	}
+	var secondaries []store.Store
+	for _, prefix := range cfg.DualWriteCfg.Secondaries {
+		dbCfg, err := config.LoadDatabase(prefix)
 		if err != nil {
-			return nil, nil, fmt.Errorf("open oracle connection: %w", err)
-		}
-		if err := db.PingContext(ctx); err != nil {
-			return nil, nil, fmt.Errorf("failed to connect to oracle: %w", err)
+			closeAll()
+			return nil, nil, fmt.Errorf("load secondary database %s: %w", prefix, err)
 		}
-		if err := migration.MigrateOracle(db, migrationsPath); err != nil {
-			return nil, nil, fmt.Errorf("failed to run oracle migrations: %w", err)
-		}
-		return orastore.New(db), func() { _ = db.Close() }, nil
-
-	default: // "postgres"
-		pool, err := pgxpool.New(ctx, dsn)
+		st, cleanup, err := openDatabase(ctx, dbCfg)
 		if err != nil {
-			return nil, nil, err
+			closeAll()
+			return nil, nil, fmt.Errorf("secondary database %s: %w", prefix, err)
 		}
-		if err := pool.Ping(ctx); err != nil {
-			return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
-		}
-		if err := migration.MigratePostgres(pool, migrationsPath); err != nil {
-			return nil, nil, fmt.Errorf("failed to run migrations: %w", err)
-		}
-		return pgstore.New(pool), pool.Close, nil
+		cleanups = append(cleanups, cleanup)
+		secondaries = append(secondaries, st)
+	}
+	dw, err := dualwrite.New(primary, secondaries, dualwrite.Options{
+		Policy:        dualwrite.Policy(cfg.DualWriteCfg.Policy),
+		QueueSize:     cfg.DualWriteCfg.QueueSize,
+		RetryInterval: cfg.DualWriteCfg.RetryInterval,
+		MaxAttempts:   cfg.DualWriteCfg.MaxAttempts,
+	})
+	if err != nil {
+		closeAll()
+		return nil, nil, err
 	}
+	zap.L().Info("Dual-write enabled", zap.Strings("secondaries", cfg.DualWriteCfg.Secondaries), zap.String("policy", cfg.DualWriteCfg.Policy))
+	return dw, closeAll, nil
+}
+
+// openDatabase connects to the database described by cfg and, unless
+// DB_AUTO_MIGRATE is off, runs its migrations.
+func openDatabase(ctx context.Context, cfg *config.DatabaseConfig) (store.Store, func(), error) {
+	if cfg.DBDriver == "memory" && cfg.MemoryCfg.SnapshotPath == "" {
+		zap.L().Warn("DB_DRIVER=memory without MEMORY_SNAPSHOT_PATH; all data is lost on exit")
+		return memstore.New(), func() {}, nil
+	}
+	if !cfg.AutoMigrate && cfg.DBDriver != "memory" {
+		zap.L().Info("DB_AUTO_MIGRATE is off; not running migrations", zap.String("driver", cfg.DBDriver))
+	}
+	return open.Store(ctx, cfg, open.Options{Migrate: cfg.AutoMigrate, TimescalePolicies: true})
 }
 
 type WinetConnector interface {
@@ -230,6 +376,26 @@ type WinetConnector interface {
 	Events() <-chan winet.SessionEvent
 }
 
+// latestDataPoints returns the latest stored reading of every series as data
+// points, for backends that keep state across publishes.
+func latestDataPoints(ctx context.Context, db store.Store) ([]publisher.DataPoint, error) {
+	latest, err := db.GetLatestProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var data []publisher.DataPoint
+	for p := range latest {
+		data = append(data, publisher.DataPoint{
+			Identifier:        p.Identifier,
+			Slug:              p.Slug,
+			Value:             p.Value,
+			UnitOfMeasurement: p.UnitOfMeasurement,
+			Timestamp:         p.TimeStamp,
+		})
+	}
+	return data, nil
+}
+
 func startWinetService(ctx context.Context, winetSvc WinetConnector, health *healthState, logger *zap.Logger) error {
 	logger.Info("Starting winet service")
 	consecutiveFails := 0
@@ -262,6 +428,7 @@ func startWinetService(ctx context.Context, winetSvc WinetConnector, health *hea
 				return ctx.Err()
 			case <-time.After(backoff):
 			}
+			metrics.WinetReconnects.Inc()
 			continue
 		}
 
@@ -277,6 +444,7 @@ func startWinetService(ctx context.Context, winetSvc WinetConnector, health *hea
 			} else {
 				logger.Warn("winet connection error, reconnecting", zap.Error(event.Err))
 			}
+			metrics.WinetReconnects.Inc()
 			continue
 		case <-ctx.Done():
 			health.set("disconnected")
@@ -286,11 +454,24 @@ func startWinetService(ctx context.Context, winetSvc WinetConnector, health *hea
 	}
 }
 
-func startHTTPServer(ctx context.Context, winetSvc server.WinetService, db store.Store, authSvc *auth.Service, health *healthState, allowedOrigins []string, secureCookies bool, logger *zap.Logger) error {
+func startHTTPServer(ctx context.Context, winetSvc server.WinetService, db store.Store, authSvc *auth.Service, retentionStatus server.Retention, inverterState server.InverterState, liveStream server.Stream, loc *time.Location, reportCfg *config.ReportConfig, energyTariff *tariff.Tariff, health *healthState, allowedOrigins []string, secureCookies, metricsEnabled bool, logger *zap.Logger) error {
 	logger.Info("Starting HTTP server", zap.String("addr", serverAddr))
 
-	apiHandler := api.HandlerWithOptions(server.New(winetSvc, db, authSvc, secureCookies), api.StdHTTPServerOptions{
-		Middlewares: []api.MiddlewareFunc{server.TimeoutMiddleware, server.LoggingMiddleware(allowedOrigins), server.AuthMiddleware(authSvc)},
+	middlewares := []api.MiddlewareFunc{server.TimeoutMiddleware, server.LoggingMiddleware(allowedOrigins), server.AuthMiddleware(authSvc)}
+	if metricsEnabled {
+		// Outermost, so rejected and timed-out requests are measured too.
+		middlewares = append(middlewares, server.MetricsMiddleware)
+	}
+
+	srvImpl := server.New(winetSvc, db, authSvc, secureCookies, loc)
+	if retentionStatus != nil {
+		srvImpl.SetRetention(retentionStatus)
+	}
+	srvImpl.SetInverterState(inverterState)
+	srvImpl.SetStream(liveStream, allowedOrigins)
+	srvImpl.SetReports(reportCfg, energyTariff)
+	apiHandler := api.HandlerWithOptions(srvImpl, api.StdHTTPServerOptions{
+		Middlewares: middlewares,
 		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
 			logger.Error("HTTP handler error", zap.Error(err))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -313,6 +494,9 @@ func startHTTPServer(ctx context.Context, winetSvc server.WinetService, db store
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = fmt.Fprintf(w, `{"status":%q}`, health.get())
 	})
+	if metricsEnabled {
+		mux.Handle("GET /metrics", metrics.Handler())
+	}
 
 	srv := &http.Server{
 		Handler:      mux,
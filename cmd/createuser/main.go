@@ -8,21 +8,26 @@
 // Usage (oracle):
 //
 //	DB_DRIVER=oracle ORACLE_HOST=... ORACLE_SERVICE=... ORACLE_USER=... ORACLE_PASSWORD=... ./createuser --username alice --password supersecret123
+//
+// Usage (sqlite):
+//
+//	DB_DRIVER=sqlite SQLITE_PATH=/var/lib/winet/winet.db ./createuser --username alice --password supersecret123
+//
+// Usage (memory — adds the user to the snapshot; stop the server first, or its
+// next snapshot will overwrite the change):
+//
+//	DB_DRIVER=memory MEMORY_SNAPSHOT_PATH=winet.json ./createuser --username alice --password supersecret123
 package main
 
 import (
 	"context"
-	"database/sql"
 	"flag"
 	"fmt"
 	"os"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-	go_ora "github.com/sijms/go-ora/v2"
-
 	"github.com/anicoll/winet-integration/internal/pkg/config"
-	orastore "github.com/anicoll/winet-integration/internal/pkg/store/oracle"
-	pgstore "github.com/anicoll/winet-integration/internal/pkg/store/postgres"
+	memstore "github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	"github.com/anicoll/winet-integration/internal/pkg/store/open"
 	"github.com/anicoll/winet-integration/pkg/hasher"
 )
 
@@ -56,54 +61,21 @@ func run() error {
 	}
 
 	ctx := context.Background()
+	st, cleanup, err := open.Store(ctx, &cfg.DatabaseConfig, open.Options{})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
-	switch cfg.DBDriver {
-	case "oracle":
-		if cfg.OracleCfg.Host == "" || cfg.OracleCfg.Service == "" || cfg.OracleCfg.User == "" || cfg.OracleCfg.Password == "" {
-			return fmt.Errorf("ORACLE_HOST, ORACLE_SERVICE, ORACLE_USER and ORACLE_PASSWORD are required when DB_DRIVER=oracle")
-		}
-		urlOptions := map[string]string{"ssl": "true"}
-		if !cfg.OracleCfg.SSLVerify {
-			urlOptions["ssl verify"] = "false"
-		}
-		if cfg.OracleCfg.WalletPath != "" {
-			urlOptions["wallet"] = cfg.OracleCfg.WalletPath
-		}
-		connStr := go_ora.BuildUrl(cfg.OracleCfg.Host, cfg.OracleCfg.Port, cfg.OracleCfg.Service, cfg.OracleCfg.User, cfg.OracleCfg.Password, urlOptions)
-		db, err := sql.Open("oracle", connStr)
-		if err != nil {
-			return fmt.Errorf("connect to oracle: %w", err)
-		}
-		defer func() { _ = db.Close() }()
-		if err := db.PingContext(ctx); err != nil {
-			return fmt.Errorf("ping oracle: %w", err)
-		}
-		st := orastore.New(db)
-		user, err := st.CreateUser(ctx, *username, hash)
-		if err != nil {
-			return fmt.Errorf("create user: %w", err)
-		}
-		fmt.Printf("created user %q (id=%d)\n", user.Username, user.ID)
-
-	default: // postgres
-		if cfg.DBDSN == "" {
-			return fmt.Errorf("DATABASE_URL is required when DB_DRIVER=postgres")
-		}
-		pool, err := pgxpool.New(ctx, cfg.DBDSN)
-		if err != nil {
-			return fmt.Errorf("connect to database: %w", err)
-		}
-		defer pool.Close()
-		if err := pool.Ping(ctx); err != nil {
-			return fmt.Errorf("ping database: %w", err)
-		}
-		st := pgstore.New(pool)
-		user, err := st.CreateUser(ctx, *username, hash)
-		if err != nil {
-			return fmt.Errorf("create user: %w", err)
+	user, err := st.CreateUser(ctx, *username, hash)
+	if err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+	if mem, ok := st.(*memstore.Store); ok {
+		if err := mem.Save(); err != nil {
+			return err
 		}
-		fmt.Printf("created user %q (id=%d)\n", user.Username, user.ID)
 	}
-
+	fmt.Printf("created user %q (id=%d)\n", user.Username, user.ID)
 	return nil
 }
@@ -0,0 +1,216 @@
+// dbadmin is a local CLI tool for inspecting and running database migrations
+// by hand. It is configured with the usual database variables (DB_DRIVER,
+// DATABASE_URL, ORACLE_*, SQLITE_PATH, MIGRATIONS_FOLDER) and works the same
+// for postgres, oracle and sqlite.
+//
+// Usage:
+//
+//	DB_DRIVER=postgres DATABASE_URL=postgres://... ./dbadmin status
+//	... ./dbadmin up        # apply every pending migration
+//	... ./dbadmin down 1    # revert the most recent migration
+//	... ./dbadmin goto 5    # migrate up or down to version 5; 0 reverts everything
+//	... ./dbadmin force 5   # record the database as clean at version 5, running nothing
+//	... ./dbadmin verify    # exit non-zero unless every migration is applied and clean
+//
+// With --timescale the commands act on the TimescaleDB migrations, which sit
+// on top of the Postgres schema, instead of the schema itself.
+//
+// A migration that fails part way leaves the database dirty and every later
+// run refuses to continue. Repair the schema by hand, then force the version
+// it is actually at.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+	"github.com/anicoll/winet-integration/internal/pkg/store/open"
+)
+
+const usage = `usage: dbadmin [--timescale] <command> [argument]
+
+commands:
+  status      list migrations and whether each is applied
+  up          apply every pending migration
+  down N      revert the N most recent migrations
+  goto V      migrate up or down to version V; 0 reverts everything
+  force V     record the database as clean at version V without running anything
+  verify      fail unless every migration is applied, clean and unmodified
+
+flags:
+`
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	timescale := flag.Bool("timescale", false, "act on the TimescaleDB migrations instead of the Postgres schema")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		return fmt.Errorf("a command is required")
+	}
+
+	cfg, err := config.LoadDatabase("")
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	m, closeDB, err := openMigrator(ctx, cfg, *timescale)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	return execute(ctx, m, flag.Args(), os.Stdout)
+}
+
+func execute(ctx context.Context, m migration.Migrator, args []string, out io.Writer) error {
+	cmd := args[0]
+	switch cmd {
+	case "status", "up", "verify":
+		if len(args) != 1 {
+			return fmt.Errorf("%s takes no arguments", cmd)
+		}
+	case "down", "goto", "force":
+		if len(args) != 2 {
+			return fmt.Errorf("%s takes one argument", cmd)
+		}
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	var err error
+	switch cmd {
+	case "status":
+		_, err = printStatus(ctx, m, out)
+		return err
+	case "verify":
+		return verify(ctx, m, out)
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		n, perr := strconv.Atoi(args[1])
+		if perr != nil || n < 1 {
+			return fmt.Errorf("invalid count %q", args[1])
+		}
+		err = m.Down(ctx, n)
+	case "goto", "force":
+		v, perr := strconv.ParseUint(args[1], 10, 64)
+		if perr != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if cmd == "goto" {
+			err = m.Migrate(ctx, uint(v))
+		} else {
+			err = m.Force(ctx, uint(v))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	_, err = printStatus(ctx, m, out)
+	return err
+}
+
+// verify prints the status and fails if any migration is pending, dirty,
+// modified since it was applied, or applied without its file.
+func verify(ctx context.Context, m migration.Migrator, out io.Writer) error {
+	statuses, err := printStatus(ctx, m, out)
+	if err != nil {
+		return err
+	}
+	var problems int
+	for _, st := range statuses {
+		if !st.Applied || st.Dirty || st.Modified || st.Missing {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d of %d migrations are not applied cleanly", problems, len(statuses))
+	}
+	return nil
+}
+
+func printStatus(ctx context.Context, m migration.Migrator, out io.Writer) ([]migration.Status, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, st := range statuses {
+		state, appliedAt := "pending", ""
+		switch {
+		case st.Dirty:
+			state = "dirty"
+		case st.Missing:
+			state = "applied, file missing"
+		case st.Modified:
+			state = "applied, file modified"
+		case st.Applied:
+			state = "applied"
+		}
+		if !st.AppliedAt.IsZero() {
+			appliedAt = st.AppliedAt.Local().Format(time.DateTime)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", st.Version, st.Name, state, appliedAt)
+	}
+	return statuses, w.Flush()
+}
+
+// openMigrator connects to the database described by cfg and returns a
+// migrator for its migrations folder, with the function that closes both.
+func openMigrator(ctx context.Context, cfg *config.DatabaseConfig, timescale bool) (migration.Migrator, func(), error) {
+	if timescale && cfg.DBDriver != "postgres" {
+		return nil, nil, fmt.Errorf("--timescale needs DB_DRIVER=postgres")
+	}
+	if cfg.DBDriver == "memory" {
+		return nil, nil, errors.New("DB_DRIVER=memory has no migrations")
+	}
+	conn, err := open.Connect(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r *migration.Runner
+	switch {
+	case cfg.DBDriver == "oracle":
+		return migration.NewOracle(conn.SQL, open.MigrationsPath(cfg)), conn.Close, nil
+	case cfg.DBDriver == "sqlite":
+		r, err = migration.NewSQLite(conn.SQL, open.MigrationsPath(cfg))
+	case timescale:
+		r, err = migration.NewTimescale(conn.Pool, open.TimescaleMigrationsPath(cfg))
+	default: // postgres
+		r, err = migration.NewPostgres(conn.Pool, open.MigrationsPath(cfg))
+	}
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return r, func() {
+		_ = r.Close()
+		conn.Close()
+	}, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+)
+
+type fakeMigrator struct {
+	calls    []string
+	statuses []migration.Status
+}
+
+func (f *fakeMigrator) Status(context.Context) ([]migration.Status, error) {
+	return f.statuses, nil
+}
+
+func (f *fakeMigrator) Up(context.Context) error {
+	f.calls = append(f.calls, "up")
+	return nil
+}
+
+func (f *fakeMigrator) Migrate(_ context.Context, v uint) error {
+	f.calls = append(f.calls, fmt.Sprint("goto ", v))
+	return nil
+}
+
+func (f *fakeMigrator) Down(_ context.Context, n int) error {
+	f.calls = append(f.calls, fmt.Sprint("down ", n))
+	return nil
+}
+
+func (f *fakeMigrator) Force(_ context.Context, v uint) error {
+	f.calls = append(f.calls, fmt.Sprint("force ", v))
+	return nil
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"up"}, "up"},
+		{[]string{"down", "2"}, "down 2"},
+		{[]string{"goto", "3"}, "goto 3"},
+		{[]string{"goto", "0"}, "goto 0"},
+		{[]string{"force", "2"}, "force 2"},
+	}
+	for _, tt := range tests {
+		m := &fakeMigrator{}
+		require.NoError(t, execute(context.Background(), m, tt.args, &bytes.Buffer{}))
+		assert.Equal(t, []string{tt.want}, m.calls)
+	}
+}
+
+func TestExecute_RejectsBadArguments(t *testing.T) {
+	ctx := context.Background()
+	for _, args := range [][]string{{"goto"}, {"goto", "-1"}, {"force", "x"}, {"up", "3"}, {"down"}, {"down", "0"}, {"verify", "1"}, {"sideways"}} {
+		m := &fakeMigrator{}
+		assert.Error(t, execute(ctx, m, args, &bytes.Buffer{}), "%v", args)
+		assert.Empty(t, m.calls, "%v", args)
+	}
+}
+
+func TestPrintStatus(t *testing.T) {
+	applied := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	m := &fakeMigrator{statuses: []migration.Status{
+		{Version: 1, Name: "000001_initial_schema", Applied: true, AppliedAt: applied},
+		{Version: 2, Name: "000002_drop_amber_tables", Applied: true, AppliedAt: applied, Dirty: true},
+		{Version: 3, Name: "000003_create_property_rollup"},
+	}}
+	var out bytes.Buffer
+	require.NoError(t, execute(context.Background(), m, []string{"status"}, &out))
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+	assert.Contains(t, string(lines[1]), "applied  2026-01-02 03:04:05")
+	assert.Contains(t, string(lines[2]), "dirty")
+	assert.Contains(t, string(lines[3]), "pending")
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	clean := []migration.Status{
+		{Version: 1, Name: "000001_a", Applied: true},
+		{Version: 2, Name: "000002_b", Applied: true},
+	}
+	require.NoError(t, execute(ctx, &fakeMigrator{statuses: clean}, []string{"verify"}, &bytes.Buffer{}))
+
+	for name, st := range map[string]migration.Status{
+		"pending":  {Version: 3, Name: "000003_c"},
+		"dirty":    {Version: 3, Name: "000003_c", Applied: true, Dirty: true},
+		"modified": {Version: 3, Name: "000003_c", Applied: true, Modified: true},
+		"missing":  {Version: 3, Name: "3", Applied: true, Missing: true},
+	} {
+		m := &fakeMigrator{statuses: append(clean[:2:2], st)}
+		err := execute(ctx, m, []string{"verify"}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "1 of 3 migrations", name)
+	}
+}
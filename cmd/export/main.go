@@ -0,0 +1,154 @@
+// export is a local CLI tool that dumps stored readings for a date range into
+// the same CSV or Parquet layout written by the file sink (FILESINK_DIR).
+//
+// Usage (postgres):
+//
+//	DB_DRIVER=postgres DATABASE_URL=postgres://... ./export --from 2026-01-01 --to 2026-02-01 --format parquet --out readings.parquet
+//
+// Usage (oracle):
+//
+//	DB_DRIVER=oracle ORACLE_HOST=... ORACLE_SERVICE=... ORACLE_USER=... ORACLE_PASSWORD=... ./export --from 2026-01-01 --format csv --compression gzip --out readings.csv.gz
+//
+// Usage (sqlite):
+//
+//	DB_DRIVER=sqlite SQLITE_PATH=/var/lib/winet/winet.db ./export --from 2026-01-01 --format csv --out readings.csv
+//
+// --from and --to accept a date (YYYY-MM-DD, UTC midnight) or an RFC 3339
+// timestamp; --to defaults to now. Output goes to stdout when --out is "-".
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/filesink"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	"github.com/anicoll/winet-integration/internal/pkg/store/open"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	fromFlag := flag.String("from", "", "start of range, YYYY-MM-DD or RFC 3339 (required)")
+	toFlag := flag.String("to", "", "end of range, YYYY-MM-DD or RFC 3339 (default now)")
+	format := flag.String("format", filesink.FormatCSV, "output format: csv or parquet")
+	compression := flag.String("compression", "", "csv: none|gzip; parquet: none|snappy|gzip|zstd")
+	identifier := flag.String("identifier", "", "only export this device identifier")
+	slug := flag.String("slug", "", "only export this sensor slug")
+	out := flag.String("out", "-", "output file, or - for stdout")
+	flag.Parse()
+
+	if *fromFlag == "" {
+		return fmt.Errorf("--from is required")
+	}
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	to := time.Now().UTC()
+	if *toFlag != "" {
+		if to, err = parseTime(*toFlag); err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("--from must be before --to")
+	}
+	if _, err := filesink.Extension(*format, *compression); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadDatabase("")
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	ctx := context.Background()
+	st, cleanup, err := open.Store(ctx, cfg, open.Options{})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", *out, err)
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+
+	n, err := export(ctx, st, w, from, to, *identifier, *slug, *format, *compression)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d readings\n", n)
+	return nil
+}
+
+type series struct{ identifier, slug string }
+
+// export writes every reading in [from, to] to w. The store has no "all
+// series" query, so the series are discovered from the latest readings and
+// fetched one at a time, each sorted by timestamp.
+func export(ctx context.Context, st store.Store, w io.Writer, from, to time.Time, identifier, slug, format, compression string) (int, error) {
+	latest, err := st.GetLatestProperties(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list series: %w", err)
+	}
+	var all []series
+	for p := range latest {
+		if (identifier == "" || p.Identifier == identifier) && (slug == "" || p.Slug == slug) {
+			all = append(all, series{p.Identifier, p.Slug})
+		}
+	}
+	slices.SortFunc(all, func(a, b series) int {
+		return cmp.Or(cmp.Compare(a.identifier, b.identifier), cmp.Compare(a.slug, b.slug))
+	})
+
+	rw, err := filesink.NewRowWriter(w, format, compression, true)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, s := range all {
+		props, err := st.GetProperties(ctx, s.identifier, s.slug, &from, &to)
+		if err != nil {
+			return total, fmt.Errorf("get %s/%s: %w", s.identifier, s.slug, err)
+		}
+		rows := make([]filesink.Row, 0, len(props))
+		for _, p := range props {
+			rows = append(rows, filesink.RowFromProperty(p))
+		}
+		slices.SortFunc(rows, func(a, b filesink.Row) int { return a.Timestamp.Compare(b.Timestamp) })
+		if err := rw.Write(rows); err != nil {
+			return total, fmt.Errorf("write: %w", err)
+		}
+		total += len(rows)
+	}
+	if err := rw.Close(); err != nil {
+		return total, fmt.Errorf("write: %w", err)
+	}
+	return total, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,633 @@
+// migratedata is a local CLI tool that copies devices, users, refresh tokens,
+// readings, rollups, the sensor catalog, the command audit log and inverter
+// state from one store backend to another, for example from a local Postgres
+// to Oracle Autonomous Database.
+//
+// The source is configured with the usual database variables (DB_DRIVER,
+// DATABASE_URL, ORACLE_*, SQLITE_PATH, MEMORY_SNAPSHOT_PATH); the target with
+// the same variables prefixed by TARGET_. Migrations are run on the target
+// before anything is copied.
+//
+// Usage (postgres to oracle):
+//
+//	DB_DRIVER=postgres DATABASE_URL=postgres://... \
+//	TARGET_DB_DRIVER=oracle TARGET_ORACLE_HOST=... TARGET_ORACLE_SERVICE=... TARGET_ORACLE_USER=... TARGET_ORACLE_PASSWORD=... \
+//	./migratedata --batch-size 5000
+//
+// Usage (sqlite to postgres):
+//
+//	DB_DRIVER=sqlite SQLITE_PATH=/var/lib/winet/winet.db \
+//	TARGET_DB_DRIVER=postgres TARGET_DATABASE_URL=postgres://... ./migratedata
+//
+// Readings, rollups and audit log entries are copied in batches in source
+// order and the last copied position is recorded in the --state file after
+// every batch, so an interrupted run picks up where it stopped. Devices,
+// users, refresh tokens, the sensor catalog and inverter state are small and
+// copied idempotently on every run. Stop the server writing to the source first, or
+// readings written during the copy may be missed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	"github.com/anicoll/winet-integration/internal/pkg/store/open"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	batchSize := flag.Int("batch-size", 1000, "readings copied per batch")
+	statePath := flag.String("state", "migratedata.state.json", "file recording progress, so an interrupted run can resume")
+	verifyOnly := flag.Bool("verify", false, "only compare source and target counts")
+	flag.Parse()
+
+	if *batchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+
+	srcCfg, err := config.LoadDatabase("")
+	if err != nil {
+		return fmt.Errorf("load source config: %w", err)
+	}
+	dstCfg, err := config.LoadDatabase("TARGET_")
+	if err != nil {
+		return fmt.Errorf("load target config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	src, closeSrc, err := open.Store(ctx, srcCfg, open.Options{})
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	defer closeSrc()
+	dst, closeDst, err := open.Store(ctx, dstCfg, open.Options{Migrate: true})
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+	defer closeDst()
+
+	m := &migrator{src: src, dst: dst, batchSize: *batchSize, statePath: *statePath, out: os.Stdout}
+	if !*verifyOnly {
+		if err := m.migrate(ctx); err != nil {
+			return err
+		}
+	}
+	return m.verify(ctx)
+}
+
+// checkpoint is the progress saved in the --state file.
+type checkpoint struct {
+	LastPropertyID int          `json:"last_property_id"`
+	Properties     int          `json:"properties"`
+	LastRollup     store.Rollup `json:"last_rollup"`
+	Rollups        int          `json:"rollups"`
+	LastCommandID  int          `json:"last_command_id"`
+	Commands       int          `json:"commands"`
+}
+
+// saver is implemented by stores that must be saved explicitly, i.e. the
+// memory store's snapshot.
+type saver interface {
+	Save() error
+}
+
+type migrator struct {
+	src, dst  store.Store
+	batchSize int
+	statePath string
+	out       io.Writer
+}
+
+func (m *migrator) migrate(ctx context.Context) error {
+	if err := m.copyDevices(ctx); err != nil {
+		return fmt.Errorf("devices: %w", err)
+	}
+	userIDs, err := m.copyUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("users: %w", err)
+	}
+	if err := m.copyRefreshTokens(ctx, userIDs); err != nil {
+		return fmt.Errorf("refresh tokens: %w", err)
+	}
+	if err := m.copyProperties(ctx); err != nil {
+		return fmt.Errorf("readings: %w", err)
+	}
+	if err := m.copySensors(ctx); err != nil {
+		return fmt.Errorf("sensors: %w", err)
+	}
+	if err := m.copyRollups(ctx); err != nil {
+		return fmt.Errorf("rollups: %w", err)
+	}
+	if err := m.copyCommands(ctx); err != nil {
+		return fmt.Errorf("commands: %w", err)
+	}
+	if err := m.copyInverterStates(ctx); err != nil {
+		return fmt.Errorf("inverter states: %w", err)
+	}
+	return m.save()
+}
+
+func (m *migrator) copyDevices(ctx context.Context) error {
+	devices, err := m.src.ListDevices(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if err := m.dst.RegisterDevice(ctx, &d); err != nil {
+			return fmt.Errorf("register %s: %w", d.ID, err)
+		}
+	}
+	fmt.Fprintf(m.out, "devices: %d copied\n", len(devices))
+	return nil
+}
+
+// copyUsers creates the source users missing from the target and returns the
+// target user ID for every source username. Users that already exist keep
+// their target password.
+func (m *migrator) copyUsers(ctx context.Context) (map[string]int, error) {
+	users, err := m.src.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := m.dst.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]int, len(users))
+	for _, u := range existing {
+		ids[u.Username] = u.ID
+	}
+	created := 0
+	for _, u := range users {
+		if _, ok := ids[u.Username]; ok {
+			continue
+		}
+		nu, err := m.dst.CreateUser(ctx, u.Username, u.PasswordHash)
+		if err != nil {
+			return nil, fmt.Errorf("create %q: %w", u.Username, err)
+		}
+		ids[u.Username] = nu.ID
+		created++
+	}
+	fmt.Fprintf(m.out, "users: %d copied, %d already present\n", created, len(users)-created)
+	return ids, nil
+}
+
+// copyRefreshTokens copies the unexpired refresh tokens, pointing each at the
+// target ID of its user, so signed-in sessions survive the move.
+func (m *migrator) copyRefreshTokens(ctx context.Context, userIDs map[string]int) error {
+	tokens, err := m.src.ListRefreshTokens(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	copied := 0
+	for _, t := range tokens {
+		userID, ok := userIDs[t.Username]
+		if !ok || t.ExpiresAt.Before(now) {
+			continue
+		}
+		if err := m.dst.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+			TokenHash: t.TokenHash,
+			UserID:    userID,
+			Username:  t.Username,
+			ExpiresAt: t.ExpiresAt,
+		}); err != nil {
+			return err
+		}
+		copied++
+	}
+	fmt.Fprintf(m.out, "refresh tokens: %d copied, %d expired skipped\n", copied, len(tokens)-copied)
+	return nil
+}
+
+// copyProperties copies the readings after the checkpoint in batches. Write
+// also maintains the target's latest readings and rollups.
+//
+// A run that stopped between writing a batch and saving the checkpoint
+// would copy that batch again, so the first batch of every run skips
+// readings the target already has.
+func (m *migrator) copyProperties(ctx context.Context) error {
+	cp, err := m.load()
+	if err != nil {
+		return err
+	}
+	total, err := m.src.CountProperties(ctx)
+	if err != nil {
+		return err
+	}
+	if cp.LastPropertyID > 0 {
+		fmt.Fprintf(m.out, "readings: resuming after source id %d (%d copied)\n", cp.LastPropertyID, cp.Properties)
+	}
+
+	first := true
+	for {
+		batch, err := m.src.ScanProperties(ctx, cp.LastPropertyID, m.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		lastID := batch[len(batch)-1].ID
+		if first {
+			if batch, err = m.withoutCopied(ctx, batch); err != nil {
+				return err
+			}
+			first = false
+		}
+		if len(batch) > 0 {
+			data := make([]publisher.DataPoint, len(batch))
+			for i, p := range batch {
+				data[i] = publisher.DataPoint{
+					Value:             p.Value,
+					Slug:              p.Slug,
+					Timestamp:         p.TimeStamp,
+					Identifier:        p.Identifier,
+					UnitOfMeasurement: p.UnitOfMeasurement,
+				}
+			}
+			if err := m.dst.Write(ctx, data); err != nil {
+				return err
+			}
+		}
+		cp.LastPropertyID = lastID
+		cp.Properties += len(batch)
+		if err := m.save(); err != nil {
+			return err
+		}
+		if err := m.store(cp); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.out, "readings: %d/%d copied\n", cp.Properties, total)
+	}
+	fmt.Fprintf(m.out, "readings: done, %d copied\n", cp.Properties)
+	return nil
+}
+
+// withoutCopied drops the readings of batch that the target already holds,
+// matched on identifier, slug, timestamp and value. It queries each series
+// once over the batch's time range.
+func (m *migrator) withoutCopied(ctx context.Context, batch []store.Property) ([]store.Property, error) {
+	type key struct {
+		identifier, slug, value string
+		ts                      int64
+	}
+	type span struct{ from, to time.Time }
+	spans := map[[2]string]*span{}
+	for _, p := range batch {
+		k := [2]string{p.Identifier, p.Slug}
+		sp, ok := spans[k]
+		if !ok {
+			spans[k] = &span{p.TimeStamp, p.TimeStamp}
+			continue
+		}
+		if p.TimeStamp.Before(sp.from) {
+			sp.from = p.TimeStamp
+		}
+		if p.TimeStamp.After(sp.to) {
+			sp.to = p.TimeStamp
+		}
+	}
+	have := map[key]int{}
+	for k, sp := range spans {
+		props, err := m.dst.GetProperties(ctx, k[0], k[1], &sp.from, &sp.to)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range props {
+			have[key{p.Identifier, p.Slug, p.Value, p.TimeStamp.UnixMicro()}]++
+		}
+	}
+	if len(have) == 0 {
+		return batch, nil
+	}
+
+	out := make([]store.Property, 0, len(batch))
+	for _, p := range batch {
+		k := key{p.Identifier, p.Slug, p.Value, p.TimeStamp.UnixMicro()}
+		if have[k] > 0 {
+			have[k]--
+			continue
+		}
+		out = append(out, p)
+	}
+	if skipped := len(batch) - len(out); skipped > 0 {
+		fmt.Fprintf(m.out, "readings: skipped %d already in the target\n", skipped)
+	}
+	return out, nil
+}
+
+// copySensors merges the source catalog into the target's. Write only saw
+// the readings still held in the source, so first seen and names of sensors
+// whose early readings were deleted by retention come from here. Importing
+// merges, so it is repeated on every run.
+func (m *migrator) copySensors(ctx context.Context) error {
+	sensors, err := m.src.GetSensors(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.dst.ImportSensors(ctx, sensors); err != nil {
+		return err
+	}
+	fmt.Fprintf(m.out, "sensors: %d copied\n", len(sensors))
+	return nil
+}
+
+// copyRollups copies the rollups after the checkpoint in batches. They
+// replace the buckets Write built from the copied readings, and keep the
+// history whose readings retention already deleted from the source. An
+// imported bucket replaces the stored one, so a repeated batch is harmless.
+func (m *migrator) copyRollups(ctx context.Context) error {
+	cp, err := m.load()
+	if err != nil {
+		return err
+	}
+	total, err := m.src.CountRollups(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		batch, err := m.src.ScanRollups(ctx, cp.LastRollup, m.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if err := m.dst.ImportRollups(ctx, batch); err != nil {
+			return err
+		}
+		cp.LastRollup = batch[len(batch)-1]
+		cp.Rollups += len(batch)
+		if err := m.save(); err != nil {
+			return err
+		}
+		if err := m.store(cp); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.out, "rollups: %d/%d copied\n", cp.Rollups, total)
+	}
+	fmt.Fprintf(m.out, "rollups: done, %d copied\n", cp.Rollups)
+	return nil
+}
+
+// copyCommands copies the command audit log after the checkpoint in source
+// ID order. Like copyProperties, the first batch of every run skips entries
+// the target already has.
+func (m *migrator) copyCommands(ctx context.Context) error {
+	cp, err := m.load()
+	if err != nil {
+		return err
+	}
+	total, err := m.src.CountCommands(ctx)
+	if err != nil {
+		return err
+	}
+	first := true
+	for {
+		batch, err := m.src.ScanCommands(ctx, cp.LastCommandID, m.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		lastID := batch[len(batch)-1].ID
+		if first {
+			if batch, err = m.withoutCopiedCommands(ctx, batch); err != nil {
+				return err
+			}
+			first = false
+		}
+		for _, c := range batch {
+			if err := m.dst.RecordCommand(ctx, c); err != nil {
+				return err
+			}
+		}
+		cp.LastCommandID = lastID
+		cp.Commands += len(batch)
+		if err := m.save(); err != nil {
+			return err
+		}
+		if err := m.store(cp); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.out, "commands: %d/%d copied\n", cp.Commands, total)
+	}
+	fmt.Fprintf(m.out, "commands: done, %d copied\n", cp.Commands)
+	return nil
+}
+
+// withoutCopiedCommands drops the entries of batch that the target already
+// holds, matched on request time, source, user and command.
+func (m *migrator) withoutCopiedCommands(ctx context.Context, batch []store.Command) ([]store.Command, error) {
+	type key struct {
+		source, user, command string
+		ts                    int64
+	}
+	from, to := batch[0].RequestedAt, batch[0].RequestedAt
+	for _, c := range batch {
+		if c.RequestedAt.Before(from) {
+			from = c.RequestedAt
+		}
+		if c.RequestedAt.After(to) {
+			to = c.RequestedAt
+		}
+	}
+	existing, err := m.dst.GetCommands(ctx, store.CommandFilter{From: &from, To: &to, Limit: math.MaxInt32})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return batch, nil
+	}
+	have := map[key]int{}
+	for _, c := range existing {
+		have[key{c.Source, c.User, c.Command, c.RequestedAt.UnixMicro()}]++
+	}
+
+	out := make([]store.Command, 0, len(batch))
+	for _, c := range batch {
+		k := key{c.Source, c.User, c.Command, c.RequestedAt.UnixMicro()}
+		if have[k] > 0 {
+			have[k]--
+			continue
+		}
+		out = append(out, c)
+	}
+	if skipped := len(batch) - len(out); skipped > 0 {
+		fmt.Fprintf(m.out, "commands: skipped %d already in the target\n", skipped)
+	}
+	return out, nil
+}
+
+// copyInverterStates copies the last commanded state of each inverter. Saving
+// replaces the stored state, so it is repeated on every run.
+func (m *migrator) copyInverterStates(ctx context.Context) error {
+	states, err := m.src.GetInverterStates(ctx)
+	if err != nil {
+		return err
+	}
+	for _, st := range states {
+		if err := m.dst.SaveInverterState(ctx, st); err != nil {
+			return fmt.Errorf("save %s: %w", st.ID, err)
+		}
+	}
+	fmt.Fprintf(m.out, "inverter states: %d copied\n", len(states))
+	return nil
+}
+
+// verify prints the source and target counts and fails when the target has
+// fewer of anything than the source. The target may hold more if it already
+// had data of its own.
+func (m *migrator) verify(ctx context.Context) error {
+	type count struct {
+		name     string
+		src, dst int
+	}
+	var counts []count
+
+	srcDevices, err := m.src.ListDevices(ctx)
+	if err != nil {
+		return err
+	}
+	dstDevices, err := m.dst.ListDevices(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"devices", len(srcDevices), len(dstDevices)})
+
+	srcUsers, err := m.src.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	dstUsers, err := m.dst.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"users", len(srcUsers), len(dstUsers)})
+
+	srcProps, err := m.src.CountProperties(ctx)
+	if err != nil {
+		return err
+	}
+	dstProps, err := m.dst.CountProperties(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"readings", srcProps, dstProps})
+
+	srcSensors, err := m.src.GetSensors(ctx)
+	if err != nil {
+		return err
+	}
+	dstSensors, err := m.dst.GetSensors(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"sensors", len(srcSensors), len(dstSensors)})
+
+	srcRollups, err := m.src.CountRollups(ctx)
+	if err != nil {
+		return err
+	}
+	dstRollups, err := m.dst.CountRollups(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"rollups", srcRollups, dstRollups})
+
+	srcCommands, err := m.src.CountCommands(ctx)
+	if err != nil {
+		return err
+	}
+	dstCommands, err := m.dst.CountCommands(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"commands", srcCommands, dstCommands})
+
+	srcStates, err := m.src.GetInverterStates(ctx)
+	if err != nil {
+		return err
+	}
+	dstStates, err := m.dst.GetInverterStates(ctx)
+	if err != nil {
+		return err
+	}
+	counts = append(counts, count{"inverters", len(srcStates), len(dstStates)})
+
+	var short []string
+	fmt.Fprintf(m.out, "%-10s %12s %12s\n", "", "source", "target")
+	for _, c := range counts {
+		fmt.Fprintf(m.out, "%-10s %12d %12d\n", c.name, c.src, c.dst)
+		if c.dst < c.src {
+			short = append(short, c.name)
+		}
+	}
+	if len(short) > 0 {
+		return fmt.Errorf("verification failed: target has fewer %v than the source", short)
+	}
+	return nil
+}
+
+// save persists the target when it is the memory store, so the snapshot is
+// never behind the checkpoint.
+func (m *migrator) save() error {
+	if sv, ok := m.dst.(saver); ok {
+		return sv.Save()
+	}
+	return nil
+}
+
+func (m *migrator) load() (checkpoint, error) {
+	var cp checkpoint
+	data, err := os.ReadFile(m.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, fmt.Errorf("read state: %w", err)
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return cp, fmt.Errorf("decode state %s: %w", m.statePath, err)
+	}
+	return cp, nil
+}
+
+// store writes cp to a temporary file renamed into place, so a crash leaves
+// the previous checkpoint intact.
+func (m *migrator) store(cp checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := m.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := os.Rename(tmp, m.statePath); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return nil
+}
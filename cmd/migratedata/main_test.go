@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	memstore "github.com/anicoll/winet-integration/internal/pkg/store/memory"
+)
+
+// seedSource returns a memory store holding a device, a user with one live
+// and one expired refresh token, two audit log entries, an inverter state,
+// n readings and a rollup whose readings retention has already deleted.
+func seedSource(t *testing.T, n int) *memstore.Store {
+	t.Helper()
+	ctx := context.Background()
+	src := memstore.New()
+	require.NoError(t, src.RegisterDevice(ctx, &model.Device{ID: "SN-1", Model: "SH10RT", SerialNumber: "A123"}))
+
+	// A user created first in the source only, so IDs differ between stores.
+	_, err := src.CreateUser(ctx, "old", "$2a$10$old")
+	require.NoError(t, err)
+	user, err := src.CreateUser(ctx, "alice", "$2a$10$alice")
+	require.NoError(t, err)
+	require.NoError(t, src.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+		TokenHash: "live", UserID: user.ID, Username: user.Username, ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, src.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+		TokenHash: "expired", UserID: user.ID, Username: user.Username, ExpiresAt: time.Now().Add(-time.Hour),
+	}))
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := make([]publisher.DataPoint, n)
+	for i := range data {
+		data[i] = publisher.DataPoint{
+			Timestamp: start.Add(time.Duration(i) * time.Minute), UnitOfMeasurement: "W",
+			Value: "100", Identifier: "SN-1", Slug: "load_power", Name: "Load Power",
+		}
+	}
+	require.NoError(t, src.Write(ctx, data))
+
+	// History from before the readings: only its rollup and catalog entry
+	// are left.
+	require.NoError(t, src.ImportRollups(ctx, []store.Rollup{{
+		Identifier: "SN-1", Slug: "load_power", Resolution: 24 * time.Hour,
+		Aggregate: store.Aggregate{
+			BucketStart: start.AddDate(0, 0, -30), UnitOfMeasurement: "W",
+			Min: 50, Max: 150, Sum: 1000, Count: 10, Last: 80, LastTimeStamp: start.AddDate(0, 0, -30).Add(time.Hour),
+		},
+	}}))
+	require.NoError(t, src.ImportSensors(ctx, []store.Sensor{{
+		Identifier: "SN-1", Slug: "load_power", UnitOfMeasurement: "W",
+		FirstSeen: start.AddDate(0, 0, -30), LastSeen: start.AddDate(0, 0, -30), LastValue: "80",
+	}}))
+
+	for _, c := range []string{"charge", "self_consumption"} {
+		require.NoError(t, src.RecordCommand(ctx, store.Command{
+			RequestedAt: start, Source: "api", User: "alice", Command: c, Success: true, Latency: time.Second,
+		}))
+	}
+	require.NoError(t, src.SaveInverterState(ctx, store.InverterState{
+		ID: "SN-1", State: "charging", BatteryState: "CHARGE", ChargeRate: 2.5, UpdatedAt: start,
+	}))
+	return src
+}
+
+func newTarget(t *testing.T) *memstore.Store {
+	t.Helper()
+	dst, err := memstore.Open(filepath.Join(t.TempDir(), "target.json"), time.Minute)
+	require.NoError(t, err)
+	return dst
+}
+
+func TestMigrate_CopiesEverything(t *testing.T) {
+	ctx := context.Background()
+	src, dst := seedSource(t, 25), newTarget(t)
+	m := &migrator{src: src, dst: dst, batchSize: 10, statePath: filepath.Join(t.TempDir(), "state.json"), out: io.Discard}
+
+	require.NoError(t, m.migrate(ctx))
+	require.NoError(t, m.verify(ctx))
+
+	devices, err := dst.ListDevices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Device{{ID: "SN-1", Model: "SH10RT", SerialNumber: "A123"}}, devices)
+
+	alice, err := dst.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "$2a$10$alice", alice.PasswordHash)
+
+	tok, err := dst.GetRefreshToken(ctx, "live")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, tok.UserID, "token points at the target user ID")
+	_, err = dst.GetRefreshToken(ctx, "expired")
+	assert.Error(t, err, "expired tokens are not copied")
+
+	n, err := dst.CountProperties(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 25, n)
+
+	sensors, err := dst.GetSensors(ctx)
+	require.NoError(t, err)
+	require.Len(t, sensors, 1)
+	assert.Equal(t, "Load Power", sensors[0].Name, "names are only copied with the catalog")
+	assert.True(t, sensors[0].FirstSeen.Equal(time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC)), "first seen comes from the source catalog")
+	assert.Equal(t, "100", sensors[0].LastValue)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	days, err := dst.GetAggregates(ctx, "SN-1", "load_power", 24*time.Hour, start.AddDate(0, 0, -31), start.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, days, 2, "the retained history and the copied day")
+	assert.Equal(t, 10, days[0].Count)
+	assert.Equal(t, 25, days[1].Count)
+
+	cmds, err := dst.GetCommands(ctx, store.CommandFilter{})
+	require.NoError(t, err)
+	require.Len(t, cmds, 2)
+	assert.Equal(t, "self_consumption", cmds[0].Command)
+
+	states, err := dst.GetInverterStates(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "charging", states[0].State)
+
+	cp, err := m.load()
+	require.NoError(t, err)
+	assert.Equal(t, 25, cp.Properties)
+	assert.Equal(t, 2, cp.Commands)
+	assert.Equal(t, 30, cp.Rollups, "25 minutes, 2 quarter hours, an hour and a day, plus the retained bucket")
+}
+
+func TestMigrate_ResumesWithoutDuplicates(t *testing.T) {
+	ctx := context.Background()
+	src, dst := seedSource(t, 25), newTarget(t)
+	m := &migrator{src: src, dst: dst, batchSize: 10, statePath: filepath.Join(t.TempDir(), "state.json"), out: io.Discard}
+
+	// Simulate a run that wrote the first batch but stopped before saving
+	// its checkpoint.
+	first, err := src.ScanProperties(ctx, 0, 10)
+	require.NoError(t, err)
+	data := make([]publisher.DataPoint, len(first))
+	for i, p := range first {
+		data[i] = publisher.DataPoint{Value: p.Value, Slug: p.Slug, Timestamp: p.TimeStamp, Identifier: p.Identifier, UnitOfMeasurement: p.UnitOfMeasurement}
+	}
+	require.NoError(t, dst.Write(ctx, data))
+	// Likewise for the first audit log entry.
+	cmds, err := src.ScanCommands(ctx, 0, 1)
+	require.NoError(t, err)
+	require.NoError(t, dst.RecordCommand(ctx, cmds[0]))
+
+	require.NoError(t, m.migrate(ctx))
+	n, err := dst.CountProperties(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 25, n)
+	n, err = dst.CountCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// Running again finds nothing new.
+	require.NoError(t, m.migrate(ctx))
+	n, err = dst.CountProperties(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 25, n)
+	n, err = dst.CountCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = dst.CountRollups(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 30, n)
+	require.NoError(t, m.verify(ctx))
+}
+
+func TestVerify_FailsWhenTargetIsShort(t *testing.T) {
+	ctx := context.Background()
+	m := &migrator{src: seedSource(t, 5), dst: newTarget(t), out: io.Discard}
+
+	err := m.verify(ctx)
+	require.Error(t, err)
+	for _, name := range []string{"readings", "sensors", "rollups", "commands", "inverters"} {
+		assert.Contains(t, err.Error(), name)
+	}
+}
@@ -0,0 +1,107 @@
+// Package audit persists inverter and battery commands to the store's
+// command audit log. Events arrive through winet's command hook, which must
+// not block, so they are queued and written from Run.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+const (
+	queueSize = 256
+	// drainTimeout bounds the final writes on shutdown.
+	drainTimeout = 5 * time.Second
+)
+
+// Store is the part of store.Store the recorder writes to.
+type Store interface {
+	RecordCommand(ctx context.Context, cmd store.Command) error
+}
+
+// Recorder writes command events to a Store.
+type Recorder struct {
+	store  Store
+	logger *zap.Logger
+	queue  chan model.CommandEvent
+}
+
+// New returns a Recorder writing to st. Call Run to start writing.
+func New(st Store) *Recorder {
+	return &Recorder{
+		store:  st,
+		logger: zap.L(),
+		queue:  make(chan model.CommandEvent, queueSize),
+	}
+}
+
+// CommandHook queues a command event. It is non-blocking and suitable for
+// winet's SetCommandHook; events are dropped if the queue is full.
+func (r *Recorder) CommandHook(event model.CommandEvent) {
+	select {
+	case r.queue <- event:
+	default:
+		r.logger.Warn("audit: queue full, dropping command", zap.String("command", event.Command))
+	}
+}
+
+// Run writes queued events until ctx is cancelled, then writes whatever is
+// still queued.
+func (r *Recorder) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			r.drain()
+			return ctx.Err()
+		case event := <-r.queue:
+			r.record(ctx, event)
+		}
+	}
+}
+
+func (r *Recorder) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	for {
+		select {
+		case event := <-r.queue:
+			r.record(ctx, event)
+		default:
+			return
+		}
+	}
+}
+
+func (r *Recorder) record(ctx context.Context, event model.CommandEvent) {
+	if err := r.store.RecordCommand(ctx, ToCommand(event)); err != nil {
+		r.logger.Error("audit: record command", zap.String("command", event.Command), zap.Error(err))
+	}
+}
+
+// ToCommand converts a command event to an audit log entry. The event is
+// stamped when WiNet replied, so the request time is that less the latency.
+// Only the API and MQTT paths tag their commands with an origin; anything
+// else was issued by the service itself and is recorded as automation.
+func ToCommand(event model.CommandEvent) store.Command {
+	latency := time.Duration(event.LatencyMs) * time.Millisecond
+	source := event.Source
+	if source == "" {
+		source = model.CommandSourceAutomation
+	}
+	return store.Command{
+		RequestedAt: event.Timestamp.Add(-latency),
+		Source:      string(source),
+		User:        event.User,
+		Command:     event.Command,
+		Params:      event.Params,
+		Success:     event.Success,
+		ResultCode:  event.ResultCode,
+		Error:       event.Error,
+		Latency:     latency,
+	}
+}
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+)
+
+func TestRecorder_WritesQueuedCommandsOnShutdown(t *testing.T) {
+	st := memory.New()
+	r := New(st)
+
+	replied := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	r.CommandHook(model.CommandEvent{
+		Command:    "discharge",
+		Params:     map[string]string{"power": "5.0"},
+		Source:     model.CommandSourceAPI,
+		User:       "alice",
+		Success:    true,
+		ResultCode: 1,
+		Timestamp:  replied,
+		LatencyMs:  250,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, r.Run(ctx), context.Canceled)
+
+	got, err := st.GetCommands(context.Background(), store.CommandFilter{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "discharge", got[0].Command)
+	assert.Equal(t, "api", got[0].Source)
+	assert.Equal(t, "alice", got[0].User)
+	assert.Equal(t, map[string]string{"power": "5.0"}, got[0].Params)
+	assert.Equal(t, 1, got[0].ResultCode)
+	assert.Equal(t, 250*time.Millisecond, got[0].Latency)
+	assert.True(t, replied.Add(-250*time.Millisecond).Equal(got[0].RequestedAt), "requested before WiNet replied")
+}
+
+func TestRecorder_RecordsCommandsWithoutOriginAsAutomation(t *testing.T) {
+	st := memory.New()
+	r := New(st)
+	r.CommandHook(model.CommandEvent{Command: "self_consumption", Success: true, Timestamp: time.Now()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, r.Run(ctx), context.Canceled)
+
+	got, err := st.GetCommands(context.Background(), store.CommandFilter{Source: "automation"})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "self_consumption", got[0].Command)
+	assert.Empty(t, got[0].User)
+}
+
+func TestRecorder_CommandHookDoesNotBlockWhenFull(t *testing.T) {
+	r := New(memory.New())
+
+	done := make(chan struct{})
+	go func() {
+		for range queueSize + 1 {
+			r.CommandHook(model.CommandEvent{Command: "battery_stop"})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("CommandHook blocked with a full queue")
+	}
+	assert.Len(t, r.queue, queueSize)
+}
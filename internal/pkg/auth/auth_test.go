@@ -2,24 +2,21 @@ package auth
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 
-	"github.com/anicoll/winet-integration/internal/pkg/store"
-	authmocks "github.com/anicoll/winet-integration/mocks/auth"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
 )
 
 const (
 	testSecret   = "test-secret-that-is-long-enough-32c"
 	testPassword = "correct-password-123"
 	testUsername = "alice"
-	testUserID   = 42
+	testUserID   = 1 // the first ID the memory store assigns
 )
 
 // hashForTest uses minimum bcrypt cost so tests run fast.
@@ -30,31 +27,19 @@ func hashForTest(t *testing.T, pw string) string {
 	return string(h)
 }
 
-// noopTokenStore returns a TokenStore mock that accepts any call and returns zero values.
-func noopTokenStore(t *testing.T) *authmocks.TokenStore {
+// validUserStore returns a memory store holding the test user; it serves as
+// both the user and the refresh token store.
+func validUserStore(t *testing.T) *memory.Store {
 	t.Helper()
-	ts := authmocks.NewTokenStore(t)
-	ts.EXPECT().StoreRefreshToken(mock.Anything, mock.Anything).Return(nil).Maybe()
-	ts.EXPECT().GetRefreshToken(mock.Anything, mock.Anything).Return(store.RefreshToken{}, errors.New("not found")).Maybe()
-	ts.EXPECT().DeleteRefreshToken(mock.Anything, mock.Anything).Return(nil).Maybe()
-	ts.EXPECT().DeleteExpiredRefreshTokens(mock.Anything).Return(nil).Maybe()
-	return ts
-}
-
-func validUserStore(t *testing.T) *authmocks.UserStore {
-	t.Helper()
-	us := authmocks.NewUserStore(t)
-	us.EXPECT().GetUserByUsername(mock.Anything, mock.Anything).Return(store.User{
-		ID:           testUserID,
-		Username:     testUsername,
-		PasswordHash: hashForTest(t, testPassword),
-	}, nil).Maybe()
-	return us
+	st := memory.New()
+	_, err := st.CreateUser(context.Background(), testUsername, hashForTest(t, testPassword))
+	require.NoError(t, err)
+	return st
 }
 
-func newTestService(t *testing.T, userStore UserStore) *Service {
+func newTestService(t *testing.T, st *memory.Store) *Service {
 	t.Helper()
-	return NewService(testSecret, 15*time.Minute, 24*time.Hour, userStore, noopTokenStore(t))
+	return NewService(testSecret, 15*time.Minute, 24*time.Hour, st, st)
 }
 
 // --- Login ---
@@ -70,9 +55,7 @@ func TestLogin_Success(t *testing.T) {
 }
 
 func TestLogin_UnknownUser(t *testing.T) {
-	us := authmocks.NewUserStore(t)
-	us.EXPECT().GetUserByUsername(mock.Anything, mock.Anything).Return(store.User{}, errors.New("not found"))
-	svc := newTestService(t, us)
+	svc := newTestService(t, memory.New())
 
 	_, _, err := svc.Login(context.Background(), "nobody", testPassword)
 
@@ -112,6 +95,19 @@ func TestRefresh_Success(t *testing.T) {
 	assert.NotEmpty(t, newAccess)
 }
 
+func TestRefresh_AfterRestartReadsTokenStore(t *testing.T) {
+	st := validUserStore(t)
+	_, refreshToken, err := newTestService(t, st).Login(context.Background(), testUsername, testPassword)
+	require.NoError(t, err)
+
+	// A new service has an empty cache and must find the token in the store.
+	newAccess, err := newTestService(t, st).Refresh(context.Background(), refreshToken)
+	require.NoError(t, err)
+	claims, err := newTestService(t, st).ValidateAccessToken(newAccess)
+	require.NoError(t, err)
+	assert.Equal(t, testUserID, claims.UserID)
+}
+
 func TestRefresh_UnknownToken(t *testing.T) {
 	svc := newTestService(t, validUserStore(t))
 
@@ -122,7 +118,8 @@ func TestRefresh_UnknownToken(t *testing.T) {
 
 func TestRefresh_ExpiredToken(t *testing.T) {
 	// Create a service with a zero refresh TTL so the token is already expired.
-	svc := NewService(testSecret, 15*time.Minute, -time.Second, validUserStore(t), noopTokenStore(t))
+	st := validUserStore(t)
+	svc := NewService(testSecret, 15*time.Minute, -time.Second, st, st)
 	_, refreshToken, err := svc.Login(context.Background(), testUsername, testPassword)
 	require.NoError(t, err)
 
@@ -180,7 +177,8 @@ func TestValidateAccessToken_Valid(t *testing.T) {
 
 func TestValidateAccessToken_Expired(t *testing.T) {
 	// Zero access TTL means the token expires immediately.
-	svc := NewService(testSecret, -time.Second, 24*time.Hour, validUserStore(t), noopTokenStore(t))
+	st := validUserStore(t)
+	svc := NewService(testSecret, -time.Second, 24*time.Hour, st, st)
 	accessToken, _, err := svc.Login(context.Background(), testUsername, testPassword)
 	require.NoError(t, err)
 
@@ -191,7 +189,8 @@ func TestValidateAccessToken_Expired(t *testing.T) {
 
 func TestValidateAccessToken_WrongSecret(t *testing.T) {
 	svcA := newTestService(t, validUserStore(t))
-	svcB := NewService("a-completely-different-secret-xyz", 15*time.Minute, 24*time.Hour, validUserStore(t), noopTokenStore(t))
+	stB := validUserStore(t)
+	svcB := NewService("a-completely-different-secret-xyz", 15*time.Minute, 24*time.Hour, stB, stB)
 
 	tokenFromA, _, err := svcA.Login(context.Background(), testUsername, testPassword)
 	require.NoError(t, err)
@@ -8,16 +8,36 @@ import (
 
 // Config holds all application configuration populated from environment variables.
 type Config struct {
-	WinetCfg         WinetConfig
-	MqttCfg          MQTTConfig
-	AuthCfg          AuthConfig
+	WinetCfg     WinetConfig
+	MqttCfg      MQTTConfig
+	InfluxCfg    InfluxConfig
+	WebhookCfg   WebhookConfig
+	FileSinkCfg  FileSinkConfig
+	PVOutputCfg  PVOutputConfig
+	RetentionCfg RetentionConfig
+	TariffCfg    TariffConfig
+	ReportCfg    ReportConfig
+	AuthCfg      AuthConfig
+	DualWriteCfg DualWriteConfig
+	DatabaseConfig
+	LogLevel       string   `env:"LOG_LEVEL"          envDefault:"info"`
+	Timezone       string   `env:"TIMEZONE"           envDefault:"Australia/Adelaide"`
+	MetricsEnabled bool     `env:"METRICS_ENABLED"    envDefault:"true"`
+	RoutesFile     string   `env:"PUBLISHER_ROUTES_FILE"`
+	AllowedOrigins []string `env:"ALLOWED_ORIGIN,required" envSeparator:","`
+}
+
+// DatabaseConfig selects and configures the store backend. It is embedded in
+// Config; the data tools load it on its own with LoadDatabase.
+type DatabaseConfig struct {
 	OracleCfg        OracleConfig
-	LogLevel         string   `env:"LOG_LEVEL"          envDefault:"info"`
-	DBDriver         string   `env:"DB_DRIVER"          envDefault:"postgres"`
-	DBDSN            string   `env:"DATABASE_URL"`
-	MigrationsFolder string   `env:"MIGRATIONS_FOLDER"  envDefault:""`
-	Timezone         string   `env:"TIMEZONE"           envDefault:"Australia/Adelaide"`
-	AllowedOrigins   []string `env:"ALLOWED_ORIGIN,required" envSeparator:","`
+	TimescaleCfg     TimescaleConfig
+	SQLiteCfg        SQLiteConfig
+	MemoryCfg        MemoryConfig
+	DBDriver         string `env:"DB_DRIVER"         envDefault:"postgres"`
+	DBDSN            string `env:"DATABASE_URL"`
+	MigrationsFolder string `env:"MIGRATIONS_FOLDER" envDefault:""`
+	AutoMigrate      bool   `env:"DB_AUTO_MIGRATE"   envDefault:"true"`
 }
 
 // Load parses configuration from environment variables.
@@ -30,6 +50,17 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// LoadDatabase parses only the database settings, with every variable name
+// prefixed by prefix: LoadDatabase("TARGET_") reads TARGET_DB_DRIVER,
+// TARGET_DATABASE_URL, TARGET_ORACLE_HOST and so on.
+func LoadDatabase(prefix string) (*DatabaseConfig, error) {
+	cfg := &DatabaseConfig{}
+	if err := env.ParseWithOptions(cfg, env.Options{Prefix: prefix}); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 type WinetConfig struct {
 	Host         string        `env:"WINET_HOST,required"`
 	Username     string        `env:"WINET_USERNAME,required"`
@@ -42,6 +73,98 @@ type MQTTConfig struct {
 	Host     string `env:"MQTT_HOST"`
 	Username string `env:"MQTT_USERNAME"`
 	Password string `env:"MQTT_PASSWORD"`
+	Snapshot bool   `env:"MQTT_SNAPSHOT"`
+}
+
+// InfluxConfig holds InfluxDB v2 write API parameters.
+// Publishing is enabled when URL is set; Org and Bucket are then required.
+type InfluxConfig struct {
+	URL           string        `env:"INFLUX_URL"`
+	Token         string        `env:"INFLUX_TOKEN"`
+	Org           string        `env:"INFLUX_ORG"`
+	Bucket        string        `env:"INFLUX_BUCKET"`
+	Measurement   string        `env:"INFLUX_MEASUREMENT"    envDefault:"winet"`
+	BatchSize     int           `env:"INFLUX_BATCH_SIZE"     envDefault:"500"`
+	FlushInterval time.Duration `env:"INFLUX_FLUSH_INTERVAL" envDefault:"10s"`
+	MaxRetries    int           `env:"INFLUX_MAX_RETRIES"    envDefault:"3"`
+}
+
+// FileSinkConfig holds rolling file sink parameters.
+// Dir enables the sink; Retention is the number of daily files kept (0 keeps all).
+type FileSinkConfig struct {
+	Dir         string `env:"FILESINK_DIR"`
+	Format      string `env:"FILESINK_FORMAT"      envDefault:"csv"`
+	Compression string `env:"FILESINK_COMPRESSION"`
+	Retention   int    `env:"FILESINK_RETENTION"   envDefault:"30"`
+}
+
+// PVOutputConfig holds PVOutput.org status upload parameters.
+// Uploading is enabled when APIKey is set; SystemID is then required.
+// The *Slug fields map DataPoint slugs onto PVOutput's status values; an empty
+// slug leaves that value out. Identifier restricts readings to one device.
+type PVOutputConfig struct {
+	APIKey                string        `env:"PVOUTPUT_API_KEY"`
+	SystemID              string        `env:"PVOUTPUT_SYSTEM_ID"`
+	URL                   string        `env:"PVOUTPUT_URL"                     envDefault:"https://pvoutput.org"`
+	Interval              time.Duration `env:"PVOUTPUT_INTERVAL"                envDefault:"5m"`
+	Donation              bool          `env:"PVOUTPUT_DONATION"`
+	Identifier            string        `env:"PVOUTPUT_IDENTIFIER"`
+	EnergyGenerationSlug  string        `env:"PVOUTPUT_ENERGY_GENERATION_SLUG"  envDefault:"daily_pv_yield"`
+	PowerGenerationSlug   string        `env:"PVOUTPUT_POWER_GENERATION_SLUG"   envDefault:"total_dc_power"`
+	EnergyConsumptionSlug string        `env:"PVOUTPUT_ENERGY_CONSUMPTION_SLUG" envDefault:"daily_load_consumption"`
+	PowerConsumptionSlug  string        `env:"PVOUTPUT_POWER_CONSUMPTION_SLUG"  envDefault:"load_power"`
+	TemperatureSlug       string        `env:"PVOUTPUT_TEMPERATURE_SLUG"        envDefault:"internal_air_temperature"`
+	VoltageSlug           string        `env:"PVOUTPUT_VOLTAGE_SLUG"            envDefault:"mppt1_voltage"`
+}
+
+// WebhookConfig holds outbound webhook parameters.
+// Delivery is enabled when at least one URL is set.
+type WebhookConfig struct {
+	URLs        []string      `env:"WEBHOOK_URLS"         envSeparator:","`
+	Secret      string        `env:"WEBHOOK_SECRET"`
+	Slugs       []string      `env:"WEBHOOK_SLUGS"        envSeparator:","`
+	Events      []string      `env:"WEBHOOK_EVENTS"       envSeparator:"," envDefault:"readings,device,command"`
+	BatchWindow time.Duration `env:"WEBHOOK_BATCH_WINDOW" envDefault:"30s"`
+	MaxRetries  int           `env:"WEBHOOK_MAX_RETRIES"  envDefault:"5"`
+}
+
+// RetentionConfig holds the scheduled data retention parameters. Schedule is a
+// standard cron expression evaluated in TIMEZONE. Slugs overrides Raw per slug
+// ("slug:720h,..."); Rollups sets retention per rollup resolution
+// ("1m:192h,1h:8760h"). A zero duration keeps data forever. Retention deletes
+// readings, so it only runs once Enabled is set.
+type RetentionConfig struct {
+	Enabled  bool                     `env:"RETENTION_ENABLED"`
+	Schedule string                   `env:"RETENTION_SCHEDULE" envDefault:"0 3 * * *"`
+	Raw      time.Duration            `env:"RETENTION_RAW"      envDefault:"192h"`
+	Slugs    map[string]time.Duration `env:"RETENTION_SLUGS"`
+	Rollups  map[string]time.Duration `env:"RETENTION_ROLLUPS"  envDefault:"1m:192h"`
+}
+
+// TariffConfig prices the energy in GET /reports/energy. Rates are per kWh.
+// ImportPeriods overrides ImportRate within windows of local time
+// ("16:00-21:00=0.45,22:00-07:00=0.18"); windows may cross midnight but must
+// not overlap. DailyCharge is the fixed supply charge per day.
+type TariffConfig struct {
+	Currency      string             `env:"TARIFF_CURRENCY"       envDefault:"AUD"`
+	ImportRate    float64            `env:"TARIFF_IMPORT_RATE"`
+	ImportPeriods map[string]float64 `env:"TARIFF_IMPORT_PERIODS" envKeyValSeparator:"="`
+	ExportRate    float64            `env:"TARIFF_EXPORT_RATE"`
+	DailyCharge   float64            `env:"TARIFF_DAILY_CHARGE"`
+}
+
+// ReportConfig maps the inverter's daily energy counters, which reset at
+// local midnight, onto GET /reports/energy. An empty slug leaves that value
+// at zero. Identifier restricts the report to one device; otherwise every
+// device reporting a slug is summed.
+type ReportConfig struct {
+	Identifier      string `env:"REPORT_IDENTIFIER"`
+	PVSlug          string `env:"REPORT_PV_SLUG"          envDefault:"daily_pv_yield"`
+	ConsumptionSlug string `env:"REPORT_CONSUMPTION_SLUG" envDefault:"daily_load_consumption"`
+	ImportSlug      string `env:"REPORT_IMPORT_SLUG"      envDefault:"daily_purchased_energy"`
+	ExportSlug      string `env:"REPORT_EXPORT_SLUG"      envDefault:"daily_feed_in_energy"`
+	ChargeSlug      string `env:"REPORT_CHARGE_SLUG"      envDefault:"daily_battery_charging_energy"`
+	DischargeSlug   string `env:"REPORT_DISCHARGE_SLUG"   envDefault:"daily_battery_discharging_energy"`
 }
 
 type AuthConfig struct {
@@ -51,6 +174,29 @@ type AuthConfig struct {
 	SecureCookies   bool          `env:"SECURE_COOKIES"   envDefault:"true"`
 }
 
+// TimescaleConfig enables TimescaleDB features when DB_DRIVER=postgres. If the
+// server does not have the timescaledb extension the store falls back to
+// plain Postgres. RefreshWindow must be shorter than every raw retention.
+type TimescaleConfig struct {
+	Enabled       bool          `env:"TIMESCALE_ENABLED"`
+	CompressAfter time.Duration `env:"TIMESCALE_COMPRESS_AFTER" envDefault:"168h"`
+	RefreshWindow time.Duration `env:"TIMESCALE_REFRESH_WINDOW" envDefault:"48h"`
+}
+
+// DualWriteConfig makes the service write to secondary databases as well as
+// the primary one while moving between backends. Each entry of Secondaries is
+// the variable prefix of a database loaded with LoadDatabase, e.g. "OLD_"
+// reads OLD_DB_DRIVER, OLD_DATABASE_URL and so on. Policy is "best-effort"
+// (only the primary must succeed; secondaries catch up from a queue of
+// QueueSize writes) or "all" (every store must succeed).
+type DualWriteConfig struct {
+	Secondaries   []string      `env:"DUAL_WRITE_SECONDARIES"     envSeparator:","`
+	Policy        string        `env:"DUAL_WRITE_POLICY"          envDefault:"best-effort"`
+	QueueSize     int           `env:"DUAL_WRITE_QUEUE_SIZE"      envDefault:"10000"`
+	RetryInterval time.Duration `env:"DUAL_WRITE_RETRY_INTERVAL"  envDefault:"30s"`
+	MaxAttempts   int           `env:"DUAL_WRITE_MAX_ATTEMPTS"    envDefault:"10"`
+}
+
 // OracleConfig holds Oracle connection parameters for SSL connections.
 // Used when DB_DRIVER=oracle. Fields are validated at connection time, not at Load().
 type OracleConfig struct {
@@ -62,3 +208,15 @@ type OracleConfig struct {
 	SSLVerify  bool   `env:"ORACLE_SSL_VERIFY" envDefault:"true"`
 	WalletPath string `env:"ORACLE_WALLET"`
 }
+
+// SQLiteConfig holds the SQLite database location. Used when DB_DRIVER=sqlite.
+type SQLiteConfig struct {
+	Path string `env:"SQLITE_PATH" envDefault:"winet.db"`
+}
+
+// MemoryConfig configures the in-memory store used when DB_DRIVER=memory.
+// Without a SnapshotPath everything is lost on exit.
+type MemoryConfig struct {
+	SnapshotPath     string        `env:"MEMORY_SNAPSHOT_PATH"`
+	SnapshotInterval time.Duration `env:"MEMORY_SNAPSHOT_INTERVAL" envDefault:"1m"`
+}
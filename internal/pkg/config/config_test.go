@@ -53,9 +53,51 @@ func TestLoad_Defaults(t *testing.T) {
 
 	assert.Equal(t, "info", cfg.LogLevel)
 	assert.Equal(t, "", cfg.MigrationsFolder)
+	assert.True(t, cfg.AutoMigrate)
 	assert.Equal(t, "Australia/Adelaide", cfg.Timezone)
 	assert.Equal(t, 30*time.Second, cfg.WinetCfg.PollInterval)
 	assert.False(t, cfg.WinetCfg.Ssl)
+	assert.True(t, cfg.MetricsEnabled)
+	assert.Empty(t, cfg.InfluxCfg.URL)
+	assert.Equal(t, "winet", cfg.InfluxCfg.Measurement)
+	assert.Equal(t, 500, cfg.InfluxCfg.BatchSize)
+	assert.Equal(t, 10*time.Second, cfg.InfluxCfg.FlushInterval)
+	assert.Empty(t, cfg.FileSinkCfg.Dir)
+	assert.Equal(t, "csv", cfg.FileSinkCfg.Format)
+	assert.Empty(t, cfg.FileSinkCfg.Compression)
+	assert.Equal(t, 30, cfg.FileSinkCfg.Retention)
+	assert.Empty(t, cfg.PVOutputCfg.APIKey)
+	assert.Equal(t, "https://pvoutput.org", cfg.PVOutputCfg.URL)
+	assert.Equal(t, 5*time.Minute, cfg.PVOutputCfg.Interval)
+	assert.Equal(t, "total_dc_power", cfg.PVOutputCfg.PowerGenerationSlug)
+	assert.Equal(t, "load_power", cfg.PVOutputCfg.PowerConsumptionSlug)
+	assert.Empty(t, cfg.WebhookCfg.URLs)
+	assert.Equal(t, []string{"readings", "device", "command"}, cfg.WebhookCfg.Events)
+	assert.Equal(t, 30*time.Second, cfg.WebhookCfg.BatchWindow)
+	assert.Equal(t, 5, cfg.WebhookCfg.MaxRetries)
+	assert.False(t, cfg.RetentionCfg.Enabled)
+	assert.False(t, cfg.TimescaleCfg.Enabled)
+	assert.Equal(t, 168*time.Hour, cfg.TimescaleCfg.CompressAfter)
+	assert.Equal(t, 48*time.Hour, cfg.TimescaleCfg.RefreshWindow)
+	assert.Equal(t, "winet.db", cfg.SQLiteCfg.Path)
+	assert.Empty(t, cfg.MemoryCfg.SnapshotPath)
+	assert.Equal(t, time.Minute, cfg.MemoryCfg.SnapshotInterval)
+	assert.Equal(t, "0 3 * * *", cfg.RetentionCfg.Schedule)
+	assert.Equal(t, 192*time.Hour, cfg.RetentionCfg.Raw)
+	assert.Empty(t, cfg.RetentionCfg.Slugs)
+	assert.Equal(t, map[string]time.Duration{"1m": 192 * time.Hour}, cfg.RetentionCfg.Rollups)
+	assert.Empty(t, cfg.DualWriteCfg.Secondaries)
+	assert.Equal(t, "best-effort", cfg.DualWriteCfg.Policy)
+	assert.Equal(t, 10000, cfg.DualWriteCfg.QueueSize)
+	assert.Equal(t, 30*time.Second, cfg.DualWriteCfg.RetryInterval)
+	assert.Equal(t, 10, cfg.DualWriteCfg.MaxAttempts)
+	assert.Equal(t, "AUD", cfg.TariffCfg.Currency)
+	assert.Zero(t, cfg.TariffCfg.ImportRate)
+	assert.Empty(t, cfg.TariffCfg.ImportPeriods)
+	assert.Empty(t, cfg.ReportCfg.Identifier)
+	assert.Equal(t, "daily_pv_yield", cfg.ReportCfg.PVSlug)
+	assert.Equal(t, "daily_purchased_energy", cfg.ReportCfg.ImportSlug)
+	assert.Equal(t, "daily_feed_in_energy", cfg.ReportCfg.ExportSlug)
 }
 
 func TestLoad_MissingWinetHost(t *testing.T) {
@@ -108,7 +150,12 @@ func TestLoad_CustomValues(t *testing.T) {
 	t.Setenv("MQTT_HOST", "mqtt://broker:1883")
 	t.Setenv("MQTT_USERNAME", "mqttuser")
 	t.Setenv("MQTT_PASSWORD", "mqttpass")
+	t.Setenv("MQTT_SNAPSHOT", "true")
 	t.Setenv("MIGRATIONS_FOLDER", "/custom/migrations")
+	t.Setenv("DB_AUTO_MIGRATE", "false")
+	t.Setenv("RETENTION_SLUGS", "battery_power:720h,load_power:0s")
+	t.Setenv("TARIFF_IMPORT_RATE", "0.32")
+	t.Setenv("TARIFF_IMPORT_PERIODS", "16:00-21:00=0.45,22:00-07:00=0.18")
 
 	cfg, err := Load()
 	require.NoError(t, err)
@@ -120,5 +167,38 @@ func TestLoad_CustomValues(t *testing.T) {
 	assert.Equal(t, "mqtt://broker:1883", cfg.MqttCfg.Host)
 	assert.Equal(t, "mqttuser", cfg.MqttCfg.Username)
 	assert.Equal(t, "mqttpass", cfg.MqttCfg.Password)
+	assert.True(t, cfg.MqttCfg.Snapshot)
 	assert.Equal(t, "/custom/migrations", cfg.MigrationsFolder)
+	assert.False(t, cfg.AutoMigrate)
+	assert.Equal(t, map[string]time.Duration{"battery_power": 720 * time.Hour, "load_power": 0}, cfg.RetentionCfg.Slugs)
+	assert.InDelta(t, 0.32, cfg.TariffCfg.ImportRate, 1e-9)
+	assert.Equal(t, map[string]float64{"16:00-21:00": 0.45, "22:00-07:00": 0.18}, cfg.TariffCfg.ImportPeriods)
+}
+
+func TestLoadDatabase_Prefix(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("TARGET_DB_DRIVER", "oracle")
+	t.Setenv("TARGET_ORACLE_HOST", "adb.example.com")
+	t.Setenv("TARGET_ORACLE_SERVICE", "winet_high")
+
+	cfg, err := LoadDatabase("TARGET_")
+	require.NoError(t, err)
+
+	assert.Equal(t, "oracle", cfg.DBDriver)
+	assert.Equal(t, "adb.example.com", cfg.OracleCfg.Host)
+	assert.Equal(t, "winet_high", cfg.OracleCfg.Service)
+	assert.Equal(t, 1522, cfg.OracleCfg.Port)
+	assert.Empty(t, cfg.DBDSN, "unprefixed DATABASE_URL is not read")
+}
+
+func TestLoadDatabase_DoesNotRequireServiceSettings(t *testing.T) {
+	unsetenv(t, "WINET_HOST")
+	unsetenv(t, "JWT_SECRET")
+	unsetenv(t, "ALLOWED_ORIGIN")
+	t.Setenv("DB_DRIVER", "sqlite")
+
+	cfg, err := LoadDatabase("")
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", cfg.DBDriver)
+	assert.Equal(t, "winet.db", cfg.SQLiteCfg.Path)
 }
@@ -0,0 +1,231 @@
+// Package control keeps the Inverter table in step with the control state the
+// service puts the inverter into. Successful commands arrive through winet's
+// command hook and set the state; readings arrive through the device status
+// hook and correct it when the inverter reports otherwise, e.g. after a fault
+// or a manual stop. Both hooks must not block, so the state is saved from Run.
+package control
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// Inverter states.
+const (
+	StateOn      = "ON"
+	StateOff     = "OFF"
+	StateFault   = "FAULT"
+	StateUnknown = "UNKNOWN"
+)
+
+// Battery states.
+const (
+	BatteryCharging        = "CHARGING"
+	BatteryDischarging     = "DISCHARGING"
+	BatteryStopped         = "STOPPED"
+	BatterySelfConsumption = "SELF_CONSUMPTION"
+	BatteryUnknown         = "UNKNOWN"
+)
+
+// saveTimeout bounds the final save on shutdown.
+const saveTimeout = 5 * time.Second
+
+// Store is the part of store.Store the tracker reads and writes.
+type Store interface {
+	SaveInverterState(ctx context.Context, st store.InverterState) error
+	GetInverterStates(ctx context.Context) ([]store.InverterState, error)
+}
+
+// Tracker holds the inverter's control state in memory and persists it.
+type Tracker struct {
+	store  Store
+	logger *zap.Logger
+	dirty  chan struct{}
+
+	mu    sync.RWMutex
+	state store.InverterState
+	known bool
+}
+
+// New returns a Tracker backed by st. Call Load to restore the last saved
+// state and Run to persist changes.
+func New(st Store) *Tracker {
+	return &Tracker{
+		store:  st,
+		logger: zap.L(),
+		dirty:  make(chan struct{}, 1),
+	}
+}
+
+// Load restores the most recently updated inverter state, so the service
+// knows which mode it last put the inverter into before any command is sent.
+func (t *Tracker) Load(ctx context.Context) error {
+	states, err := t.store.GetInverterStates(ctx)
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	t.state, t.known = states[0], true
+	t.mu.Unlock()
+	t.logger.Info("restored inverter control state",
+		zap.String("id", states[0].ID),
+		zap.String("state", states[0].State),
+		zap.String("battery_state", states[0].BatteryState))
+	return nil
+}
+
+// State returns the current control state, or false before the first
+// successful command when nothing was restored.
+func (t *Tracker) State() (store.InverterState, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.state, t.known
+}
+
+// CommandHook applies a successful command to the state. It is non-blocking
+// and suitable for winet's SetCommandHook.
+func (t *Tracker) CommandHook(event model.CommandEvent) {
+	if !event.Success || event.Device == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	next := t.state
+	if !t.known || next.ID != event.Device {
+		next = store.InverterState{ID: event.Device, State: StateUnknown, BatteryState: BatteryUnknown}
+	}
+	if !apply(&next, event) {
+		return
+	}
+	next.UpdatedAt = event.Timestamp
+	t.state, t.known = next, true
+	t.markDirty()
+}
+
+// apply sets the fields of st that event changes, reporting false for
+// commands that do not affect the control state.
+func apply(st *store.InverterState, event model.CommandEvent) bool {
+	switch event.Command {
+	case "self_consumption":
+		st.BatteryState, st.ChargeRate = BatterySelfConsumption, 0
+	case "charge":
+		st.BatteryState, st.ChargeRate = BatteryCharging, parseRate(event.Params["power"])
+	case "discharge":
+		st.BatteryState, st.ChargeRate = BatteryDischarging, parseRate(event.Params["power"])
+	case "battery_stop":
+		st.BatteryState, st.ChargeRate = BatteryStopped, 0
+	case "inverter_state":
+		st.State = StateOn
+		if event.Params["disable"] == "true" {
+			st.State = StateOff
+		}
+	case "feedin_limitation":
+		st.FeedinEnabled = event.Params["feedin_limited"] != "true"
+	default:
+		return false
+	}
+	return true
+}
+
+func parseRate(power string) float64 {
+	v, err := strconv.ParseFloat(power, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// DeviceStatusHook reconciles the inverter state with the running status
+// reading of deviceID. It is non-blocking and suitable for winet's
+// SetDeviceStatusHook. Readings of devices other than the commanded inverter,
+// such as a battery's own running status, are ignored. The battery mode has
+// no reading that reliably reflects it, so only the inverter state is
+// reconciled.
+func (t *Tracker) DeviceStatusHook(deviceID string, statuses []model.DeviceStatus) {
+	var observed string
+	for _, st := range statuses {
+		if st.Slug == model.RunningStatusTextSensor.String() && st.Value != nil {
+			observed = stateFromRunningStatus(*st.Value)
+			break
+		}
+	}
+	if observed == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.known || t.state.ID != deviceID || t.state.State == observed {
+		return
+	}
+	t.logger.Warn("inverter state differs from the last command, following the reading",
+		zap.String("id", t.state.ID),
+		zap.String("commanded", t.state.State),
+		zap.String("observed", observed))
+	t.state.State = observed
+	t.state.ReconciledAt = time.Now()
+	t.markDirty()
+}
+
+// stateFromRunningStatus maps the running_status text sensor to an inverter
+// state, or "" when the status says nothing definite (e.g. standby).
+func stateFromRunningStatus(v string) string {
+	v = strings.ToLower(v)
+	switch {
+	case strings.Contains(v, "fault"):
+		return StateFault
+	case strings.Contains(v, "stop"), strings.Contains(v, "shutdown"), v == "off":
+		return StateOff
+	case strings.Contains(v, "run"):
+		return StateOn
+	}
+	return ""
+}
+
+// markDirty asks Run to save the state. Callers must hold t.mu.
+func (t *Tracker) markDirty() {
+	select {
+	case t.dirty <- struct{}{}:
+	default: // a save is already pending
+	}
+}
+
+// Run saves the state whenever it changes until ctx is cancelled, then saves
+// a pending change once more.
+func (t *Tracker) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-t.dirty:
+				saveCtx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+				t.save(saveCtx)
+				cancel()
+			default:
+			}
+			return ctx.Err()
+		case <-t.dirty:
+			t.save(ctx)
+		}
+	}
+}
+
+func (t *Tracker) save(ctx context.Context) {
+	st, ok := t.State()
+	if !ok {
+		return
+	}
+	if err := t.store.SaveInverterState(ctx, st); err != nil {
+		t.logger.Error("control: save inverter state", zap.String("id", st.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,97 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+)
+
+func ptr(s string) *string { return &s }
+
+// runOnce lets Run save any pending change and stop.
+func runOnce(t *testing.T, tr *Tracker) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, tr.Run(ctx), context.Canceled)
+}
+
+func TestTracker_CommandsSetStateAndSurviveRestart(t *testing.T) {
+	st := memory.New()
+	tr := New(st)
+	at := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+
+	tr.CommandHook(model.CommandEvent{Command: "discharge", Device: "SN001", Params: map[string]string{"power": "5.5"}, Success: true, Timestamp: at})
+	tr.CommandHook(model.CommandEvent{Command: "feedin_limitation", Device: "SN001", Params: map[string]string{"feedin_limited": "false"}, Success: true, Timestamp: at.Add(time.Minute)})
+	// Failed commands leave the state alone.
+	tr.CommandHook(model.CommandEvent{Command: "battery_stop", Device: "SN001", Success: false, Timestamp: at.Add(2 * time.Minute)})
+	runOnce(t, tr)
+
+	restarted := New(st)
+	require.NoError(t, restarted.Load(context.Background()))
+	got, ok := restarted.State()
+	require.True(t, ok)
+	assert.Equal(t, "SN001", got.ID)
+	assert.Equal(t, BatteryDischarging, got.BatteryState)
+	assert.InDelta(t, 5.5, got.ChargeRate, 1e-9)
+	assert.True(t, got.FeedinEnabled)
+	assert.Equal(t, StateUnknown, got.State, "no inverter command sent yet")
+	assert.True(t, at.Add(time.Minute).Equal(got.UpdatedAt))
+}
+
+func TestTracker_UnknownBeforeFirstCommand(t *testing.T) {
+	tr := New(memory.New())
+	require.NoError(t, tr.Load(context.Background()))
+
+	_, ok := tr.State()
+	assert.False(t, ok)
+
+	// Readings alone do not create a state.
+	tr.DeviceStatusHook("SN001", []model.DeviceStatus{{Slug: "running_status", Value: ptr("Running")}})
+	_, ok = tr.State()
+	assert.False(t, ok)
+}
+
+func TestTracker_ReconcilesInverterStateWithReadings(t *testing.T) {
+	tr := New(memory.New())
+	tr.CommandHook(model.CommandEvent{Command: "inverter_state", Device: "SN001", Params: map[string]string{"disable": "false"}, Success: true, Timestamp: time.Now()})
+
+	tr.DeviceStatusHook("SN001", []model.DeviceStatus{{Slug: "running_status", Value: ptr("Running")}})
+	got, _ := tr.State()
+	assert.Equal(t, StateOn, got.State)
+	assert.True(t, got.ReconciledAt.IsZero(), "reading agrees with the command")
+
+	tr.DeviceStatusHook("SN001", []model.DeviceStatus{{Slug: "running_status", Value: ptr("Fault")}})
+	got, _ = tr.State()
+	assert.Equal(t, StateFault, got.State)
+	assert.False(t, got.ReconciledAt.IsZero())
+
+	// Ambiguous statuses are ignored.
+	tr.DeviceStatusHook("SN001", []model.DeviceStatus{{Slug: "running_status", Value: ptr("Standby")}})
+	got, _ = tr.State()
+	assert.Equal(t, StateFault, got.State)
+
+	// So are other devices, such as the battery.
+	tr.DeviceStatusHook("SN002", []model.DeviceStatus{{Slug: "running_status", Value: ptr("Running")}})
+	got, _ = tr.State()
+	assert.Equal(t, StateFault, got.State)
+}
+
+func TestStateFromRunningStatus(t *testing.T) {
+	for in, want := range map[string]string{
+		"Running":        StateOn,
+		"Stop":           StateOff,
+		"Emergency Stop": StateOff,
+		"Shutdown":       StateOff,
+		"Fault":          StateFault,
+		"Standby":        "",
+	} {
+		assert.Equal(t, want, stateFromRunningStatus(in), in)
+	}
+}
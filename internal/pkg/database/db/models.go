@@ -10,6 +10,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CommandAudit struct {
+	ID           int       `json:"id"`
+	RequestedAt  time.Time `json:"requested_at"`
+	Source       string    `json:"source"`
+	Username     string    `json:"username"`
+	Command      string    `json:"command"`
+	Params       []byte    `json:"params"`
+	Success      bool      `json:"success"`
+	ResultCode   int       `json:"result_code"`
+	ErrorMessage string    `json:"error_message"`
+	LatencyMs    int64     `json:"latency_ms"`
+}
+
 type Device struct {
 	ID           string             `json:"id"`
 	Model        pgtype.Text        `json:"model"`
@@ -25,6 +38,7 @@ type Inverter struct {
 	FeedinEnabled bool               `json:"feedin_enabled"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	ReconciledAt  pgtype.Timestamptz `json:"reconciled_at"`
 }
 
 type Property struct {
@@ -36,6 +50,30 @@ type Property struct {
 	Slug              string    `json:"slug"`
 }
 
+type PropertyLatest struct {
+	Identifier        string    `json:"identifier"`
+	Slug              string    `json:"slug"`
+	TimeStamp         time.Time `json:"time_stamp"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Value             string    `json:"value"`
+	Name              string    `json:"name"`
+	FirstSeen         time.Time `json:"first_seen"`
+}
+
+type PropertyRollup struct {
+	Resolution        int       `json:"resolution"`
+	BucketStart       time.Time `json:"bucket_start"`
+	Identifier        string    `json:"identifier"`
+	Slug              string    `json:"slug"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	MinValue          float64   `json:"min_value"`
+	MaxValue          float64   `json:"max_value"`
+	SumValue          float64   `json:"sum_value"`
+	SampleCount       int       `json:"sample_count"`
+	LastValue         float64   `json:"last_value"`
+	LastTimeStamp     time.Time `json:"last_time_stamp"`
+}
+
 type RefreshToken struct {
 	TokenHash string             `json:"token_hash"`
 	UserID    int                `json:"user_id"`
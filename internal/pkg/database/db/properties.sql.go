@@ -10,39 +10,75 @@ import (
 	"time"
 )
 
-const cleanupProperties = `-- name: CleanupProperties :exec
-DELETE FROM Property WHERE time_stamp < $1
+type CopyPropertiesParams struct {
+	TimeStamp         time.Time `json:"time_stamp"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Value             string    `json:"value"`
+	Identifier        string    `json:"identifier"`
+	Slug              string    `json:"slug"`
+}
+
+const countProperties = `-- name: CountProperties :one
+SELECT COUNT(*) FROM Property
 `
 
-func (q *Queries) CleanupProperties(ctx context.Context, timeStamp time.Time) error {
-	_, err := q.db.Exec(ctx, cleanupProperties, timeStamp)
-	return err
+func (q *Queries) CountProperties(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countProperties)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteProperties = `-- name: DeleteProperties :execrows
+DELETE FROM Property
+WHERE time_stamp < $1
+  AND ($2::TEXT = '' OR slug = $2::TEXT)
+  AND NOT (slug = ANY(COALESCE($3::TEXT[], '{}')))
+`
+
+type DeletePropertiesParams struct {
+	Before  time.Time `json:"before"`
+	Slug    string    `json:"slug"`
+	Exclude []string  `json:"exclude"`
+}
+
+func (q *Queries) DeleteProperties(ctx context.Context, arg DeletePropertiesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProperties, arg.Before, arg.Slug, arg.Exclude)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getLatestProperties = `-- name: GetLatestProperties :many
-SELECT DISTINCT ON (identifier, slug)
-    id, time_stamp, unit_of_measurement, value, identifier, slug
-FROM Property
-WHERE time_stamp > NOW() - INTERVAL '1 day'
-ORDER BY identifier, slug, time_stamp DESC
+SELECT identifier, slug, time_stamp, unit_of_measurement, value
+FROM property_latest
+ORDER BY identifier, slug
 `
 
-func (q *Queries) GetLatestProperties(ctx context.Context) ([]Property, error) {
+type GetLatestPropertiesRow struct {
+	Identifier        string    `json:"identifier"`
+	Slug              string    `json:"slug"`
+	TimeStamp         time.Time `json:"time_stamp"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Value             string    `json:"value"`
+}
+
+func (q *Queries) GetLatestProperties(ctx context.Context) ([]GetLatestPropertiesRow, error) {
 	rows, err := q.db.Query(ctx, getLatestProperties)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Property
+	var items []GetLatestPropertiesRow
 	for rows.Next() {
-		var i Property
+		var i GetLatestPropertiesRow
 		if err := rows.Scan(
-			&i.ID,
+			&i.Identifier,
+			&i.Slug,
 			&i.TimeStamp,
 			&i.UnitOfMeasurement,
 			&i.Value,
-			&i.Identifier,
-			&i.Slug,
 		); err != nil {
 			return nil, err
 		}
@@ -100,6 +136,89 @@ func (q *Queries) GetProperties(ctx context.Context, arg GetPropertiesParams) ([
 	return items, nil
 }
 
+const getPropertyRange = `-- name: GetPropertyRange :many
+SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+FROM Property
+WHERE identifier = $1 AND slug = $2 AND time_stamp BETWEEN $3 AND $4
+ORDER BY time_stamp, id
+LIMIT $5::INTEGER
+`
+
+type GetPropertyRangeParams struct {
+	Identifier string    `json:"identifier"`
+	Slug       string    `json:"slug"`
+	FromTime   time.Time `json:"from_time"`
+	ToTime     time.Time `json:"to_time"`
+	RowLimit   int       `json:"row_limit"`
+}
+
+func (q *Queries) GetPropertyRange(ctx context.Context, arg GetPropertyRangeParams) ([]Property, error) {
+	rows, err := q.db.Query(ctx, getPropertyRange,
+		arg.Identifier,
+		arg.Slug,
+		arg.FromTime,
+		arg.ToTime,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Property
+	for rows.Next() {
+		var i Property
+		if err := rows.Scan(
+			&i.ID,
+			&i.TimeStamp,
+			&i.UnitOfMeasurement,
+			&i.Value,
+			&i.Identifier,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSensors = `-- name: GetSensors :many
+SELECT identifier, slug, time_stamp, unit_of_measurement, value, name, first_seen
+FROM property_latest
+ORDER BY identifier, slug
+`
+
+func (q *Queries) GetSensors(ctx context.Context) ([]PropertyLatest, error) {
+	rows, err := q.db.Query(ctx, getSensors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PropertyLatest
+	for rows.Next() {
+		var i PropertyLatest
+		if err := rows.Scan(
+			&i.Identifier,
+			&i.Slug,
+			&i.TimeStamp,
+			&i.UnitOfMeasurement,
+			&i.Value,
+			&i.Name,
+			&i.FirstSeen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertProperty = `-- name: InsertProperty :one
 INSERT INTO Property (time_stamp, unit_of_measurement, value, identifier, slug)
 VALUES ($1, $2, $3, $4, $5)
@@ -133,3 +252,43 @@ func (q *Queries) InsertProperty(ctx context.Context, arg InsertPropertyParams)
 	)
 	return i, err
 }
+
+const scanProperties = `-- name: ScanProperties :many
+SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+FROM Property
+WHERE id > $1
+ORDER BY id
+LIMIT $2::INTEGER
+`
+
+type ScanPropertiesParams struct {
+	AfterID  int `json:"after_id"`
+	RowLimit int `json:"row_limit"`
+}
+
+func (q *Queries) ScanProperties(ctx context.Context, arg ScanPropertiesParams) ([]Property, error) {
+	rows, err := q.db.Query(ctx, scanProperties, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Property
+	for rows.Next() {
+		var i Property
+		if err := rows.Scan(
+			&i.ID,
+			&i.TimeStamp,
+			&i.UnitOfMeasurement,
+			&i.Value,
+			&i.Identifier,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -45,6 +45,36 @@ func (q *Queries) GetRefreshToken(ctx context.Context, tokenHash string) (Refres
 	return i, err
 }
 
+const listRefreshTokens = `-- name: ListRefreshTokens :many
+SELECT token_hash, user_id, username, expires_at, created_at FROM refresh_tokens ORDER BY created_at, token_hash
+`
+
+func (q *Queries) ListRefreshTokens(ctx context.Context) ([]RefreshToken, error) {
+	rows, err := q.db.Query(ctx, listRefreshTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.TokenHash,
+			&i.UserID,
+			&i.Username,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const storeRefreshToken = `-- name: StoreRefreshToken :exec
 INSERT INTO refresh_tokens (token_hash, user_id, username, expires_at)
 VALUES ($1, $2, $3, $4)
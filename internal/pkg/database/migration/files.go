@@ -0,0 +1,92 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// migrationFile is one numbered migration with its up and optional down file.
+type migrationFile struct {
+	version  uint
+	name     string // up file name without .up.sql
+	up, down string
+	checksum string // SHA-256 of the up file
+}
+
+var migrationName = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+
+// loadMigrations reads the migration files in dir, oldest first.
+func loadMigrations(dir string) ([]migrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("migration: read dir: %w", err)
+	}
+	byVersion := map[uint]*migrationFile{}
+	for _, e := range entries {
+		m := migrationName.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		v, err := parseVersion(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration: %s: %w", e.Name(), err)
+		}
+		f, ok := byVersion[v]
+		if !ok {
+			f = &migrationFile{version: v}
+			byVersion[v] = f
+		}
+		existing := &f.up
+		if m[2] == "down" {
+			existing = &f.down
+		}
+		if *existing != "" {
+			return nil, fmt.Errorf("migration: duplicate version %d: %s and %s", v, *existing, e.Name())
+		}
+		*existing = e.Name()
+	}
+
+	out := make([]migrationFile, 0, len(byVersion))
+	for _, f := range byVersion {
+		if f.up == "" {
+			return nil, fmt.Errorf("migration: %s has no up migration", f.down)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, f.up))
+		if err != nil {
+			return nil, fmt.Errorf("migration: read %s: %w", f.up, err)
+		}
+		sum := sha256.Sum256(content)
+		f.checksum = hex.EncodeToString(sum[:])
+		f.name = strings.TrimSuffix(f.up, ".up.sql")
+		out = append(out, *f)
+	}
+	slices.SortFunc(out, func(a, b migrationFile) int { return cmpUint(a.version, b.version) })
+	return out, nil
+}
+
+// parseVersion returns the number a migration name starts with.
+func parseVersion(name string) (uint, error) {
+	digits, _, _ := strings.Cut(name, "_")
+	v, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration version %q", name)
+	}
+	return uint(v), nil
+}
+
+func cmpUint(a, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -3,112 +3,272 @@ package migration
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/sijms/go-ora/v2"
 )
 
-// MigratePostgres runs up migrations against an existing pgxpool.Pool.
-func MigratePostgres(pool *pgxpool.Pool, folderPath string) error {
+// Migrator runs the migrations in one folder against one database.
+type Migrator interface {
+	// Status lists every migration file and every applied migration,
+	// oldest first.
+	Status(ctx context.Context) ([]Status, error)
+	// Up applies every pending migration.
+	Up(ctx context.Context) error
+	// Migrate migrates up or down to version; 0 reverts every migration.
+	Migrate(ctx context.Context, version uint) error
+	// Down reverts the n most recently applied migrations.
+	Down(ctx context.Context, n int) error
+	// Force records the database as clean at version without running
+	// anything, after a failed migration has been repaired by hand.
+	Force(ctx context.Context, version uint) error
+}
+
+// Status describes one migration: its file and, if applied, its record.
+type Status struct {
+	Version   uint
+	Name      string // file name without .up.sql, as recorded
+	Applied   bool
+	AppliedAt time.Time // zero where the database does not record it
+	Dirty     bool
+	// Modified reports that the up file no longer matches the recorded
+	// checksum. Only Oracle records checksums.
+	Modified bool
+	// Missing reports a recorded migration whose up file is gone.
+	Missing bool
+	HasDown bool
+}
+
+// Runner is a Migrator backed by golang-migrate, for Postgres, TimescaleDB
+// and SQLite. golang-migrate records only the current version and whether it
+// is dirty, and takes the database's own lock while it runs. Its methods do
+// not observe ctx once a migration has started.
+type Runner struct {
+	m      *migrate.Migrate
+	dir    string
+	prefix string
+	close  func() error
+}
+
+var _ Migrator = (*Runner)(nil)
+
+// NewPostgres returns a Runner for the base schema in folderPath.
+func NewPostgres(pool *pgxpool.Pool, folderPath string) (*Runner, error) {
+	return newPostgres(pool, folderPath, "postgres migration", &postgres.Config{})
+}
+
+// TimescaleMigrationsTable records the TimescaleDB migrations separately from
+// the base Postgres schema, so they can be applied on top of it optionally.
+const TimescaleMigrationsTable = "timescale_schema_migrations"
+
+// NewTimescale returns a Runner for the TimescaleDB migrations in
+// folderPath. Statements run one at a time outside a transaction, as
+// continuous aggregates cannot be created inside one.
+func NewTimescale(pool *pgxpool.Pool, folderPath string) (*Runner, error) {
+	return newPostgres(pool, folderPath, "timescale migration", &postgres.Config{
+		MigrationsTable:       TimescaleMigrationsTable,
+		MultiStatementEnabled: true,
+	})
+}
+
+func newPostgres(pool *pgxpool.Pool, folderPath, prefix string, cfg *postgres.Config) (*Runner, error) {
 	db := stdlib.OpenDBFromPool(pool)
-	defer func() { _ = db.Close() }()
 
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	driver, err := postgres.WithInstance(db, cfg)
 	if err != nil {
-		return fmt.Errorf("postgres migration: create driver: %w", err)
+		_ = db.Close()
+		return nil, fmt.Errorf("%s: create driver: %w", prefix, err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance("file://"+folderPath, "postgres", driver)
 	if err != nil {
-		return fmt.Errorf("postgres migration: init: %w", err)
+		_ = driver.Close()
+		return nil, fmt.Errorf("%s: init: %w", prefix, err)
+	}
+	return &Runner{m: m, dir: folderPath, prefix: prefix, close: func() error {
+		_, err := m.Close()
+		return err
+	}}, nil
+}
+
+// NewSQLite returns a Runner for an existing *sql.DB opened with Open from
+// the store/sqlite package. Close leaves db open.
+func NewSQLite(db *sql.DB, folderPath string) (*Runner, error) {
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("sqlite migration: create driver: %w", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://"+folderPath, "sqlite", driver)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite migration: init: %w", err)
 	}
+	// Closing m would close db, which belongs to the caller.
+	return &Runner{m: m, dir: folderPath, prefix: "sqlite migration", close: func() error { return nil }}, nil
+}
+
+// Close releases the connection the Runner holds.
+func (r *Runner) Close() error {
+	return r.close()
+}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("postgres migration: up: %w", err)
+func (r *Runner) Up(context.Context) error {
+	return r.wrap("up", r.m.Up())
+}
+
+func (r *Runner) Migrate(_ context.Context, version uint) error {
+	if version == 0 {
+		return r.wrap("down", r.m.Down())
 	}
-	return nil
+	return r.wrap(fmt.Sprintf("migrate to %d", version), r.m.Migrate(version))
 }
 
-// MigrateOracle runs up migrations against an existing *sql.DB opened with the oracle driver.
-func MigrateOracle(db *sql.DB, folderPath string) error {
-	ctx := context.Background()
+func (r *Runner) Down(_ context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("%s: down: at least one migration must be reverted", r.prefix)
+	}
+	return r.wrap(fmt.Sprintf("down %d", n), r.m.Steps(-n))
+}
 
-	if _, err := db.ExecContext(ctx, `
-		BEGIN
-			EXECUTE IMMEDIATE 'CREATE TABLE schema_migrations (
-				version   VARCHAR2(256) PRIMARY KEY,
-				applied_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
-			)';
-		EXCEPTION
-			WHEN OTHERS THEN
-				IF SQLCODE != -955 THEN RAISE; END IF;
-		END;`); err != nil {
-		return fmt.Errorf("oracle migration: ensure schema_migrations: %w", err)
+func (r *Runner) Force(_ context.Context, version uint) error {
+	v := int(version)
+	if version == 0 {
+		v = -1 // golang-migrate's "no migration applied"
 	}
+	return r.wrap(fmt.Sprintf("force %d", version), r.m.Force(v))
+}
 
-	entries, err := os.ReadDir(folderPath)
+func (r *Runner) Status(context.Context) ([]Status, error) {
+	files, err := loadMigrations(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	current, dirty, err := r.m.Version()
+	applied := true
+	if errors.Is(err, migrate.ErrNilVersion) {
+		applied, err = false, nil
+	}
 	if err != nil {
-		return fmt.Errorf("oracle migration: read dir: %w", err)
+		return nil, fmt.Errorf("%s: version: %w", r.prefix, err)
 	}
 
-	var upFiles []string
-	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".up.sql") {
-			upFiles = append(upFiles, e.Name())
+	out := make([]Status, 0, len(files)+1)
+	found := false
+	for _, f := range files {
+		st := Status{Version: f.version, Name: f.name, HasDown: f.down != ""}
+		st.Applied = applied && f.version <= current
+		if applied && f.version == current {
+			st.Dirty, found = dirty, true
 		}
+		out = append(out, st)
 	}
-	sort.Strings(upFiles)
+	if applied && !found {
+		out = append(out, Status{Version: current, Name: strconv.FormatUint(uint64(current), 10), Applied: true, Dirty: dirty, Missing: true})
+	}
+	return out, nil
+}
 
-	for _, name := range upFiles {
-		version := strings.TrimSuffix(name, ".up.sql")
+// wrap ignores migrate.ErrNoChange and prefixes any other error.
+func (r *Runner) wrap(op string, err error) error {
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return fmt.Errorf("%s: %s: %w", r.prefix, op, err)
+}
 
-		var count int
-		if err := db.QueryRowContext(ctx,
-			`SELECT COUNT(*) FROM schema_migrations WHERE version = :1`, version,
-		).Scan(&count); err != nil {
-			return fmt.Errorf("oracle migration: check %s: %w", version, err)
-		}
-		if count > 0 {
-			continue
-		}
+// MigratePostgres runs up migrations against an existing pgxpool.Pool.
+func MigratePostgres(pool *pgxpool.Pool, folderPath string) error {
+	r, err := NewPostgres(pool, folderPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	return r.Up(context.Background())
+}
 
-		content, err := os.ReadFile(filepath.Join(folderPath, name))
-		if err != nil {
-			return fmt.Errorf("oracle migration: read %s: %w", name, err)
-		}
+// MigrateTimescale applies the TimescaleDB migrations in folderPath on top of
+// the base Postgres schema. It reports false, without error, when the
+// timescaledb extension is not available on the server, so callers can fall
+// back to plain Postgres.
+func MigrateTimescale(ctx context.Context, pool *pgxpool.Pool, folderPath string) (bool, error) {
+	available, err := TimescaleAvailable(ctx, pool)
+	if err != nil || !available {
+		return false, err
+	}
 
-		for _, stmt := range splitStatements(string(content)) {
-			if _, err := db.ExecContext(ctx, stmt); err != nil {
-				return fmt.Errorf("oracle migration: apply %s: %w\nSQL: %s", name, err, stmt)
-			}
-		}
+	r, err := NewTimescale(pool, folderPath)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = r.Close() }()
+	if err := r.Up(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-		if _, err := db.ExecContext(ctx,
-			`INSERT INTO schema_migrations (version) VALUES (:1)`, version,
-		); err != nil {
-			return fmt.Errorf("oracle migration: record %s: %w", version, err)
-		}
+// TimescaleAvailable reports whether the timescaledb extension is available
+// on the server.
+func TimescaleAvailable(ctx context.Context, pool *pgxpool.Pool) (bool, error) {
+	var available bool
+	if err := pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb')`,
+	).Scan(&available); err != nil {
+		return false, fmt.Errorf("timescale migration: check extension: %w", err)
 	}
-	return nil
+	return available, nil
 }
 
-// splitStatements splits a SQL file containing multiple statements separated by
-// semicolons, returning only non-empty trimmed statements.
-func splitStatements(sql string) []string {
-	var out []string
-	for s := range strings.SplitSeq(sql, ";") {
-		if trimmed := strings.TrimSpace(s); trimmed != "" {
-			out = append(out, trimmed)
-		}
+// TimescaleApplied reports whether every TimescaleDB migration in folderPath
+// has been applied cleanly. It only reads timescale_schema_migrations, so it
+// is safe to call when migrations are run separately.
+func TimescaleApplied(ctx context.Context, pool *pgxpool.Pool, folderPath string) (bool, error) {
+	files, err := loadMigrations(folderPath)
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 {
+		return true, nil
+	}
+
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, TimescaleMigrationsTable).Scan(&exists); err != nil {
+		return false, fmt.Errorf("timescale migration: check migrations table: %w", err)
+	}
+	if !exists {
+		return false, nil
+	}
+	var (
+		version int64
+		dirty   bool
+	)
+	err = pool.QueryRow(ctx, `SELECT version, dirty FROM `+TimescaleMigrationsTable+` LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("timescale migration: version: %w", err)
+	}
+	return !dirty && version >= int64(files[len(files)-1].version), nil
+}
+
+// MigrateSQLite runs up migrations against an existing *sql.DB opened with
+// Open from the store/sqlite package.
+func MigrateSQLite(db *sql.DB, folderPath string) error {
+	r, err := NewSQLite(db, folderPath)
+	if err != nil {
+		return err
 	}
-	return out
+	defer func() { _ = r.Close() }()
+	return r.Up(context.Background())
 }
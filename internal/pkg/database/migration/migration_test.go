@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqlitestore "github.com/anicoll/winet-integration/internal/pkg/store/sqlite"
+)
+
+// newSQLiteRunner returns a Runner for the repository's SQLite migrations
+// against an empty database.
+func newSQLiteRunner(t *testing.T) *Runner {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sqlitestore.Open(ctx, filepath.Join(t.TempDir(), "winet.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	r, err := NewSQLite(db, filepath.Join("..", "..", "..", "..", "migrations", "sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func applied(t *testing.T, m Migrator) []uint {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	var out []uint
+	for _, st := range statuses {
+		assert.False(t, st.Dirty, "%s is dirty", st.Name)
+		if st.Applied {
+			out = append(out, st.Version)
+		}
+	}
+	return out
+}
+
+func TestRunner_UpDownGoto(t *testing.T) {
+	ctx := context.Background()
+	r := newSQLiteRunner(t)
+
+	statuses, err := r.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 5)
+	assert.Empty(t, applied(t, r))
+
+	require.NoError(t, r.Up(ctx))
+	assert.Equal(t, []uint{1, 2, 3, 4, 5}, applied(t, r))
+	require.NoError(t, r.Up(ctx), "nothing to do is not an error")
+
+	require.NoError(t, r.Down(ctx, 3))
+	assert.Equal(t, []uint{1, 2}, applied(t, r))
+
+	require.NoError(t, r.Migrate(ctx, 3))
+	assert.Equal(t, []uint{1, 2, 3}, applied(t, r))
+
+	require.NoError(t, r.Migrate(ctx, 0))
+	assert.Empty(t, applied(t, r))
+}
+
+func TestRunner_Force(t *testing.T) {
+	ctx := context.Background()
+	r := newSQLiteRunner(t)
+
+	require.NoError(t, r.Force(ctx, 2))
+	assert.Equal(t, []uint{1, 2}, applied(t, r))
+
+	require.NoError(t, r.Force(ctx, 0))
+	assert.Empty(t, applied(t, r))
+}
+
+func TestRunner_DownTooFar(t *testing.T) {
+	ctx := context.Background()
+	r := newSQLiteRunner(t)
+	require.NoError(t, r.Migrate(ctx, 1))
+
+	assert.Error(t, r.Down(ctx, 2))
+	assert.Error(t, r.Down(ctx, 0))
+}
@@ -0,0 +1,547 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OracleLockTimeout is how long an Oracle migration waits for another
+// instance to finish before giving up.
+const OracleLockTimeout = 60 * time.Second
+
+// ErrOracleLocked is returned when another instance holds the migration lock
+// for longer than OracleLockTimeout.
+var ErrOracleLocked = errors.New("oracle migration: another instance is migrating")
+
+// DirtyError is returned when a migration failed part way through. Repair
+// the schema by hand, then record the version it is actually at with Force.
+type DirtyError struct {
+	Version uint
+	Name    string
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("oracle migration: database is dirty at %s; repair it by hand, then force the version", e.Name)
+}
+
+// ChecksumError is returned when an applied migration file has changed since
+// it was applied.
+type ChecksumError struct {
+	Name          string
+	Recorded, Now string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("oracle migration: %s has changed since it was applied (checksum %s, was %s)", e.Name, e.Now, e.Recorded)
+}
+
+// Oracle applies the migrations in a folder to an Oracle database, in the
+// spirit of golang-migrate, which has no Oracle driver.
+//
+// Each file is recorded in schema_migrations with a SHA-256 checksum and a
+// dirty flag that is set while it runs, so a file that fails part way is
+// not silently retried. Oracle commits every DDL statement, so a failed file
+// cannot be rolled back automatically. A row lock on schema_migrations_lock,
+// held by a separate session, keeps two instances from migrating at once and
+// is released by Oracle if the process dies.
+type Oracle struct {
+	db          *sql.DB
+	dir         string
+	lockTimeout time.Duration
+}
+
+// NewOracle returns a migrator for the migrations in folderPath.
+func NewOracle(db *sql.DB, folderPath string) *Oracle {
+	return &Oracle{db: db, dir: folderPath, lockTimeout: OracleLockTimeout}
+}
+
+// MigrateOracle runs up migrations against an existing *sql.DB opened with the oracle driver.
+func MigrateOracle(db *sql.DB, folderPath string) error {
+	return NewOracle(db, folderPath).Up(context.Background())
+}
+
+var _ Migrator = (*Oracle)(nil)
+
+// Up applies every pending migration.
+func (o *Oracle) Up(ctx context.Context) error {
+	return o.migrate(ctx, plan{})
+}
+
+// Migrate migrates up or down to version; 0 reverts every migration.
+func (o *Oracle) Migrate(ctx context.Context, version uint) error {
+	return o.migrate(ctx, plan{target: &version})
+}
+
+// Down reverts the n most recently applied migrations.
+func (o *Oracle) Down(ctx context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("oracle migration: down: at least one migration must be reverted")
+	}
+	return o.migrate(ctx, plan{steps: n})
+}
+
+// Force records the database as being exactly at version, clean, without
+// running anything: migrations above it are forgotten and those at or below
+// it are recorded as applied with the checksums of the files as they are now.
+// Use it after repairing a dirty database by hand.
+func (o *Oracle) Force(ctx context.Context, version uint) error {
+	if err := o.ensureTables(ctx); err != nil {
+		return err
+	}
+	files, err := loadMigrations(o.dir)
+	if err != nil {
+		return err
+	}
+	unlock, err := o.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := o.records(ctx, o.db)
+	if err != nil {
+		return err
+	}
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("oracle migration: force: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for v, r := range records {
+		if v > version {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = :1`, r.name); err != nil {
+				return fmt.Errorf("oracle migration: force: %w", err)
+			}
+		}
+	}
+	for _, f := range files {
+		if f.version > version {
+			break
+		}
+		if _, err := tx.ExecContext(ctx, `
+			MERGE INTO schema_migrations m
+			USING (SELECT :1 AS version, :2 AS checksum FROM dual) s
+			ON (m.version = s.version)
+			WHEN MATCHED THEN UPDATE SET m.checksum = s.checksum, m.dirty = 0
+			WHEN NOT MATCHED THEN INSERT (version, checksum, dirty) VALUES (s.version, s.checksum, 0)`,
+			f.name, f.checksum,
+		); err != nil {
+			return fmt.Errorf("oracle migration: force %s: %w", f.name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("oracle migration: force: %w", err)
+	}
+	return nil
+}
+
+// Status lists every migration file and every recorded migration, oldest
+// first. It does not take the lock.
+func (o *Oracle) Status(ctx context.Context) ([]Status, error) {
+	if err := o.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+	files, err := loadMigrations(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	records, err := o.records(ctx, o.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Status
+	for _, f := range files {
+		st := Status{Version: f.version, Name: f.name, HasDown: f.down != ""}
+		if r, ok := records[f.version]; ok {
+			st.Applied, st.AppliedAt, st.Dirty = true, r.appliedAt, r.dirty
+			st.Modified = r.checksum != "" && r.checksum != f.checksum
+			delete(records, f.version)
+		}
+		out = append(out, st)
+	}
+	for v, r := range records {
+		out = append(out, Status{Version: v, Name: r.name, Applied: true, AppliedAt: r.appliedAt, Dirty: r.dirty, Missing: true})
+	}
+	slices.SortFunc(out, func(a, b Status) int { return cmpUint(a.Version, b.Version) })
+	return out, nil
+}
+
+// plan says what migrate does: revert the applied migrations above target
+// and apply the pending ones up to it (all of them when target is nil), or,
+// when steps is set, only revert the steps most recent migrations.
+type plan struct {
+	target *uint
+	steps  int
+}
+
+func (o *Oracle) migrate(ctx context.Context, p plan) error {
+	if err := o.ensureTables(ctx); err != nil {
+		return err
+	}
+	files, err := loadMigrations(o.dir)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := o.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Statements run on one dedicated session, separate from the lock's.
+	conn, err := o.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("oracle migration: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	records, err := o.records(ctx, conn)
+	if err != nil {
+		return err
+	}
+	byVersion := map[uint]migrationFile{}
+	for _, f := range files {
+		byVersion[f.version] = f
+	}
+	for v, r := range records {
+		if r.dirty {
+			return &DirtyError{Version: v, Name: r.name}
+		}
+		f, ok := byVersion[v]
+		if !ok {
+			continue
+		}
+		switch r.checksum {
+		case "":
+			// Recorded before checksums were; trust the file as it is now.
+			if _, err := conn.ExecContext(ctx,
+				`UPDATE schema_migrations SET checksum = :1 WHERE version = :2`, f.checksum, r.name,
+			); err != nil {
+				return fmt.Errorf("oracle migration: record checksum of %s: %w", r.name, err)
+			}
+		case f.checksum:
+		default:
+			return &ChecksumError{Name: r.name, Recorded: r.checksum, Now: f.checksum}
+		}
+	}
+
+	// Revert, newest first.
+	var down []uint
+	for v := range records {
+		if p.steps > 0 || p.target != nil && v > *p.target {
+			down = append(down, v)
+		}
+	}
+	slices.Sort(down)
+	slices.Reverse(down)
+	if p.steps > 0 {
+		if len(down) < p.steps {
+			return fmt.Errorf("oracle migration: down %d: only %d migrations are applied", p.steps, len(down))
+		}
+		down = down[:p.steps]
+	}
+	for _, v := range down {
+		f, ok := byVersion[v]
+		if !ok {
+			return fmt.Errorf("oracle migration: %s is applied but its files are missing", records[v].name)
+		}
+		if err := o.down(ctx, conn, f); err != nil {
+			return err
+		}
+	}
+	if p.steps > 0 {
+		return nil
+	}
+
+	for _, f := range files {
+		if _, ok := records[f.version]; ok {
+			continue
+		}
+		if p.target != nil && f.version > *p.target {
+			break
+		}
+		if err := o.up(ctx, conn, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Oracle) up(ctx context.Context, conn *sql.Conn, f migrationFile) error {
+	content, err := os.ReadFile(filepath.Join(o.dir, f.up))
+	if err != nil {
+		return fmt.Errorf("oracle migration: read %s: %w", f.up, err)
+	}
+	if _, err := conn.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, checksum, dirty) VALUES (:1, :2, 1)`, f.name, f.checksum,
+	); err != nil {
+		return fmt.Errorf("oracle migration: record %s: %w", f.name, err)
+	}
+	for _, stmt := range splitStatements(string(content)) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("oracle migration: apply %s: %w\nSQL: %s", f.up, err, stmt)
+		}
+	}
+	if _, err := conn.ExecContext(ctx,
+		`UPDATE schema_migrations SET dirty = 0, applied_at = SYSTIMESTAMP WHERE version = :1`, f.name,
+	); err != nil {
+		return fmt.Errorf("oracle migration: record %s: %w", f.name, err)
+	}
+	return nil
+}
+
+func (o *Oracle) down(ctx context.Context, conn *sql.Conn, f migrationFile) error {
+	if f.down == "" {
+		return fmt.Errorf("oracle migration: %s has no down migration", f.name)
+	}
+	content, err := os.ReadFile(filepath.Join(o.dir, f.down))
+	if err != nil {
+		return fmt.Errorf("oracle migration: read %s: %w", f.down, err)
+	}
+	if _, err := conn.ExecContext(ctx,
+		`UPDATE schema_migrations SET dirty = 1 WHERE version = :1`, f.name,
+	); err != nil {
+		return fmt.Errorf("oracle migration: mark %s: %w", f.name, err)
+	}
+	for _, stmt := range splitStatements(string(content)) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("oracle migration: revert %s: %w\nSQL: %s", f.down, err, stmt)
+		}
+	}
+	if _, err := conn.ExecContext(ctx,
+		`DELETE FROM schema_migrations WHERE version = :1`, f.name,
+	); err != nil {
+		return fmt.Errorf("oracle migration: unrecord %s: %w", f.name, err)
+	}
+	return nil
+}
+
+// ensureTables creates schema_migrations and the lock table, and adds the
+// checksum and dirty columns to a schema_migrations created before them.
+// ORA-00955 (name already used) and ORA-01430 (column already exists) mean
+// the work is already done.
+func (o *Oracle) ensureTables(ctx context.Context) error {
+	for _, stmt := range []string{
+		`BEGIN
+			EXECUTE IMMEDIATE 'CREATE TABLE schema_migrations (
+				version    VARCHAR2(256) PRIMARY KEY,
+				applied_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+			)';
+		EXCEPTION
+			WHEN OTHERS THEN
+				IF SQLCODE != -955 THEN RAISE; END IF;
+		END;`,
+		`BEGIN
+			EXECUTE IMMEDIATE 'ALTER TABLE schema_migrations ADD (
+				checksum VARCHAR2(64),
+				dirty    NUMBER(1) DEFAULT 0 NOT NULL
+			)';
+		EXCEPTION
+			WHEN OTHERS THEN
+				IF SQLCODE != -1430 THEN RAISE; END IF;
+		END;`,
+		`BEGIN
+			EXECUTE IMMEDIATE 'CREATE TABLE schema_migrations_lock (id NUMBER(1) PRIMARY KEY)';
+		EXCEPTION
+			WHEN OTHERS THEN
+				IF SQLCODE != -955 THEN RAISE; END IF;
+		END;`,
+		`BEGIN
+			INSERT INTO schema_migrations_lock (id) VALUES (1);
+		EXCEPTION
+			WHEN DUP_VAL_ON_INDEX THEN NULL;
+		END;`,
+	} {
+		if _, err := o.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("oracle migration: ensure schema_migrations: %w", err)
+		}
+	}
+	return nil
+}
+
+// lock takes the migration lock in a transaction of its own and returns the
+// function that releases it.
+func (o *Oracle) lock(ctx context.Context) (func(), error) {
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("oracle migration: lock: %w", err)
+	}
+	var id int
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT id FROM schema_migrations_lock WHERE id = 1 FOR UPDATE WAIT %d`, int(o.lockTimeout.Seconds()),
+	)).Scan(&id)
+	if err != nil {
+		_ = tx.Rollback()
+		// ORA-30006: resource busy; acquire with WAIT timeout expired.
+		if strings.Contains(err.Error(), "ORA-30006") {
+			return nil, ErrOracleLocked
+		}
+		return nil, fmt.Errorf("oracle migration: lock: %w", err)
+	}
+	return func() { _ = tx.Rollback() }, nil
+}
+
+type record struct {
+	name      string
+	appliedAt time.Time
+	checksum  string
+	dirty     bool
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// records returns the recorded migrations by version.
+func (o *Oracle) records(ctx context.Context, q querier) (map[uint]record, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, applied_at, checksum, dirty FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("oracle migration: read schema_migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := map[uint]record{}
+	for rows.Next() {
+		var (
+			r        record
+			checksum sql.NullString
+			dirty    int
+		)
+		if err := rows.Scan(&r.name, &r.appliedAt, &checksum, &dirty); err != nil {
+			return nil, fmt.Errorf("oracle migration: read schema_migrations: %w", err)
+		}
+		r.checksum, r.dirty = checksum.String, dirty != 0
+		v, err := parseVersion(r.name)
+		if err != nil {
+			return nil, fmt.Errorf("oracle migration: schema_migrations: %w", err)
+		}
+		out[v] = r
+	}
+	return out, rows.Err()
+}
+
+// splitStatements splits an Oracle migration file into statements the
+// driver can run one at a time. SQL statements end at a semicolon, which is
+// dropped. PL/SQL blocks (BEGIN, DECLARE, CREATE PROCEDURE, TRIGGER, …)
+// contain semicolons of their own, so, as in SQL*Plus, they end at a line
+// holding only "/" and keep their final semicolon. Comments are removed,
+// and semicolons inside quoted strings and identifiers are ignored.
+func splitStatements(src string) []string {
+	var (
+		out     []string
+		buf     strings.Builder
+		plsql   bool
+		started bool
+	)
+	flush := func() {
+		stmt := strings.TrimSpace(buf.String())
+		if stmt != "" {
+			out = append(out, stmt)
+		}
+		buf.Reset()
+		plsql, started = false, false
+	}
+
+	lineStart := true
+	for i := 0; i < len(src); {
+		if lineStart {
+			line, _, _ := strings.Cut(src[i:], "\n")
+			if strings.TrimSpace(line) == "/" {
+				flush()
+				i += len(line)
+				continue
+			}
+		}
+		c := src[i]
+		lineStart = c == '\n'
+		switch {
+		case strings.HasPrefix(src[i:], "--"):
+			if end := strings.IndexByte(src[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(src)
+			}
+			buf.WriteByte(' ')
+		case c == '\'' || c == '"':
+			if !started {
+				started = true
+			}
+			end := quoteEnd(src, i)
+			buf.WriteString(src[i:end])
+			i = end
+		case c == ';' && !plsql:
+			flush()
+			i++
+		default:
+			if !started && !isSpace(c) {
+				started = true
+				plsql = isPLSQL(src[i:])
+			}
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return out
+}
+
+// quoteEnd returns the index just past the quoted string or identifier that
+// starts at src[i]. A doubled quote inside it is an escaped quote.
+func quoteEnd(src string, i int) int {
+	q := src[i]
+	for j := i + 1; j < len(src); j++ {
+		if src[j] != q {
+			continue
+		}
+		if j+1 < len(src) && src[j+1] == q {
+			j++
+			continue
+		}
+		return j + 1
+	}
+	return len(src)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isPLSQL reports whether the statement starting at s is a PL/SQL block or
+// stored program unit.
+func isPLSQL(s string) bool {
+	words := strings.Fields(strings.ToUpper(s[:min(len(s), 200)]))
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "BEGIN", "DECLARE":
+		return true
+	case "CREATE":
+	default:
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "EDITIONABLE", "NONEDITIONABLE":
+			continue
+		case "PROCEDURE", "FUNCTION", "PACKAGE", "TRIGGER", "TYPE", "LIBRARY":
+			return true
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "plain statements",
+			src:  "CREATE TABLE a (id NUMBER);\n\nCREATE INDEX idx_a ON a (id);\n",
+			want: []string{"CREATE TABLE a (id NUMBER)", "CREATE INDEX idx_a ON a (id)"},
+		},
+		{
+			name: "comments are dropped",
+			src:  "-- a comment; with a semicolon\nDROP TABLE a; /* and; another */\nDROP TABLE b;",
+			want: []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+		{
+			name: "semicolons in quotes",
+			src:  "INSERT INTO a (s) VALUES ('x;''y');\nCREATE TABLE \"odd;name\" (id NUMBER);",
+			want: []string{"INSERT INTO a (s) VALUES ('x;''y')", "CREATE TABLE \"odd;name\" (id NUMBER)"},
+		},
+		{
+			name: "anonymous block ends at slash",
+			src: "BEGIN\n  EXECUTE IMMEDIATE 'DROP TABLE a';\nEXCEPTION\n  WHEN OTHERS THEN NULL;\nEND;\n/\n" +
+				"DROP TABLE b;",
+			want: []string{
+				"BEGIN\n  EXECUTE IMMEDIATE 'DROP TABLE a';\nEXCEPTION\n  WHEN OTHERS THEN NULL;\nEND;",
+				"DROP TABLE b",
+			},
+		},
+		{
+			name: "trigger",
+			src:  "CREATE OR REPLACE TRIGGER trg_a\nBEFORE UPDATE ON a FOR EACH ROW\nBEGIN\n  :NEW.updated_at := SYSTIMESTAMP;\nEND;\n/\n",
+			want: []string{
+				"CREATE OR REPLACE TRIGGER trg_a\nBEFORE UPDATE ON a FOR EACH ROW\nBEGIN\n  :NEW.updated_at := SYSTIMESTAMP;\nEND;",
+			},
+		},
+		{
+			name: "block without trailing slash",
+			src:  "DECLARE\n  n NUMBER;\nBEGIN\n  NULL;\nEND;",
+			want: []string{"DECLARE\n  n NUMBER;\nBEGIN\n  NULL;\nEND;"},
+		},
+		{
+			name: "division is not a terminator",
+			src:  "SELECT 4\n/ 2 FROM dual;",
+			want: []string{"SELECT 4\n/ 2 FROM dual"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitStatements(tt.src))
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"000002_second.up.sql":  "DROP TABLE a;",
+		"000001_first.up.sql":   "CREATE TABLE a (id NUMBER);",
+		"000001_first.down.sql": "DROP TABLE a;",
+		"000010_tenth.up.sql":   "CREATE TABLE b (id NUMBER);",
+		"000010_tenth.down.sql": "DROP TABLE b;",
+		"README.md":             "not a migration",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	files, err := loadMigrations(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	assert.Equal(t, uint(1), files[0].version)
+	assert.Equal(t, "000001_first", files[0].name)
+	assert.Equal(t, "000001_first.down.sql", files[0].down)
+	assert.Equal(t, uint(2), files[1].version)
+	assert.Empty(t, files[1].down, "down migrations are optional")
+	assert.Equal(t, uint(10), files[2].version)
+	assert.Len(t, files[0].checksum, 64)
+	assert.NotEqual(t, files[0].checksum, files[2].checksum)
+}
+
+func TestLoadMigrations_RejectsDuplicateVersions(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000001_a.up.sql"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000001_b.up.sql"), nil, 0o600))
+
+	_, err := loadMigrations(dir)
+	assert.ErrorContains(t, err, "duplicate version 1")
+}
+
+func TestLoadMigrations_RepoFiles(t *testing.T) {
+	files, err := loadMigrations(filepath.Join("..", "..", "..", "..", "migrations", "oracle"))
+	require.NoError(t, err)
+	for _, f := range files {
+		assert.NotEmpty(t, f.down, "%s has no down migration", f.name)
+		for _, name := range []string{f.up, f.down} {
+			content, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "migrations", "oracle", name))
+			require.NoError(t, err)
+			for _, stmt := range splitStatements(string(content)) {
+				assert.NotContains(t, stmt, ";", "%s: statement not split: %s", name, stmt)
+			}
+		}
+	}
+}
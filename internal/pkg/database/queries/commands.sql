@@ -0,0 +1,23 @@
+-- name: InsertCommand :exec
+INSERT INTO command_audit (requested_at, source, username, command, params, success, result_code, error_message, latency_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetCommands :many
+SELECT id, requested_at, source, username, command, params, success, result_code, error_message, latency_ms
+FROM command_audit
+WHERE requested_at BETWEEN @from AND @to
+  AND (@username::TEXT = '' OR username = @username::TEXT)
+  AND (@source::TEXT = '' OR source = @source::TEXT)
+  AND (@command::TEXT = '' OR command = @command::TEXT)
+ORDER BY requested_at DESC, id DESC
+LIMIT @row_limit::INTEGER;
+
+-- name: ScanCommands :many
+SELECT id, requested_at, source, username, command, params, success, result_code, error_message, latency_ms
+FROM command_audit
+WHERE id > @after_id
+ORDER BY id
+LIMIT @row_limit::INTEGER;
+
+-- name: CountCommands :one
+SELECT COUNT(*) FROM command_audit;
@@ -2,3 +2,6 @@
 INSERT INTO Device (id, model, serial_number)
 VALUES ($1, $2, $3)
 ON CONFLICT (id) DO NOTHING;
+
+-- name: ListDevices :many
+SELECT * FROM Device ORDER BY id;
@@ -0,0 +1,15 @@
+-- name: UpsertInverter :exec
+INSERT INTO Inverter (id, state, battery_state, charge_rate, feedin_enabled, updated_at, reconciled_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (id) DO UPDATE SET
+    state          = EXCLUDED.state,
+    battery_state  = EXCLUDED.battery_state,
+    charge_rate    = EXCLUDED.charge_rate,
+    feedin_enabled = EXCLUDED.feedin_enabled,
+    updated_at     = EXCLUDED.updated_at,
+    reconciled_at  = EXCLUDED.reconciled_at;
+
+-- name: GetInverters :many
+SELECT id, state, battery_state, charge_rate, feedin_enabled, created_at, updated_at, reconciled_at
+FROM Inverter
+ORDER BY updated_at DESC;
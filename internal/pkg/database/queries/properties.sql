@@ -3,18 +3,58 @@ INSERT INTO Property (time_stamp, unit_of_measurement, value, identifier, slug)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING id, time_stamp, unit_of_measurement, value, identifier, slug;
 
+-- name: CopyProperties :copyfrom
+INSERT INTO Property (time_stamp, unit_of_measurement, value, identifier, slug)
+VALUES ($1, $2, $3, $4, $5);
+
 -- name: GetProperties :many
 SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
 FROM Property
 WHERE identifier = $1 AND slug = $2 AND time_stamp BETWEEN $3 AND $4
 ORDER BY time_stamp DESC;
 
+-- name: GetPropertyRange :many
+SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+FROM Property
+WHERE identifier = @identifier AND slug = @slug AND time_stamp BETWEEN @from_time AND @to_time
+ORDER BY time_stamp, id
+LIMIT @row_limit::INTEGER;
+
 -- name: GetLatestProperties :many
-SELECT DISTINCT ON (identifier, slug)
-    id, time_stamp, unit_of_measurement, value, identifier, slug
+SELECT identifier, slug, time_stamp, unit_of_measurement, value
+FROM property_latest
+ORDER BY identifier, slug;
+
+-- name: GetSensors :many
+SELECT identifier, slug, time_stamp, unit_of_measurement, value, name, first_seen
+FROM property_latest
+ORDER BY identifier, slug;
+
+-- name: UpsertPropertyLatest :batchexec
+INSERT INTO property_latest (identifier, slug, time_stamp, unit_of_measurement, value, name, first_seen)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (identifier, slug) DO UPDATE SET
+    time_stamp          = GREATEST(property_latest.time_stamp, EXCLUDED.time_stamp),
+    unit_of_measurement = CASE WHEN EXCLUDED.time_stamp >= property_latest.time_stamp
+                               THEN EXCLUDED.unit_of_measurement ELSE property_latest.unit_of_measurement END,
+    value               = CASE WHEN EXCLUDED.time_stamp >= property_latest.time_stamp
+                               THEN EXCLUDED.value ELSE property_latest.value END,
+    name                = CASE WHEN EXCLUDED.time_stamp >= property_latest.time_stamp
+                               THEN COALESCE(NULLIF(EXCLUDED.name, ''), property_latest.name) ELSE property_latest.name END,
+    first_seen          = LEAST(property_latest.first_seen, EXCLUDED.first_seen);
+
+-- name: DeleteProperties :execrows
+DELETE FROM Property
+WHERE time_stamp < @before
+  AND (@slug::TEXT = '' OR slug = @slug::TEXT)
+  AND NOT (slug = ANY(COALESCE(@exclude::TEXT[], '{}')));
+
+-- name: ScanProperties :many
+SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
 FROM Property
-WHERE time_stamp > NOW() - INTERVAL '1 day'
-ORDER BY identifier, slug, time_stamp DESC;
+WHERE id > @after_id
+ORDER BY id
+LIMIT @row_limit::INTEGER;
 
--- name: CleanupProperties :exec
-DELETE FROM Property WHERE time_stamp < $1;
+-- name: CountProperties :one
+SELECT COUNT(*) FROM Property;
@@ -11,3 +11,6 @@ DELETE FROM refresh_tokens WHERE token_hash = $1;
 
 -- name: DeleteExpiredRefreshTokens :exec
 DELETE FROM refresh_tokens WHERE expires_at < NOW();
+
+-- name: ListRefreshTokens :many
+SELECT * FROM refresh_tokens ORDER BY created_at, token_hash;
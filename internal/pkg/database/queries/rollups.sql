@@ -0,0 +1,77 @@
+-- name: UpsertPropertyRollups :batchexec
+INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+VALUES (@resolution, @bucket_start, @identifier, @slug, @unit_of_measurement,
+        @value::DOUBLE PRECISION, @value::DOUBLE PRECISION, @value::DOUBLE PRECISION, 1, @value::DOUBLE PRECISION, @time_stamp)
+ON CONFLICT (identifier, slug, resolution, bucket_start) DO UPDATE SET
+    min_value           = LEAST(property_rollup.min_value, EXCLUDED.min_value),
+    max_value           = GREATEST(property_rollup.max_value, EXCLUDED.max_value),
+    sum_value           = property_rollup.sum_value + EXCLUDED.sum_value,
+    sample_count        = property_rollup.sample_count + 1,
+    last_value          = CASE WHEN EXCLUDED.last_time_stamp >= property_rollup.last_time_stamp
+                               THEN EXCLUDED.last_value ELSE property_rollup.last_value END,
+    unit_of_measurement = CASE WHEN EXCLUDED.last_time_stamp >= property_rollup.last_time_stamp
+                               THEN EXCLUDED.unit_of_measurement ELSE property_rollup.unit_of_measurement END,
+    last_time_stamp     = GREATEST(property_rollup.last_time_stamp, EXCLUDED.last_time_stamp);
+
+-- name: GetPropertyRollups :many
+SELECT bucket_start, unit_of_measurement, min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+FROM property_rollup
+WHERE identifier = $1 AND slug = $2 AND resolution = $3 AND bucket_start >= $4 AND bucket_start < $5
+ORDER BY bucket_start;
+
+-- name: ImportPropertyRollups :batchexec
+INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (identifier, slug, resolution, bucket_start) DO UPDATE SET
+    unit_of_measurement = EXCLUDED.unit_of_measurement,
+    min_value           = EXCLUDED.min_value,
+    max_value           = EXCLUDED.max_value,
+    sum_value           = EXCLUDED.sum_value,
+    sample_count        = EXCLUDED.sample_count,
+    last_value          = EXCLUDED.last_value,
+    last_time_stamp     = EXCLUDED.last_time_stamp;
+
+-- name: ScanPropertyRollups :many
+SELECT resolution, bucket_start, identifier, slug, unit_of_measurement,
+       min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+FROM property_rollup
+WHERE (identifier, slug, resolution, bucket_start) > (@after_identifier::TEXT, @after_slug::TEXT, @after_resolution::INTEGER, @after_bucket_start::TIMESTAMPTZ)
+ORDER BY identifier, slug, resolution, bucket_start
+LIMIT @row_limit::INTEGER;
+
+-- name: CountPropertyRollups :one
+SELECT COUNT(*) FROM property_rollup;
+
+-- name: DeletePropertyRollups :execrows
+DELETE FROM property_rollup WHERE resolution = $1 AND bucket_start < $2;
+
+-- name: RollupProperties :exec
+-- Fills in rollup buckets that are missing for the selected readings.
+-- Existing buckets were maintained on write and are left alone.
+WITH numeric_property AS MATERIALIZED (
+        SELECT time_stamp, identifier, slug, unit_of_measurement, value::DOUBLE PRECISION AS v
+        FROM Property
+        WHERE time_stamp < @before
+          AND (@slug::TEXT = '' OR slug = @slug::TEXT)
+          AND NOT (slug = ANY(COALESCE(@exclude::TEXT[], '{}')))
+          AND value ~ '^-?[0-9]+(\.[0-9]+)?$'
+)
+INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+SELECT r.resolution,
+       to_timestamp(floor(extract(epoch FROM p.time_stamp) / r.resolution) * r.resolution) AS bucket_start,
+       p.identifier,
+       p.slug,
+       (array_agg(p.unit_of_measurement ORDER BY p.time_stamp DESC))[1],
+       MIN(p.v),
+       MAX(p.v),
+       SUM(p.v),
+       COUNT(*),
+       (array_agg(p.v ORDER BY p.time_stamp DESC))[1],
+       MAX(p.time_stamp)
+FROM numeric_property p
+CROSS JOIN (VALUES (60), (900), (3600), (86400)) AS r(resolution)
+GROUP BY r.resolution, bucket_start, p.identifier, p.slug
+ON CONFLICT (identifier, slug, resolution, bucket_start) DO NOTHING;
@@ -3,3 +3,6 @@ SELECT * FROM users WHERE username = $1 LIMIT 1;
 
 -- name: CreateUser :one
 INSERT INTO users (username, password_hash) VALUES ($1, $2) RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users ORDER BY id;
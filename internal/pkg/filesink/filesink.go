@@ -0,0 +1,212 @@
+// Package filesink is a publisher.Publisher backend that appends readings to
+// daily-rotated CSV or Parquet files for offline analysis (pandas, DuckDB).
+//
+// Files are named readings-YYYY-MM-DD<ext> after the UTC date of the reading.
+// CSV files are appended to across restarts; Parquet files cannot be appended
+// to, so a restart on the same day starts a new part (readings-YYYY-MM-DD.1.parquet).
+// A Parquet file is only readable once it has been closed, i.e. after the day
+// rotates or the service shuts down.
+package filesink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+const filePrefix = "readings-"
+
+type service struct {
+	cfg    *config.FileSinkConfig
+	ext    string
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	day  string // UTC date of the open file
+	file *os.File
+	w    RowWriter
+}
+
+// compile-time check that *service satisfies publisher.Publisher.
+var _ publisher.Publisher = (*service)(nil)
+
+// New returns a file sink writing into cfg.Dir, creating it if needed.
+// Call Run so the open file is closed cleanly on shutdown.
+func New(cfg *config.FileSinkConfig) (*service, error) {
+	ext, err := Extension(cfg.Format, cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", cfg.Dir, err)
+	}
+	return &service{cfg: cfg, ext: ext, logger: zap.L()}, nil
+}
+
+// Name identifies the backend in logs and metrics.
+func (s *service) Name() string { return "file" }
+
+// Write appends the data points to the file for their UTC date, rotating
+// when the date changes.
+func (s *service) Write(_ context.Context, data []publisher.DataPoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := make([]Row, 0, len(data))
+	for _, dp := range data {
+		day := dp.Timestamp.UTC().Format(time.DateOnly)
+		if day != s.day {
+			if err := s.writeRows(rows); err != nil {
+				return err
+			}
+			rows = rows[:0]
+			if err := s.rotate(day); err != nil {
+				return err
+			}
+		}
+		rows = append(rows, RowFromDataPoint(dp))
+	}
+	return s.writeRows(rows)
+}
+
+// RegisterDevice is a no-op; devices are represented by the identifier column.
+func (s *service) RegisterDevice(_ context.Context, _ *model.Device) error {
+	return nil
+}
+
+// Run blocks until ctx is cancelled and then closes the open file.
+func (s *service) Run(ctx context.Context) error {
+	<-ctx.Done()
+	if err := s.Close(); err != nil {
+		s.logger.Error("filesink: close failed", zap.Error(err))
+	}
+	return ctx.Err()
+}
+
+// Close finalises the open file. Subsequent writes open a new one.
+func (s *service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeCurrent()
+}
+
+func (s *service) writeRows(rows []Row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := s.w.Write(rows); err != nil {
+		return fmt.Errorf("filesink: write %s: %w", s.file.Name(), err)
+	}
+	return s.w.Flush()
+}
+
+func (s *service) closeCurrent() error {
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.w.Close(), s.file.Close())
+	s.file, s.w, s.day = nil, nil, ""
+	return err
+}
+
+func (s *service) rotate(day string) error {
+	if err := s.closeCurrent(); err != nil {
+		s.logger.Error("filesink: close failed", zap.Error(err))
+	}
+
+	path, err := s.pathFor(day)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("filesink: open %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("filesink: stat %s: %w", path, err)
+	}
+	w, err := NewRowWriter(f, s.cfg.Format, s.cfg.Compression, info.Size() == 0)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.file, s.w, s.day = f, w, day
+
+	s.prune()
+	return nil
+}
+
+// pathFor returns the file to write day's readings to. CSV reuses the day's
+// file; Parquet picks the first part number that does not exist yet.
+func (s *service) pathFor(day string) (string, error) {
+	base := filepath.Join(s.cfg.Dir, filePrefix+day)
+	if s.cfg.Format != FormatParquet {
+		return base + s.ext, nil
+	}
+	for part := 0; ; part++ {
+		path := base + s.ext
+		if part > 0 {
+			path = fmt.Sprintf("%s.%d%s", base, part, s.ext)
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		} else if err != nil {
+			return "", fmt.Errorf("filesink: stat %s: %w", path, err)
+		}
+	}
+}
+
+// prune removes files for all but the newest Retention days.
+func (s *service) prune() {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(s.cfg.Dir, filePrefix+"*"+s.ext))
+	if err != nil {
+		s.logger.Error("filesink: list files", zap.Error(err))
+		return
+	}
+	byDay := map[string][]string{}
+	for _, m := range matches {
+		name := strings.TrimPrefix(filepath.Base(m), filePrefix)
+		if len(name) < len(time.DateOnly) {
+			continue
+		}
+		day := name[:len(time.DateOnly)]
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			continue
+		}
+		byDay[day] = append(byDay[day], m)
+	}
+	days := make([]string, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	slices.Sort(days)
+	for _, day := range days[:max(len(days)-s.cfg.Retention, 0)] {
+		if day == s.day {
+			continue
+		}
+		for _, path := range byDay[day] {
+			if err := os.Remove(path); err != nil {
+				s.logger.Error("filesink: remove expired file", zap.String("path", path), zap.Error(err))
+				continue
+			}
+			s.logger.Info("filesink: removed expired file", zap.String("path", path))
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package filesink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+var day1 = time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+
+func dp(ts time.Time, slug, value string) publisher.DataPoint {
+	return publisher.DataPoint{Identifier: "SH10RT_SN1", Slug: slug, Value: value, UnitOfMeasurement: "kW", Timestamp: ts}
+}
+
+func newSink(t *testing.T, format, compression string, retention int) (*service, string) {
+	t.Helper()
+	dir := t.TempDir()
+	svc, err := New(&config.FileSinkConfig{Dir: dir, Format: format, Compression: compression, Retention: retention})
+	require.NoError(t, err)
+	return svc, dir
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = gz
+	}
+	records, err := csv.NewReader(r).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestExtension(t *testing.T) {
+	for _, tc := range []struct {
+		format, compression, want string
+	}{
+		{FormatCSV, "", ".csv"},
+		{FormatCSV, CompressionGzip, ".csv.gz"},
+		{FormatParquet, "", ".parquet"},
+		{FormatParquet, CompressionZstd, ".parquet"},
+	} {
+		got, err := Extension(tc.format, tc.compression)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+
+	_, err := Extension(FormatCSV, CompressionZstd)
+	assert.Error(t, err)
+	_, err = Extension("json", "")
+	assert.Error(t, err)
+}
+
+func TestWrite_CSV_RotatesDailyAndAppends(t *testing.T) {
+	svc, dir := newSink(t, FormatCSV, "", 0)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		dp(day1, "battery_power", "1.5"),
+		dp(day1.Add(2*time.Minute), "battery_power", "1.6"),
+	}))
+	require.NoError(t, svc.Close())
+	// A restart on the same day appends without repeating the header.
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(day1.Add(30*time.Second), "battery_power", "1.7")}))
+	require.NoError(t, svc.Close())
+
+	assert.ElementsMatch(t, []string{"readings-2026-03-01.csv", "readings-2026-03-02.csv"}, files(t, dir))
+	assert.Equal(t, [][]string{
+		csvHeader,
+		{"2026-03-01T23:59:00Z", "SH10RT_SN1", "battery_power", "1.5", "kW"},
+		{"2026-03-01T23:59:30Z", "SH10RT_SN1", "battery_power", "1.7", "kW"},
+	}, readCSV(t, filepath.Join(dir, "readings-2026-03-01.csv")))
+	assert.Len(t, readCSV(t, filepath.Join(dir, "readings-2026-03-02.csv")), 2)
+}
+
+func TestWrite_CSV_Gzip(t *testing.T) {
+	svc, dir := newSink(t, FormatCSV, CompressionGzip, 0)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(day1, "battery_power", "1.5")}))
+	require.NoError(t, svc.Close())
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(day1, "load_power", "0.4")}))
+	require.NoError(t, svc.Close())
+
+	records := readCSV(t, filepath.Join(dir, "readings-2026-03-01.csv.gz"))
+	require.Len(t, records, 3, "concatenated gzip members read back as one stream")
+	assert.Equal(t, "load_power", records[2][2])
+}
+
+func TestWrite_Parquet_NewPartPerOpen(t *testing.T) {
+	svc, dir := newSink(t, FormatParquet, CompressionZstd, 0)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(day1, "battery_power", "1.5"), dp(day1, "load_power", "0.4")}))
+	require.NoError(t, svc.Close())
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(day1, "battery_power", "1.6")}))
+	require.NoError(t, svc.Close())
+
+	assert.ElementsMatch(t, []string{"readings-2026-03-01.parquet", "readings-2026-03-01.1.parquet"}, files(t, dir))
+
+	rows, err := parquet.ReadFile[Row](filepath.Join(dir, "readings-2026-03-01.parquet"))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.True(t, day1.Equal(rows[0].Timestamp))
+	assert.Equal(t, Row{Timestamp: rows[1].Timestamp, Identifier: "SH10RT_SN1", Slug: "load_power", Value: "0.4", UnitOfMeasurement: "kW"}, rows[1])
+}
+
+func TestWrite_Retention_KeepsNewestDays(t *testing.T) {
+	svc, dir := newSink(t, FormatCSV, "", 2)
+	ctx := context.Background()
+
+	for i := range 4 {
+		require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(day1.AddDate(0, 0, i), "battery_power", "1")}))
+	}
+	require.NoError(t, svc.Close())
+
+	assert.ElementsMatch(t, []string{"readings-2026-03-03.csv", "readings-2026-03-04.csv"}, files(t, dir))
+}
+
+func TestNewRowWriter_CSVWithoutHeader(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, FormatCSV, "", false)
+	require.NoError(t, err)
+	require.NoError(t, w.Write([]Row{RowFromDataPoint(dp(day1, "battery_power", "1.5"))}))
+	require.NoError(t, w.Close())
+	assert.Equal(t, "2026-03-01T23:59:00Z,SH10RT_SN1,battery_power,1.5,kW\n", buf.String())
+}
@@ -0,0 +1,193 @@
+package filesink
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// Supported formats.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Supported compression codecs. CSV accepts none and gzip; Parquet accepts
+// none, snappy, gzip and zstd. An empty value selects the format default
+// (none for CSV, snappy for Parquet).
+const (
+	CompressionNone   = "none"
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+var csvHeader = []string{"timestamp", "identifier", "slug", "value", "unit_of_measurement"}
+
+// Row is a single reading as written to disk. CSV and Parquet files share
+// these columns so either can be loaded into the same analysis code.
+type Row struct {
+	Timestamp         time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	Identifier        string    `parquet:"identifier,dict"`
+	Slug              string    `parquet:"slug,dict"`
+	Value             string    `parquet:"value"`
+	UnitOfMeasurement string    `parquet:"unit_of_measurement,dict"`
+}
+
+// RowFromDataPoint converts a published reading to a Row.
+func RowFromDataPoint(dp publisher.DataPoint) Row {
+	return Row{
+		Timestamp:         dp.Timestamp.UTC(),
+		Identifier:        dp.Identifier,
+		Slug:              dp.Slug,
+		Value:             dp.Value,
+		UnitOfMeasurement: dp.UnitOfMeasurement,
+	}
+}
+
+// RowFromProperty converts a stored reading to a Row.
+func RowFromProperty(p store.Property) Row {
+	return Row{
+		Timestamp:         p.TimeStamp.UTC(),
+		Identifier:        p.Identifier,
+		Slug:              p.Slug,
+		Value:             p.Value,
+		UnitOfMeasurement: p.UnitOfMeasurement,
+	}
+}
+
+// RowWriter encodes rows onto an underlying io.Writer.
+type RowWriter interface {
+	Write(rows []Row) error
+	// Flush pushes buffered rows to the underlying writer where the format
+	// allows it. Parquet data only becomes readable once Close writes the footer.
+	Flush() error
+	// Close flushes remaining rows and writes any trailer. It does not close
+	// the underlying writer.
+	Close() error
+}
+
+// Extension returns the file extension for format and compression, e.g.
+// ".csv.gz" or ".parquet". It also validates the combination.
+func Extension(format, compression string) (string, error) {
+	switch format {
+	case FormatCSV:
+		switch compression {
+		case "", CompressionNone:
+			return ".csv", nil
+		case CompressionGzip:
+			return ".csv.gz", nil
+		}
+	case FormatParquet:
+		if _, err := parquetCodec(compression); err != nil {
+			return "", err
+		}
+		return ".parquet", nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (want %s or %s)", format, FormatCSV, FormatParquet)
+	}
+	return "", fmt.Errorf("unsupported compression %q for %s", compression, format)
+}
+
+// NewRowWriter returns a RowWriter for format and compression. header controls
+// whether a CSV header line is written; it is ignored for Parquet.
+func NewRowWriter(w io.Writer, format, compression string, header bool) (RowWriter, error) {
+	if _, err := Extension(format, compression); err != nil {
+		return nil, err
+	}
+	if format == FormatParquet {
+		codec, _ := parquetCodec(compression)
+		return &parquetWriter{w: parquet.NewGenericWriter[Row](w, parquet.Compression(codec))}, nil
+	}
+
+	cw := &csvWriter{}
+	if compression == CompressionGzip {
+		cw.gz = gzip.NewWriter(w)
+		w = cw.gz
+	}
+	cw.w = csv.NewWriter(w)
+	if header {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return nil, err
+		}
+	}
+	return cw, nil
+}
+
+func parquetCodec(compression string) (compress.Codec, error) {
+	switch compression {
+	case "", CompressionSnappy:
+		return &parquet.Snappy, nil
+	case CompressionNone:
+		return &parquet.Uncompressed, nil
+	case CompressionGzip:
+		return &parquet.Gzip, nil
+	case CompressionZstd:
+		return &parquet.Zstd, nil
+	}
+	return nil, fmt.Errorf("unsupported compression %q for %s", compression, FormatParquet)
+}
+
+type csvWriter struct {
+	w  *csv.Writer
+	gz *gzip.Writer
+}
+
+func (c *csvWriter) Write(rows []Row) error {
+	for _, r := range rows {
+		if err := c.w.Write([]string{
+			r.Timestamp.UTC().Format(time.RFC3339Nano),
+			r.Identifier,
+			r.Slug,
+			r.Value,
+			r.UnitOfMeasurement,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	if c.gz != nil {
+		return c.gz.Flush()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	if c.gz != nil {
+		return c.gz.Close()
+	}
+	return nil
+}
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[Row]
+}
+
+func (p *parquetWriter) Write(rows []Row) error {
+	_, err := p.w.Write(rows)
+	return err
+}
+
+// Flush is a no-op: row groups are cut by the parquet writer itself, and a
+// partially written file is unreadable until Close writes the footer anyway.
+func (p *parquetWriter) Flush() error { return nil }
+
+func (p *parquetWriter) Close() error { return p.w.Close() }
@@ -0,0 +1,221 @@
+// Package influx is an InfluxDB v2 publisher.Publisher backend.
+// Data points are encoded as line protocol, buffered, and written in batches
+// to the /api/v2/write HTTP endpoint with retry on transient failures.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	retryBase      = 500 * time.Millisecond
+	// maxBufferedLines bounds memory use while InfluxDB is unreachable;
+	// the oldest lines are dropped first.
+	maxBufferedLines = 50_000
+)
+
+// errPermanent marks write failures that must not be retried (4xx other than 429).
+var errPermanent = errors.New("influx: permanent write failure")
+
+type service struct {
+	cfg       *config.InfluxConfig
+	client    *http.Client
+	writeURL  string
+	retryBase time.Duration
+	logger    *zap.Logger
+
+	mu    sync.Mutex
+	lines []string
+
+	flushMu sync.Mutex    // serialises flushes so batches are written in order
+	full    chan struct{} // wakes Run when the buffer reaches BatchSize
+}
+
+// compile-time check that *service satisfies publisher.Publisher.
+var _ publisher.Publisher = (*service)(nil)
+
+// New returns an InfluxDB publisher. Call Run to start periodic flushing.
+func New(cfg *config.InfluxConfig) (*service, error) {
+	if cfg.Org == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("INFLUX_ORG and INFLUX_BUCKET are required when INFLUX_URL is set")
+	}
+	if cfg.BatchSize < 1 {
+		return nil, fmt.Errorf("INFLUX_BATCH_SIZE must be at least 1, got %d", cfg.BatchSize)
+	}
+	if cfg.FlushInterval <= 0 {
+		return nil, fmt.Errorf("INFLUX_FLUSH_INTERVAL must be positive, got %s", cfg.FlushInterval)
+	}
+	q := url.Values{}
+	q.Set("org", cfg.Org)
+	q.Set("bucket", cfg.Bucket)
+	q.Set("precision", "ns")
+
+	return &service{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: requestTimeout},
+		writeURL:  strings.TrimRight(cfg.URL, "/") + "/api/v2/write?" + q.Encode(),
+		retryBase: retryBase,
+		logger:    zap.L(),
+		full:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Name identifies the backend in logs and metrics.
+func (s *service) Name() string { return "influx" }
+
+// Write encodes the data points and buffers them. Once the buffer reaches the
+// configured batch size Run is woken to flush it; Write itself never waits on
+// InfluxDB, so a slow or unreachable server cannot hold up other backends.
+func (s *service) Write(_ context.Context, data []publisher.DataPoint) error {
+	if len(data) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	for _, dp := range data {
+		s.lines = append(s.lines, encodeLine(s.cfg.Measurement, dp))
+	}
+	if over := len(s.lines) - maxBufferedLines; over > 0 {
+		s.logger.Warn("influx: buffer full, dropping oldest lines", zap.Int("dropped", over))
+		s.lines = s.lines[over:]
+	}
+	full := len(s.lines) >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		// A flush is already pending when the channel is full.
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// RegisterDevice is a no-op; devices are represented by the identifier tag.
+func (s *service) RegisterDevice(_ context.Context, _ *model.Device) error {
+	return nil
+}
+
+// Run flushes the buffer every FlushInterval, and whenever Write fills a
+// batch, until ctx is cancelled, then performs a final flush with a short
+// grace period.
+func (s *service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			if err := s.Flush(flushCtx); err != nil {
+				s.logger.Error("influx: final flush failed", zap.Error(err))
+			}
+			cancel()
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.full:
+		}
+		if err := s.Flush(ctx); err != nil {
+			s.logger.Error("influx: flush failed", zap.Error(err))
+		}
+	}
+}
+
+// Flush writes all buffered lines in batches of BatchSize. Batches that fail
+// with a retryable error are put back at the front of the buffer.
+func (s *service) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	pending := s.lines
+	s.lines = nil
+	s.mu.Unlock()
+
+	for len(pending) > 0 {
+		n := min(len(pending), s.cfg.BatchSize)
+		batch := pending[:n]
+		if err := s.writeWithRetry(ctx, batch); err != nil {
+			if errors.Is(err, errPermanent) {
+				s.logger.Error("influx: dropping batch", zap.Int("lines", n), zap.Error(err))
+				pending = pending[n:]
+				continue
+			}
+			s.requeue(pending)
+			return err
+		}
+		pending = pending[n:]
+	}
+	return nil
+}
+
+// requeue puts unsent lines back in front of anything written since the flush began.
+func (s *service) requeue(unsent []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = slices.Concat(unsent, s.lines)
+	if over := len(s.lines) - maxBufferedLines; over > 0 {
+		s.logger.Warn("influx: buffer full, dropping oldest lines", zap.Int("dropped", over))
+		s.lines = s.lines[over:]
+	}
+}
+
+func (s *service) writeWithRetry(ctx context.Context, batch []string) error {
+	body := []byte(strings.Join(batch, "\n"))
+	var err error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.retryBase * (1 << min(attempt-1, 6))):
+			}
+		}
+		if err = s.write(ctx, body); err == nil || errors.Is(err, errPermanent) {
+			return err
+		}
+		s.logger.Warn("influx: write failed, retrying", zap.Int("attempt", attempt+1), zap.Error(err))
+	}
+	return err
+}
+
+func (s *service) write(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.writeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+s.cfg.Token)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode/100 == 2 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	err = fmt.Errorf("influx: write returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	if res.StatusCode/100 == 4 && res.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	return err
+}
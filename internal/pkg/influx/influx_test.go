@@ -0,0 +1,226 @@
+package influx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+// influxStandIn is a minimal /api/v2/write endpoint that records request bodies
+// and replies with the queued status codes (200 once the queue is empty).
+type influxStandIn struct {
+	mu       sync.Mutex
+	bodies   []string
+	queries  []string
+	auth     []string
+	statuses []int
+}
+
+func (f *influxStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	f.bodies = append(f.bodies, string(body))
+	f.queries = append(f.queries, r.URL.RawQuery)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	status := http.StatusNoContent
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// sent returns the request bodies received so far.
+func (f *influxStandIn) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.bodies)
+}
+
+func newTestService(t *testing.T, h http.Handler, batchSize, retries int) *service {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	svc, err := New(&config.InfluxConfig{
+		URL:           srv.URL,
+		Token:         "secret",
+		Org:           "home",
+		Bucket:        "solar",
+		Measurement:   "winet",
+		BatchSize:     batchSize,
+		FlushInterval: time.Hour,
+		MaxRetries:    retries,
+	})
+	require.NoError(t, err)
+	svc.retryBase = time.Millisecond
+	return svc
+}
+
+func dp(slug, value, unit string) publisher.DataPoint {
+	return publisher.DataPoint{
+		Identifier:        "SH10RT_SN1",
+		Slug:              slug,
+		Value:             value,
+		UnitOfMeasurement: unit,
+		Timestamp:         time.Unix(1700000000, 5),
+	}
+}
+
+func TestNew_RequiresOrgAndBucket(t *testing.T) {
+	_, err := New(&config.InfluxConfig{URL: "http://localhost:8086"})
+	assert.Error(t, err)
+}
+
+func TestNew_RejectsBatchSizeBelowOne(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		_, err := New(&config.InfluxConfig{URL: "http://localhost:8086", Org: "home", Bucket: "solar", BatchSize: size, FlushInterval: time.Second})
+		assert.Error(t, err, "batch size %d", size)
+	}
+}
+
+func TestNew_RejectsNonPositiveFlushInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := New(&config.InfluxConfig{URL: "http://localhost:8086", Org: "home", Bucket: "solar", BatchSize: 10, FlushInterval: interval})
+		assert.Error(t, err, "flush interval %s", interval)
+	}
+}
+
+func TestEncodeLine(t *testing.T) {
+	cases := []struct {
+		name string
+		in   publisher.DataPoint
+		want string
+	}{
+		{"numeric", dp("battery_power", "2.5000", "kW"), `winet,identifier=SH10RT_SN1,slug=battery_power,unit=kW value=2.5 1700000000000000005`},
+		{"text sensor", dp("running_status", "Run \"ok\"", ""), `winet,identifier=SH10RT_SN1,slug=running_status text="Run \"ok\"" 1700000000000000005`},
+		{"unparseable", dp("battery_power", "--", "kW"), `winet,identifier=SH10RT_SN1,slug=battery_power,unit=kW text="--" 1700000000000000005`},
+		{"escaped tags", dp("a b", "1", "m=s,x"), `winet,identifier=SH10RT_SN1,slug=a\ b,unit=m\=s\,x value=1 1700000000000000005`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, encodeLine("winet", tc.in))
+		})
+	}
+}
+
+func TestWrite_BuffersUntilBatchSize(t *testing.T) {
+	standIn := &influxStandIn{}
+	svc := newTestService(t, standIn, 2, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp("battery_power", "1", "kW")}))
+	assert.Empty(t, standIn.sent(), "below batch size nothing is sent")
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp("load_power", "2", "kW")}))
+	require.Eventually(t, func() bool { return len(standIn.sent()) == 1 }, time.Second, 5*time.Millisecond,
+		"a full batch wakes Run without waiting for the flush interval")
+	cancel()
+	<-done
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	assert.Equal(t, 2, len(strings.Split(standIn.bodies[0], "\n")))
+	assert.Equal(t, "Token secret", standIn.auth[0])
+	assert.Contains(t, standIn.queries[0], "bucket=solar")
+	assert.Contains(t, standIn.queries[0], "org=home")
+	assert.Contains(t, standIn.queries[0], "precision=ns")
+}
+
+func TestWrite_DoesNotWaitForAHangingServer(t *testing.T) {
+	release := make(chan struct{})
+	hang := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	svc := newTestService(t, hang, 1, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+	defer func() {
+		close(release)
+		cancel()
+		<-done
+	}()
+
+	wrote := make(chan struct{})
+	go func() {
+		defer close(wrote)
+		for range 10 {
+			assert.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp("battery_power", "1", "kW")}))
+		}
+	}()
+	select {
+	case <-wrote:
+	case <-time.After(time.Second):
+		t.Fatal("Write blocked while InfluxDB hangs")
+	}
+}
+
+func TestFlush_SplitsIntoBatches(t *testing.T) {
+	standIn := &influxStandIn{}
+	svc := newTestService(t, standIn, 2, 0)
+	svc.lines = []string{"a", "b", "c"}
+
+	require.NoError(t, svc.Flush(context.Background()))
+	assert.Equal(t, []string{"a\nb", "c"}, standIn.bodies)
+}
+
+func TestFlush_RetriesTransientErrors(t *testing.T) {
+	standIn := &influxStandIn{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	svc := newTestService(t, standIn, 10, 3)
+	svc.lines = []string{"a"}
+
+	require.NoError(t, svc.Flush(context.Background()))
+	assert.Len(t, standIn.bodies, 3)
+	assert.Empty(t, svc.lines)
+}
+
+func TestFlush_RequeuesAfterRetriesExhausted(t *testing.T) {
+	standIn := &influxStandIn{statuses: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	svc := newTestService(t, standIn, 10, 1)
+	svc.lines = []string{"a", "b"}
+
+	require.Error(t, svc.Flush(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, svc.lines, "unsent lines stay buffered for the next flush")
+
+	require.NoError(t, svc.Flush(context.Background()))
+	assert.Empty(t, svc.lines)
+}
+
+func TestFlush_DropsPermanentFailures(t *testing.T) {
+	standIn := &influxStandIn{statuses: []int{http.StatusBadRequest}}
+	svc := newTestService(t, standIn, 1, 3)
+	svc.lines = []string{"bad", "good"}
+
+	require.NoError(t, svc.Flush(context.Background()))
+	assert.Equal(t, []string{"bad", "good"}, standIn.bodies, "400 is not retried; the next batch still goes out")
+	assert.Empty(t, svc.lines)
+}
+
+func TestRun_FinalFlushOnShutdown(t *testing.T) {
+	standIn := &influxStandIn{}
+	svc := newTestService(t, standIn, 100, 0)
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{dp("battery_power", "1", "kW")}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, svc.Run(ctx), context.Canceled)
+	assert.Len(t, standIn.bodies, 1)
+}
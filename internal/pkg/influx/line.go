@@ -0,0 +1,47 @@
+package influx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringFieldEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// encodeLine renders a DataPoint as a single line-protocol record:
+//
+//	<measurement>,identifier=<id>,slug=<slug>[,unit=<unit>] value=<float>|text="<str>" <unix-ns>
+//
+// Numeric readings are written to the float field "value"; text sensors and
+// values that fail to parse go to the string field "text" so the two never
+// conflict on field type.
+func encodeLine(measurement string, dp publisher.DataPoint) string {
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(measurement))
+	b.WriteString(",identifier=")
+	b.WriteString(tagEscaper.Replace(dp.Identifier))
+	b.WriteString(",slug=")
+	b.WriteString(tagEscaper.Replace(dp.Slug))
+	if dp.UnitOfMeasurement != "" {
+		b.WriteString(",unit=")
+		b.WriteString(tagEscaper.Replace(dp.UnitOfMeasurement))
+	}
+
+	if f, ok := dp.Float(); ok {
+		b.WriteString(" value=")
+		b.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+	} else {
+		b.WriteString(` text="`)
+		b.WriteString(stringFieldEscaper.Replace(dp.Value))
+		b.WriteString(`"`)
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(dp.Timestamp.UnixNano(), 10))
+	return b.String()
+}
@@ -0,0 +1,103 @@
+// Package metrics exposes inverter readings and service internals to Prometheus.
+// All collectors are registered on Registry, which is served by Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "winet"
+
+// Registry holds every collector exported by the service. A dedicated registry
+// keeps test binaries and tools from sharing prometheus.DefaultRegisterer.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// PollCycleDuration observes how long one full device poll cycle takes.
+	PollCycleDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "poll_cycle_duration_seconds",
+		Help:      "Duration of a complete WiNet device poll cycle.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 5, 10, 20, 30, 60},
+	})
+
+	// WinetReconnects counts reconnect attempts after a failure or session event.
+	WinetReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconnects_total",
+		Help:      "Number of WiNet reconnect attempts.",
+	})
+
+	// Commands counts inverter/battery commands by type and outcome
+	// (success, failure or error).
+	Commands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Inverter and battery commands sent, by command type and result.",
+	}, []string{"command", "result"})
+
+	// PublishErrors counts failed Write calls per publisher backend.
+	PublishErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "publish_errors_total",
+		Help:      "Failed publisher writes, by backend.",
+	}, []string{"backend"})
+
+	// DualWriteDropped counts writes a dual-write secondary never received,
+	// by secondary and reason (queue_full, max_attempts or shutdown).
+	DualWriteDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dualwrite_dropped_writes_total",
+		Help:      "Writes dropped for a dual-write secondary, by secondary and reason.",
+	}, []string{"secondary", "reason"})
+
+	// HTTPRequestDuration observes API latency by route pattern, method and status code.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP API request latency, by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		PollCycleDuration,
+		WinetReconnects,
+		Commands,
+		PublishErrors,
+		DualWriteDropped,
+		HTTPRequestDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveCommand records the outcome of an inverter command. A command that
+// returns an error is counted as "error"; a rejected command as "failure".
+func ObserveCommand(command string, success bool, err error) {
+	result := "success"
+	switch {
+	case err != nil:
+		result = "error"
+	case !success:
+		result = "failure"
+	}
+	Commands.WithLabelValues(command, result).Inc()
+}
+
+// ObserveHTTPRequest records the latency of a completed API request.
+func ObserveHTTPRequest(route, method string, code int, elapsed time.Duration) {
+	HTTPRequestDuration.WithLabelValues(route, method, strconv.Itoa(code)).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+func TestPublisher_Write_ExportsNumericReadings(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p := NewPublisher(reg)
+
+	now := time.Now()
+	require.NoError(t, p.Write(context.Background(), []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "2.5000", UnitOfMeasurement: "kW", Timestamp: now},
+		{Identifier: "SH10RT_SN1", Slug: "running_status", Value: "Running", Timestamp: now},
+		{Identifier: "SH10RT_SN1", Slug: "battery_level", Value: "--", UnitOfMeasurement: "%", Timestamp: now},
+	}))
+
+	expected := `
+# HELP winet_reading Latest numeric reading reported by the inverter.
+# TYPE winet_reading gauge
+winet_reading{identifier="SH10RT_SN1",slug="battery_power",unit="kW"} 2.5
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "winet_reading"))
+}
+
+func TestPublisher_Write_UnitChangeReplacesSeries(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p := NewPublisher(reg)
+	ctx := context.Background()
+
+	require.NoError(t, p.Write(ctx, []publisher.DataPoint{{Identifier: "SN1", Slug: "reactive_power", Value: "1", UnitOfMeasurement: "kvar"}}))
+	require.NoError(t, p.Write(ctx, []publisher.DataPoint{{Identifier: "SN1", Slug: "reactive_power", Value: "1000", UnitOfMeasurement: "var"}}))
+
+	assert.Equal(t, 1, testutil.CollectAndCount(p.readings))
+	assert.InDelta(t, 1000.0, testutil.ToFloat64(p.readings.WithLabelValues("SN1", "reactive_power", "var")), 1e-9)
+}
+
+func TestObserveCommand(t *testing.T) {
+	success := testutil.ToFloat64(Commands.WithLabelValues("charge", "success"))
+	failure := testutil.ToFloat64(Commands.WithLabelValues("charge", "failure"))
+	errored := testutil.ToFloat64(Commands.WithLabelValues("charge", "error"))
+
+	ObserveCommand("charge", true, nil)
+	ObserveCommand("charge", false, nil)
+	ObserveCommand("charge", false, errors.New("timed out"))
+
+	assert.InDelta(t, success+1, testutil.ToFloat64(Commands.WithLabelValues("charge", "success")), 1e-9)
+	assert.InDelta(t, failure+1, testutil.ToFloat64(Commands.WithLabelValues("charge", "failure")), 1e-9)
+	assert.InDelta(t, errored+1, testutil.ToFloat64(Commands.WithLabelValues("charge", "error")), 1e-9)
+}
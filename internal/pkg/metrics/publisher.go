@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+// compile-time check that *Publisher satisfies publisher.Publisher.
+var _ publisher.Publisher = (*Publisher)(nil)
+
+// Publisher keeps the latest value of every numeric DataPoint as a gauge
+// labelled by identifier, slug and unit. Text sensors are skipped.
+type Publisher struct {
+	readings *prometheus.GaugeVec
+
+	mu    sync.Mutex
+	units map[[2]string]string // identifier+slug -> unit label currently exported
+}
+
+// NewPublisher creates the readings gauge and registers it on reg.
+func NewPublisher(reg prometheus.Registerer) *Publisher {
+	readings := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reading",
+		Help:      "Latest numeric reading reported by the inverter.",
+	}, []string{"identifier", "slug", "unit"})
+	reg.MustRegister(readings)
+	return &Publisher{
+		readings: readings,
+		units:    make(map[[2]string]string),
+	}
+}
+
+// Name identifies the backend in publish error metrics.
+func (p *Publisher) Name() string { return "prometheus" }
+
+// Write updates the gauge for every numeric data point.
+func (p *Publisher) Write(_ context.Context, data []publisher.DataPoint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, dp := range data {
+		v, ok := dp.Float()
+		if !ok {
+			continue
+		}
+		key := [2]string{dp.Identifier, dp.Slug}
+		// Drop the old series if the unit changed so a reading is only exported once.
+		if old, seen := p.units[key]; seen && old != dp.UnitOfMeasurement {
+			p.readings.DeleteLabelValues(dp.Identifier, dp.Slug, old)
+		}
+		p.units[key] = dp.UnitOfMeasurement
+		p.readings.WithLabelValues(dp.Identifier, dp.Slug, dp.UnitOfMeasurement).Set(v)
+	}
+	return nil
+}
+
+// RegisterDevice is a no-op; devices appear through the identifier label.
+func (p *Publisher) RegisterDevice(_ context.Context, _ *model.Device) error {
+	return nil
+}
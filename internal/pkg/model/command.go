@@ -0,0 +1,33 @@
+package model
+
+import "context"
+
+// CommandSource says what issued an inverter or battery command.
+type CommandSource string
+
+const (
+	CommandSourceAPI        CommandSource = "api"
+	CommandSourceAutomation CommandSource = "automation"
+	CommandSourceMQTT       CommandSource = "mqtt"
+)
+
+// CommandOrigin identifies who issued a command, for the audit log.
+type CommandOrigin struct {
+	Source CommandSource
+	// User is the authenticated username; empty for automation.
+	User string
+}
+
+type commandOriginKey struct{}
+
+// WithCommandOrigin returns a copy of ctx that carries origin to the
+// inverter command it is passed to.
+func WithCommandOrigin(ctx context.Context, origin CommandOrigin) context.Context {
+	return context.WithValue(ctx, commandOriginKey{}, origin)
+}
+
+// CommandOriginFromContext returns the origin stored by WithCommandOrigin.
+func CommandOriginFromContext(ctx context.Context) (CommandOrigin, bool) {
+	o, ok := ctx.Value(commandOriginKey{}).(CommandOrigin)
+	return o, ok
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type RegisterDevice struct {
 	Name         string   `json:"name"`
 	Identifiers  []string `json:"identifiers"`
@@ -21,6 +23,22 @@ type Device struct {
 	SerialNumber string
 }
 
+// CommandEvent describes an inverter or battery command after WiNet replied.
+// Source and User come from the CommandOrigin of the command's context;
+// Device is the identifier of the inverter the session is connected to.
+type CommandEvent struct {
+	Command    string            `json:"command"`
+	Device     string            `json:"device,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Source     CommandSource     `json:"source,omitempty"`
+	User       string            `json:"user,omitempty"`
+	Success    bool              `json:"success"`
+	ResultCode int               `json:"result_code,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+	LatencyMs  int64             `json:"latency_ms"`
+}
+
 type DeviceStatus struct {
 	Name  string  `json:"name"`
 	Slug  string  `json:"slug"`
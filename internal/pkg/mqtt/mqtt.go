@@ -2,6 +2,7 @@ package mqtt
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	paho_mqtt "github.com/eclipse/paho.mqtt.golang"
@@ -11,15 +12,35 @@ import (
 type service struct {
 	client paho_mqtt.Client
 	logger *zap.Logger
+
+	snapshot   bool
+	snapshotMu sync.Mutex
+	latest     map[string]map[string]snapshotValue // identifier -> slug -> value
+}
+
+// WithSnapshot toggles publishing a retained whole-device JSON document to
+// <prefix>/<identifier>/snapshot after every written batch.
+func WithSnapshot(enabled bool) func(*service) {
+	return func(s *service) {
+		s.snapshot = enabled
+	}
 }
 
-func New(client paho_mqtt.Client) *service {
-	return &service{
+func New(client paho_mqtt.Client, opts ...func(*service)) *service {
+	s := &service{
 		client: client,
 		logger: zap.L(), // returns the global logger.
+		latest: make(map[string]map[string]snapshotValue),
+	}
+	for _, o := range opts {
+		o(s)
 	}
+	return s
 }
 
+// Name identifies the backend in logs and metrics.
+func (s *service) Name() string { return "mqtt" }
+
 func (s *service) Connect() error {
 	configuredDevices = make(map[string]struct{})
 	token := s.client.Connect()
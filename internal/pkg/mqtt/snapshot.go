@@ -0,0 +1,97 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+// deviceSnapshot is the retained whole-device document published to
+// <prefix>/<identifier>/snapshot.
+type deviceSnapshot struct {
+	Identifier string                   `json:"identifier"`
+	Timestamp  time.Time                `json:"timestamp"`
+	Values     map[string]snapshotValue `json:"values"`
+}
+
+type snapshotValue struct {
+	Value             string    `json:"value"`
+	UnitOfMeasurement string    `json:"unit_of_measurement,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// publishSnapshots folds the batch into the in-memory device state and
+// publishes a retained snapshot for every identifier present in the batch.
+// MultiPublisher only forwards changed readings, so the snapshot is built from
+// the accumulated state rather than from the batch alone.
+func (s *service) publishSnapshots(data []publisher.DataPoint) error {
+	pollTimes := make(map[string]time.Time)
+
+	s.snapshotMu.Lock()
+	for _, d := range data {
+		s.fold(d)
+		if d.Timestamp.After(pollTimes[d.Identifier]) {
+			pollTimes[d.Identifier] = d.Timestamp
+		}
+	}
+
+	payloads := make(map[string][]byte, len(pollTimes))
+	for identifier, ts := range pollTimes {
+		payload, err := json.Marshal(deviceSnapshot{
+			Identifier: identifier,
+			Timestamp:  ts,
+			Values:     s.latest[identifier],
+		})
+		if err != nil {
+			s.snapshotMu.Unlock()
+			return err
+		}
+		payloads[identifier] = payload
+	}
+	s.snapshotMu.Unlock()
+
+	for identifier, payload := range payloads {
+		topic := fmt.Sprintf("%s/%s/snapshot", topicPrefix, identifier)
+		token := s.client.Publish(topic, 1, true, payload)
+		if !token.WaitTimeout(time.Second * 10) {
+			return fmt.Errorf("unable to publish %s in time", topic)
+		}
+		if err := token.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Seed folds previously stored readings into the device state without
+// publishing, so the first snapshot after a restart carries every known
+// value rather than only those seen since startup. Readings older than the
+// state already held for their series are ignored.
+func (s *service) Seed(data []publisher.DataPoint) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	for _, d := range data {
+		if cur, ok := s.latest[d.Identifier][d.Slug]; ok && !d.Timestamp.After(cur.Timestamp) {
+			continue
+		}
+		s.fold(d)
+	}
+}
+
+// fold records d as the latest value of its series. Callers must hold
+// s.snapshotMu.
+func (s *service) fold(d publisher.DataPoint) {
+	values, ok := s.latest[d.Identifier]
+	if !ok {
+		values = make(map[string]snapshotValue)
+		s.latest[d.Identifier] = values
+	}
+	v := snapshotValue{Value: d.Value, Timestamp: d.Timestamp}
+	if !model.TextSensors.HasSlug(d.Slug) {
+		v.UnitOfMeasurement = d.UnitOfMeasurement
+	}
+	values[d.Slug] = v
+}
@@ -0,0 +1,174 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	paho_mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+type published struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// fakeClient records every Publish call. Unused paho methods panic via the nil embedded interface.
+type fakeClient struct {
+	paho_mqtt.Client
+	published []published
+	// token is returned by Publish; doneToken when nil.
+	token paho_mqtt.Token
+}
+
+func (c *fakeClient) Publish(topic string, qos byte, retained bool, payload any) paho_mqtt.Token {
+	c.published = append(c.published, published{topic: topic, qos: qos, retained: retained, payload: payload.([]byte)})
+	if c.token != nil {
+		return c.token
+	}
+	return doneToken{}
+}
+
+type doneToken struct{}
+
+func (doneToken) Wait() bool                     { return true }
+func (doneToken) WaitTimeout(time.Duration) bool { return true }
+func (doneToken) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+func (doneToken) Error() error { return nil }
+
+// timedOutToken never completes.
+type timedOutToken struct{ doneToken }
+
+func (timedOutToken) WaitTimeout(time.Duration) bool { return false }
+
+func (c *fakeClient) snapshots() []published {
+	var out []published
+	for _, p := range c.published {
+		if strings.HasSuffix(p.topic, "/snapshot") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func TestWrite_SnapshotDisabled_PublishesOnlyStateTopics(t *testing.T) {
+	client := &fakeClient{}
+	svc := New(client)
+
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW", Timestamp: time.Now()},
+	}))
+
+	assert.Empty(t, client.snapshots())
+	require.Len(t, client.published, 1)
+	assert.Equal(t, "homeassistant/sensor/SH10RT_SN1/battery_power/state", client.published[0].topic)
+}
+
+func TestWrite_Snapshot_AccumulatesStateAcrossBatches(t *testing.T) {
+	client := &fakeClient{}
+	svc := New(client, WithSnapshot(true))
+	ctx := context.Background()
+
+	t1 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(30 * time.Second)
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW", Timestamp: t1},
+		{Identifier: "SH10RT_SN1", Slug: "running_status", Value: "Running", UnitOfMeasurement: "", Timestamp: t1},
+	}))
+	// Second cycle only carries the changed reading.
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "2.5000", UnitOfMeasurement: "kW", Timestamp: t2},
+	}))
+
+	snaps := client.snapshots()
+	require.Len(t, snaps, 2)
+	last := snaps[1]
+	assert.Equal(t, "homeassistant/sensor/SH10RT_SN1/snapshot", last.topic)
+	assert.True(t, last.retained)
+	assert.Equal(t, byte(1), last.qos)
+
+	var got deviceSnapshot
+	require.NoError(t, json.Unmarshal(last.payload, &got))
+	assert.Equal(t, "SH10RT_SN1", got.Identifier)
+	assert.True(t, t2.Equal(got.Timestamp))
+	require.Len(t, got.Values, 2)
+	assert.Equal(t, "2.5000", got.Values["battery_power"].Value)
+	assert.Equal(t, "kW", got.Values["battery_power"].UnitOfMeasurement)
+	assert.Equal(t, "Running", got.Values["running_status"].Value)
+	assert.Empty(t, got.Values["running_status"].UnitOfMeasurement)
+}
+
+func TestWrite_Snapshot_OnePerIdentifier(t *testing.T) {
+	client := &fakeClient{}
+	svc := New(client, WithSnapshot(true))
+
+	now := time.Now()
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW", Timestamp: now},
+		{Identifier: "SBR096_SN2", Slug: "battery_voltage", Value: "400.0000", UnitOfMeasurement: "V", Timestamp: now},
+	}))
+
+	topics := []string{}
+	for _, p := range client.snapshots() {
+		topics = append(topics, p.topic)
+	}
+	assert.ElementsMatch(t, []string{
+		"homeassistant/sensor/SH10RT_SN1/snapshot",
+		"homeassistant/sensor/SBR096_SN2/snapshot",
+	}, topics)
+}
+
+func TestWrite_Snapshot_SeededFromStoredReadings(t *testing.T) {
+	client := &fakeClient{}
+	svc := New(client, WithSnapshot(true))
+
+	t1 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	svc.Seed([]publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW", Timestamp: t1},
+		{Identifier: "SH10RT_SN1", Slug: "running_status", Value: "Running", UnitOfMeasurement: "", Timestamp: t1},
+	})
+	assert.Empty(t, client.published, "seeding publishes nothing")
+
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "2.5000", UnitOfMeasurement: "kW", Timestamp: t2},
+	}))
+	// A late seed does not overwrite the newer reading.
+	svc.Seed([]publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW", Timestamp: t1},
+	})
+
+	snaps := client.snapshots()
+	require.Len(t, snaps, 1)
+	var got deviceSnapshot
+	require.NoError(t, json.Unmarshal(snaps[0].payload, &got))
+	require.Len(t, got.Values, 2, "the first snapshot carries the stored readings too")
+	assert.Equal(t, "2.5000", got.Values["battery_power"].Value)
+	assert.Equal(t, "Running", got.Values["running_status"].Value)
+	assert.True(t, t1.Equal(got.Values["running_status"].Timestamp))
+	assert.Equal(t, "2.5000", svc.latest["SH10RT_SN1"]["battery_power"].Value)
+}
+
+func TestWrite_Snapshot_PublishTimeoutIsAnError(t *testing.T) {
+	client := &fakeClient{token: timedOutToken{}}
+	svc := New(client, WithSnapshot(true))
+
+	err := svc.Write(context.Background(), []publisher.DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW", Timestamp: time.Now()},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "homeassistant/sensor/SH10RT_SN1/snapshot")
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/anicoll/winet-integration/internal/pkg/publisher"
 )
 
+// topicPrefix is the Home Assistant discovery prefix shared by every topic we publish.
+const topicPrefix = "homeassistant/sensor"
+
 var configuredDevices map[string]struct{}
 
 func (s *service) Write(ctx context.Context, data []publisher.DataPoint) error {
@@ -19,6 +22,9 @@ func (s *service) Write(ctx context.Context, data []publisher.DataPoint) error {
 			return err
 		}
 	}
+	if s.snapshot {
+		return s.publishSnapshots(data)
+	}
 	return nil
 }
 
@@ -29,7 +35,7 @@ func (s *service) RegisterDevice(_ context.Context, device *model.Device) error
 	registerMessage := defaultRegisterMsg(device)
 	slugIdentifier := fmt.Sprintf("%s_%s", device.Model, device.SerialNumber)
 
-	topic := fmt.Sprintf("homeassistant/sensor/%s/config", slugIdentifier)
+	topic := fmt.Sprintf("%s/%s/config", topicPrefix, slugIdentifier)
 
 	payload, err := json.Marshal(registerMessage)
 	if err != nil {
@@ -48,7 +54,7 @@ func (s *service) RegisterDevice(_ context.Context, device *model.Device) error
 
 func (s *service) publishDataPoint(data publisher.DataPoint) error {
 	isTextSensor := model.TextSensors.HasSlug(data.Slug)
-	topic := fmt.Sprintf("homeassistant/sensor/%s/%s/state", data.Identifier, data.Slug)
+	topic := fmt.Sprintf("%s/%s/%s/state", topicPrefix, data.Identifier, data.Slug)
 
 	payload := map[string]string{
 		"value": data.Value,
@@ -78,7 +84,7 @@ func defaultRegisterMsg(device *model.Device) model.RegisterMessage {
 	slugIdentifier := fmt.Sprintf("%s_%s", device.Model, device.SerialNumber)
 
 	return model.RegisterMessage{
-		Tilda:      fmt.Sprintf("homeassistant/sensor/%s", slugIdentifier),
+		Tilda:      fmt.Sprintf("%s/%s", topicPrefix, slugIdentifier),
 		Name:       name,
 		ID:         strings.ToLower(slugIdentifier),
 		StateTopic: "~/state",
@@ -57,6 +57,7 @@ func (Normalizer) Normalize(device model.Device, status model.DeviceStatus) (Dat
 		Timestamp:         time.Now(),
 		Identifier:        slugIdentifier,
 		UnitOfMeasurement: status.Unit,
+		Name:              status.Name,
 	}, false
 }
 
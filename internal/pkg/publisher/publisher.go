@@ -3,6 +3,10 @@ package publisher
 import (
 	"context"
 	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +23,23 @@ type DataPoint struct {
 	Timestamp         time.Time
 	Identifier        string
 	UnitOfMeasurement string
+	// Name is the sensor's display name from the WiNet i18n properties,
+	// empty when unknown.
+	Name string
+}
+
+// Float returns the reading as a float64. It reports false for text sensors
+// and values that do not parse as a finite number: ParseFloat accepts "NaN"
+// and "Inf", which would poison rollup sums and cannot be encoded as JSON.
+func (d DataPoint) Float() (float64, bool) {
+	if model.TextSensors.HasSlug(d.Slug) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(d.Value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
 }
 
 // Publisher is implemented by each backend (MQTT, database, etc.).
@@ -34,11 +55,27 @@ type DataPublisher interface {
 	RegisterDevice(ctx context.Context, device *model.Device) error
 }
 
+// Name returns the backend name used in logs and metrics. Backends may
+// implement Name() string; otherwise the lower-cased type name is used.
+func Name(p Publisher) string {
+	if n, ok := p.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return strings.ToLower(t.String())
+}
+
 // MultiPublisher normalizes device data and fans it out to a set of Publisher backends.
 type MultiPublisher struct {
 	publishers []Publisher
+	routers    []*router // parallel to publishers; nil means no route
 	normalizer Normalizer
 	sensors    sync.Map
+
+	onWriteError func(backend string, err error)
 }
 
 // NewMultiPublisher returns a MultiPublisher that writes to all given backends.
@@ -46,15 +83,58 @@ func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
 	return &MultiPublisher{publishers: publishers}
 }
 
-// PublishData normalizes device statuses and writes deduplicated DataPoints to all backends.
+// SetWriteErrorHook registers a callback invoked with the backend name each
+// time a backend Write fails. The callback must be non-blocking.
+func (m *MultiPublisher) SetWriteErrorHook(fn func(backend string, err error)) {
+	m.onWriteError = fn
+}
+
+// SetRoutes installs per-backend routing rules, keyed by backend name. It
+// returns an error if a route names a backend that is not registered, so
+// typos do not silently leave a backend unfiltered.
+func (m *MultiPublisher) SetRoutes(routes Routes) error {
+	if err := routes.Validate(); err != nil {
+		return err
+	}
+	routers := make([]*router, len(m.publishers))
+	matched := map[string]bool{}
+	for i, p := range m.publishers {
+		name := Name(p)
+		if route, ok := routes[name]; ok {
+			routers[i] = newRouter(route)
+			matched[name] = true
+		}
+	}
+	var unknown []string
+	for name := range routes {
+		if !matched[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("routes for unregistered backends: %s", strings.Join(unknown, ", "))
+	}
+	m.routers = routers
+	return nil
+}
+
+// PublishData normalizes device statuses and writes deduplicated DataPoints
+// to all backends, applying each backend's route if one is set.
 func (m *MultiPublisher) PublishData(ctx context.Context, deviceStatusMap map[model.Device][]model.DeviceStatus) error {
 	data := make([]DataPoint, 0)
+	// now is the newest reading time, deduplicated readings included, which
+	// routes measure min_interval by.
+	var now time.Time
 	for device, statuses := range deviceStatusMap {
 		for _, status := range statuses {
 			dp, skip := m.normalizer.Normalize(device, status)
 			if skip {
 				continue
 			}
+			if dp.Timestamp.After(now) {
+				now = dp.Timestamp
+			}
 			key := fmt.Sprintf("%s_%s", dp.Identifier, dp.Slug)
 			if !m.shouldUpdate(key, dp.Value) {
 				continue
@@ -62,9 +142,19 @@ func (m *MultiPublisher) PublishData(ctx context.Context, deviceStatusMap map[mo
 			data = append(data, dp)
 		}
 	}
-	for _, p := range m.publishers {
-		if err := p.Write(ctx, data); err != nil {
-			zap.L().Error("failed to publish data", zap.Error(err))
+	for i, p := range m.publishers {
+		batch := data
+		if m.routers != nil && m.routers[i] != nil {
+			if batch = m.routers[i].apply(data, now); len(batch) == 0 {
+				continue
+			}
+		}
+		if err := p.Write(ctx, batch); err != nil {
+			backend := Name(p)
+			zap.L().Error("failed to publish data", zap.Error(err), zap.String("backend", backend))
+			if m.onWriteError != nil {
+				m.onWriteError(backend, err)
+			}
 		}
 	}
 	zap.L().Debug("published data points", zap.Int("count", len(data)))
@@ -133,6 +133,15 @@ func TestNormalizer_TextSensor_NilValue_Skips(t *testing.T) {
 	assert.True(t, skip)
 }
 
+func TestNormalizer_CarriesDisplayName(t *testing.T) {
+	n := Normalizer{}
+	device := model.Device{ID: "1", Model: "XH3000", SerialNumber: "SN-NAME"}
+	v := "80"
+	dp, skip := n.Normalize(device, model.DeviceStatus{Name: "Battery Level (SOC)", Slug: "battery_level", Unit: "%", Value: &v})
+	require.False(t, skip)
+	assert.Equal(t, "Battery Level (SOC)", dp.Name)
+}
+
 func TestNormalizer_SlugIdentifier_DotsStripped(t *testing.T) {
 	n := Normalizer{}
 	device := model.Device{ID: "1", Model: "SH5.0RS", SerialNumber: "SN001"}
@@ -222,3 +231,48 @@ func TestMultiPublisher_PublishData_BackendErrorDoesNotStopOtherBackends(t *test
 	// p2 must still have been called despite p1 failing.
 	assert.Len(t, p2.writes, 1)
 }
+
+// --- DataPoint.Float ---
+
+func TestDataPoint_Float(t *testing.T) {
+	f, ok := DataPoint{Slug: "battery_power", Value: "3.2000"}.Float()
+	assert.True(t, ok)
+	assert.InDelta(t, 3.2, f, 1e-9)
+
+	_, ok = DataPoint{Slug: "running_status", Value: "1"}.Float()
+	assert.False(t, ok, "text sensors are never numeric")
+
+	_, ok = DataPoint{Slug: "battery_power", Value: "n/a"}.Float()
+	assert.False(t, ok)
+
+	for _, v := range []string{"NaN", "Inf", "-Inf", "+infinity", "1e400"} {
+		_, ok = DataPoint{Slug: "battery_power", Value: v}.Float()
+		assert.False(t, ok, v)
+	}
+}
+
+type namedPublisher struct{ stubPublisher }
+
+func (*namedPublisher) Name() string { return "named" }
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "named", Name(&namedPublisher{}))
+	assert.Equal(t, "publisher.stubpublisher", Name(&stubPublisher{}))
+}
+
+func TestMultiPublisher_PublishData_WriteErrorHook(t *testing.T) {
+	p1 := &namedPublisher{stubPublisher{writeErr: errors.New("backend down")}}
+	p2 := &stubPublisher{}
+	mp := NewMultiPublisher(p1, p2)
+
+	var failed []string
+	mp.SetWriteErrorHook(func(backend string, _ error) { failed = append(failed, backend) })
+
+	v := "5.0"
+	device := model.Device{ID: "1", Model: "XH3000", SerialNumber: "SN-HOOK"}
+	require.NoError(t, mp.PublishData(context.Background(), map[model.Device][]model.DeviceStatus{
+		device: {{Slug: "battery_power", Unit: "kW", Value: &v}},
+	}))
+
+	assert.Equal(t, []string{"named"}, failed)
+}
@@ -0,0 +1,253 @@
+package publisher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Routes maps a backend name (see Name) to the rule that decides which data
+// points that backend receives. Backends without a route receive everything.
+//
+// Routes are loaded from YAML, for example:
+//
+//	mqtt:
+//	  include_slugs: ["battery_*", "load_power"]
+//	  min_interval: 1m
+//	webhook:
+//	  include_slugs: ["*_energy", "*_yield"]
+//	  exclude_identifiers: ["SBR096_*"]
+//	  transforms:
+//	    - slugs: ["*_energy", "*_yield"]
+//	      scale: 1000
+//	      unit: Wh
+//	      precision: 0
+type Routes map[string]Route
+
+// Route filters and transforms the data points sent to one backend.
+// Patterns are path.Match globs. A point must match an include pattern (when
+// any are set) and no exclude pattern for both slug and identifier.
+type Route struct {
+	IncludeSlugs       []string `yaml:"include_slugs"`
+	ExcludeSlugs       []string `yaml:"exclude_slugs"`
+	IncludeIdentifiers []string `yaml:"include_identifiers"`
+	ExcludeIdentifiers []string `yaml:"exclude_identifiers"`
+	// MinInterval holds back points for an identifier+slug timestamped sooner
+	// than this after the last point forwarded for it. The latest held-back
+	// point is forwarded once the readings show the interval has elapsed, so a
+	// backend never misses the final value of a series that then stops
+	// changing. Points older than one already seen for the series are
+	// dropped. All of this runs on reading timestamps, never the wall clock.
+	MinInterval time.Duration `yaml:"min_interval"`
+	// Transforms are applied in order to the numeric points they match.
+	Transforms []Transform `yaml:"transforms"`
+}
+
+// Transform rewrites numeric values as value*Scale + Offset, optionally
+// replacing the unit and rounding to Precision decimal places.
+type Transform struct {
+	Slugs     []string `yaml:"slugs"`
+	Scale     *float64 `yaml:"scale"`
+	Offset    float64  `yaml:"offset"`
+	Unit      string   `yaml:"unit"`
+	Precision *int     `yaml:"precision"`
+}
+
+// defaultPrecision matches the number of decimals produced by the Normalizer.
+const defaultPrecision = 4
+
+// LoadRoutes reads and validates routes from a YAML file.
+func LoadRoutes(file string) (Routes, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read routes: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	var routes Routes
+	if err := dec.Decode(&routes); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse routes %s: %w", file, err)
+	}
+	if err := routes.Validate(); err != nil {
+		return nil, fmt.Errorf("routes %s: %w", file, err)
+	}
+	return routes, nil
+}
+
+// Validate checks every pattern and transform.
+func (r Routes) Validate() error {
+	var errs []error
+	for backend, route := range r {
+		patterns := slices.Concat(route.IncludeSlugs, route.ExcludeSlugs, route.IncludeIdentifiers, route.ExcludeIdentifiers)
+		for _, t := range route.Transforms {
+			if len(t.Slugs) == 0 {
+				errs = append(errs, fmt.Errorf("%s: transform needs at least one slug pattern", backend))
+			}
+			if (t.Scale != nil && !isFinite(*t.Scale)) || !isFinite(t.Offset) {
+				errs = append(errs, fmt.Errorf("%s: scale and offset must be finite", backend))
+			}
+			if t.Precision != nil && (*t.Precision < 0 || *t.Precision > 12) {
+				errs = append(errs, fmt.Errorf("%s: precision must be between 0 and 12", backend))
+			}
+			patterns = append(patterns, t.Slugs...)
+		}
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				errs = append(errs, fmt.Errorf("%s: bad pattern %q: %w", backend, p, err))
+			}
+		}
+		if route.MinInterval < 0 {
+			errs = append(errs, fmt.Errorf("%s: min_interval must not be negative", backend))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply filters and transforms data as the route does for a publish, without
+// MinInterval. It is for passing stored readings to a backend outside the
+// publish path.
+func (r Route) Apply(data []DataPoint) []DataPoint {
+	r.MinInterval = 0
+	return newRouter(r).apply(data, time.Time{})
+}
+
+// router applies a Route, tracking when each series was last forwarded.
+type router struct {
+	route Route
+
+	mu sync.Mutex
+	// clock is the newest reading time seen, the only clock MinInterval uses.
+	clock time.Time
+	// lastSent is the reading time at which each series was last forwarded.
+	lastSent map[string]time.Time
+	// newest is the timestamp of the newest point seen for each series.
+	newest map[string]time.Time
+	// held is the latest point of each series held back by MinInterval.
+	held map[string]DataPoint
+}
+
+func newRouter(route Route) *router {
+	return &router{
+		route:    route,
+		lastSent: map[string]time.Time{},
+		newest:   map[string]time.Time{},
+		held:     map[string]DataPoint{},
+	}
+}
+
+// apply returns the points the backend should receive, transformed. now is
+// the newest reading time of the publish, including readings deduplicated
+// away. Held-back points whose interval has elapsed by then are forwarded
+// after data; MultiPublisher calls apply on every publish, even when nothing
+// changed, so they go out within one poll of falling due.
+func (r *router) apply(data []DataPoint, now time.Time) []DataPoint {
+	out := make([]DataPoint, 0, len(data))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.After(r.clock) {
+		r.clock = now
+	}
+	for _, dp := range data {
+		if !matches(dp.Slug, r.route.IncludeSlugs, r.route.ExcludeSlugs) ||
+			!matches(dp.Identifier, r.route.IncludeIdentifiers, r.route.ExcludeIdentifiers) {
+			continue
+		}
+		if r.route.MinInterval > 0 {
+			key := dp.Identifier + "_" + dp.Slug
+			if newest, ok := r.newest[key]; ok && !dp.Timestamp.After(newest) {
+				continue
+			}
+			r.newest[key] = dp.Timestamp
+			if dp.Timestamp.After(r.clock) {
+				r.clock = dp.Timestamp
+			}
+			if last, ok := r.lastSent[key]; ok && dp.Timestamp.Sub(last) < r.route.MinInterval {
+				r.held[key] = dp
+				continue
+			}
+			delete(r.held, key)
+			r.lastSent[key] = dp.Timestamp
+		}
+		out = append(out, r.transform(dp))
+	}
+	return append(out, r.due()...)
+}
+
+// due removes and returns the held-back points whose interval has elapsed by
+// the reading clock. Callers must hold r.mu.
+func (r *router) due() []DataPoint {
+	if len(r.held) == 0 {
+		return nil
+	}
+	var out []DataPoint
+	for _, key := range slices.Sorted(maps.Keys(r.held)) {
+		if r.clock.Sub(r.lastSent[key]) < r.route.MinInterval {
+			continue
+		}
+		out = append(out, r.transform(r.held[key]))
+		delete(r.held, key)
+		r.lastSent[key] = r.clock
+	}
+	return out
+}
+
+func (r *router) transform(dp DataPoint) DataPoint {
+	for _, t := range r.route.Transforms {
+		if matchAny(dp.Slug, t.Slugs) {
+			dp = t.apply(dp)
+		}
+	}
+	return dp
+}
+
+func (t Transform) apply(dp DataPoint) DataPoint {
+	if _, ok := dp.Float(); !ok {
+		return dp
+	}
+	v, ok := new(big.Rat).SetString(dp.Value)
+	if !ok { // NaN and ±Inf parse as floats but not as rationals
+		return dp
+	}
+	if t.Scale != nil {
+		v.Mul(v, new(big.Rat).SetFloat64(*t.Scale))
+	}
+	v.Add(v, new(big.Rat).SetFloat64(t.Offset))
+	precision := defaultPrecision
+	if t.Precision != nil {
+		precision = *t.Precision
+	}
+	dp.Value = v.FloatString(precision)
+	if t.Unit != "" {
+		dp.UnitOfMeasurement = t.Unit
+	}
+	return dp
+}
+
+func isFinite(f float64) bool { return !math.IsNaN(f) && !math.IsInf(f, 0) }
+
+func matches(s string, include, exclude []string) bool {
+	if len(include) > 0 && !matchAny(s, include) {
+		return false
+	}
+	return !matchAny(s, exclude)
+}
+
+func matchAny(s string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
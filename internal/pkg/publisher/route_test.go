@@ -0,0 +1,252 @@
+package publisher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+)
+
+type namedStub struct {
+	stubPublisher
+	name string
+}
+
+func (n *namedStub) Name() string { return n.name }
+
+func writeRoutes(t *testing.T, yml string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "routes.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(yml), 0o600))
+	return file
+}
+
+func TestLoadRoutes(t *testing.T) {
+	routes, err := LoadRoutes(writeRoutes(t, `
+mqtt:
+  include_slugs: ["battery_*"]
+  min_interval: 1m
+webhook:
+  exclude_identifiers: ["SBR*"]
+  transforms:
+    - slugs: ["*_energy"]
+      scale: 1000
+      unit: Wh
+      precision: 0
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"battery_*"}, routes["mqtt"].IncludeSlugs)
+	assert.Equal(t, time.Minute, routes["mqtt"].MinInterval)
+	require.Len(t, routes["webhook"].Transforms, 1)
+	assert.Equal(t, 1000.0, *routes["webhook"].Transforms[0].Scale)
+	assert.Equal(t, 0, *routes["webhook"].Transforms[0].Precision)
+}
+
+func TestLoadRoutes_Invalid(t *testing.T) {
+	for name, yml := range map[string]string{
+		"unknown field":      "mqtt:\n  include_slug: [a]\n",
+		"bad pattern":        "mqtt:\n  include_slugs: [\"[\"]\n",
+		"transform no slugs": "mqtt:\n  transforms:\n    - scale: 2\n",
+		"negative interval":  "mqtt:\n  min_interval: -1s\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadRoutes(writeRoutes(t, yml))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetRoutes_UnknownBackend(t *testing.T) {
+	mp := NewMultiPublisher(&namedStub{name: "mqtt"})
+	err := mp.SetRoutes(Routes{"mqqt": {}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mqqt")
+}
+
+func TestRouter_IncludeExclude(t *testing.T) {
+	r := newRouter(Route{
+		IncludeSlugs:       []string{"battery_*", "load_power"},
+		ExcludeSlugs:       []string{"battery_voltage"},
+		ExcludeIdentifiers: []string{"SBR*"},
+	})
+	now := time.Now()
+	out := r.apply([]DataPoint{
+		{Identifier: "SH10RT_SN1", Slug: "battery_power", Value: "1", Timestamp: now},
+		{Identifier: "SH10RT_SN1", Slug: "battery_voltage", Value: "400", Timestamp: now},
+		{Identifier: "SH10RT_SN1", Slug: "load_power", Value: "2", Timestamp: now},
+		{Identifier: "SH10RT_SN1", Slug: "grid_power", Value: "3", Timestamp: now},
+		{Identifier: "SBR096_SN2", Slug: "battery_power", Value: "4", Timestamp: now},
+	}, now)
+	require.Len(t, out, 2)
+	assert.Equal(t, "battery_power", out[0].Slug)
+	assert.Equal(t, "load_power", out[1].Slug)
+}
+
+func TestRouter_MinInterval(t *testing.T) {
+	r := newRouter(Route{MinInterval: time.Minute})
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	dp := func(slug string, at time.Duration) DataPoint {
+		return DataPoint{Identifier: "SN1", Slug: slug, Value: "1", Timestamp: t0.Add(at)}
+	}
+
+	assert.Len(t, r.apply([]DataPoint{dp("a", 0), dp("b", 0)}, t0), 2)
+	assert.Empty(t, r.apply([]DataPoint{dp("a", 30*time.Second), dp("b", 59*time.Second)}, t0.Add(59*time.Second)))
+	out := r.apply([]DataPoint{dp("a", time.Minute), dp("b", 70*time.Second)}, t0.Add(70*time.Second))
+	require.Len(t, out, 2, "newer points replace the held-back ones")
+	assert.Equal(t, t0.Add(time.Minute), out[0].Timestamp)
+	assert.Equal(t, t0.Add(70*time.Second), out[1].Timestamp)
+	assert.Empty(t, r.apply([]DataPoint{dp("b", 90*time.Second)}, t0.Add(90*time.Second)), "interval restarts from the last forwarded point")
+}
+
+func TestRouter_MinInterval_ForwardsHeldPointWhenDue(t *testing.T) {
+	r := newRouter(Route{MinInterval: time.Minute})
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := t0
+	point := func(value string) DataPoint {
+		return DataPoint{Identifier: "SN1", Slug: "load_power", Value: value, Timestamp: clock}
+	}
+
+	assert.Len(t, r.apply([]DataPoint{point("1")}, clock), 1)
+	clock = t0.Add(20 * time.Second)
+	assert.Empty(t, r.apply([]DataPoint{point("2")}, clock))
+	clock = t0.Add(40 * time.Second)
+	assert.Empty(t, r.apply([]DataPoint{point("3")}, clock))
+
+	// The value stops changing, so later publishes carry nothing for it.
+	clock = t0.Add(50 * time.Second)
+	assert.Empty(t, r.apply(nil, clock), "not due yet")
+	clock = t0.Add(time.Minute)
+	out := r.apply(nil, clock)
+	require.Len(t, out, 1, "the last value goes out once the interval has elapsed")
+	assert.Equal(t, "3", out[0].Value)
+	assert.Equal(t, t0.Add(40*time.Second), out[0].Timestamp, "with the time it was read")
+
+	clock = t0.Add(90 * time.Second)
+	assert.Empty(t, r.apply([]DataPoint{point("4")}, clock), "the interval restarts when a held point is forwarded")
+	clock = t0.Add(2 * time.Minute)
+	out = r.apply(nil, clock)
+	require.Len(t, out, 1)
+	assert.Equal(t, "4", out[0].Value)
+	assert.Empty(t, r.apply(nil, clock), "forwarded once")
+}
+
+func TestRouter_MinInterval_UsesReadingTimestamps(t *testing.T) {
+	r := newRouter(Route{MinInterval: time.Minute})
+	// A backfill from long ago: the wall clock must not make every point due.
+	t0 := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	point := func(value string, at time.Duration) []DataPoint {
+		return []DataPoint{{Identifier: "SN1", Slug: "load_power", Value: value, Timestamp: t0.Add(at)}}
+	}
+
+	assert.Len(t, r.apply(point("1", 0), t0), 1)
+	assert.Empty(t, r.apply(point("2", 20*time.Second), t0.Add(20*time.Second)))
+	assert.Empty(t, r.apply(point("3", 40*time.Second), t0.Add(40*time.Second)))
+	// Out of order: older than the held point, so dropped.
+	assert.Empty(t, r.apply(point("late", 30*time.Second), t0.Add(40*time.Second)))
+	assert.Empty(t, r.apply(point("stale", -time.Hour), t0.Add(40*time.Second)))
+
+	out := r.apply(nil, t0.Add(time.Minute))
+	require.Len(t, out, 1)
+	assert.Equal(t, "3", out[0].Value)
+	out = r.apply(point("4", 2*time.Minute), t0.Add(2*time.Minute))
+	require.Len(t, out, 1)
+	assert.Equal(t, "4", out[0].Value)
+}
+
+func TestPublishData_MinIntervalForwardsChangeThatThenHolds(t *testing.T) {
+	mqtt := &namedStub{name: "mqtt"}
+	mp := NewMultiPublisher(mqtt)
+	interval := 50 * time.Millisecond
+	require.NoError(t, mp.SetRoutes(Routes{"mqtt": {MinInterval: interval}}))
+
+	device := model.Device{Model: "SH10RT", SerialNumber: "SN1"}
+	publish := func(v string) {
+		require.NoError(t, mp.PublishData(context.Background(), map[model.Device][]model.DeviceStatus{
+			device: {{Slug: "load_power", Unit: "kW", Value: &v}},
+		}))
+	}
+	publish("1.0")
+	publish("2.0") // changed inside the interval: held back
+	publish("2.0") // unchanged: deduplicated before routing
+	require.Len(t, mqtt.writes, 1)
+
+	// The readings are stamped when they are normalised, so the next poll's
+	// reading time shows the interval has passed.
+	time.Sleep(interval)
+	publish("2.0")
+	require.Len(t, mqtt.writes, 2)
+	require.Len(t, mqtt.writes[1], 1)
+	assert.Equal(t, "2.0000", mqtt.writes[1][0].Value)
+}
+
+func TestRouter_Transforms(t *testing.T) {
+	scale, zero := 1000.0, 0
+	r := newRouter(Route{Transforms: []Transform{
+		{Slugs: []string{"*_energy"}, Scale: &scale, Unit: "Wh", Precision: &zero},
+		{Slugs: []string{"*_temperature"}, Offset: -1.5},
+	}})
+	out := r.apply([]DataPoint{
+		{Slug: "daily_pv_energy", Value: "12.3456", UnitOfMeasurement: "kWh"},
+		{Slug: "internal_air_temperature", Value: "40.0000", UnitOfMeasurement: "°C"},
+		{Slug: "running_status", Value: "Running"},
+		{Slug: "battery_power", Value: "1.0000", UnitOfMeasurement: "kW"},
+	}, time.Time{})
+	require.Len(t, out, 4)
+	assert.Equal(t, DataPoint{Slug: "daily_pv_energy", Value: "12346", UnitOfMeasurement: "Wh"}, out[0])
+	assert.Equal(t, "38.5000", out[1].Value)
+	assert.Equal(t, "Running", out[2].Value, "text sensors are not transformed")
+	assert.Equal(t, "1.0000", out[3].Value)
+}
+
+func TestRoute_Apply(t *testing.T) {
+	scale := 1000.0
+	route := Route{
+		ExcludeSlugs: []string{"grid_*"},
+		MinInterval:  time.Hour,
+		Transforms:   []Transform{{Slugs: []string{"*_energy"}, Scale: &scale, Unit: "Wh"}},
+	}
+	now := time.Now()
+	data := []DataPoint{
+		{Identifier: "SN1", Slug: "daily_pv_energy", Value: "1.5", UnitOfMeasurement: "kWh", Timestamp: now},
+		{Identifier: "SN1", Slug: "grid_power", Value: "3", Timestamp: now},
+	}
+	for range 2 {
+		out := route.Apply(data)
+		require.Len(t, out, 1, "filtered, and never held back")
+		assert.Equal(t, "1500.0000", out[0].Value)
+		assert.Equal(t, "Wh", out[0].UnitOfMeasurement)
+	}
+	assert.Len(t, Route{}.Apply(data), 2)
+}
+
+func TestPublishData_AppliesRoutesPerBackend(t *testing.T) {
+	db := &namedStub{name: "postgres"}
+	mqtt := &namedStub{name: "mqtt"}
+	mp := NewMultiPublisher(db, mqtt)
+	require.NoError(t, mp.SetRoutes(Routes{"mqtt": {IncludeSlugs: []string{"battery_*"}}}))
+
+	device := model.Device{Model: "SH10RT", SerialNumber: "SN1"}
+	v1, v2 := "1.0", "2.0"
+	require.NoError(t, mp.PublishData(context.Background(), map[model.Device][]model.DeviceStatus{
+		device: {{Slug: "battery_power", Unit: "kW", Value: &v1}, {Slug: "load_power", Unit: "kW", Value: &v2}},
+	}))
+	require.Len(t, db.writes, 1)
+	assert.Len(t, db.writes[0], 2)
+	require.Len(t, mqtt.writes, 1)
+	require.Len(t, mqtt.writes[0], 1)
+	assert.Equal(t, "battery_power", mqtt.writes[0][0].Slug)
+
+	// A cycle with nothing routed to mqtt skips its Write entirely.
+	v2 = "3.0"
+	require.NoError(t, mp.PublishData(context.Background(), map[model.Device][]model.DeviceStatus{
+		device: {{Slug: "load_power", Unit: "kW", Value: &v2}},
+	}))
+	assert.Len(t, db.writes, 2)
+	assert.Len(t, mqtt.writes, 1)
+}
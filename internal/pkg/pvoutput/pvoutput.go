@@ -0,0 +1,342 @@
+// Package pvoutput is a publisher.Publisher backend that uploads system status
+// to PVOutput.org. Readings are averaged into PVOutput status intervals (5, 10
+// or 15 minutes), weighted by how long each value held, and sent with the addbatchstatus service once each interval
+// has closed. Intervals that cannot be delivered — network or PVOutput
+// outages, rate limiting — are kept and backfilled on the next upload, for as
+// far back as PVOutput accepts.
+//
+// Readings only arrive when a value changes, so each field's latest value is
+// carried forward into later intervals until a new reading replaces it. Daily
+// energy totals are not carried past local midnight, when the inverter resets
+// them.
+package pvoutput
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	batchPath      = "/service/r2/addbatchstatus.jsp"
+
+	// PVOutput limits; donors get larger batches and a longer backfill window.
+	batchSize         = 30
+	donationBatchSize = 100
+	backfill          = 14 * 24 * time.Hour
+	donationBackfill  = 90 * 24 * time.Hour
+	// rateLimitPause is used when PVOutput rejects a request as rate limited
+	// without saying when the limit resets.
+	rateLimitPause = time.Hour
+)
+
+var (
+	errRateLimited = errors.New("pvoutput: rate limit exceeded")
+	// errPermanent marks requests PVOutput rejected (4xx); retrying will not help.
+	errPermanent = errors.New("pvoutput: request rejected")
+)
+
+type service struct {
+	cfg    *config.PVOutputConfig
+	loc    *time.Location
+	client *http.Client
+	fields map[string]field
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	statuses map[time.Time]*status // keyed by interval start
+	latest   map[field]reading     // carried forward until the next reading
+
+	blockedUntil time.Time // only touched by the upload loop
+}
+
+// reading is a field value and the time up to which it has been added to the
+// statuses.
+type reading struct {
+	value float64
+	at    time.Time
+}
+
+// compile-time check that *service satisfies publisher.Publisher.
+var _ publisher.Publisher = (*service)(nil)
+
+// New returns a PVOutput uploader. Status dates and times are reported in loc,
+// which should match the system's timezone on PVOutput. Call Run to start uploading.
+func New(cfg *config.PVOutputConfig, loc *time.Location) (*service, error) {
+	if cfg.SystemID == "" {
+		return nil, fmt.Errorf("PVOUTPUT_SYSTEM_ID is required when PVOUTPUT_API_KEY is set")
+	}
+	switch cfg.Interval {
+	case 5 * time.Minute, 10 * time.Minute, 15 * time.Minute:
+	default:
+		return nil, fmt.Errorf("PVOUTPUT_INTERVAL must be 5m, 10m or 15m, got %s", cfg.Interval)
+	}
+
+	fields := map[string]field{}
+	for slug, f := range map[string]field{
+		cfg.EnergyGenerationSlug:  energyGeneration,
+		cfg.PowerGenerationSlug:   powerGeneration,
+		cfg.EnergyConsumptionSlug: energyConsumption,
+		cfg.PowerConsumptionSlug:  powerConsumption,
+		cfg.TemperatureSlug:       temperature,
+		cfg.VoltageSlug:           voltage,
+	} {
+		if slug != "" {
+			fields[slug] = f
+		}
+	}
+
+	return &service{
+		cfg:      cfg,
+		loc:      loc,
+		client:   &http.Client{Timeout: requestTimeout},
+		fields:   fields,
+		logger:   zap.L(),
+		now:      time.Now,
+		statuses: map[time.Time]*status{},
+		latest:   map[field]reading{},
+	}, nil
+}
+
+// Name identifies the backend in logs and metrics.
+func (s *service) Name() string { return "pvoutput" }
+
+// Write adds mapped readings to the statuses, carrying each field's previous
+// value forward up to the new reading. Readings older than the field's latest
+// are ignored.
+func (s *service) Write(_ context.Context, data []publisher.DataPoint) error {
+	oldest := s.now().Add(-s.window())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dp := range data {
+		f, ok := s.fields[dp.Slug]
+		if !ok || (s.cfg.Identifier != "" && dp.Identifier != s.cfg.Identifier) {
+			continue
+		}
+		v, ok := dp.Float()
+		if !ok {
+			continue
+		}
+		if prev, ok := s.latest[f]; ok {
+			if dp.Timestamp.Before(prev.at) {
+				continue
+			}
+			s.carry(f, prev, dp.Timestamp, oldest)
+		}
+		v = toPVOutputUnit(v, dp.UnitOfMeasurement)
+		s.statusAt(dp.Timestamp).hold(f, v, 0)
+		s.latest[f] = reading{value: v, at: dp.Timestamp}
+	}
+	return nil
+}
+
+// carry adds the field's value to every interval from r.at up to until, never
+// earlier than oldest. Daily totals stop at local midnight and are forgotten,
+// since the inverter starts the next day from zero. Callers hold s.mu.
+func (s *service) carry(f field, r reading, until, oldest time.Time) {
+	if f.cumulative() {
+		y, m, d := r.at.In(s.loc).Date()
+		if midnight := time.Date(y, m, d+1, 0, 0, 0, 0, s.loc); midnight.Before(until) {
+			until = midnight
+			delete(s.latest, f)
+		}
+	}
+	t := r.at
+	if t.Before(oldest) {
+		t = oldest
+	}
+	for t.Before(until) {
+		st := s.statusAt(t)
+		end := st.start.Add(s.cfg.Interval)
+		if end.After(until) {
+			end = until
+		}
+		st.hold(f, r.value, end.Sub(t))
+		t = end
+	}
+}
+
+// statusAt returns the status for the interval containing t, creating it if
+// needed. Callers hold s.mu.
+func (s *service) statusAt(t time.Time) *status {
+	start := t.In(s.loc).Truncate(s.cfg.Interval)
+	st, ok := s.statuses[start]
+	if !ok {
+		st = &status{start: start}
+		s.statuses[start] = st
+	}
+	return st
+}
+
+// RegisterDevice is a no-op; the PVOutput system is configured out of band.
+func (s *service) RegisterDevice(_ context.Context, _ *model.Device) error {
+	return nil
+}
+
+// Run uploads closed intervals every Interval until ctx is cancelled.
+func (s *service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.upload(ctx); err != nil {
+				s.logger.Warn("pvoutput: upload failed, will retry next interval", zap.Error(err))
+			}
+		}
+	}
+}
+
+// upload sends every closed interval, oldest first, in batches. Statuses stay
+// queued on transient failures; statuses PVOutput rejects outright, or that
+// have aged out of the backfill window, are dropped.
+func (s *service) upload(ctx context.Context) error {
+	now := s.now()
+	if now.Before(s.blockedUntil) {
+		return nil
+	}
+
+	for {
+		batch := s.closed(now)
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.send(ctx, batch)
+		switch {
+		case err == nil:
+			s.remove(batch)
+		case errors.Is(err, errRateLimited):
+			s.logger.Warn("pvoutput: rate limited", zap.Time("until", s.blockedUntil))
+			return nil
+		case errors.Is(err, errPermanent):
+			s.logger.Error("pvoutput: dropping rejected statuses", zap.Int("statuses", len(batch)), zap.Error(err))
+			s.remove(batch)
+		default:
+			return err
+		}
+	}
+}
+
+// window is how far back PVOutput accepts statuses.
+func (s *service) window() time.Duration {
+	if s.cfg.Donation {
+		return donationBackfill
+	}
+	return backfill
+}
+
+// closed carries every field's latest value forward to now, then returns up to
+// one batch of complete, uploadable statuses, oldest first, discarding any
+// older than the backfill window.
+func (s *service) closed(now time.Time) []*status {
+	size := batchSize
+	if s.cfg.Donation {
+		size = donationBatchSize
+	}
+	oldest := now.Add(-s.window())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for f, r := range s.latest {
+		if r.at.Before(now) {
+			s.carry(f, r, now, oldest)
+			if _, ok := s.latest[f]; ok {
+				s.latest[f] = reading{value: r.value, at: now}
+			}
+		}
+	}
+	var out []*status
+	for start, st := range s.statuses {
+		switch {
+		case start.Before(oldest):
+			s.logger.Warn("pvoutput: status outside backfill window, dropping", zap.Time("start", start))
+			delete(s.statuses, start)
+		case !start.Add(s.cfg.Interval).After(now) && st.uploadable():
+			out = append(out, st)
+		}
+	}
+	slices.SortFunc(out, func(a, b *status) int { return a.start.Compare(b.start) })
+	return out[:min(len(out), size)]
+}
+
+func (s *service) remove(batch []*status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range batch {
+		delete(s.statuses, st.start)
+	}
+}
+
+func (s *service) send(ctx context.Context, batch []*status) error {
+	lines := make([]string, len(batch))
+	for i, st := range batch {
+		lines[i] = st.encode()
+	}
+	form := url.Values{"data": {strings.Join(lines, ";")}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.cfg.URL, "/")+batchPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Pvoutput-Apikey", s.cfg.APIKey)
+	req.Header.Set("X-Pvoutput-SystemId", s.cfg.SystemID)
+	req.Header.Set("X-Rate-Limit", "1")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	msg := strings.TrimSpace(string(body))
+
+	s.trackRateLimit(res)
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil
+	case res.StatusCode == http.StatusForbidden && strings.Contains(msg, "Exceeded"),
+		res.StatusCode == http.StatusTooManyRequests:
+		if !s.blockedUntil.After(s.now()) {
+			s.blockedUntil = s.now().Add(rateLimitPause)
+		}
+		return errRateLimited
+	case res.StatusCode/100 == 4:
+		return fmt.Errorf("%w: %s: %s", errPermanent, res.Status, msg)
+	default:
+		return fmt.Errorf("pvoutput: %s: %s", res.Status, msg)
+	}
+}
+
+// trackRateLimit pauses uploads until the reset time once PVOutput reports no
+// remaining requests for the current hour.
+func (s *service) trackRateLimit(res *http.Response) {
+	remaining, err := strconv.Atoi(res.Header.Get("X-Rate-Limit-Remaining"))
+	if err != nil || remaining > 0 {
+		return
+	}
+	reset, err := strconv.ParseInt(res.Header.Get("X-Rate-Limit-Reset"), 10, 64)
+	if err != nil {
+		s.blockedUntil = s.now().Add(rateLimitPause)
+		return
+	}
+	s.blockedUntil = time.Unix(reset, 0)
+}
@@ -0,0 +1,252 @@
+package pvoutput
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+var adelaide = time.FixedZone("ACDT", 10*3600+1800)
+
+// stub is a stand-in for the PVOutput API that records the batch data of each
+// request and replies with the queued responses (200 once the queue is empty).
+type stub struct {
+	mu        sync.Mutex
+	batches   []string
+	headers   []http.Header
+	responses []func(w http.ResponseWriter)
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != batchPath || r.ParseForm() != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.batches = append(s.batches, r.PostForm.Get("data"))
+	s.headers = append(s.headers, r.Header.Clone())
+	if len(s.responses) > 0 {
+		respond := s.responses[0]
+		s.responses = s.responses[1:]
+		respond(w)
+		return
+	}
+	_, _ = w.Write([]byte("OK 200: Added Status"))
+}
+
+func newTestService(t *testing.T, api *stub, mutate ...func(*config.PVOutputConfig)) (*service, *time.Time) {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	cfg := &config.PVOutputConfig{
+		APIKey:                "key",
+		SystemID:              "1234",
+		URL:                   srv.URL,
+		Interval:              5 * time.Minute,
+		EnergyGenerationSlug:  "daily_pv_yield",
+		PowerGenerationSlug:   "total_dc_power",
+		EnergyConsumptionSlug: "daily_load_consumption",
+		PowerConsumptionSlug:  "load_power",
+		TemperatureSlug:       "internal_air_temperature",
+		VoltageSlug:           "mppt1_voltage",
+	}
+	for _, m := range mutate {
+		m(cfg)
+	}
+	svc, err := New(cfg, adelaide)
+	require.NoError(t, err)
+	now := time.Date(2026, 1, 10, 10, 0, 0, 0, adelaide)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func dp(ts time.Time, slug, value, unit string) publisher.DataPoint {
+	return publisher.DataPoint{Identifier: "SH10RT_SN1", Slug: slug, Value: value, UnitOfMeasurement: unit, Timestamp: ts}
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(&config.PVOutputConfig{APIKey: "key", Interval: 5 * time.Minute}, time.UTC)
+	assert.Error(t, err, "system id required")
+	_, err = New(&config.PVOutputConfig{APIKey: "key", SystemID: "1", Interval: 7 * time.Minute}, time.UTC)
+	assert.Error(t, err, "unsupported interval")
+}
+
+func TestUpload_AggregatesIntervalIntoStatus(t *testing.T) {
+	api := &stub{}
+	svc, now := newTestService(t, api)
+	ctx := context.Background()
+	start := now.Add(-5 * time.Minute) // 09:55 interval
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		dp(start, "total_dc_power", "3.0000", "kW"),
+		dp(start, "load_power", "1000.0000", "W"),
+		dp(start, "daily_pv_yield", "10.0000", "kWh"),
+		dp(start, "internal_air_temperature", "40.0000", "°C"),
+		dp(start, "mppt1_voltage", "350.0000", "V"),
+		dp(start, "battery_power", "9.0000", "kW"), // unmapped
+	}))
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		dp(start.Add(time.Minute), "total_dc_power", "4.0000", "kW"),
+		dp(start.Add(time.Minute), "load_power", "2000.0000", "W"),
+		dp(start.Add(time.Minute), "daily_pv_yield", "10.2500", "kWh"),
+		dp(start.Add(time.Minute), "internal_air_temperature", "41.0000", "°C"),
+		dp(start.Add(time.Minute), "mppt1_voltage", "352.0000", "V"),
+	}))
+	// The current interval is still open and must not be sent.
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(*now, "total_dc_power", "5.0000", "kW")}))
+
+	require.NoError(t, svc.upload(ctx))
+
+	// Each value is weighted by how long it held: one minute, then four.
+	require.Len(t, api.batches, 1)
+	assert.Equal(t, "20260110,09:55,10250,3800,,1800,40.8,351.6", api.batches[0])
+	assert.Equal(t, "key", api.headers[0].Get("X-Pvoutput-Apikey"))
+	assert.Equal(t, "1234", api.headers[0].Get("X-Pvoutput-SystemId"))
+	assert.Len(t, svc.statuses, 1, "open interval stays queued")
+}
+
+func TestUpload_BackfillsAfterOutageInBatches(t *testing.T) {
+	api := &stub{responses: []func(http.ResponseWriter){
+		func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+	}}
+	svc, now := newTestService(t, api)
+	ctx := context.Background()
+
+	// 40 closed intervals; the first upload attempt fails.
+	for i := 40; i >= 1; i-- {
+		require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(now.Add(-time.Duration(i)*5*time.Minute), "total_dc_power", "1", "kW")}))
+	}
+	assert.Error(t, svc.upload(ctx))
+	require.Len(t, svc.statuses, 40)
+
+	require.NoError(t, svc.upload(ctx))
+	require.Len(t, api.batches, 3)
+	assert.Len(t, strings.Split(api.batches[1], ";"), batchSize)
+	assert.Len(t, strings.Split(api.batches[2], ";"), 10)
+	assert.True(t, strings.HasPrefix(api.batches[1], "20260110,06:40,"), "oldest interval first")
+	assert.Empty(t, svc.statuses)
+}
+
+func TestUpload_DropsStatusesOutsideBackfillWindow(t *testing.T) {
+	api := &stub{}
+	svc, now := newTestService(t, api)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		dp(now.AddDate(0, 0, -15), "total_dc_power", "1", "kW"),
+	}))
+	require.NoError(t, svc.upload(ctx))
+
+	// The reading is carried forward, but only from the start of the window.
+	require.NotEmpty(t, api.batches)
+	assert.True(t, strings.HasPrefix(api.batches[0], "20251227,10:00,"), api.batches[0][:20])
+	assert.Len(t, strings.Split(strings.Join(api.batches, ";"), ";"), int(backfill/(5*time.Minute)))
+	assert.Empty(t, svc.statuses)
+}
+
+func TestUpload_RespectsRateLimit(t *testing.T) {
+	reset := time.Date(2026, 1, 10, 10, 30, 0, 0, adelaide)
+	api := &stub{responses: []func(http.ResponseWriter){
+		func(w http.ResponseWriter) {
+			w.Header().Set("X-Rate-Limit-Remaining", "0")
+			w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("Forbidden 403: Exceeded 60 requests per hour"))
+		},
+	}}
+	svc, now := newTestService(t, api)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{dp(now.Add(-5*time.Minute), "total_dc_power", "1", "kW")}))
+	require.NoError(t, svc.upload(ctx))
+	assert.Equal(t, reset, svc.blockedUntil.In(adelaide))
+	assert.Len(t, svc.statuses, 1, "rate-limited statuses are kept")
+
+	*now = reset.Add(-time.Minute)
+	require.NoError(t, svc.upload(ctx))
+	assert.Len(t, api.batches, 1, "no requests until the limit resets")
+
+	*now = reset
+	require.NoError(t, svc.upload(ctx))
+	assert.Len(t, api.batches, 2)
+	assert.Empty(t, svc.statuses)
+}
+
+func TestUpload_DropsRejectedBatch(t *testing.T) {
+	api := &stub{responses: []func(http.ResponseWriter){
+		func(w http.ResponseWriter) { http.Error(w, "Bad request 400: Invalid data", http.StatusBadRequest) },
+	}}
+	svc, now := newTestService(t, api)
+
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{dp(now.Add(-5*time.Minute), "total_dc_power", "1", "kW")}))
+	require.NoError(t, svc.upload(context.Background()))
+
+	assert.Len(t, api.batches, 1)
+	assert.Empty(t, svc.statuses)
+}
+
+func TestWrite_IdentifierFilterAndTextValues(t *testing.T) {
+	api := &stub{}
+	svc, now := newTestService(t, api, func(c *config.PVOutputConfig) { c.Identifier = "OTHER" })
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{dp(*now, "total_dc_power", "1", "kW")}))
+	assert.Empty(t, svc.statuses)
+
+	svc, now = newTestService(t, api)
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{dp(*now, "total_dc_power", "n/a", "kW")}))
+	assert.Empty(t, svc.statuses)
+}
+
+func TestUpload_CarriesValuesForwardWeightedByTime(t *testing.T) {
+	api := &stub{}
+	svc, now := newTestService(t, api)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		dp(now.Add(-20*time.Minute), "total_dc_power", "2", "kW"),
+		dp(now.Add(-20*time.Minute), "daily_pv_yield", "5", "kWh"),
+	}))
+	// Unchanged readings are not published, so 09:45 has none of its own.
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{
+		dp(now.Add(-7*time.Minute-30*time.Second), "total_dc_power", "4", "kW"),
+	}))
+	require.NoError(t, svc.upload(ctx))
+
+	require.Len(t, api.batches, 1)
+	assert.Equal(t, strings.Join([]string{
+		"20260110,09:40,5000,2000,,,,",
+		"20260110,09:45,5000,2000,,,,",
+		"20260110,09:50,5000,3000,,,,",
+		"20260110,09:55,5000,4000,,,,",
+	}, ";"), api.batches[0])
+}
+
+func TestUpload_DoesNotCarryDailyTotalsPastMidnight(t *testing.T) {
+	api := &stub{}
+	svc, now := newTestService(t, api)
+	*now = time.Date(2026, 1, 10, 0, 10, 0, 0, adelaide)
+
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{
+		dp(now.Add(-13*time.Minute), "total_dc_power", "0", "kW"),
+		dp(now.Add(-13*time.Minute), "daily_pv_yield", "20", "kWh"),
+	}))
+	require.NoError(t, svc.upload(context.Background()))
+
+	require.Len(t, api.batches, 1)
+	assert.Equal(t, strings.Join([]string{
+		"20260109,23:55,20000,0,,,,",
+		"20260110,00:00,,0,,,,",
+		"20260110,00:05,,0,,,,",
+	}, ";"), api.batches[0])
+}
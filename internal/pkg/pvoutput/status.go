@@ -0,0 +1,97 @@
+package pvoutput
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field indexes PVOutput's status values v1–v6.
+type field int
+
+const (
+	energyGeneration  field = iota // v1, Wh generated today
+	powerGeneration                // v2, W
+	energyConsumption              // v3, Wh consumed today
+	powerConsumption               // v4, W
+	temperature                    // v5, °C
+	voltage                        // v6, V
+	numFields
+)
+
+// cumulative reports whether the field is a running daily total, for which
+// the latest sample is reported rather than the interval average.
+func (f field) cumulative() bool {
+	return f == energyGeneration || f == energyConsumption
+}
+
+// toPVOutputUnit converts a reading to the unit PVOutput expects for the field
+// (W for power, Wh for energy). Other units pass through unchanged.
+func toPVOutputUnit(value float64, unit string) float64 {
+	switch unit {
+	case "kW", "kWh":
+		return value * 1000
+	case "MWh":
+		return value * 1_000_000
+	}
+	return value
+}
+
+// status aggregates the readings of one interval. Each reading holds from its
+// timestamp until the next reading of the same field, so averages are weighted
+// by how long each value was in effect.
+type status struct {
+	start    time.Time // interval start in the system's local time
+	weighted [numFields]float64
+	held     [numFields]time.Duration
+	last     [numFields]float64
+	seen     [numFields]bool
+}
+
+// hold records that the field had value v for d within the interval.
+func (s *status) hold(f field, v float64, d time.Duration) {
+	s.weighted[f] += v * d.Seconds()
+	s.held[f] += d
+	s.last[f] = v
+	s.seen[f] = true
+}
+
+func (s *status) value(f field) (float64, bool) {
+	if !s.seen[f] {
+		return 0, false
+	}
+	if f.cumulative() || s.held[f] == 0 {
+		return s.last[f], true
+	}
+	return s.weighted[f] / s.held[f].Seconds(), true
+}
+
+// uploadable reports whether the status carries at least one of v1–v4,
+// which PVOutput requires.
+func (s *status) uploadable() bool {
+	for f := energyGeneration; f <= powerConsumption; f++ {
+		if s.seen[f] {
+			return true
+		}
+	}
+	return false
+}
+
+// encode renders the status in addbatchstatus format: d,t,v1,v2,v3,v4,v5,v6.
+// Missing values are left empty.
+func (s *status) encode() string {
+	parts := make([]string, 0, 2+numFields)
+	parts = append(parts, s.start.Format("20060102"), s.start.Format("15:04"))
+	for f := range numFields {
+		v, ok := s.value(f)
+		switch {
+		case !ok:
+			parts = append(parts, "")
+		case f == temperature || f == voltage:
+			parts = append(parts, strconv.FormatFloat(v, 'f', 1, 64))
+		default:
+			parts = append(parts, strconv.FormatInt(int64(v+0.5), 10))
+		}
+	}
+	return strings.Join(parts, ",")
+}
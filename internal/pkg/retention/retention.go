@@ -0,0 +1,134 @@
+// Package retention runs the scheduled data retention pass: raw readings and
+// rollups that have outlived the configured policy are deleted, with raw
+// readings rolled up first so long-term history survives.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// Cleaner applies a retention policy. store.Store satisfies it.
+type Cleaner interface {
+	Cleanup(ctx context.Context, policy store.RetentionPolicy) (store.RetentionResult, error)
+}
+
+// Run is the outcome of one retention pass. Err is empty on success.
+type Run struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	store.RetentionResult
+	Err string
+}
+
+// Status describes the configured policy and the most recent pass.
+type Status struct {
+	Schedule string
+	Policy   store.RetentionPolicy
+	NextRun  time.Time
+	LastRun  *Run
+}
+
+type Service struct {
+	db       Cleaner
+	policy   store.RetentionPolicy
+	spec     string
+	schedule cron.Schedule
+	loc      *time.Location
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	lastRun *Run
+}
+
+// New returns a retention service for db. The cron schedule in cfg is
+// evaluated in loc. Call Run to start it.
+func New(db Cleaner, cfg *config.RetentionConfig, loc *time.Location) (*Service, error) {
+	schedule, err := cron.ParseStandard(cfg.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_SCHEDULE %q: %w", cfg.Schedule, err)
+	}
+	policy := store.RetentionPolicy{
+		Raw:     cfg.Raw,
+		Slugs:   cfg.Slugs,
+		Rollups: make(map[time.Duration]time.Duration, len(cfg.Rollups)),
+	}
+	for res, d := range cfg.Rollups {
+		r, err := time.ParseDuration(res)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rollup resolution %q in RETENTION_ROLLUPS: %w", res, err)
+		}
+		policy.Rollups[r] = d
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid retention policy: %w", err)
+	}
+	return &Service{
+		db:       db,
+		policy:   policy,
+		spec:     cfg.Schedule,
+		schedule: schedule,
+		loc:      loc,
+		logger:   zap.L(),
+		now:      time.Now,
+	}, nil
+}
+
+// Run applies the policy on schedule until ctx is cancelled. A failed pass is
+// logged and retried at the next scheduled time.
+func (s *Service) Run(ctx context.Context) error {
+	for {
+		now := s.now()
+		timer := time.NewTimer(s.schedule.Next(now.In(s.loc)).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce applies the policy immediately and records the outcome.
+func (s *Service) RunOnce(ctx context.Context) Run {
+	run := Run{StartedAt: s.now()}
+	res, err := s.db.Cleanup(ctx, s.policy)
+	run.FinishedAt = s.now()
+	run.RetentionResult = res
+	if err != nil {
+		run.Err = err.Error()
+		s.logger.Error("retention: cleanup failed", zap.Error(err))
+	} else {
+		s.logger.Info("retention: cleanup finished",
+			zap.Int64("rows_deleted", res.RowsDeleted),
+			zap.Int64("rollups_deleted", res.RollupsDeleted),
+			zap.Duration("took", run.FinishedAt.Sub(run.StartedAt)))
+	}
+
+	s.mu.Lock()
+	s.lastRun = &run
+	s.mu.Unlock()
+	return run
+}
+
+// Status reports the policy, when it next runs and the result of the last run.
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Status{
+		Schedule: s.spec,
+		Policy:   s.policy,
+		NextRun:  s.schedule.Next(s.now().In(s.loc)),
+		LastRun:  s.lastRun,
+	}
+}
@@ -0,0 +1,110 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+type fakeCleaner struct {
+	policies []store.RetentionPolicy
+	res      store.RetentionResult
+	err      error
+}
+
+func (f *fakeCleaner) Cleanup(_ context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	f.policies = append(f.policies, policy)
+	return f.res, f.err
+}
+
+func testConfig() *config.RetentionConfig {
+	return &config.RetentionConfig{
+		Enabled:  true,
+		Schedule: "0 3 * * *",
+		Raw:      192 * time.Hour,
+		Slugs:    map[string]time.Duration{"battery_power": 720 * time.Hour},
+		Rollups:  map[string]time.Duration{"1m": 192 * time.Hour, "1h": 0},
+	}
+}
+
+func TestNew_BuildsPolicy(t *testing.T) {
+	svc, err := New(&fakeCleaner{}, testConfig(), time.UTC)
+	require.NoError(t, err)
+
+	assert.Equal(t, store.RetentionPolicy{
+		Raw:     192 * time.Hour,
+		Slugs:   map[string]time.Duration{"battery_power": 720 * time.Hour},
+		Rollups: map[time.Duration]time.Duration{time.Minute: 192 * time.Hour, time.Hour: 0},
+	}, svc.policy)
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	for name, mutate := range map[string]func(*config.RetentionConfig){
+		"bad schedule":       func(c *config.RetentionConfig) { c.Schedule = "at three" },
+		"bad resolution":     func(c *config.RetentionConfig) { c.Rollups = map[string]time.Duration{"hourly": time.Hour} },
+		"unknown resolution": func(c *config.RetentionConfig) { c.Rollups = map[string]time.Duration{"5m": time.Hour} },
+		"negative raw":       func(c *config.RetentionConfig) { c.Raw = -time.Hour },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := testConfig()
+			mutate(cfg)
+			_, err := New(&fakeCleaner{}, cfg, time.UTC)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRunOnce_RecordsResult(t *testing.T) {
+	db := &fakeCleaner{res: store.RetentionResult{RowsDeleted: 10, RollupsDeleted: 2}}
+	svc, err := New(db, testConfig(), time.UTC)
+	require.NoError(t, err)
+
+	run := svc.RunOnce(context.Background())
+
+	require.Len(t, db.policies, 1)
+	assert.Equal(t, svc.policy, db.policies[0])
+	assert.Equal(t, int64(10), run.RowsDeleted)
+	assert.Empty(t, run.Err)
+	require.NotNil(t, svc.Status().LastRun)
+	assert.Equal(t, run, *svc.Status().LastRun)
+}
+
+func TestRunOnce_RecordsError(t *testing.T) {
+	svc, err := New(&fakeCleaner{err: errors.New("db down")}, testConfig(), time.UTC)
+	require.NoError(t, err)
+
+	run := svc.RunOnce(context.Background())
+
+	assert.Equal(t, "db down", run.Err)
+	assert.Equal(t, "db down", svc.Status().LastRun.Err)
+}
+
+func TestStatus_NextRunInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Australia/Adelaide")
+	require.NoError(t, err)
+	svc, err := New(&fakeCleaner{}, testConfig(), loc)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, loc) }
+
+	status := svc.Status()
+
+	assert.Nil(t, status.LastRun)
+	assert.Equal(t, "0 3 * * *", status.Schedule)
+	assert.True(t, time.Date(2026, 3, 11, 3, 0, 0, 0, loc).Equal(status.NextRun))
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	svc, err := New(&fakeCleaner{}, testConfig(), time.UTC)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, svc.Run(ctx), context.Canceled)
+}
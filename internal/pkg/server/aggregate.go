@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+// maxAggregatePoints caps how many buckets a single aggregate query may return.
+const maxAggregatePoints = 5000
+
+// autoBuckets are the bucket widths tried, finest first, when the caller does
+// not pass one. The first that keeps the range within the target point count wins.
+var autoBuckets = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+const targetAggregatePoints = 500
+
+// GetPropertyIdentifierSlugAggregate implements api.ServerInterface.
+func (s *server) GetPropertyIdentifierSlugAggregate(w http.ResponseWriter, r *http.Request, identifier string, slug string, params api.GetPropertyIdentifierSlugAggregateParams) {
+	to := time.Now()
+	if params.To != nil {
+		to = *params.To
+	}
+	from := to.AddDate(0, 0, -2)
+	if params.From != nil {
+		from = *params.From
+	}
+	if !from.Before(to) {
+		handleError(w, &clientError{errors.New("from must be before to")})
+		return
+	}
+
+	var bucket time.Duration
+	if params.Bucket != nil {
+		b, err := parseBucket(*params.Bucket)
+		if err != nil {
+			handleError(w, &clientError{err})
+			return
+		}
+		bucket = b
+	} else {
+		bucket = pickBucket(to.Sub(from), targetAggregatePoints)
+	}
+	if n := to.Sub(from) / bucket; n > maxAggregatePoints {
+		handleError(w, &clientError{fmt.Errorf("bucket %s yields %d points, at most %d allowed", bucket, n, maxAggregatePoints)})
+		return
+	}
+	resolution, err := store.PickResolution(bucket)
+	if err != nil {
+		handleError(w, &clientError{err})
+		return
+	}
+
+	fn := api.Avg
+	if params.Fn != nil {
+		if !params.Fn.Valid() {
+			handleError(w, &clientError{fmt.Errorf("unknown fn %q", *params.Fn)})
+			return
+		}
+		fn = *params.Fn
+	}
+
+	// Widen the range to whole buckets so the first and last points are complete.
+	aggs, err := s.db.GetAggregates(r.Context(), identifier, slug, resolution,
+		store.BucketStart(from, bucket), store.BucketStart(to, bucket).Add(bucket))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	points := []api.AggregatePoint{}
+	for _, a := range store.Rebucket(aggs, bucket) {
+		points = append(points, api.AggregatePoint{
+			Timestamp:         a.BucketStart,
+			Value:             aggregateValue(a, fn),
+			Count:             a.Count,
+			UnitOfMeasurement: a.UnitOfMeasurement,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(points); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// parseBucket parses a bucket width such as "15m", "1h" or "7d". It must be at
+// least a minute and a whole number of minutes.
+func parseBucket(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid bucket %q", s)
+	}
+	if d < time.Minute || d%time.Minute != 0 {
+		return 0, fmt.Errorf("bucket %q must be a whole number of minutes", s)
+	}
+	return d, nil
+}
+
+func pickBucket(span time.Duration, target int) time.Duration {
+	for _, b := range autoBuckets {
+		if span/b <= time.Duration(target) {
+			return b
+		}
+	}
+	return autoBuckets[len(autoBuckets)-1]
+}
+
+func aggregateValue(a store.Aggregate, fn api.GetPropertyIdentifierSlugAggregateParamsFn) float64 {
+	switch fn {
+	case api.Min:
+		return a.Min
+	case api.Max:
+		return a.Max
+	case api.Last:
+		return a.Last
+	case api.Sum:
+		return a.Sum
+	case api.Count:
+		return float64(a.Count)
+	default:
+		return a.Avg()
+	}
+}
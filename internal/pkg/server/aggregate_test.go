@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+func getAggregate(svc *server, params api.GetPropertyIdentifierSlugAggregateParams) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/property/XH3000_SN001/battery_power/aggregate", nil)
+	svc.GetPropertyIdentifierSlugAggregate(rec, r, "XH3000_SN001", "battery_power", params)
+	return rec
+}
+
+func TestGetPropertyIdentifierSlugAggregate_MergesRollups(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+	bucket, fn := "30m", api.Max
+
+	db := servermocks.NewDatabase(t)
+	db.EXPECT().GetAggregates(mock.Anything, "XH3000_SN001", "battery_power", 15*time.Minute, from, to.Add(30*time.Minute)).
+		Return([]store.Aggregate{
+			{BucketStart: from, UnitOfMeasurement: "kW", Min: 1, Max: 2, Sum: 3, Count: 2, Last: 2, LastTimeStamp: from.Add(time.Minute)},
+			{BucketStart: from.Add(15 * time.Minute), UnitOfMeasurement: "kW", Min: 4, Max: 6, Sum: 10, Count: 2, Last: 6, LastTimeStamp: from.Add(16 * time.Minute)},
+			{BucketStart: from.Add(time.Hour), UnitOfMeasurement: "kW", Min: 1, Max: 1, Sum: 1, Count: 1, Last: 1, LastTimeStamp: from.Add(time.Hour)},
+		}, nil)
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+
+	rec := getAggregate(svc, api.GetPropertyIdentifierSlugAggregateParams{Bucket: &bucket, Fn: &fn, From: &from, To: &to})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []api.AggregatePoint
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	require.Len(t, got, 2)
+	assert.True(t, from.Equal(got[0].Timestamp))
+	assert.InDelta(t, 6, got[0].Value, 1e-9)
+	assert.Equal(t, 4, got[0].Count)
+	assert.Equal(t, "kW", got[0].UnitOfMeasurement)
+	assert.True(t, from.Add(time.Hour).Equal(got[1].Timestamp))
+}
+
+func TestGetPropertyIdentifierSlugAggregate_DefaultsToAvgAndAutoBucket(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+
+	db := servermocks.NewDatabase(t)
+	// 48h / 500 points -> 15m buckets, served from the 15m rollup.
+	db.EXPECT().GetAggregates(mock.Anything, "XH3000_SN001", "battery_power", 15*time.Minute, from, to.Add(15*time.Minute)).
+		Return([]store.Aggregate{{BucketStart: from, Sum: 9, Count: 3}}, nil)
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+
+	rec := getAggregate(svc, api.GetPropertyIdentifierSlugAggregateParams{From: &from, To: &to})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []api.AggregatePoint
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	require.Len(t, got, 1)
+	assert.InDelta(t, 3, got[0].Value, 1e-9)
+}
+
+func TestGetPropertyIdentifierSlugAggregate_InvalidParams_Returns400(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(30 * 24 * time.Hour)
+	bad := api.GetPropertyIdentifierSlugAggregateParamsFn("median")
+	soon, sub, minute := "soon", "30s", "1m"
+
+	cases := map[string]api.GetPropertyIdentifierSlugAggregateParams{
+		"unparseable bucket": {Bucket: &soon, From: &from, To: &to},
+		"sub-minute bucket":  {Bucket: &sub, From: &from, To: &to},
+		"too many points":    {Bucket: &minute, From: &from, To: &to},
+		"unknown fn":         {Fn: &bad, From: &from, To: &to},
+		"inverted range":     {From: &to, To: &from},
+	}
+	for name, params := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+			assert.Equal(t, http.StatusBadRequest, getAggregate(svc, params).Code)
+		})
+	}
+}
+
+func TestParseBucket(t *testing.T) {
+	cases := map[string]time.Duration{
+		"1m":  time.Minute,
+		"15m": 15 * time.Minute,
+		"1h":  time.Hour,
+		"1d":  24 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := parseBucket(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "0m", "90s", "-1d", "xd"} {
+		_, err := parseBucket(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+// maxCommandLimit caps how many audit entries a single request may return.
+const maxCommandLimit = 1000
+
+// GetCommands implements api.ServerInterface.
+func (s *server) GetCommands(w http.ResponseWriter, r *http.Request, params api.GetCommandsParams) {
+	filter := store.CommandFilter{From: params.From, To: params.To}
+	if params.From != nil && params.To != nil && params.From.After(*params.To) {
+		handleError(w, &clientError{fmt.Errorf("from must not be after to")})
+		return
+	}
+	if params.User != nil {
+		filter.User = *params.User
+	}
+	if params.Source != nil {
+		if !params.Source.Valid() {
+			handleError(w, &clientError{fmt.Errorf("unknown source %q", *params.Source)})
+			return
+		}
+		filter.Source = string(*params.Source)
+	}
+	if params.Command != nil {
+		filter.Command = *params.Command
+	}
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxCommandLimit {
+			handleError(w, &clientError{fmt.Errorf("limit must be between 1 and %d", maxCommandLimit)})
+			return
+		}
+		filter.Limit = *params.Limit
+	}
+
+	cmds, err := s.db.GetCommands(r.Context(), filter)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	resp := make([]api.CommandAudit, 0, len(cmds))
+	for _, c := range cmds {
+		resp = append(resp, commandAudit(c))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+func commandAudit(c store.Command) api.CommandAudit {
+	out := api.CommandAudit{
+		Id:          c.ID,
+		RequestedAt: c.RequestedAt,
+		Source:      c.Source,
+		Command:     c.Command,
+		Success:     c.Success,
+		ResultCode:  c.ResultCode,
+		LatencyMs:   c.Latency.Milliseconds(),
+	}
+	if c.User != "" {
+		out.User = &c.User
+	}
+	if len(c.Params) > 0 {
+		out.Params = &c.Params
+	}
+	if c.Error != "" {
+		out.Error = &c.Error
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+func getCommands(svc *server, params api.GetCommandsParams) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/commands", nil)
+	svc.GetCommands(rec, r, params)
+	return rec
+}
+
+func TestGetCommands_FiltersAndMapsEntries(t *testing.T) {
+	st := memory.New()
+	at := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	for _, c := range []store.Command{
+		{RequestedAt: at, Source: "api", User: "alice", Command: "discharge", Params: map[string]string{"power": "5.0"}, Success: true, ResultCode: 1, Latency: 840 * time.Millisecond},
+		{RequestedAt: at.Add(time.Minute), Source: "api", User: "bob", Command: "battery_stop", Error: "timed out", Latency: 10 * time.Second},
+	} {
+		require.NoError(t, st.RecordCommand(context.Background(), c))
+	}
+	svc := newTestServer(servermocks.NewWinetService(t), st)
+
+	user := "alice"
+	rec := getCommands(svc, api.GetCommandsParams{User: &user})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []api.CommandAudit
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "discharge", got[0].Command)
+	assert.True(t, at.Equal(got[0].RequestedAt))
+	require.NotNil(t, got[0].User)
+	assert.Equal(t, "alice", *got[0].User)
+	require.NotNil(t, got[0].Params)
+	assert.Equal(t, map[string]string{"power": "5.0"}, *got[0].Params)
+	assert.True(t, got[0].Success)
+	assert.Equal(t, 1, got[0].ResultCode)
+	assert.Equal(t, int64(840), got[0].LatencyMs)
+	assert.Nil(t, got[0].Error)
+}
+
+func TestGetCommands_EmptyLogReturnsEmptyArray(t *testing.T) {
+	svc := newTestServer(servermocks.NewWinetService(t), memory.New())
+
+	rec := getCommands(svc, api.GetCommandsParams{})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestGetCommands_InvalidParams_Returns400(t *testing.T) {
+	zero, tooMany := 0, maxCommandLimit+1
+	unknown := api.GetCommandsParamsSource("cron")
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	for name, params := range map[string]api.GetCommandsParams{
+		"zero limit":     {Limit: &zero},
+		"limit too high": {Limit: &tooMany},
+		"unknown source": {Source: &unknown},
+		"from after to":  {From: &from, To: &to},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+			assert.Equal(t, http.StatusBadRequest, getCommands(svc, params).Code)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+// InverterState reports the control state the service last put the inverter
+// into.
+type InverterState interface {
+	State() (store.InverterState, bool)
+}
+
+// SetInverterState exposes st on GET /inverter/state. Without it the endpoint
+// always answers 404.
+func (s *server) SetInverterState(st InverterState) {
+	s.inverterState = st
+}
+
+// GetInverterState implements api.ServerInterface.
+func (s *server) GetInverterState(w http.ResponseWriter, r *http.Request) {
+	if s.inverterState == nil {
+		http.Error(w, "inverter state unknown", http.StatusNotFound)
+		return
+	}
+	st, ok := s.inverterState.State()
+	if !ok {
+		http.Error(w, "inverter state unknown", http.StatusNotFound)
+		return
+	}
+	resp := api.InverterState{
+		Id:            st.ID,
+		State:         api.InverterStateState(st.State),
+		BatteryState:  api.InverterStateBatteryState(st.BatteryState),
+		ChargeRate:    st.ChargeRate,
+		FeedinEnabled: st.FeedinEnabled,
+		UpdatedAt:     st.UpdatedAt,
+	}
+	if !st.ReconciledAt.IsZero() {
+		resp.ReconciledAt = &st.ReconciledAt
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handleError(w, err)
+		return
+	}
+}
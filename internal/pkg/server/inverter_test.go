@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+type stubInverterState struct {
+	state store.InverterState
+	known bool
+}
+
+func (s stubInverterState) State() (store.InverterState, bool) { return s.state, s.known }
+
+func getInverterState(svc *server) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	svc.GetInverterState(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/inverter/state", nil))
+	return rec
+}
+
+func TestGetInverterState_ReturnsState(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+	svc.SetInverterState(stubInverterState{known: true, state: store.InverterState{
+		ID:            "SN001",
+		State:         "ON",
+		BatteryState:  "DISCHARGING",
+		ChargeRate:    5.5,
+		FeedinEnabled: true,
+		UpdatedAt:     updated,
+	}})
+
+	rec := getInverterState(svc)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got api.InverterState
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "SN001", got.Id)
+	assert.Equal(t, api.InverterStateStateON, got.State)
+	assert.Equal(t, api.InverterStateBatteryStateDISCHARGING, got.BatteryState)
+	assert.InDelta(t, 5.5, got.ChargeRate, 1e-9)
+	assert.True(t, got.FeedinEnabled)
+	assert.True(t, updated.Equal(got.UpdatedAt))
+	assert.Nil(t, got.ReconciledAt)
+}
+
+func TestGetInverterState_Unknown_Returns404(t *testing.T) {
+	svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+	assert.Equal(t, http.StatusNotFound, getInverterState(svc).Code, "no tracker")
+
+	svc.SetInverterState(stubInverterState{})
+	assert.Equal(t, http.StatusNotFound, getInverterState(svc).Code, "no command sent yet")
+}
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"slices"
 	"strings"
@@ -11,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/anicoll/winet-integration/internal/pkg/auth"
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
 )
 
 type contextKey string
@@ -27,7 +30,7 @@ func LoggingMiddleware(allowedOrigins []string) func(http.Handler) http.Handler
 	logger := zap.L()
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger.Info(r.RequestURI)
+			logger.Info(redactedURI(r))
 			origin := r.Header.Get("Origin")
 			if slices.Contains(allowedOrigins, origin) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
@@ -44,7 +47,23 @@ func LoggingMiddleware(allowedOrigins []string) func(http.Handler) http.Handler
 	}
 }
 
+// redactedURI returns the request URI with any access_token query parameter
+// masked, so stream tokens do not end up in the logs.
+func redactedURI(r *http.Request) string {
+	q := r.URL.Query()
+	if !q.Has(accessTokenParam) {
+		return r.RequestURI
+	}
+	q.Set(accessTokenParam, "REDACTED")
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// accessTokenParam carries the access token on the stream endpoints, as
+// EventSource and browser WebSockets cannot set an Authorization header.
+const accessTokenParam = "access_token"
+
 // AuthMiddleware validates the Bearer token on every request except /auth/* and /health.
+// The stream endpoints also accept the token in the access_token query parameter.
 func AuthMiddleware(svc *auth.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -54,12 +73,17 @@ func AuthMiddleware(svc *auth.Service) func(http.Handler) http.Handler {
 			}
 
 			header := r.Header.Get("Authorization")
-			if !strings.HasPrefix(header, "Bearer ") {
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok && isStreamPath(r.URL.Path) {
+				token = r.URL.Query().Get(accessTokenParam)
+				ok = token != ""
+			}
+			if !ok {
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			claims, err := svc.ValidateAccessToken(strings.TrimPrefix(header, "Bearer "))
+			claims, err := svc.ValidateAccessToken(token)
 			if err != nil {
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
@@ -71,8 +95,49 @@ func AuthMiddleware(svc *auth.Service) func(http.Handler) http.Handler {
 	}
 }
 
+// statusRecorder captures the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// Hijack lets the WebSocket endpoint take over the connection; the upgrader
+// looks for http.Hijacker directly rather than unwrapping.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// MetricsMiddleware records request latency by matched route pattern.
+// It must run inside the router so r.Pattern is populated.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(route, r.Method, rec.status, time.Since(start))
+	})
+}
+
+// TimeoutMiddleware cancels a request after 5 seconds, except on the
+// long-lived stream endpoints.
 func TimeoutMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isStreamPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/anicoll/winet-integration/internal/pkg/auth"
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 	authmocks "github.com/anicoll/winet-integration/mocks/auth"
 )
@@ -135,6 +136,33 @@ func TestAuthMiddleware_HealthExempt(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
 
+func TestAuthMiddleware_StreamAcceptsQueryToken(t *testing.T) {
+	svc := newMiddlewareAuthService(t)
+	handler := AuthMiddleware(svc)(okHandler)
+	token := validBearerToken(t, svc)
+
+	for path, want := range map[string]int{
+		"/stream":     http.StatusOK,
+		"/stream/ws":  http.StatusOK,
+		"/properties": http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, path+"?access_token="+token, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, path)
+	}
+}
+
+func TestAuthMiddleware_StreamRejectsInvalidQueryToken(t *testing.T) {
+	handler := AuthMiddleware(newMiddlewareAuthService(t))(okHandler)
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/stream?access_token=not.a.real.token", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
 // --- LoggingMiddleware ---
 
 func TestLoggingMiddleware_SetsCORSHeaders(t *testing.T) {
@@ -176,3 +204,68 @@ func TestLoggingMiddleware_NoCORSWhenOriginNotAllowed(t *testing.T) {
 	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+func TestRedactedURI(t *testing.T) {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/stream?slug=load_power&access_token=secret", nil)
+	got := redactedURI(req)
+	assert.NotContains(t, got, "secret")
+	assert.Contains(t, got, "slug=load_power")
+
+	req = httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/properties?x=1", nil)
+	assert.Equal(t, "/properties?x=1", redactedURI(req))
+}
+
+// --- TimeoutMiddleware ---
+
+func TestTimeoutMiddleware_StreamHasNoDeadline(t *testing.T) {
+	for path, wantDeadline := range map[string]bool{"/stream": false, "/stream/ws": false, "/properties": true} {
+		var hasDeadline bool
+		handler := TimeoutMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, hasDeadline = r.Context().Deadline()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, nil))
+		assert.Equal(t, wantDeadline, hasDeadline, path)
+	}
+}
+
+// --- MetricsMiddleware ---
+
+// requestCount returns how many requests were observed for the given labels.
+func requestCount(t *testing.T, route, method, code string) uint64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != "winet_http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["route"] == route && labels["method"] == method && labels["code"] == code {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsMiddleware_RecordsRoutePatternAndStatus(t *testing.T) {
+	const pattern = "GET /property/{identifier}/{slug}"
+	mux := http.NewServeMux()
+	mux.Handle(pattern, MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	before := requestCount(t, pattern, http.MethodGet, "418")
+
+	for _, path := range []string{"/property/SN1/battery_power", "/property/SN2/load_power"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+	}
+
+	assert.Equal(t, before+2, requestCount(t, pattern, http.MethodGet, "418"), "both paths share the route pattern label")
+}
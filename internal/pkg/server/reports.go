@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/tariff"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+const (
+	// maxReportDays caps the span of one energy report.
+	maxReportDays = 731
+	// reportResolution is the rollup the daily counters are read from. Every
+	// timezone offset in use is a multiple of it, so its buckets never
+	// straddle local midnight.
+	reportResolution = 15 * time.Minute
+)
+
+type reports struct {
+	cfg    *config.ReportConfig
+	tariff *tariff.Tariff
+}
+
+// SetReports exposes GET /reports/energy, built from the daily counters named
+// in cfg and priced with t. Without it the endpoint answers 404.
+func (s *server) SetReports(cfg *config.ReportConfig, t *tariff.Tariff) {
+	s.reports = &reports{cfg: cfg, tariff: t}
+}
+
+// energyTotals is the energy, in kWh, of one day or period, and what the
+// import cost at the rates in force when it was drawn.
+type energyTotals struct {
+	pv, consumption, gridImport, gridExport, charge, discharge float64
+	importCost                                                 float64
+}
+
+func (t *energyTotals) add(o energyTotals) {
+	t.pv += o.pv
+	t.consumption += o.consumption
+	t.gridImport += o.gridImport
+	t.gridExport += o.gridExport
+	t.charge += o.charge
+	t.discharge += o.discharge
+	t.importCost += o.importCost
+}
+
+// GetReportsEnergy implements api.ServerInterface.
+func (s *server) GetReportsEnergy(w http.ResponseWriter, r *http.Request, params api.GetReportsEnergyParams) {
+	if s.reports == nil {
+		http.NotFound(w, r)
+		return
+	}
+	interval := api.Day
+	if params.Interval != nil {
+		if !params.Interval.Valid() {
+			handleError(w, &clientError{fmt.Errorf("unknown interval %q", *params.Interval)})
+			return
+		}
+		interval = *params.Interval
+	}
+
+	now := time.Now().In(s.loc)
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+	if params.To != nil {
+		last = s.localDate(params.To.Time)
+	}
+	first := last.AddDate(0, 0, -6)
+	if interval == api.Month {
+		first = time.Date(last.Year(), last.Month()-11, 1, 0, 0, 0, 0, s.loc)
+	}
+	if params.From != nil {
+		first = s.localDate(params.From.Time)
+	}
+	if last.Before(first) {
+		handleError(w, &clientError{errors.New("from must not be after to")})
+		return
+	}
+	// Local dates, so a day is counted even when DST makes it 23 or 25 hours.
+	days := make([]time.Time, 0, 32)
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		if len(days) == maxReportDays {
+			handleError(w, &clientError{fmt.Errorf("the range spans more than %d days", maxReportDays)})
+			return
+		}
+		days = append(days, d)
+	}
+
+	totals, err := s.dailyEnergy(r.Context(), days)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	resp := api.EnergyReport{
+		Interval: string(interval),
+		Timezone: s.loc.String(),
+		Currency: s.reports.tariff.Currency,
+		Periods:  []api.EnergyPeriod{},
+	}
+	var all energyTotals
+	for i := 0; i < len(days); {
+		// A month period runs to the end of its month or of the range.
+		j := i + 1
+		for interval == api.Month && j < len(days) && days[j].Month() == days[i].Month() {
+			j++
+		}
+		var period energyTotals
+		for _, t := range totals[i:j] {
+			period.add(t)
+		}
+		all.add(period)
+		resp.Periods = append(resp.Periods, s.energyPeriod(period, days[i], days[j-1].AddDate(0, 0, 1), j-i))
+		i = j
+	}
+	resp.Total = s.energyPeriod(all, first, last.AddDate(0, 0, 1), len(days))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// localDate returns local midnight on the calendar date of d.
+func (s *server) localDate(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, s.loc)
+}
+
+// dailyEnergy returns the totals of each of days, consecutive local
+// midnights.
+func (s *server) dailyEnergy(ctx context.Context, days []time.Time) ([]energyTotals, error) {
+	cfg := s.reports.cfg
+	var identifiers map[string][]string
+	if cfg.Identifier == "" {
+		sensors, err := s.db.GetSensors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		identifiers = make(map[string][]string)
+		for _, sensor := range sensors {
+			identifiers[sensor.Slug] = append(identifiers[sensor.Slug], sensor.Identifier)
+		}
+	}
+
+	index := make(map[string]int, len(days))
+	for i, d := range days {
+		index[d.Format(time.DateOnly)] = i
+	}
+	totals := make([]energyTotals, len(days))
+	from, to := days[0], days[len(days)-1].AddDate(0, 0, 1)
+	for slug, field := range map[string]func(*energyTotals) *float64{
+		cfg.PVSlug:          func(t *energyTotals) *float64 { return &t.pv },
+		cfg.ConsumptionSlug: func(t *energyTotals) *float64 { return &t.consumption },
+		cfg.ImportSlug:      func(t *energyTotals) *float64 { return &t.gridImport },
+		cfg.ExportSlug:      func(t *energyTotals) *float64 { return &t.gridExport },
+		cfg.ChargeSlug:      func(t *energyTotals) *float64 { return &t.charge },
+		cfg.DischargeSlug:   func(t *energyTotals) *float64 { return &t.discharge },
+	} {
+		if slug == "" {
+			continue
+		}
+		ids := identifiers[slug]
+		if cfg.Identifier != "" {
+			ids = []string{cfg.Identifier}
+		}
+		for _, identifier := range ids {
+			aggs, err := s.db.GetAggregates(ctx, identifier, slug, reportResolution, from, to)
+			if err != nil {
+				return nil, err
+			}
+			// The counter restarts from zero each local day; each bucket
+			// adds what it rose by since the bucket before.
+			day, prev := "", 0.0
+			for _, a := range aggs {
+				ts := a.BucketStart.In(s.loc)
+				i, ok := index[ts.Format(time.DateOnly)]
+				if !ok {
+					continue
+				}
+				if d := ts.Format(time.DateOnly); d != day {
+					day, prev = d, 0
+				}
+				v := a.Last
+				if a.UnitOfMeasurement == "Wh" {
+					v /= 1000
+				}
+				inc := v - prev
+				if v < prev {
+					// Reset without a reading of zero.
+					inc = v
+				}
+				prev = v
+				*field(&totals[i]) += inc
+				if slug == cfg.ImportSlug {
+					totals[i].importCost += inc * s.reports.tariff.ImportRate(a.BucketStart)
+				}
+			}
+		}
+	}
+	return totals, nil
+}
+
+// energyPeriod prices t over [start, end), which spans days local days.
+func (s *server) energyPeriod(t energyTotals, start, end time.Time, days int) api.EnergyPeriod {
+	tr := s.reports.tariff
+	exportCredit := t.gridExport * tr.ExportRate
+	supply := tr.DailyCharge * float64(days)
+	p := api.EnergyPeriod{
+		Start:            start,
+		End:              end,
+		PvGeneration:     roundReport(t.pv),
+		Consumption:      roundReport(t.consumption),
+		GridImport:       roundReport(t.gridImport),
+		GridExport:       roundReport(t.gridExport),
+		BatteryCharge:    roundReport(t.charge),
+		BatteryDischarge: roundReport(t.discharge),
+		ImportCost:       roundReport(t.importCost),
+		ExportCredit:     roundReport(exportCredit),
+		SupplyCharge:     roundReport(supply),
+		NetCost:          roundReport(t.importCost + supply - exportCredit),
+	}
+	if t.consumption > 0 {
+		ss := roundReport(min(max(1-t.gridImport/t.consumption, 0), 1))
+		p.SelfSufficiency = &ss
+	}
+	return p
+}
+
+// roundReport rounds to three decimals, dropping float noise from the sums.
+func roundReport(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	"github.com/anicoll/winet-integration/internal/pkg/tariff"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+func newReportServer(t *testing.T, db Database) (*server, *time.Location) {
+	t.Helper()
+	// Half-hour offset and daylight saving, to catch UTC-aligned days.
+	loc, err := time.LoadLocation("Australia/Adelaide")
+	require.NoError(t, err)
+	tr, err := tariff.New(&config.TariffConfig{
+		Currency:      "AUD",
+		ImportRate:    0.30,
+		ImportPeriods: map[string]float64{"16:00-21:00": 0.50, "22:00-07:00": 0.20},
+		ExportRate:    0.05,
+		DailyCharge:   1,
+	}, loc)
+	require.NoError(t, err)
+	svc := New(servermocks.NewWinetService(t), db, nil, false, loc)
+	svc.SetReports(&config.ReportConfig{
+		PVSlug:          "daily_pv_yield",
+		ConsumptionSlug: "daily_load_consumption",
+		ImportSlug:      "daily_purchased_energy",
+		ExportSlug:      "daily_feed_in_energy",
+		ChargeSlug:      "daily_battery_charging_energy",
+		DischargeSlug:   "daily_battery_discharging_energy",
+	}, tr)
+	return svc, loc
+}
+
+// writeCounters stores readings of the daily counters over 10 and 11 January
+// 2026, local time.
+func writeCounters(t *testing.T, st *memory.Store, loc *time.Location) {
+	t.Helper()
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 1, day, hour, minute, 0, 0, loc) }
+	for _, r := range []struct {
+		ts    time.Time
+		slug  string
+		value string
+	}{
+		{at(10, 6, 0), "daily_purchased_energy", "1"}, // off-peak
+		{at(10, 8, 0), "daily_pv_yield", "1"},
+		{at(10, 10, 0), "daily_load_consumption", "5"},
+		{at(10, 12, 0), "daily_pv_yield", "10"},
+		{at(10, 14, 0), "daily_feed_in_energy", "4"},
+		{at(10, 15, 0), "daily_battery_charging_energy", "5"},
+		{at(10, 18, 0), "daily_purchased_energy", "3"}, // 2 kWh at peak
+		{at(10, 20, 0), "daily_pv_yield", "20"},
+		{at(10, 21, 0), "daily_battery_discharging_energy", "4"},
+		{at(10, 22, 0), "daily_load_consumption", "10"},
+		{at(11, 0, 5), "daily_pv_yield", "0"},
+		{at(11, 12, 0), "daily_pv_yield", "15"},
+		{at(11, 12, 0), "daily_load_consumption", "8"},
+		{at(11, 12, 0), "daily_purchased_energy", "0.5"},
+		{at(11, 14, 0), "daily_feed_in_energy", "6"},
+	} {
+		require.NoError(t, st.Write(context.Background(), []publisher.DataPoint{
+			{Timestamp: r.ts.UTC(), Identifier: "SN-1", Slug: r.slug, Value: r.value, UnitOfMeasurement: "kWh"},
+		}))
+	}
+}
+
+func getEnergyReport(svc *server, params api.GetReportsEnergyParams) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	svc.GetReportsEnergy(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/reports/energy", nil), params)
+	return rec
+}
+
+func date(year int, month time.Month, day int) *openapi_types.Date {
+	return &openapi_types.Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func decodeEnergyReport(t *testing.T, rec *httptest.ResponseRecorder) api.EnergyReport {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var report api.EnergyReport
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	return report
+}
+
+func ratio(v float64) *float64 { return &v }
+
+func TestGetReportsEnergy_Daily(t *testing.T) {
+	st := memory.New()
+	svc, loc := newReportServer(t, st)
+	writeCounters(t, st, loc)
+
+	report := decodeEnergyReport(t, getEnergyReport(svc, api.GetReportsEnergyParams{From: date(2026, 1, 10), To: date(2026, 1, 11)}))
+
+	assert.Equal(t, "day", report.Interval)
+	assert.Equal(t, "Australia/Adelaide", report.Timezone)
+	assert.Equal(t, "AUD", report.Currency)
+	require.Len(t, report.Periods, 2)
+	day1, day2 := report.Periods[0], report.Periods[1]
+	assert.True(t, time.Date(2026, 1, 10, 0, 0, 0, 0, loc).Equal(day1.Start))
+	assert.True(t, time.Date(2026, 1, 11, 0, 0, 0, 0, loc).Equal(day1.End))
+	assert.Equal(t, api.EnergyPeriod{
+		Start: day1.Start, End: day1.End,
+		PvGeneration: 20, Consumption: 10, GridImport: 3, GridExport: 4, BatteryCharge: 5, BatteryDischarge: 4,
+		SelfSufficiency: ratio(0.7),
+		ImportCost:      1.2, // 1 kWh at 0.20, 2 kWh at 0.50
+		ExportCredit:    0.2,
+		SupplyCharge:    1,
+		NetCost:         2,
+	}, day1)
+	assert.Equal(t, api.EnergyPeriod{
+		Start: day2.Start, End: day2.End,
+		PvGeneration: 15, Consumption: 8, GridImport: 0.5, GridExport: 6,
+		SelfSufficiency: ratio(0.938),
+		ImportCost:      0.15,
+		ExportCredit:    0.3,
+		SupplyCharge:    1,
+		NetCost:         0.85,
+	}, day2)
+	assert.InDelta(t, 35, report.Total.PvGeneration, 1e-9)
+	assert.InDelta(t, 2.85, report.Total.NetCost, 1e-9)
+	assert.True(t, day1.Start.Equal(report.Total.Start))
+	assert.True(t, day2.End.Equal(report.Total.End))
+}
+
+func TestGetReportsEnergy_Monthly(t *testing.T) {
+	st := memory.New()
+	svc, loc := newReportServer(t, st)
+	writeCounters(t, st, loc)
+	month := api.Month
+
+	report := decodeEnergyReport(t, getEnergyReport(svc, api.GetReportsEnergyParams{
+		From: date(2025, 12, 20), To: date(2026, 2, 3), Interval: &month,
+	}))
+
+	require.Len(t, report.Periods, 3)
+	dec, jan, feb := report.Periods[0], report.Periods[1], report.Periods[2]
+	assert.True(t, time.Date(2025, 12, 20, 0, 0, 0, 0, loc).Equal(dec.Start), "the first period starts at from")
+	assert.True(t, time.Date(2026, 1, 1, 0, 0, 0, 0, loc).Equal(dec.End))
+	assert.InDelta(t, 12, dec.SupplyCharge, 1e-9)
+	assert.Nil(t, dec.SelfSufficiency, "no consumption")
+	assert.InDelta(t, 35, jan.PvGeneration, 1e-9)
+	assert.InDelta(t, 3.5, jan.GridImport, 1e-9)
+	assert.InDelta(t, 1.35, jan.ImportCost, 1e-9)
+	assert.InDelta(t, 31, jan.SupplyCharge, 1e-9)
+	assert.Equal(t, ratio(0.806), jan.SelfSufficiency)
+	assert.True(t, time.Date(2026, 2, 4, 0, 0, 0, 0, loc).Equal(feb.End), "the last period ends after to")
+	assert.InDelta(t, 3, feb.SupplyCharge, 1e-9)
+	assert.InDelta(t, 46, report.Total.SupplyCharge, 1e-9)
+}
+
+func TestGetReportsEnergy_NotConfigured_Returns404(t *testing.T) {
+	svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+
+	rec := getEnergyReport(svc, api.GetReportsEnergyParams{})
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGetReportsEnergy_InvalidParams_Returns400(t *testing.T) {
+	bad := api.GetReportsEnergyParamsInterval("week")
+
+	for name, params := range map[string]api.GetReportsEnergyParams{
+		"interval": {Interval: &bad},
+		"range":    {From: date(2026, 1, 11), To: date(2026, 1, 10)},
+		"too long": {From: date(2020, 1, 1), To: date(2026, 1, 1)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, _ := newReportServer(t, servermocks.NewDatabase(t))
+			rec := getEnergyReport(svc, params)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestGetReportsEnergy_StoreError_Returns500(t *testing.T) {
+	db := servermocks.NewDatabase(t)
+	db.EXPECT().GetSensors(mock.Anything).Return(nil, errors.New("boom"))
+	svc, _ := newReportServer(t, db)
+
+	rec := getEnergyReport(svc, api.GetReportsEnergyParams{})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
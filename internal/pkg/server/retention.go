@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anicoll/winet-integration/internal/pkg/retention"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+// Retention reports the data retention policy and its last run.
+type Retention interface {
+	Status() retention.Status
+}
+
+// SetRetention exposes r on GET /retention. Without it the endpoint reports
+// retention as disabled.
+func (s *server) SetRetention(r Retention) {
+	s.retention = r
+}
+
+// GetRetention implements api.ServerInterface.
+func (s *server) GetRetention(w http.ResponseWriter, r *http.Request) {
+	resp := api.RetentionStatus{}
+	if s.retention != nil {
+		resp = retentionStatus(s.retention.Status())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+func retentionStatus(st retention.Status) api.RetentionStatus {
+	raw := st.Policy.Raw.String()
+	slugs := make(map[string]string, len(st.Policy.Slugs))
+	for slug, d := range st.Policy.Slugs {
+		slugs[slug] = d.String()
+	}
+	rollups := make(map[string]string, len(st.Policy.Rollups))
+	for res, d := range st.Policy.Rollups {
+		rollups[res.String()] = d.String()
+	}
+	resp := api.RetentionStatus{
+		Enabled:         true,
+		Schedule:        &st.Schedule,
+		NextRun:         &st.NextRun,
+		RawRetention:    &raw,
+		SlugRetention:   &slugs,
+		RollupRetention: &rollups,
+	}
+	if run := st.LastRun; run != nil {
+		resp.LastRun = &api.RetentionRun{
+			StartedAt:      run.StartedAt,
+			FinishedAt:     run.FinishedAt,
+			RowsDeleted:    run.RowsDeleted,
+			RollupsDeleted: run.RollupsDeleted,
+			ChunksDropped:  &run.ChunksDropped,
+		}
+		if run.Err != "" {
+			resp.LastRun.Error = &run.Err
+		}
+	}
+	return resp
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/retention"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+type stubRetention struct{ status retention.Status }
+
+func (s stubRetention) Status() retention.Status { return s.status }
+
+func getRetention(t *testing.T, svc *server) api.RetentionStatus {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	svc.GetRetention(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/retention", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got api.RetentionStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	return got
+}
+
+func TestGetRetention_ReportsPolicyAndLastRun(t *testing.T) {
+	next := time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC)
+	svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+	svc.SetRetention(stubRetention{retention.Status{
+		Schedule: "0 3 * * *",
+		NextRun:  next,
+		Policy: store.RetentionPolicy{
+			Raw:     192 * time.Hour,
+			Slugs:   map[string]time.Duration{"battery_power": 720 * time.Hour},
+			Rollups: map[time.Duration]time.Duration{time.Minute: 192 * time.Hour},
+		},
+		LastRun: &retention.Run{
+			StartedAt:       next.Add(-24 * time.Hour),
+			FinishedAt:      next.Add(-24*time.Hour + time.Second),
+			RetentionResult: store.RetentionResult{RowsDeleted: 100, RollupsDeleted: 5},
+			Err:             "db down",
+		},
+	}})
+
+	got := getRetention(t, svc)
+
+	assert.True(t, got.Enabled)
+	assert.Equal(t, "0 3 * * *", *got.Schedule)
+	assert.True(t, next.Equal(*got.NextRun))
+	assert.Equal(t, "192h0m0s", *got.RawRetention)
+	assert.Equal(t, map[string]string{"battery_power": "720h0m0s"}, *got.SlugRetention)
+	assert.Equal(t, map[string]string{"1m0s": "192h0m0s"}, *got.RollupRetention)
+	require.NotNil(t, got.LastRun)
+	assert.Equal(t, int64(100), got.LastRun.RowsDeleted)
+	assert.Equal(t, int64(5), got.LastRun.RollupsDeleted)
+	assert.Equal(t, "db down", *got.LastRun.Error)
+}
+
+func TestGetRetention_Disabled(t *testing.T) {
+	svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+
+	got := getRetention(t, svc)
+
+	assert.False(t, got.Enabled)
+	assert.Nil(t, got.LastRun)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+// GetSensors implements api.ServerInterface.
+func (s *server) GetSensors(w http.ResponseWriter, r *http.Request) {
+	sensors, err := s.db.GetSensors(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	resp := make([]api.Sensor, 0, len(sensors))
+	for _, sn := range sensors {
+		resp = append(resp, sensor(sn))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+func sensor(sn store.Sensor) api.Sensor {
+	return api.Sensor{
+		Identifier:        sn.Identifier,
+		Slug:              sn.Slug,
+		Name:              sn.Name,
+		UnitOfMeasurement: sn.UnitOfMeasurement,
+		Text:              model.TextSensors.HasSlug(sn.Slug),
+		FirstSeen:         sn.FirstSeen,
+		LastSeen:          sn.LastSeen,
+		LastValue:         sn.LastValue,
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+func TestGetSensors_ReturnsCatalog(t *testing.T) {
+	st := memory.New()
+	at := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	require.NoError(t, st.Write(context.Background(), []publisher.DataPoint{
+		{Timestamp: at, Identifier: "SH10RT_SN1", Slug: "battery_level", Value: "79.0000", UnitOfMeasurement: "%", Name: "Battery Level (SOC)"},
+		{Timestamp: at, Identifier: "SH10RT_SN1", Slug: "running_status", Value: "Running", Name: "Running Status"},
+	}))
+	require.NoError(t, st.Write(context.Background(), []publisher.DataPoint{
+		{Timestamp: at.Add(time.Minute), Identifier: "SH10RT_SN1", Slug: "battery_level", Value: "80.0000", UnitOfMeasurement: "%"},
+	}))
+	svc := newTestServer(servermocks.NewWinetService(t), st)
+
+	rec := httptest.NewRecorder()
+	svc.GetSensors(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/sensors", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []api.Sensor
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, []api.Sensor{
+		{
+			Identifier: "SH10RT_SN1", Slug: "battery_level", Name: "Battery Level (SOC)", UnitOfMeasurement: "%",
+			FirstSeen: at, LastSeen: at.Add(time.Minute), LastValue: "80.0000",
+		},
+		{
+			Identifier: "SH10RT_SN1", Slug: "running_status", Name: "Running Status", Text: true,
+			FirstSeen: at, LastSeen: at, LastValue: "Running",
+		},
+	}, got)
+}
+
+func TestGetSensors_Empty(t *testing.T) {
+	svc := newTestServer(servermocks.NewWinetService(t), memory.New())
+
+	rec := httptest.NewRecorder()
+	svc.GetSensors(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/sensors", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestGetSensors_StoreError_Returns500(t *testing.T) {
+	db := servermocks.NewDatabase(t)
+	db.EXPECT().GetSensors(mock.Anything).Return(nil, errors.New("boom"))
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+
+	rec := httptest.NewRecorder()
+	svc.GetSensors(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/sensors", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,374 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+const (
+	// maxSeries caps how many series a single query may ask for.
+	maxSeries = 20
+	// defaultSeriesLimit and maxSeriesLimit bound the rows in one page.
+	defaultSeriesLimit = 10000
+	maxSeriesLimit     = 100000
+	// minSeriesPoints is the smallest useful max_points: LTTB always keeps
+	// the first and last point.
+	minSeriesPoints = 3
+	// maxLTTBRows caps the raw rows read per series for LTTB. Longer series
+	// are downsampled from the rollups instead.
+	maxLTTBRows = maxSeriesLimit
+)
+
+// seriesRef names one requested series.
+type seriesRef struct {
+	identifier string
+	slug       string
+}
+
+// seriesPoint is a numeric reading of the series-th requested series.
+type seriesPoint struct {
+	series int
+	ts     time.Time
+	value  float64
+}
+
+// seriesCursor is the decoded next_cursor. It pins the time range of the
+// first page and resumes after the rows already sent: those before After
+// and the first Skip rows at After.
+type seriesCursor struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	After time.Time `json:"after,omitzero"`
+	Skip  int       `json:"skip,omitempty"`
+}
+
+// GetSeries implements api.ServerInterface.
+func (s *server) GetSeries(w http.ResponseWriter, r *http.Request, params api.GetSeriesParams) {
+	refs, err := parseSeries(params.Series)
+	if err != nil {
+		handleError(w, &clientError{err})
+		return
+	}
+	limit := defaultSeriesLimit
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxSeriesLimit {
+			handleError(w, &clientError{fmt.Errorf("limit must be between 1 and %d", maxSeriesLimit)})
+			return
+		}
+		limit = *params.Limit
+	}
+	var maxPoints int
+	if params.MaxPoints != nil {
+		if *params.MaxPoints < minSeriesPoints || *params.MaxPoints > maxAggregatePoints {
+			handleError(w, &clientError{fmt.Errorf("max_points must be between %d and %d", minSeriesPoints, maxAggregatePoints)})
+			return
+		}
+		maxPoints = *params.MaxPoints
+	}
+	method := api.Lttb
+	if params.Downsample != nil {
+		if !params.Downsample.Valid() {
+			handleError(w, &clientError{fmt.Errorf("unknown downsample %q", *params.Downsample)})
+			return
+		}
+		method = *params.Downsample
+	}
+
+	var cur seriesCursor
+	if params.Cursor != nil {
+		if cur, err = decodeSeriesCursor(*params.Cursor); err != nil {
+			handleError(w, &clientError{err})
+			return
+		}
+	} else {
+		cur.To = time.Now()
+		if params.To != nil {
+			cur.To = *params.To
+		}
+		cur.From = cur.To.AddDate(0, 0, -2)
+		if params.From != nil {
+			cur.From = *params.From
+		}
+		if !cur.From.Before(cur.To) {
+			handleError(w, &clientError{errors.New("from must be before to")})
+			return
+		}
+	}
+
+	var (
+		points  []seriesPoint
+		through time.Time
+	)
+	units := make([]string, len(refs))
+	for i, ref := range refs {
+		var (
+			pts  []seriesPoint
+			unit string
+		)
+		switch {
+		case maxPoints == 0:
+			// Raw rows map one to one onto the output, so each series is
+			// read from the cursor and only as far as this page can reach:
+			// the rows skipped at After, the page and one more to tell
+			// whether another page follows.
+			from := cur.From
+			if cur.After.After(from) {
+				from = cur.After
+			}
+			var last time.Time
+			pts, unit, last, err = s.rawSeries(r.Context(), i, ref, from, cur.To, limit+cur.Skip+1)
+			if !last.IsZero() && (through.IsZero() || last.Before(through)) {
+				through = last
+			}
+		case method == api.Bucket || s.rawExpired(ref.slug, cur.From):
+			pts, unit, err = s.bucketedSeries(r.Context(), i, ref, cur.From, cur.To, maxPoints)
+		default:
+			var last time.Time
+			pts, unit, last, err = s.rawSeries(r.Context(), i, ref, cur.From, cur.To, maxLTTBRows)
+			if err == nil && (len(pts) == 0 || !last.IsZero()) {
+				// No raw readings, or too many to hold: the rollups cover
+				// the range either way.
+				pts, unit, err = s.bucketedSeries(r.Context(), i, ref, cur.From, cur.To, maxPoints)
+				break
+			}
+			pts = lttb(pts, maxPoints)
+		}
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		points = append(points, pts...)
+		units[i] = unit
+	}
+	// Stable, so rows with equal timestamps stay in series order and the
+	// cursor can count them.
+	slices.SortStableFunc(points, func(a, b seriesPoint) int { return a.ts.Compare(b.ts) })
+	page, next := pageSeries(points, cur, limit, through)
+
+	var nextCursor *string
+	if next != nil {
+		c := next.encode()
+		nextCursor = &c
+		w.Header().Set("X-Next-Cursor", c)
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		writeSeriesCSV(w, refs, units, page)
+		return
+	}
+
+	resp := api.SeriesPage{Series: make([]api.Series, len(refs)), NextCursor: nextCursor}
+	for i, ref := range refs {
+		resp.Series[i] = api.Series{Identifier: ref.identifier, Slug: ref.slug, UnitOfMeasurement: units[i], Points: []api.SeriesPoint{}}
+	}
+	for _, p := range page {
+		resp.Series[p.series].Points = append(resp.Series[p.series].Points, api.SeriesPoint{Timestamp: p.ts, Value: p.value})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// parseSeries parses identifier:slug pairs. The slug is taken after the last
+// colon, as identifiers are free-form.
+func parseSeries(raw []string) ([]seriesRef, error) {
+	if len(raw) == 0 || len(raw) > maxSeries {
+		return nil, fmt.Errorf("between 1 and %d series are required", maxSeries)
+	}
+	refs := make([]seriesRef, 0, len(raw))
+	for _, v := range raw {
+		i := strings.LastIndex(v, ":")
+		if i <= 0 || i == len(v)-1 {
+			return nil, fmt.Errorf("series %q must be identifier:slug", v)
+		}
+		ref := seriesRef{identifier: v[:i], slug: v[i+1:]}
+		if slices.Contains(refs, ref) {
+			return nil, fmt.Errorf("series %q is repeated", v)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// rawSeries returns the numeric readings of ref in [from, to], oldest first,
+// and their unit, reading at most limit rows. Text readings are skipped but
+// count towards limit. When the limit is reached, last is the time stamp of
+// the last row read: readings before it have all been returned, later ones
+// and possibly some at last have not. Otherwise last is zero.
+func (s *server) rawSeries(ctx context.Context, series int, ref seriesRef, from, to time.Time, limit int) (points []seriesPoint, unit string, last time.Time, err error) {
+	props, err := s.db.GetPropertyRange(ctx, ref.identifier, ref.slug, from, to, limit)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	for _, p := range props {
+		v, err := strconv.ParseFloat(p.Value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		points = append(points, seriesPoint{series: series, ts: p.TimeStamp, value: v})
+		if unit == "" {
+			unit = p.UnitOfMeasurement
+		}
+	}
+	if len(props) == limit {
+		last = props[len(props)-1].TimeStamp
+	}
+	return points, unit, last, nil
+}
+
+// rawExpired reports whether the retention policy has already deleted the
+// raw readings of slug at from.
+func (s *server) rawExpired(slug string, from time.Time) bool {
+	if s.retention == nil {
+		return false
+	}
+	policy := s.retention.Status().Policy
+	keep, ok := policy.Slugs[slug]
+	if !ok {
+		keep = policy.Raw
+	}
+	return keep > 0 && from.Before(time.Now().Add(-keep))
+}
+
+// bucketedSeries averages the rollups of ref into equal buckets, the finest
+// that keeps [from, to] within maxPoints.
+func (s *server) bucketedSeries(ctx context.Context, series int, ref seriesRef, from, to time.Time, maxPoints int) ([]seriesPoint, string, error) {
+	bucket := pickBucket(to.Sub(from), maxPoints)
+	resolution, err := store.PickResolution(bucket)
+	if err != nil {
+		return nil, "", err
+	}
+	aggs, err := s.db.GetAggregates(ctx, ref.identifier, ref.slug, resolution,
+		store.BucketStart(from, bucket), store.BucketStart(to, bucket).Add(bucket))
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		out  []seriesPoint
+		unit string
+	)
+	for _, a := range store.Rebucket(aggs, bucket) {
+		out = append(out, seriesPoint{series: series, ts: a.BucketStart, value: a.Avg()})
+		unit = a.UnitOfMeasurement
+	}
+	return out, unit, nil
+}
+
+// pageSeries returns up to limit points after the cursor position, and the
+// cursor for the page after, or nil when none remain. points must be sorted
+// by time. A non-zero through marks points from that time on as incomplete
+// because a series was read only in part; they are left for the next page.
+func pageSeries(points []seriesPoint, cur seriesCursor, limit int, through time.Time) ([]seriesPoint, *seriesCursor) {
+	if through.After(cur.After) {
+		points = points[:sort.Search(len(points), func(i int) bool { return !points[i].ts.Before(through) })]
+	}
+	start := sort.Search(len(points), func(i int) bool { return !points[i].ts.Before(cur.After) })
+	for n := 0; n < cur.Skip && start < len(points) && points[start].ts.Equal(cur.After); n++ {
+		start++
+	}
+	end := min(start+limit, len(points))
+	if end == len(points) {
+		if through.After(cur.After) {
+			next := cur
+			next.After, next.Skip = through, 0
+			return points[start:end], &next
+		}
+		return points[start:end], nil
+	}
+	last := points[end-1].ts
+	first := end - 1
+	for first > 0 && points[first-1].ts.Equal(last) {
+		first--
+	}
+	next := cur
+	next.After, next.Skip = last, end-first
+	return points[start:end], &next
+}
+
+func (c seriesCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSeriesCursor(s string) (seriesCursor, error) {
+	var c seriesCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil || !c.From.Before(c.To) || c.Skip < 0 || c.Skip > maxSeriesLimit {
+		return seriesCursor{}, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+func writeSeriesCSV(w http.ResponseWriter, refs []seriesRef, units []string, page []seriesPoint) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="series.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"timestamp", "identifier", "slug", "value", "unit_of_measurement"})
+	for _, p := range page {
+		ref := refs[p.series]
+		_ = cw.Write([]string{
+			p.ts.Format(time.RFC3339Nano),
+			ref.identifier,
+			ref.slug,
+			strconv.FormatFloat(p.value, 'f', -1, 64),
+			units[p.series],
+		})
+	}
+	cw.Flush()
+}
+
+// lttb reduces points, sorted by time, to threshold points with the
+// largest-triangle-three-buckets algorithm, which keeps the peaks and troughs
+// a chart needs. The first and last points are always kept.
+func lttb(points []seriesPoint, threshold int) []seriesPoint {
+	if threshold < minSeriesPoints || len(points) <= threshold {
+		return points
+	}
+	x := func(p seriesPoint) float64 { return float64(p.ts.UnixMilli()) }
+
+	out := make([]seriesPoint, 0, threshold)
+	out = append(out, points[0])
+	// The points between the ends are split into threshold-2 buckets; each
+	// contributes the point forming the largest triangle with the point
+	// chosen before it and the average of the bucket after it.
+	every := float64(len(points)-2) / float64(threshold-2)
+	a := 0
+	for i := range threshold - 2 {
+		nextStart := int(float64(i+1)*every) + 1
+		nextEnd := min(int(float64(i+2)*every)+1, len(points))
+		var avgX, avgY float64
+		for _, p := range points[nextStart:nextEnd] {
+			avgX += x(p)
+			avgY += p.value
+		}
+		n := float64(nextEnd - nextStart)
+		avgX, avgY = avgX/n, avgY/n
+
+		ax, ay := x(points[a]), points[a].value
+		best, bestArea := -1, -1.0
+		for j := int(float64(i)*every) + 1; j < nextStart; j++ {
+			area := math.Abs((ax-avgX)*(points[j].value-ay) - (ax-x(points[j]))*(avgY-ay))
+			if area > bestArea {
+				best, bestArea = j, area
+			}
+		}
+		out = append(out, points[best])
+		a = best
+	}
+	return append(out, points[len(points)-1])
+}
@@ -0,0 +1,312 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/retention"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+var seriesStart = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func getSeries(svc *server, accept string, params api.GetSeriesParams) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/series", nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	svc.GetSeries(rec, r, params)
+	return rec
+}
+
+// seriesReadings returns one reading a minute from seriesStart.
+func seriesReadings(identifier, slug, unit string, values ...string) []store.Property {
+	out := make([]store.Property, len(values))
+	for i, v := range values {
+		out[i] = store.Property{
+			TimeStamp: seriesStart.Add(time.Duration(i) * time.Minute), Identifier: identifier, Slug: slug, Value: v, UnitOfMeasurement: unit,
+		}
+	}
+	return out
+}
+
+// expectReadings serves props, oldest first, from GetPropertyRange,
+// honouring the range and limit.
+func expectReadings(db *servermocks.Database, identifier, slug string, props []store.Property) {
+	db.EXPECT().GetPropertyRange(mock.Anything, identifier, slug, mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, from, to time.Time, limit int) ([]store.Property, error) {
+			var out []store.Property
+			for _, p := range props {
+				if !p.TimeStamp.Before(from) && !p.TimeStamp.After(to) && len(out) < limit {
+					out = append(out, p)
+				}
+			}
+			return out, nil
+		}).Maybe()
+}
+
+func decodeSeriesPage(t *testing.T, rec *httptest.ResponseRecorder) api.SeriesPage {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page api.SeriesPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	return page
+}
+
+func TestGetSeries_ReturnsNumericReadingsOldestFirst(t *testing.T) {
+	db := servermocks.NewDatabase(t)
+	expectReadings(db, "SN-1", "load_power", seriesReadings("SN-1", "load_power", "W", "100", "200"))
+	expectReadings(db, "SN-1", "inverter_state", seriesReadings("SN-1", "inverter_state", "", "Running"))
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+	to := seriesStart.Add(time.Hour)
+
+	page := decodeSeriesPage(t, getSeries(svc, "", api.GetSeriesParams{
+		Series: []string{"SN-1:load_power", "SN-1:inverter_state"}, From: &seriesStart, To: &to,
+	}))
+
+	assert.Nil(t, page.NextCursor)
+	require.Len(t, page.Series, 2)
+	assert.Equal(t, api.Series{Identifier: "SN-1", Slug: "load_power", UnitOfMeasurement: "W", Points: []api.SeriesPoint{
+		{Timestamp: seriesStart, Value: 100},
+		{Timestamp: seriesStart.Add(time.Minute), Value: 200},
+	}}, page.Series[0])
+	assert.Empty(t, page.Series[1].Points, "text readings are left out")
+}
+
+func TestGetSeries_PagesWithCursor(t *testing.T) {
+	db := servermocks.NewDatabase(t)
+	expectReadings(db, "SN-1", "load_power", seriesReadings("SN-1", "load_power", "W", "1", "2", "3", "4"))
+	expectReadings(db, "SN-1", "pv_power", seriesReadings("SN-1", "pv_power", "W", "10", "20", "30"))
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+	to := seriesStart.Add(time.Hour)
+	limit := 3
+
+	params := api.GetSeriesParams{Series: []string{"SN-1:load_power", "SN-1:pv_power"}, From: &seriesStart, To: &to, Limit: &limit}
+	var got [2][]float64
+	pages := 0
+	for {
+		rec := getSeries(svc, "", params)
+		page := decodeSeriesPage(t, rec)
+		pages++
+		for i, s := range page.Series {
+			for _, p := range s.Points {
+				got[i] = append(got[i], p.Value)
+			}
+		}
+		if page.NextCursor == nil {
+			assert.Empty(t, rec.Header().Get("X-Next-Cursor"))
+			break
+		}
+		assert.Equal(t, *page.NextCursor, rec.Header().Get("X-Next-Cursor"))
+		// The cursor carries the range; from and to are ignored.
+		params = api.GetSeriesParams{Series: params.Series, Limit: &limit, Cursor: page.NextCursor}
+		require.Less(t, pages, 5)
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []float64{1, 2, 3, 4}, got[0])
+	assert.Equal(t, []float64{10, 20, 30}, got[1], "rows sharing a timestamp split across pages are neither lost nor repeated")
+}
+
+func TestGetSeries_ReadsOnlyWhatThePageNeeds(t *testing.T) {
+	values := make([]string, 50)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+	// Text readings count towards the rows read but yield no points, so
+	// this series is read in part while the other still has points.
+	mixed := []string{"0", "Running", "Running", "Running", "Running", "Running", "Running", "7", "8"}
+	db := servermocks.NewDatabase(t)
+	db.EXPECT().GetPropertyRange(mock.Anything, "SN-1", "load_power", mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, from, to time.Time, limit int) ([]store.Property, error) {
+			assert.LessOrEqual(t, limit, 2*3+1, "each series is read no further than the page reaches")
+			var out []store.Property
+			for _, p := range seriesReadings("SN-1", "load_power", "W", values...) {
+				if !p.TimeStamp.Before(from) && !p.TimeStamp.After(to) && len(out) < limit {
+					out = append(out, p)
+				}
+			}
+			return out, nil
+		})
+	expectReadings(db, "SN-1", "inverter_state", seriesReadings("SN-1", "inverter_state", "", mixed...))
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+	to := seriesStart.Add(time.Hour)
+	limit := 3
+
+	params := api.GetSeriesParams{Series: []string{"SN-1:load_power", "SN-1:inverter_state"}, From: &seriesStart, To: &to, Limit: &limit}
+	var got [2][]float64
+	for range 100 {
+		page := decodeSeriesPage(t, getSeries(svc, "", params))
+		for i, s := range page.Series {
+			for _, p := range s.Points {
+				got[i] = append(got[i], p.Value)
+			}
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		params = api.GetSeriesParams{Series: params.Series, Limit: &limit, Cursor: page.NextCursor}
+	}
+
+	want := make([]float64, len(values))
+	for i := range want {
+		want[i] = float64(i)
+	}
+	assert.Equal(t, want, got[0])
+	assert.Equal(t, []float64{0, 7, 8}, got[1])
+}
+
+func TestGetSeries_LTTBFallsBackToRollupsWithoutRawReadings(t *testing.T) {
+	to := seriesStart.Add(48 * time.Hour)
+	rollups := []store.Aggregate{{BucketStart: seriesStart, UnitOfMeasurement: "W", Sum: 30, Count: 3}}
+	maxPoints := 100
+
+	t.Run("older than raw retention", func(t *testing.T) {
+		db := servermocks.NewDatabase(t)
+		db.EXPECT().GetAggregates(mock.Anything, "SN-1", "load_power", 15*time.Minute, seriesStart, to.Add(30*time.Minute)).Return(rollups, nil)
+		svc := newTestServer(servermocks.NewWinetService(t), db)
+		svc.SetRetention(stubRetention{retention.Status{Policy: store.RetentionPolicy{Raw: 30 * 24 * time.Hour}}})
+
+		page := decodeSeriesPage(t, getSeries(svc, "", api.GetSeriesParams{
+			Series: []string{"SN-1:load_power"}, From: &seriesStart, To: &to, MaxPoints: &maxPoints,
+		}))
+		assert.Equal(t, []api.SeriesPoint{{Timestamp: seriesStart, Value: 10}}, page.Series[0].Points)
+	})
+
+	t.Run("no raw readings", func(t *testing.T) {
+		db := servermocks.NewDatabase(t)
+		expectReadings(db, "SN-1", "load_power", nil)
+		db.EXPECT().GetAggregates(mock.Anything, "SN-1", "load_power", 15*time.Minute, seriesStart, to.Add(30*time.Minute)).Return(rollups, nil)
+		svc := newTestServer(servermocks.NewWinetService(t), db)
+
+		page := decodeSeriesPage(t, getSeries(svc, "", api.GetSeriesParams{
+			Series: []string{"SN-1:load_power"}, From: &seriesStart, To: &to, MaxPoints: &maxPoints,
+		}))
+		assert.Equal(t, []api.SeriesPoint{{Timestamp: seriesStart, Value: 10}}, page.Series[0].Points)
+		assert.Equal(t, "W", page.Series[0].UnitOfMeasurement)
+	})
+}
+
+func TestGetSeries_LTTB(t *testing.T) {
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = "0"
+	}
+	values[42] = "500"
+	db := servermocks.NewDatabase(t)
+	expectReadings(db, "SN-1", "load_power", seriesReadings("SN-1", "load_power", "W", values...))
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+	to := seriesStart.Add(2 * time.Hour)
+	maxPoints := 10
+
+	page := decodeSeriesPage(t, getSeries(svc, "", api.GetSeriesParams{
+		Series: []string{"SN-1:load_power"}, From: &seriesStart, To: &to, MaxPoints: &maxPoints,
+	}))
+
+	pts := page.Series[0].Points
+	require.Len(t, pts, maxPoints)
+	assert.Equal(t, seriesStart, pts[0].Timestamp)
+	assert.Equal(t, seriesStart.Add(99*time.Minute), pts[len(pts)-1].Timestamp)
+	assert.True(t, slices.ContainsFunc(pts, func(p api.SeriesPoint) bool { return p.Value == 500 }), "the spike survives")
+}
+
+func TestGetSeries_BucketAveragesRollups(t *testing.T) {
+	to := seriesStart.Add(48 * time.Hour)
+	db := servermocks.NewDatabase(t)
+	// 48h within 100 points -> 30m buckets, served from the 15m rollup.
+	db.EXPECT().GetAggregates(mock.Anything, "SN-1", "load_power", 15*time.Minute, seriesStart, to.Add(30*time.Minute)).
+		Return([]store.Aggregate{
+			{BucketStart: seriesStart, UnitOfMeasurement: "W", Sum: 30, Count: 3},
+			{BucketStart: seriesStart.Add(15 * time.Minute), UnitOfMeasurement: "W", Sum: 10, Count: 1},
+		}, nil)
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+	maxPoints, method := 100, api.Bucket
+
+	page := decodeSeriesPage(t, getSeries(svc, "", api.GetSeriesParams{
+		Series: []string{"SN-1:load_power"}, From: &seriesStart, To: &to, MaxPoints: &maxPoints, Downsample: &method,
+	}))
+
+	assert.Equal(t, []api.SeriesPoint{{Timestamp: seriesStart, Value: 10}}, page.Series[0].Points)
+	assert.Equal(t, "W", page.Series[0].UnitOfMeasurement)
+}
+
+func TestGetSeries_CSV(t *testing.T) {
+	db := servermocks.NewDatabase(t)
+	expectReadings(db, "SN-1", "load_power", seriesReadings("SN-1", "load_power", "W", "1520", "0.5"))
+	expectReadings(db, "SN-2", "battery_level", seriesReadings("SN-2", "battery_level", "%", "80"))
+	svc := newTestServer(servermocks.NewWinetService(t), db)
+	to := seriesStart.Add(time.Hour)
+
+	rec := getSeries(svc, "text/csv", api.GetSeriesParams{
+		Series: []string{"SN-1:load_power", "SN-2:battery_level"}, From: &seriesStart, To: &to,
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "timestamp,identifier,slug,value,unit_of_measurement\n"+
+		"2024-06-01T00:00:00Z,SN-1,load_power,1520,W\n"+
+		"2024-06-01T00:00:00Z,SN-2,battery_level,80,%\n"+
+		"2024-06-01T00:01:00Z,SN-1,load_power,0.5,W\n", rec.Body.String())
+}
+
+func TestGetSeries_InvalidParams_Returns400(t *testing.T) {
+	to := seriesStart.Add(time.Hour)
+	zero, tooMany, bad := 0, maxAggregatePoints+1, api.GetSeriesParamsDownsample("median")
+	junk := "not-a-cursor"
+	many := make([]string, maxSeries+1)
+	for i := range many {
+		many[i] = "SN-1:slug" + strconv.Itoa(i)
+	}
+
+	for name, params := range map[string]api.GetSeriesParams{
+		"no series":        {},
+		"too many series":  {Series: many},
+		"missing slug":     {Series: []string{"SN-1"}},
+		"empty identifier": {Series: []string{":load_power"}},
+		"repeated series":  {Series: []string{"SN-1:load_power", "SN-1:load_power"}},
+		"limit":            {Series: []string{"SN-1:load_power"}, Limit: &zero},
+		"max_points":       {Series: []string{"SN-1:load_power"}, MaxPoints: &tooMany},
+		"downsample":       {Series: []string{"SN-1:load_power"}, Downsample: &bad},
+		"range":            {Series: []string{"SN-1:load_power"}, From: &to, To: &seriesStart},
+		"cursor":           {Series: []string{"SN-1:load_power"}, Cursor: &junk},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+			rec := getSeries(svc, "", params)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestLTTB(t *testing.T) {
+	points := make([]seriesPoint, 1000)
+	for i := range points {
+		points[i] = seriesPoint{ts: seriesStart.Add(time.Duration(i) * time.Second), value: math.Sin(float64(i) / 50)}
+	}
+
+	got := lttb(points, 50)
+
+	require.Len(t, got, 50)
+	assert.Equal(t, points[0], got[0])
+	assert.Equal(t, points[len(points)-1], got[len(got)-1])
+	assert.True(t, slices.IsSortedFunc(got, func(a, b seriesPoint) int { return a.ts.Compare(b.ts) }))
+	peak := slices.MaxFunc(got, func(a, b seriesPoint) int { return cmp.Compare(a.value, b.value) })
+	assert.Greater(t, peak.value, 0.99, "the maximum is kept")
+
+	assert.Equal(t, points[:10], lttb(points[:10], 50), "short series are returned as is")
+}
@@ -12,45 +12,59 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/anicoll/winet-integration/internal/pkg/auth"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 	api "github.com/anicoll/winet-integration/pkg/server"
 )
 
 var _ api.ServerInterface = (*server)(nil)
 
+// WinetService sends inverter and battery commands. ctx carries the
+// model.CommandOrigin recorded in the command audit log.
 type WinetService interface {
-	SendSelfConsumptionCommand() (bool, error)
-	SendBatteryStopCommand() (bool, error)
-	SetFeedInLimitation(feedinLimited bool) (bool, error)
+	SendSelfConsumptionCommand(ctx context.Context) (bool, error)
+	SendBatteryStopCommand(ctx context.Context) (bool, error)
+	SetFeedInLimitation(ctx context.Context, feedinLimited bool) (bool, error)
 	// like 6.6
-	SendDischargeCommand(dischargePower string) (bool, error)
+	SendDischargeCommand(ctx context.Context, dischargePower string) (bool, error)
 	// like 6.6
-	SendChargeCommand(chargePower string) (bool, error)
-	SendInverterStateChangeCommand(disable bool) (bool, error)
+	SendChargeCommand(ctx context.Context, chargePower string) (bool, error)
+	SendInverterStateChangeCommand(ctx context.Context, disable bool) (bool, error)
 }
 
 type Database interface {
 	GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error)
+	GetSensors(ctx context.Context) ([]store.Sensor, error)
 	GetProperties(ctx context.Context, identifier, slug string, from, to *time.Time) ([]store.Property, error)
+	GetPropertyRange(ctx context.Context, identifier, slug string, from, to time.Time, limit int) ([]store.Property, error)
+	GetAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error)
+	GetCommands(ctx context.Context, filter store.CommandFilter) ([]store.Command, error)
 }
 
 type server struct {
-	winets        WinetService
-	db            Database
-	authSvc       *auth.Service
-	secureCookies bool
-	logger        *zap.Logger
-	loc           *time.Location
+	winets         WinetService
+	db             Database
+	authSvc        *auth.Service
+	secureCookies  bool
+	logger         *zap.Logger
+	loc            *time.Location
+	retention      Retention
+	inverterState  InverterState
+	stream         Stream
+	allowedOrigins []string
+	reports        *reports
 }
 
-func New(ws WinetService, db Database, authSvc *auth.Service, secureCookies bool) *server {
+// New returns the API server. Calendar days and months, as in the energy
+// reports, are those of loc.
+func New(ws WinetService, db Database, authSvc *auth.Service, secureCookies bool, loc *time.Location) *server {
 	return &server{
 		winets:        ws,
 		logger:        zap.L(),
 		db:            db,
 		authSvc:       authSvc,
 		secureCookies: secureCookies,
-		loc:           time.Now().Location(),
+		loc:           loc,
 	}
 }
 
@@ -81,6 +95,16 @@ func unmarshalPayload[T any](r *http.Request) (*T, error) {
 	return &out, nil
 }
 
+// commandContext returns the request context tagged as an API command by the
+// authenticated user, for the command audit log.
+func commandContext(r *http.Request) context.Context {
+	origin := model.CommandOrigin{Source: model.CommandSourceAPI}
+	if claims, ok := ClaimsFromContext(r.Context()); ok {
+		origin.User = claims.Username
+	}
+	return model.WithCommandOrigin(r.Context(), origin)
+}
+
 func (s *server) PostBatteryState(w http.ResponseWriter, r *http.Request, state string) {
 	changeStateReq, err := unmarshalPayload[api.ChangeBatteryStatePayload](r)
 	if err != nil {
@@ -88,7 +112,7 @@ func (s *server) PostBatteryState(w http.ResponseWriter, r *http.Request, state
 		return
 	}
 
-	if err := s.changeBatteryState(changeStateReq); err != nil {
+	if err := s.changeBatteryState(commandContext(r), changeStateReq); err != nil {
 		handleError(w, err)
 		return
 	}
@@ -102,7 +126,7 @@ func (s *server) PostInverterFeedin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	success, err := s.winets.SetFeedInLimitation(feedinReq.Disable)
+	success, err := s.winets.SetFeedInLimitation(commandContext(r), feedinReq.Disable)
 	if err != nil {
 		handleError(w, err)
 		return
@@ -116,7 +140,7 @@ func (s *server) PostInverterFeedin(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *server) PostInverterState(w http.ResponseWriter, r *http.Request, state string) {
-	success, err := s.winets.SendInverterStateChangeCommand(state == string(api.Off))
+	success, err := s.winets.SendInverterStateChangeCommand(commandContext(r), state == string(api.Off))
 	if err != nil {
 		handleError(w, err)
 		return
@@ -128,11 +152,11 @@ func (s *server) PostInverterState(w http.ResponseWriter, r *http.Request, state
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (s *server) changeBatteryState(req *api.ChangeBatteryStatePayload) error {
+func (s *server) changeBatteryState(ctx context.Context, req *api.ChangeBatteryStatePayload) error {
 	switch req.State {
 	case api.SelfConsumption:
 		s.logger.Info("switching battery to", zap.String("state", string(req.State)))
-		success, err := s.winets.SendSelfConsumptionCommand()
+		success, err := s.winets.SendSelfConsumptionCommand(ctx)
 		if err != nil {
 			return err
 		}
@@ -141,7 +165,7 @@ func (s *server) changeBatteryState(req *api.ChangeBatteryStatePayload) error {
 		}
 	case api.Stop:
 		s.logger.Info("switching battery to", zap.String("state", string(req.State)))
-		success, err := s.winets.SendBatteryStopCommand()
+		success, err := s.winets.SendBatteryStopCommand(ctx)
 		if err != nil {
 			return err
 		}
@@ -153,7 +177,7 @@ func (s *server) changeBatteryState(req *api.ChangeBatteryStatePayload) error {
 			return &clientError{errors.New("power param cannot be empty")}
 		}
 		s.logger.Info("switching battery to", zap.String("state", string(req.State)), zap.String("power", *req.Power))
-		success, err := s.winets.SendChargeCommand(*req.Power)
+		success, err := s.winets.SendChargeCommand(ctx, *req.Power)
 		if err != nil {
 			return err
 		}
@@ -165,7 +189,7 @@ func (s *server) changeBatteryState(req *api.ChangeBatteryStatePayload) error {
 			return &clientError{errors.New("power param cannot be empty")}
 		}
 		s.logger.Info("switching battery to", zap.String("state", string(req.State)), zap.String("power", *req.Power))
-		success, err := s.winets.SendDischargeCommand(*req.Power)
+		success, err := s.winets.SendDischargeCommand(ctx, *req.Power)
 		if err != nil {
 			return err
 		}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"iter"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -17,8 +16,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/anicoll/winet-integration/internal/pkg/auth"
-	"github.com/anicoll/winet-integration/internal/pkg/store"
-	authmocks "github.com/anicoll/winet-integration/mocks/auth"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
 	servermocks "github.com/anicoll/winet-integration/mocks/server"
 	api "github.com/anicoll/winet-integration/pkg/server"
 )
@@ -28,7 +28,7 @@ import (
 // newTestServer builds a server with a nil auth service.
 // Safe for tests that don't exercise auth endpoints.
 func newTestServer(w WinetService, db Database) *server {
-	return New(w, db, nil, false)
+	return New(w, db, nil, false, time.UTC)
 }
 
 func postJSON(t *testing.T, body any) *http.Request {
@@ -40,21 +40,11 @@ func postJSON(t *testing.T, body any) *http.Request {
 	return r
 }
 
-func propSeq(props []store.Property) iter.Seq[store.Property] {
-	return func(yield func(store.Property) bool) {
-		for _, p := range props {
-			if !yield(p) {
-				return
-			}
-		}
-	}
-}
-
 // --- PostBatteryState ---
 
 func TestPostBatteryState_SelfConsumption(t *testing.T) {
 	w := servermocks.NewWinetService(t)
-	w.EXPECT().SendSelfConsumptionCommand().Return(true, nil)
+	w.EXPECT().SendSelfConsumptionCommand(mock.Anything).Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -67,7 +57,7 @@ func TestPostBatteryState_SelfConsumption(t *testing.T) {
 
 func TestPostBatteryState_Stop(t *testing.T) {
 	w := servermocks.NewWinetService(t)
-	w.EXPECT().SendBatteryStopCommand().Return(true, nil)
+	w.EXPECT().SendBatteryStopCommand(mock.Anything).Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -81,7 +71,7 @@ func TestPostBatteryState_Stop(t *testing.T) {
 func TestPostBatteryState_Charge_SendsPower(t *testing.T) {
 	w := servermocks.NewWinetService(t)
 	power := "6.6"
-	w.EXPECT().SendChargeCommand("6.6").Return(true, nil)
+	w.EXPECT().SendChargeCommand(mock.Anything, "6.6").Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -96,7 +86,7 @@ func TestPostBatteryState_Charge_SendsPower(t *testing.T) {
 func TestPostBatteryState_Discharge_SendsPower(t *testing.T) {
 	w := servermocks.NewWinetService(t)
 	power := "3.3"
-	w.EXPECT().SendDischargeCommand("3.3").Return(true, nil)
+	w.EXPECT().SendDischargeCommand(mock.Anything, "3.3").Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -121,11 +111,31 @@ func TestPostBatteryState_Charge_MissingPower_ReturnsError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestPostBatteryState_TagsCommandWithUser(t *testing.T) {
+	w := servermocks.NewWinetService(t)
+	power := "5.0"
+	w.EXPECT().SendDischargeCommand(mock.Anything, "5.0").
+		Run(func(ctx context.Context, _ string) {
+			origin, ok := model.CommandOriginFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, model.CommandOrigin{Source: model.CommandSourceAPI, User: "admin"}, origin)
+		}).
+		Return(true, nil)
+	svc := newTestServer(w, servermocks.NewDatabase(t))
+
+	r := postJSON(t, api.ChangeBatteryStatePayload{State: api.Discharge, Power: &power})
+	r = r.WithContext(context.WithValue(r.Context(), claimsKey, &auth.Claims{Username: "admin"}))
+	rec := httptest.NewRecorder()
+	svc.PostBatteryState(rec, r, "discharge")
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
 // --- PostInverterFeedin ---
 
 func TestPostInverterFeedin_Disable(t *testing.T) {
 	w := servermocks.NewWinetService(t)
-	w.EXPECT().SetFeedInLimitation(true).Return(true, nil)
+	w.EXPECT().SetFeedInLimitation(mock.Anything, true).Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -136,7 +146,7 @@ func TestPostInverterFeedin_Disable(t *testing.T) {
 
 func TestPostInverterFeedin_Enable(t *testing.T) {
 	w := servermocks.NewWinetService(t)
-	w.EXPECT().SetFeedInLimitation(false).Return(true, nil)
+	w.EXPECT().SetFeedInLimitation(mock.Anything, false).Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -149,7 +159,7 @@ func TestPostInverterFeedin_Enable(t *testing.T) {
 
 func TestPostInverterState_Off(t *testing.T) {
 	w := servermocks.NewWinetService(t)
-	w.EXPECT().SendInverterStateChangeCommand(true).Return(true, nil)
+	w.EXPECT().SendInverterStateChangeCommand(mock.Anything, true).Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -160,7 +170,7 @@ func TestPostInverterState_Off(t *testing.T) {
 
 func TestPostInverterState_On(t *testing.T) {
 	w := servermocks.NewWinetService(t)
-	w.EXPECT().SendInverterStateChangeCommand(false).Return(true, nil)
+	w.EXPECT().SendInverterStateChangeCommand(mock.Anything, false).Return(true, nil)
 	svc := newTestServer(w, servermocks.NewDatabase(t))
 
 	rec := httptest.NewRecorder()
@@ -172,12 +182,12 @@ func TestPostInverterState_On(t *testing.T) {
 // --- GetProperties ---
 
 func TestGetProperties_ReturnsJSON(t *testing.T) {
-	props := []store.Property{
-		{ID: 1, Identifier: "XH3000_SN001", Slug: "battery_power", Value: "5.5", UnitOfMeasurement: "kW"},
-		{ID: 2, Identifier: "XH3000_SN001", Slug: "battery_soc", Value: "80", UnitOfMeasurement: "%"},
-	}
-	db := servermocks.NewDatabase(t)
-	db.EXPECT().GetLatestProperties(mock.Anything).Return(propSeq(props), nil)
+	db := memory.New()
+	now := time.Now()
+	require.NoError(t, db.Write(context.Background(), []publisher.DataPoint{
+		{Timestamp: now, Identifier: "XH3000_SN001", Slug: "battery_power", Value: "5.5", UnitOfMeasurement: "kW"},
+		{Timestamp: now, Identifier: "XH3000_SN001", Slug: "battery_soc", Value: "80", UnitOfMeasurement: "%"},
+	}))
 	svc := newTestServer(servermocks.NewWinetService(t), db)
 
 	rec := httptest.NewRecorder()
@@ -186,9 +196,12 @@ func TestGetProperties_ReturnsJSON(t *testing.T) {
 	require.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
 
-	var got []store.Property
+	var got []map[string]any
 	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
-	assert.Len(t, got, 2)
+	require.Len(t, got, 2)
+	assert.Equal(t, "battery_power", got[0]["slug"])
+	assert.Equal(t, "5.5", got[0]["value"])
+	assert.Equal(t, float64(0), got[0]["id"], "id stays in the response, zero for latest readings")
 }
 
 func TestGetProperties_DBError_Returns500(t *testing.T) {
@@ -215,25 +228,16 @@ func newAuthService(t *testing.T) *auth.Service {
 	h, err := bcrypt.GenerateFromPassword([]byte(authTestPassword), bcrypt.MinCost)
 	require.NoError(t, err)
 
-	us := authmocks.NewUserStore(t)
-	us.EXPECT().GetUserByUsername(mock.Anything, mock.Anything).Return(store.User{
-		ID:           1,
-		Username:     authTestUsername,
-		PasswordHash: string(h),
-	}, nil).Maybe()
-
-	ts := authmocks.NewTokenStore(t)
-	ts.EXPECT().StoreRefreshToken(mock.Anything, mock.Anything).Return(nil).Maybe()
-	ts.EXPECT().GetRefreshToken(mock.Anything, mock.Anything).Return(store.RefreshToken{}, errors.New("not found")).Maybe()
-	ts.EXPECT().DeleteRefreshToken(mock.Anything, mock.Anything).Return(nil).Maybe()
-	ts.EXPECT().DeleteExpiredRefreshTokens(mock.Anything).Return(nil).Maybe()
+	st := memory.New()
+	_, err = st.CreateUser(context.Background(), authTestUsername, string(h))
+	require.NoError(t, err)
 
-	return auth.NewService(authTestSecret, 15*time.Minute, 24*time.Hour, us, ts)
+	return auth.NewService(authTestSecret, 15*time.Minute, 24*time.Hour, st, st)
 }
 
 func newAuthTestServer(t *testing.T) *server {
 	t.Helper()
-	return New(servermocks.NewWinetService(t), servermocks.NewDatabase(t), newAuthService(t), false)
+	return New(servermocks.NewWinetService(t), servermocks.NewDatabase(t), newAuthService(t), false, time.UTC)
 }
 
 // --- PostAuthLogin ---
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/stream"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+const (
+	// streamPingInterval keeps idle streams open through proxies.
+	streamPingInterval = 30 * time.Second
+	// streamWriteTimeout bounds each write to a stream client.
+	streamWriteTimeout = 10 * time.Second
+)
+
+// Stream hands out live reading subscriptions.
+type Stream interface {
+	Subscribe(f stream.Filter) (*stream.Subscription, error)
+}
+
+// SetStream exposes st on GET /stream and GET /stream/ws. allowedOrigins
+// are the browser origins, besides the server's own, that may open the
+// WebSocket. Without a stream both endpoints answer 404.
+func (s *server) SetStream(st Stream, allowedOrigins []string) {
+	s.stream = st
+	s.allowedOrigins = allowedOrigins
+}
+
+// isStreamPath reports whether path is one of the long-lived stream
+// endpoints, which are exempt from the request timeout and accept the
+// access token as a query parameter.
+func isStreamPath(path string) bool {
+	return path == "/stream" || path == "/stream/ws"
+}
+
+// GetStream implements api.ServerInterface.
+func (s *server) GetStream(w http.ResponseWriter, r *http.Request, params api.GetStreamParams) {
+	sub, ok := s.subscribe(w, params.Identifier, params.Slug)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for ordinary requests.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		s.logger.Warn("stream: response cannot be flushed", zap.Error(err))
+		return
+	}
+
+	s.pump(r.Context(), sub, func(batch []publisher.DataPoint) error {
+		data, err := json.Marshal(readings(batch))
+		if err != nil {
+			return err
+		}
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(w, "event: readings\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}, func() error {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+}
+
+// GetStreamWs implements api.ServerInterface.
+func (s *server) GetStreamWs(w http.ResponseWriter, r *http.Request, params api.GetStreamWsParams) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	sub, ok := s.subscribe(w, params.Identifier, params.Slug)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already answered the request.
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Read, and discard, until the client goes away, so control frames are
+	// handled and a closed connection ends the stream.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	s.pump(ctx, sub, func(batch []publisher.DataPoint) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		return conn.WriteJSON(readings(batch))
+	}, func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+	})
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+}
+
+// subscribe opens a subscription for the filters, answering the request
+// itself when it cannot.
+func (s *server) subscribe(w http.ResponseWriter, identifiers, slugs *[]string) (*stream.Subscription, bool) {
+	if s.stream == nil {
+		http.Error(w, "streaming is not enabled", http.StatusNotFound)
+		return nil, false
+	}
+	var f stream.Filter
+	if identifiers != nil {
+		f.Identifiers = *identifiers
+	}
+	if slugs != nil {
+		f.Slugs = *slugs
+	}
+	sub, err := s.stream.Subscribe(f)
+	if errors.Is(err, stream.ErrClosed) {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	if err != nil {
+		handleError(w, err)
+		return nil, false
+	}
+	return sub, true
+}
+
+// pump sends each batch from sub with send, and pings when idle, until the
+// subscription or ctx ends, a write fails, or the caller's access token
+// expires.
+func (s *server) pump(ctx context.Context, sub *stream.Subscription, send func([]publisher.DataPoint) error, ping func() error) {
+	var expired <-chan time.Time
+	if claims, ok := ClaimsFromContext(ctx); ok && claims.ExpiresAt != nil {
+		timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch, ok := <-sub.C():
+			if !ok {
+				if sub.Lagged() {
+					s.logger.Info("stream: client fell behind; closing")
+				}
+				return
+			}
+			if err := send(batch); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				return
+			}
+		case <-expired:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkOrigin allows WebSocket handshakes from non-browser clients, from the
+// server's own origin and from the allowed origins.
+func (s *server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(s.allowedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func readings(batch []publisher.DataPoint) []api.Reading {
+	out := make([]api.Reading, len(batch))
+	for i, dp := range batch {
+		out[i] = api.Reading{
+			Timestamp:         dp.Timestamp,
+			Identifier:        dp.Identifier,
+			Slug:              dp.Slug,
+			Value:             dp.Value,
+			UnitOfMeasurement: dp.UnitOfMeasurement,
+		}
+	}
+	return out
+}
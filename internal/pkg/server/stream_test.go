@@ -0,0 +1,211 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/auth"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/stream"
+	servermocks "github.com/anicoll/winet-integration/mocks/server"
+	api "github.com/anicoll/winet-integration/pkg/server"
+)
+
+var streamBatch = []publisher.DataPoint{
+	{Timestamp: time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), Identifier: "SN-1", Slug: "load_power", Value: "1520", UnitOfMeasurement: "W"},
+	{Timestamp: time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), Identifier: "SN-1", Slug: "battery_level", Value: "80", UnitOfMeasurement: "%"},
+}
+
+// newStreamServer serves the API with hub as its stream. claims, when set,
+// are put in every request context as AuthMiddleware would.
+func newStreamServer(t *testing.T, hub *stream.Hub, claims *auth.Claims) *httptest.Server {
+	t.Helper()
+	svc := newTestServer(servermocks.NewWinetService(t), servermocks.NewDatabase(t))
+	if hub != nil {
+		svc.SetStream(hub, []string{"https://dashboard.example"})
+	}
+	handler := api.HandlerWithOptions(svc, api.StdHTTPServerOptions{
+		Middlewares: []api.MiddlewareFunc{TimeoutMiddleware, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if claims != nil {
+					r = r.WithContext(context.WithValue(r.Context(), claimsKey, claims))
+				}
+				next.ServeHTTP(w, r)
+			})
+		}, MetricsMiddleware},
+	})
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// waitForSubscriber blocks until the handler has subscribed to hub.
+func waitForSubscriber(t *testing.T, hub *stream.Hub) {
+	t.Helper()
+	require.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+// nextEvent reads one Server-Sent Event, skipping comments.
+func nextEvent(t *testing.T, r *bufio.Reader) (event, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestGetStream_SendsFilteredReadings(t *testing.T) {
+	hub := stream.New()
+	srv := newStreamServer(t, hub, nil)
+
+	resp, err := http.Get(srv.URL + "/stream?identifier=SN-1&slug=load_power&slug=pv_power")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	waitForSubscriber(t, hub)
+	require.NoError(t, hub.Write(context.Background(), streamBatch))
+
+	event, data := nextEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "readings", event)
+	var got []api.Reading
+	require.NoError(t, json.Unmarshal([]byte(data), &got))
+	assert.Equal(t, []api.Reading{{
+		Timestamp: streamBatch[0].Timestamp, Identifier: "SN-1", Slug: "load_power", Value: "1520", UnitOfMeasurement: "W",
+	}}, got)
+}
+
+func TestGetStream_OutlivesRequestTimeout(t *testing.T) {
+	hub := stream.New()
+	srv := newStreamServer(t, hub, nil)
+
+	resp, err := http.Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	waitForSubscriber(t, hub)
+
+	// TimeoutMiddleware would have cut an ordinary request off after 5s; the
+	// stream is still subscribed well after the handler started.
+	assert.Never(t, func() bool { return hub.Subscribers() == 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestGetStream_EndsWhenTokenExpires(t *testing.T) {
+	hub := stream.New()
+	// NumericDate has whole-second precision, so this expires in 1-2s.
+	claims := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(2 * time.Second))}}
+	srv := newStreamServer(t, hub, claims)
+
+	resp, err := http.Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	waitForSubscriber(t, hub)
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 0 }, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestGetStream_EndsOnHubShutdown(t *testing.T) {
+	hub := stream.New()
+	srv := newStreamServer(t, hub, nil)
+
+	resp, err := http.Get(srv.URL + "/stream")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	waitForSubscriber(t, hub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = hub.Run(ctx)
+
+	_, err = bufio.NewReader(resp.Body).ReadString('\n')
+	assert.Error(t, err, "the response ends")
+}
+
+func TestGetStream_NotEnabled_Returns404(t *testing.T) {
+	srv := newStreamServer(t, nil, nil)
+
+	for _, path := range []string{"/stream", "/stream/ws"} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+}
+
+func TestGetStreamWs_SendsReadings(t *testing.T) {
+	hub := stream.New()
+	srv := newStreamServer(t, hub, nil)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/stream/ws?slug=battery_level", nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	waitForSubscriber(t, hub)
+	require.NoError(t, hub.Write(context.Background(), streamBatch))
+
+	var got []api.Reading
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "battery_level", got[0].Slug)
+	assert.Equal(t, "80", got[0].Value)
+}
+
+func TestGetStreamWs_UnsubscribesWhenClientLeaves(t *testing.T) {
+	hub := stream.New()
+	srv := newStreamServer(t, hub, nil)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/stream/ws", nil)
+	require.NoError(t, err)
+	waitForSubscriber(t, hub)
+
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestGetStreamWs_ChecksOrigin(t *testing.T) {
+	hub := stream.New()
+	srv := newStreamServer(t, hub, nil)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://dashboard.example"}})
+	require.NoError(t, err, "allowed origin")
+	_ = conn.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestGetStreamWs_RequiresUpgrade(t *testing.T) {
+	srv := newStreamServer(t, stream.New(), nil)
+
+	resp, err := http.Get(srv.URL + "/stream/ws")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
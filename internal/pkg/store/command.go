@@ -0,0 +1,56 @@
+package store
+
+import "time"
+
+// DefaultCommandLimit is the number of audit entries GetCommands returns when
+// CommandFilter.Limit is zero.
+const DefaultCommandLimit = 100
+
+// Command is one entry of the command audit log: an inverter or battery
+// command, who issued it and how WiNet answered.
+type Command struct {
+	ID          int               `json:"id"`
+	RequestedAt time.Time         `json:"requested_at"`
+	Source      string            `json:"source"`
+	User        string            `json:"user"`
+	Command     string            `json:"command"`
+	Params      map[string]string `json:"params"`
+	Success     bool              `json:"success"`
+	ResultCode  int               `json:"result_code"`
+	Error       string            `json:"error"`
+	Latency     time.Duration     `json:"latency"`
+}
+
+// CommandFilter selects audit log entries. Empty fields match everything;
+// From and To bound RequestedAt inclusively.
+type CommandFilter struct {
+	From    *time.Time
+	To      *time.Time
+	User    string
+	Source  string
+	Command string
+	// Limit caps the number of entries, newest first. Zero means
+	// DefaultCommandLimit.
+	Limit int
+}
+
+// Range returns the RequestedAt bounds of f with open ends replaced by the
+// unix epoch and the end of year 9999, so stores can always bind both.
+func (f CommandFilter) Range() (from, to time.Time) {
+	from, to = time.Unix(0, 0).UTC(), time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	if f.From != nil {
+		from = *f.From
+	}
+	if f.To != nil {
+		to = *f.To
+	}
+	return from, to
+}
+
+// LimitOrDefault returns Limit, or DefaultCommandLimit when it is not set.
+func (f CommandFilter) LimitOrDefault() int {
+	if f.Limit <= 0 {
+		return DefaultCommandLimit
+	}
+	return f.Limit
+}
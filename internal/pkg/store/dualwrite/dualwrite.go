@@ -0,0 +1,435 @@
+// Package dualwrite is a store.Store that writes to a primary store and
+// replicates every write to one or more secondaries, for moving between
+// database backends without downtime. Reads are always served by the primary.
+//
+// With PolicyBestEffort a write succeeds once the primary has it; each
+// secondary gets it through its own catch-up queue, drained by Run and
+// retried while the secondary is unavailable. With PolicyAll every store is
+// written synchronously and any failure fails the write.
+package dualwrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// compile-time check that *Store satisfies store.Store.
+var _ store.Store = (*Store)(nil)
+
+// Policy decides how secondary failures affect a write.
+type Policy string
+
+const (
+	// PolicyBestEffort requires only the primary to succeed. Secondary
+	// writes are queued and retried in the background.
+	PolicyBestEffort Policy = "best-effort"
+	// PolicyAll writes every store synchronously and fails the write if any
+	// of them fails. The primary is written first and is not rolled back.
+	PolicyAll Policy = "all"
+)
+
+const (
+	// firstRetry is the delay before the first retry of a failed secondary
+	// write; it doubles up to Options.RetryInterval.
+	firstRetry = time.Second
+	// drainTimeout bounds the final secondary writes on shutdown.
+	drainTimeout = 5 * time.Second
+)
+
+// Options configures a Store.
+type Options struct {
+	Policy Policy
+	// QueueSize is the number of writes each secondary can fall behind by
+	// before new ones are dropped.
+	QueueSize int
+	// RetryInterval caps the delay between retries of a failed write.
+	RetryInterval time.Duration
+	// MaxAttempts drops a write after it has failed this many times; zero
+	// retries forever, which lets a write the secondary always rejects hold
+	// up its queue.
+	MaxAttempts int
+}
+
+// op is a write to replay on a secondary.
+type op struct {
+	name  string
+	apply func(ctx context.Context, st store.Store) error
+}
+
+type secondary struct {
+	name  string
+	store store.Store
+	queue chan op
+}
+
+// Store fans writes out to a primary and its secondaries.
+type Store struct {
+	primary     store.Store
+	secondaries []*secondary
+	opts        Options
+	logger      *zap.Logger
+}
+
+// New returns a Store reading from primary and writing to primary and
+// secondaries. With PolicyBestEffort, call Run to replicate.
+func New(primary store.Store, secondaries []store.Store, opts Options) (*Store, error) {
+	switch opts.Policy {
+	case PolicyBestEffort, PolicyAll:
+	default:
+		return nil, fmt.Errorf("unknown dual-write policy %q", opts.Policy)
+	}
+	if opts.Policy == PolicyBestEffort && opts.QueueSize < 1 {
+		return nil, fmt.Errorf("dual-write queue size must be positive")
+	}
+	if opts.RetryInterval <= 0 {
+		return nil, fmt.Errorf("dual-write retry interval must be positive")
+	}
+	if opts.MaxAttempts < 0 {
+		return nil, fmt.Errorf("dual-write max attempts must not be negative")
+	}
+	s := &Store{primary: primary, opts: opts, logger: zap.L()}
+	for i, st := range secondaries {
+		s.secondaries = append(s.secondaries, &secondary{
+			name:  fmt.Sprintf("%d:%s", i+1, storeName(st)),
+			store: st,
+			queue: make(chan op, opts.QueueSize),
+		})
+	}
+	return s, nil
+}
+
+func storeName(st store.Store) string {
+	if n, ok := st.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", st)
+}
+
+// Name identifies the backend in logs and metrics.
+func (s *Store) Name() string { return "dualwrite" }
+
+// Stores returns the primary followed by the secondaries.
+func (s *Store) Stores() []store.Store {
+	out := []store.Store{s.primary}
+	for _, sec := range s.secondaries {
+		out = append(out, sec.store)
+	}
+	return out
+}
+
+// Run replicates queued writes to the secondaries until ctx is cancelled,
+// then makes one last attempt at whatever is still queued.
+func (s *Store) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, sec := range s.secondaries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.replicate(ctx, sec)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (s *Store) replicate(ctx context.Context, sec *secondary) {
+	for {
+		select {
+		case <-ctx.Done():
+			s.drain(sec, nil)
+			return
+		case o := <-sec.queue:
+			if !s.applyWithRetry(ctx, sec, o) {
+				s.drain(sec, &o)
+				return
+			}
+		}
+	}
+}
+
+// applyWithRetry applies o to sec until it succeeds or has failed
+// MaxAttempts times. It reports false if ctx was cancelled first.
+func (s *Store) applyWithRetry(ctx context.Context, sec *secondary, o op) bool {
+	delay := min(firstRetry, s.opts.RetryInterval)
+	for attempt := 1; ; attempt++ {
+		err := o.apply(ctx, sec.store)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		if s.opts.MaxAttempts > 0 && attempt >= s.opts.MaxAttempts {
+			s.logger.Error("dualwrite: giving up on secondary write",
+				zap.String("secondary", sec.name), zap.String("op", o.name), zap.Int("attempts", attempt), zap.Error(err))
+			metrics.DualWriteDropped.WithLabelValues(sec.name, "max_attempts").Inc()
+			return true
+		}
+		s.logger.Warn("dualwrite: secondary write failed, retrying",
+			zap.String("secondary", sec.name), zap.String("op", o.name), zap.Duration("in", delay), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, s.opts.RetryInterval)
+	}
+}
+
+// drain makes one attempt at pending and the queued writes, stopping at the
+// first failure.
+func (s *Store) drain(sec *secondary, pending *op) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if pending != nil {
+		if err := pending.apply(ctx, sec.store); err != nil {
+			s.lost(sec, 1+len(sec.queue), err)
+			return
+		}
+	}
+	for {
+		select {
+		case o := <-sec.queue:
+			if err := o.apply(ctx, sec.store); err != nil {
+				s.lost(sec, 1+len(sec.queue), err)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *Store) lost(sec *secondary, n int, err error) {
+	s.logger.Error("dualwrite: secondary writes lost on shutdown",
+		zap.String("secondary", sec.name), zap.Int("writes", n), zap.Error(err))
+	metrics.DualWriteDropped.WithLabelValues(sec.name, "shutdown").Add(float64(n))
+}
+
+// write applies fn to the primary and then to the secondaries according to
+// the policy. Queued writes run after the caller has returned, so callers
+// copy any slice or map that fn captures.
+func (s *Store) write(ctx context.Context, name string, fn func(ctx context.Context, st store.Store) error) error {
+	if err := fn(ctx, s.primary); err != nil {
+		return err
+	}
+	return s.replay(ctx, name, fn)
+}
+
+// replay applies fn to the secondaries only.
+func (s *Store) replay(ctx context.Context, name string, fn func(ctx context.Context, st store.Store) error) error {
+	if s.opts.Policy == PolicyAll {
+		var errs []error
+		for _, sec := range s.secondaries {
+			if err := fn(ctx, sec.store); err != nil {
+				errs = append(errs, fmt.Errorf("secondary %s: %w", sec.name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	for _, sec := range s.secondaries {
+		select {
+		case sec.queue <- op{name: name, apply: fn}:
+		default:
+			s.logger.Error("dualwrite: secondary queue full, dropping write",
+				zap.String("secondary", sec.name), zap.String("op", name))
+			metrics.DualWriteDropped.WithLabelValues(sec.name, "queue_full").Inc()
+		}
+	}
+	return nil
+}
+
+// --- sensor data ---
+
+func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
+	data = slices.Clone(data)
+	return s.write(ctx, "write", func(ctx context.Context, st store.Store) error {
+		return st.Write(ctx, data)
+	})
+}
+
+func (s *Store) RegisterDevice(ctx context.Context, device *model.Device) error {
+	d := *device
+	return s.write(ctx, "register device", func(ctx context.Context, st store.Store) error {
+		return st.RegisterDevice(ctx, &d)
+	})
+}
+
+func (s *Store) GetProperties(ctx context.Context, identifier, slug string, from, to *time.Time) ([]store.Property, error) {
+	return s.primary.GetProperties(ctx, identifier, slug, from, to)
+}
+
+func (s *Store) GetPropertyRange(ctx context.Context, identifier, slug string, from, to time.Time, limit int) ([]store.Property, error) {
+	return s.primary.GetPropertyRange(ctx, identifier, slug, from, to, limit)
+}
+
+func (s *Store) GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error) {
+	return s.primary.GetLatestProperties(ctx)
+}
+
+func (s *Store) GetSensors(ctx context.Context) ([]store.Sensor, error) {
+	return s.primary.GetSensors(ctx)
+}
+
+func (s *Store) GetAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error) {
+	return s.primary.GetAggregates(ctx, identifier, slug, resolution, from, to)
+}
+
+// Cleanup applies policy to every store and returns the primary's result.
+func (s *Store) Cleanup(ctx context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	res, err := s.primary.Cleanup(ctx, policy)
+	if err != nil {
+		return res, err
+	}
+	return res, s.replay(ctx, "cleanup", func(ctx context.Context, st store.Store) error {
+		_, err := st.Cleanup(ctx, policy)
+		return err
+	})
+}
+
+// --- command audit ---
+
+func (s *Store) RecordCommand(ctx context.Context, cmd store.Command) error {
+	cmd.Params = maps.Clone(cmd.Params)
+	return s.write(ctx, "record command", func(ctx context.Context, st store.Store) error {
+		return st.RecordCommand(ctx, cmd)
+	})
+}
+
+func (s *Store) GetCommands(ctx context.Context, filter store.CommandFilter) ([]store.Command, error) {
+	return s.primary.GetCommands(ctx, filter)
+}
+
+// --- inverter control state ---
+
+func (s *Store) SaveInverterState(ctx context.Context, st store.InverterState) error {
+	return s.write(ctx, "save inverter state", func(ctx context.Context, target store.Store) error {
+		return target.SaveInverterState(ctx, st)
+	})
+}
+
+func (s *Store) GetInverterStates(ctx context.Context) ([]store.InverterState, error) {
+	return s.primary.GetInverterStates(ctx)
+}
+
+// --- bulk export ---
+
+func (s *Store) ListDevices(ctx context.Context) ([]model.Device, error) {
+	return s.primary.ListDevices(ctx)
+}
+
+func (s *Store) ScanProperties(ctx context.Context, afterID, limit int) ([]store.Property, error) {
+	return s.primary.ScanProperties(ctx, afterID, limit)
+}
+
+func (s *Store) CountProperties(ctx context.Context) (int, error) {
+	return s.primary.CountProperties(ctx)
+}
+
+func (s *Store) ListUsers(ctx context.Context) ([]store.User, error) {
+	return s.primary.ListUsers(ctx)
+}
+
+func (s *Store) ListRefreshTokens(ctx context.Context) ([]store.RefreshToken, error) {
+	return s.primary.ListRefreshTokens(ctx)
+}
+
+func (s *Store) ScanRollups(ctx context.Context, after store.Rollup, limit int) ([]store.Rollup, error) {
+	return s.primary.ScanRollups(ctx, after, limit)
+}
+
+func (s *Store) CountRollups(ctx context.Context) (int, error) {
+	return s.primary.CountRollups(ctx)
+}
+
+func (s *Store) ScanCommands(ctx context.Context, afterID, limit int) ([]store.Command, error) {
+	return s.primary.ScanCommands(ctx, afterID, limit)
+}
+
+func (s *Store) CountCommands(ctx context.Context) (int, error) {
+	return s.primary.CountCommands(ctx)
+}
+
+// --- bulk import ---
+
+func (s *Store) ImportRollups(ctx context.Context, rollups []store.Rollup) error {
+	rollups = slices.Clone(rollups)
+	return s.write(ctx, "import rollups", func(ctx context.Context, st store.Store) error {
+		return st.ImportRollups(ctx, rollups)
+	})
+}
+
+func (s *Store) ImportSensors(ctx context.Context, sensors []store.Sensor) error {
+	sensors = slices.Clone(sensors)
+	return s.write(ctx, "import sensors", func(ctx context.Context, st store.Store) error {
+		return st.ImportSensors(ctx, sensors)
+	})
+}
+
+// --- auth ---
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (store.User, error) {
+	return s.primary.GetUserByUsername(ctx, username)
+}
+
+// CreateUser returns the primary's user. A secondary that already has the
+// username, e.g. after cmd/migratedata, is left alone.
+func (s *Store) CreateUser(ctx context.Context, username, passwordHash string) (store.User, error) {
+	u, err := s.primary.CreateUser(ctx, username, passwordHash)
+	if err != nil {
+		return u, err
+	}
+	return u, s.replay(ctx, "create user", func(ctx context.Context, st store.Store) error {
+		if _, err := st.GetUserByUsername(ctx, username); err == nil {
+			return nil
+		}
+		_, err := st.CreateUser(ctx, username, passwordHash)
+		return err
+	})
+}
+
+// StoreRefreshToken stores the token in every store. User IDs differ between
+// stores, so each secondary's is looked up by username.
+func (s *Store) StoreRefreshToken(ctx context.Context, arg store.StoreRefreshTokenParams) error {
+	if err := s.primary.StoreRefreshToken(ctx, arg); err != nil {
+		return err
+	}
+	return s.replay(ctx, "store refresh token", func(ctx context.Context, st store.Store) error {
+		u, err := st.GetUserByUsername(ctx, arg.Username)
+		if err != nil {
+			return fmt.Errorf("look up user %q: %w", arg.Username, err)
+		}
+		p := arg
+		p.UserID = u.ID
+		return st.StoreRefreshToken(ctx, p)
+	})
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, tokenHash string) (store.RefreshToken, error) {
+	return s.primary.GetRefreshToken(ctx, tokenHash)
+}
+
+func (s *Store) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	return s.write(ctx, "delete refresh token", func(ctx context.Context, st store.Store) error {
+		return st.DeleteRefreshToken(ctx, tokenHash)
+	})
+}
+
+func (s *Store) DeleteExpiredRefreshTokens(ctx context.Context) error {
+	return s.write(ctx, "delete expired refresh tokens", func(ctx context.Context, st store.Store) error {
+		return st.DeleteExpiredRefreshTokens(ctx)
+	})
+}
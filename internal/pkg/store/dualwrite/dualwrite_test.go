@@ -0,0 +1,241 @@
+package dualwrite
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	"github.com/anicoll/winet-integration/internal/pkg/store/storetest"
+)
+
+// flaky fails Write until its failures are used up.
+type flaky struct {
+	store.Store
+	mu       sync.Mutex
+	failures int
+}
+
+func (f *flaky) Write(ctx context.Context, data []publisher.DataPoint) error {
+	f.mu.Lock()
+	if f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		return errors.New("unavailable")
+	}
+	f.mu.Unlock()
+	return f.Store.Write(ctx, data)
+}
+
+func options(policy Policy) Options {
+	return Options{Policy: policy, QueueSize: 16, RetryInterval: 10 * time.Millisecond}
+}
+
+// run starts s.Run until the test ends.
+func run(t *testing.T, s *Store) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = s.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func reading(value string) []publisher.DataPoint {
+	return []publisher.DataPoint{{
+		Timestamp: time.Now().UTC(), UnitOfMeasurement: "W", Value: value, Identifier: "SN-1", Slug: "load_power",
+	}}
+}
+
+func count(t *testing.T, st store.Store) int {
+	t.Helper()
+	n, err := st.CountProperties(context.Background())
+	require.NoError(t, err)
+	return n
+}
+
+func TestConformance(t *testing.T) {
+	s, err := New(memory.New(), []store.Store{memory.New()}, options(PolicyAll))
+	require.NoError(t, err)
+	storetest.Run(t, func(*testing.T) store.Store { return s })
+}
+
+func TestNew_RejectsUnknownPolicy(t *testing.T) {
+	_, err := New(memory.New(), nil, Options{Policy: "some", QueueSize: 1, RetryInterval: time.Second})
+	assert.ErrorContains(t, err, "unknown dual-write policy")
+}
+
+func TestBestEffort_ReplicatesWrites(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := memory.New(), memory.New()
+	// Give the secondary a user first so user IDs differ between the stores.
+	_, err := secondary.CreateUser(ctx, "other", "$2a$10$other")
+	require.NoError(t, err)
+
+	s, err := New(primary, []store.Store{secondary}, options(PolicyBestEffort))
+	require.NoError(t, err)
+	run(t, s)
+
+	require.NoError(t, s.Write(ctx, reading("1")))
+	require.NoError(t, s.RegisterDevice(ctx, &model.Device{ID: "SN-1", Model: "SH10RT"}))
+	user, err := s.CreateUser(ctx, "alice", "$2a$10$alice")
+	require.NoError(t, err)
+	require.NoError(t, s.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+		TokenHash: "tok", UserID: user.ID, Username: user.Username, ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	assert.Eventually(t, func() bool {
+		_, err := secondary.GetRefreshToken(ctx, "tok")
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 1, count(t, secondary))
+	devices, err := secondary.ListDevices(ctx)
+	require.NoError(t, err)
+	assert.Len(t, devices, 1)
+	secUser, err := secondary.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.NotEqual(t, user.ID, secUser.ID)
+	tok, err := secondary.GetRefreshToken(ctx, "tok")
+	require.NoError(t, err)
+	assert.Equal(t, secUser.ID, tok.UserID, "token points at the secondary's user")
+}
+
+func TestBestEffort_PrimaryFailureFailsWrite(t *testing.T) {
+	primary := &flaky{Store: memory.New(), failures: 1}
+	s, err := New(primary, []store.Store{memory.New()}, options(PolicyBestEffort))
+	require.NoError(t, err)
+
+	require.Error(t, s.Write(context.Background(), reading("1")))
+	assert.Empty(t, s.secondaries[0].queue, "nothing replicated")
+}
+
+func TestBestEffort_SecondaryCatchesUp(t *testing.T) {
+	secondary := &flaky{Store: memory.New(), failures: 3}
+	s, err := New(memory.New(), []store.Store{secondary}, options(PolicyBestEffort))
+	require.NoError(t, err)
+	run(t, s)
+
+	require.NoError(t, s.Write(context.Background(), reading("1")), "secondary failures do not fail the write")
+	require.NoError(t, s.Write(context.Background(), reading("2")))
+
+	assert.Eventually(t, func() bool { return count(t, secondary) == 2 }, time.Second, 5*time.Millisecond)
+}
+
+func TestBestEffort_GivesUpAfterMaxAttempts(t *testing.T) {
+	secondary := &flaky{Store: memory.New(), failures: 2}
+	opts := options(PolicyBestEffort)
+	opts.MaxAttempts = 2
+	s, err := New(memory.New(), []store.Store{secondary}, opts)
+	require.NoError(t, err)
+	run(t, s)
+
+	require.NoError(t, s.Write(context.Background(), reading("1")))
+	require.NoError(t, s.Write(context.Background(), reading("2")))
+
+	assert.Eventually(t, func() bool { return count(t, secondary) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Never(t, func() bool { return count(t, secondary) > 1 }, 50*time.Millisecond, 5*time.Millisecond)
+}
+
+// poisoned rejects every Write of the given value.
+type poisoned struct {
+	store.Store
+	value string
+}
+
+func (p *poisoned) Write(ctx context.Context, data []publisher.DataPoint) error {
+	for _, dp := range data {
+		if dp.Value == p.value {
+			return errors.New("invalid value")
+		}
+	}
+	return p.Store.Write(ctx, data)
+}
+
+func TestBestEffort_PoisonWriteDoesNotBlockQueue(t *testing.T) {
+	secondary := &poisoned{Store: memory.New(), value: "bad"}
+	opts := options(PolicyBestEffort)
+	opts.MaxAttempts = 3
+	s, err := New(memory.New(), []store.Store{secondary}, opts)
+	require.NoError(t, err)
+	run(t, s)
+
+	require.NoError(t, s.Write(context.Background(), reading("bad")))
+	require.NoError(t, s.Write(context.Background(), reading("1")))
+	require.NoError(t, s.Write(context.Background(), reading("2")))
+
+	assert.Eventually(t, func() bool { return count(t, secondary) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 3, count(t, s.primary))
+}
+
+func TestBestEffort_QueueFullDropsWrites(t *testing.T) {
+	opts := options(PolicyBestEffort)
+	opts.QueueSize = 1
+	primary := memory.New()
+	s, err := New(primary, []store.Store{memory.New()}, opts)
+	require.NoError(t, err)
+	dropped := metrics.DualWriteDropped.WithLabelValues(s.secondaries[0].name, "queue_full")
+	before := testutil.ToFloat64(dropped)
+	core, logs := observer.New(zap.ErrorLevel)
+	s.logger = zap.New(core)
+
+	require.NoError(t, s.Write(context.Background(), reading("1")))
+	require.NoError(t, s.Write(context.Background(), reading("2")))
+	assert.Equal(t, 2, count(t, primary))
+	assert.Len(t, s.secondaries[0].queue, 1)
+	assert.InDelta(t, before+1, testutil.ToFloat64(dropped), 1e-9, "the dropped write is counted")
+	assert.Equal(t, 1, logs.FilterMessage("dualwrite: secondary queue full, dropping write").Len())
+}
+
+func TestRun_DrainsQueueOnShutdown(t *testing.T) {
+	secondary := memory.New()
+	s, err := New(memory.New(), []store.Store{secondary}, options(PolicyBestEffort))
+	require.NoError(t, err)
+	require.NoError(t, s.Write(context.Background(), reading("1")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, s.Run(ctx), context.Canceled)
+	assert.Equal(t, 1, count(t, secondary))
+}
+
+func TestAll_SecondaryFailureFailsWrite(t *testing.T) {
+	primary := memory.New()
+	s, err := New(primary, []store.Store{&flaky{Store: memory.New(), failures: 1}}, options(PolicyAll))
+	require.NoError(t, err)
+
+	err = s.Write(context.Background(), reading("1"))
+	require.ErrorContains(t, err, "unavailable")
+	assert.Equal(t, 1, count(t, primary), "the primary is not rolled back")
+}
+
+func TestReadsComeFromPrimary(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := memory.New(), memory.New()
+	require.NoError(t, secondary.Write(ctx, reading("secondary only")))
+	s, err := New(primary, []store.Store{secondary}, options(PolicyAll))
+	require.NoError(t, err)
+
+	latest, err := s.GetLatestProperties(ctx)
+	require.NoError(t, err)
+	for p := range latest {
+		t.Errorf("unexpected reading from the secondary: %+v", p)
+	}
+}
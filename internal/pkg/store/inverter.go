@@ -0,0 +1,18 @@
+package store
+
+import "time"
+
+// InverterState is the control state the service last put an inverter into,
+// corrected by readings when they show otherwise. It is one row of the
+// Inverter table.
+type InverterState struct {
+	// ID is the inverter's device identifier.
+	ID            string    `json:"id"`
+	State         string    `json:"state"`
+	BatteryState  string    `json:"battery_state"`
+	ChargeRate    float64   `json:"charge_rate"`
+	FeedinEnabled bool      `json:"feedin_enabled"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	// ReconciledAt is when readings last overrode State; zero if never.
+	ReconciledAt time.Time `json:"reconciled_at"`
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) GetUserByUsername(_ context.Context, username string) (store.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[username]
+	if !ok {
+		return store.User{}, fmt.Errorf("user %q: %w", username, ErrNotFound)
+	}
+	return u, nil
+}
+
+func (s *Store) CreateUser(_ context.Context, username, passwordHash string) (store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[username]; ok {
+		return store.User{}, fmt.Errorf("user %q already exists", username)
+	}
+	s.nextUserID++
+	now := time.Now().UTC()
+	u := store.User{
+		ID:           s.nextUserID,
+		Username:     username,
+		PasswordHash: passwordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.users[username] = u
+	s.changed()
+	return u, nil
+}
+
+// StoreRefreshToken saves a refresh token, or extends the expiry of an
+// existing token with the same hash.
+func (s *Store) StoreRefreshToken(_ context.Context, arg store.StoreRefreshTokenParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, ok := s.tokens[arg.TokenHash]
+	if !ok {
+		tok = store.RefreshToken{
+			TokenHash: arg.TokenHash,
+			UserID:    arg.UserID,
+			Username:  arg.Username,
+			CreatedAt: time.Now().UTC(),
+		}
+	}
+	tok.ExpiresAt = arg.ExpiresAt.UTC()
+	s.tokens[arg.TokenHash] = tok
+	s.changed()
+	return nil
+}
+
+func (s *Store) GetRefreshToken(_ context.Context, tokenHash string) (store.RefreshToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tok, ok := s.tokens[tokenHash]
+	if !ok {
+		return store.RefreshToken{}, fmt.Errorf("refresh token: %w", ErrNotFound)
+	}
+	return tok, nil
+}
+
+func (s *Store) DeleteRefreshToken(_ context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tokens[tokenHash]; ok {
+		delete(s.tokens, tokenHash)
+		s.changed()
+	}
+	return nil
+}
+
+func (s *Store) DeleteExpiredRefreshTokens(_ context.Context) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, tok := range s.tokens {
+		if tok.ExpiresAt.Before(now) {
+			delete(s.tokens, hash)
+			s.changed()
+		}
+	}
+	return nil
+}
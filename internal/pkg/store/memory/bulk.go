@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) ListDevices(_ context.Context) ([]model.Device, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.Device, 0, len(s.devices))
+	for _, d := range s.devices {
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b model.Device) int { return cmp.Compare(a.ID, b.ID) })
+	return out, nil
+}
+
+// ScanProperties collects and sorts every matching reading on each call, as
+// readings are held per series in timestamp order. That is fine for the
+// volumes the memory store is meant for.
+func (s *Store) ScanProperties(_ context.Context, afterID, limit int) ([]store.Property, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []store.Property
+	for _, ser := range s.series {
+		for _, p := range ser.props {
+			if p.ID > afterID {
+				out = append(out, p)
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b store.Property) int { return cmp.Compare(a.ID, b.ID) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Store) CountProperties(_ context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, ser := range s.series {
+		n += len(ser.props)
+	}
+	return n, nil
+}
+
+func (s *Store) ListUsers(_ context.Context) ([]store.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]store.User, 0, len(s.users))
+	for _, u := range s.users {
+		out = append(out, u)
+	}
+	slices.SortFunc(out, func(a, b store.User) int { return cmp.Compare(a.ID, b.ID) })
+	return out, nil
+}
+
+func (s *Store) ListRefreshTokens(_ context.Context) ([]store.RefreshToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]store.RefreshToken, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		out = append(out, t)
+	}
+	slices.SortFunc(out, func(a, b store.RefreshToken) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.TokenHash, b.TokenHash))
+	})
+	return out, nil
+}
+
+// ScanRollups collects and sorts every rollup on each call, like
+// ScanProperties.
+func (s *Store) ScanRollups(_ context.Context, after store.Rollup, limit int) ([]store.Rollup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []store.Rollup
+	for key, ser := range s.series {
+		for bk, a := range ser.rollups {
+			r := store.Rollup{Identifier: key.identifier, Slug: key.slug, Resolution: bk.resolution, Aggregate: a}
+			if store.CompareRollups(r, after) > 0 {
+				out = append(out, r)
+			}
+		}
+	}
+	slices.SortFunc(out, store.CompareRollups)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Store) CountRollups(_ context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, ser := range s.series {
+		n += len(ser.rollups)
+	}
+	return n, nil
+}
+
+func (s *Store) ScanCommands(_ context.Context, afterID, limit int) ([]store.Command, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// commands is appended in ID order.
+	i, _ := slices.BinarySearchFunc(s.commands, afterID+1, func(c store.Command, id int) int { return cmp.Compare(c.ID, id) })
+	out := make([]store.Command, 0, min(limit, len(s.commands)-i))
+	for _, c := range s.commands[i:min(i+limit, len(s.commands))] {
+		c.Params = maps.Clone(c.Params)
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (s *Store) CountCommands(_ context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.commands), nil
+}
+
+func (s *Store) ImportRollups(_ context.Context, rollups []store.Rollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range rollups {
+		a := r.Aggregate
+		a.BucketStart, a.LastTimeStamp = a.BucketStart.UTC(), a.LastTimeStamp.UTC()
+		s.seriesFor(r.Identifier, r.Slug).rollups[bucketKey{resolution: r.Resolution, start: a.BucketStart.UnixNano()}] = a
+	}
+	s.changed()
+	return nil
+}
+
+func (s *Store) ImportSensors(_ context.Context, sensors []store.Sensor) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sn := range sensors {
+		ser := s.seriesFor(sn.Identifier, sn.Slug)
+		ser.catalog(sn.FirstSeen.UTC(), "")
+		ser.catalog(sn.LastSeen.UTC(), sn.Name)
+		ser.setLatest(store.Property{
+			TimeStamp:         sn.LastSeen.UTC(),
+			UnitOfMeasurement: sn.UnitOfMeasurement,
+			Value:             sn.LastValue,
+			Identifier:        sn.Identifier,
+			Slug:              sn.Slug,
+		})
+	}
+	s.changed()
+	return nil
+}
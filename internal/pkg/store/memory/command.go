@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// RecordCommand appends cmd to the audit log. Latency is kept to the
+// millisecond, as the database stores keep it.
+func (s *Store) RecordCommand(_ context.Context, cmd store.Command) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextCommandID++
+	cmd.ID = s.nextCommandID
+	cmd.RequestedAt = cmd.RequestedAt.UTC()
+	cmd.Params = maps.Clone(cmd.Params)
+	cmd.Latency = cmd.Latency.Truncate(time.Millisecond)
+	s.commands = append(s.commands, cmd)
+	s.changed()
+	return nil
+}
+
+func (s *Store) GetCommands(_ context.Context, filter store.CommandFilter) ([]store.Command, error) {
+	from, to := filter.Range()
+	s.mu.RLock()
+	var out []store.Command
+	for _, c := range s.commands {
+		if c.RequestedAt.Before(from) || c.RequestedAt.After(to) ||
+			(filter.User != "" && c.User != filter.User) ||
+			(filter.Source != "" && c.Source != filter.Source) ||
+			(filter.Command != "" && c.Command != filter.Command) {
+			continue
+		}
+		c.Params = maps.Clone(c.Params)
+		out = append(out, c)
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(out, func(a, b store.Command) int {
+		return cmp.Or(b.RequestedAt.Compare(a.RequestedAt), cmp.Compare(b.ID, a.ID))
+	})
+	if limit := filter.LimitOrDefault(); len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
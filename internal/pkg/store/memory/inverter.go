@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) SaveInverterState(_ context.Context, st store.InverterState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.UpdatedAt = st.UpdatedAt.UTC()
+	if !st.ReconciledAt.IsZero() {
+		st.ReconciledAt = st.ReconciledAt.UTC()
+	}
+	s.inverters[st.ID] = st
+	s.changed()
+	return nil
+}
+
+func (s *Store) GetInverterStates(_ context.Context) ([]store.InverterState, error) {
+	s.mu.RLock()
+	out := make([]store.InverterState, 0, len(s.inverters))
+	for _, st := range s.inverters {
+		out = append(out, st)
+	}
+	s.mu.RUnlock()
+	slices.SortFunc(out, func(a, b store.InverterState) int {
+		return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return out, nil
+}
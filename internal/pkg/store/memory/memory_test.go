@@ -0,0 +1,144 @@
+package memory_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	"github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	"github.com/anicoll/winet-integration/internal/pkg/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(*testing.T) store.Store { return memory.New() })
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "winet.json")
+
+	st, err := memory.Open(path, time.Minute)
+	require.NoError(t, err)
+
+	ts := time.Now().UTC().Truncate(time.Minute)
+	require.NoError(t, st.Write(ctx, []publisher.DataPoint{
+		{Timestamp: ts, UnitOfMeasurement: "W", Value: "100", Identifier: "SN-SNAP", Slug: "load_power", Name: "Load Power"},
+		{Timestamp: ts.Add(time.Second), UnitOfMeasurement: "W", Value: "300", Identifier: "SN-SNAP", Slug: "load_power"},
+	}))
+	require.NoError(t, st.RegisterDevice(ctx, &model.Device{ID: "dev", Model: "SH10RT", SerialNumber: "SN-SNAP"}))
+	user, err := st.CreateUser(ctx, "alice", "hash")
+	require.NoError(t, err)
+	require.NoError(t, st.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+		TokenHash: "tok", UserID: user.ID, Username: user.Username, ExpiresAt: ts.Add(time.Hour),
+	}))
+	require.NoError(t, st.Save())
+
+	reopened, err := memory.Open(path, time.Minute)
+	require.NoError(t, err)
+
+	from, to := ts, ts.Add(time.Minute)
+	props, err := reopened.GetProperties(ctx, "SN-SNAP", "load_power", &from, &to)
+	require.NoError(t, err)
+	require.Len(t, props, 2)
+	assert.Equal(t, "300", props[0].Value)
+
+	aggs, err := reopened.GetAggregates(ctx, "SN-SNAP", "load_power", time.Minute, from, to)
+	require.NoError(t, err)
+	require.Len(t, aggs, 1)
+	assert.Equal(t, 2, aggs[0].Count)
+	assert.InDelta(t, 400.0, aggs[0].Sum, 1e-9)
+
+	sensors, err := reopened.GetSensors(ctx)
+	require.NoError(t, err)
+	require.Len(t, sensors, 1)
+	assert.Equal(t, "Load Power", sensors[0].Name)
+	assert.True(t, ts.Equal(sensors[0].FirstSeen))
+
+	got, err := reopened.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	tok, err := reopened.GetRefreshToken(ctx, "tok")
+	require.NoError(t, err)
+	assert.True(t, ts.Add(time.Hour).Equal(tok.ExpiresAt))
+
+	// IDs continue from the snapshot rather than restarting.
+	bob, err := reopened.CreateUser(ctx, "bob", "hash")
+	require.NoError(t, err)
+	assert.Greater(t, bob.ID, user.ID)
+}
+
+func TestOpen_MissingSnapshotStartsEmpty(t *testing.T) {
+	st, err := memory.Open(filepath.Join(t.TempDir(), "missing.json"), time.Minute)
+	require.NoError(t, err)
+
+	props, err := st.GetLatestProperties(context.Background())
+	require.NoError(t, err)
+	for range props {
+		t.Fatal("expected no properties")
+	}
+}
+
+func TestOpen_CorruptSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrupt.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := memory.Open(path, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestRun_SavesOnShutdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "winet.json")
+	st, err := memory.Open(path, time.Hour)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- st.Run(ctx) }()
+
+	_, err = st.CreateUser(context.Background(), "alice", "hash")
+	require.NoError(t, err)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	reopened, err := memory.Open(path, time.Hour)
+	require.NoError(t, err)
+	_, err = reopened.GetUserByUsername(context.Background(), "alice")
+	assert.NoError(t, err)
+}
+
+func TestSave_WithoutPath(t *testing.T) {
+	assert.Error(t, memory.New().Save())
+}
+
+func TestWrite_ConcurrentWithReads(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			dp := publisher.DataPoint{Timestamp: now.Add(-time.Duration(i) * time.Second), UnitOfMeasurement: "W", Value: "1", Identifier: "SN-RACE", Slug: "load_power"}
+			assert.NoError(t, st.Write(ctx, []publisher.DataPoint{dp}))
+			_, err := st.GetProperties(ctx, "SN-RACE", "load_power", nil, nil)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	props, err := st.GetProperties(ctx, "SN-RACE", "load_power", nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, props, 8)
+	for i := 1; i < len(props); i++ {
+		assert.False(t, props[i].TimeStamp.After(props[i-1].TimeStamp), "newest first")
+	}
+}
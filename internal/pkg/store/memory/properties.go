@@ -0,0 +1,274 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) Write(_ context.Context, data []publisher.DataPoint) error {
+	if len(data) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, dp := range data {
+		s.nextPropertyID++
+		p := store.Property{
+			ID:                s.nextPropertyID,
+			TimeStamp:         dp.Timestamp.UTC(),
+			UnitOfMeasurement: dp.UnitOfMeasurement,
+			Value:             dp.Value,
+			Identifier:        dp.Identifier,
+			Slug:              dp.Slug,
+		}
+		ser := s.seriesFor(dp.Identifier, dp.Slug)
+		ser.insert(p)
+		ser.catalog(p.TimeStamp, dp.Name)
+		ser.setLatest(p)
+		if v, ok := dp.Float(); ok {
+			fold(ser.rollups, p, v)
+		}
+	}
+	s.changed()
+	return nil
+}
+
+// insert adds p after any readings with the same or an earlier timestamp.
+func (ser *series) insert(p store.Property) {
+	i := sort.Search(len(ser.props), func(i int) bool { return ser.props[i].TimeStamp.After(p.TimeStamp) })
+	ser.props = slices.Insert(ser.props, i, p)
+}
+
+// setLatest records p as the latest reading unless a newer one is already
+// recorded. Latest readings carry no ID, as in the database stores.
+func (ser *series) setLatest(p store.Property) {
+	if ser.latest != nil && p.TimeStamp.Before(ser.latest.TimeStamp) {
+		return
+	}
+	p.ID = 0
+	ser.latest = &p
+}
+
+// catalog records a reading at ts, named name, in the series' catalog
+// details. As in the database stores, firstSeen is the earliest reading
+// written, whatever the order, only a reading that is not older than the latest one may change
+// the name, and an empty name keeps the old one. Call it before setLatest.
+func (ser *series) catalog(ts time.Time, name string) {
+	if ser.firstSeen.IsZero() || ts.Before(ser.firstSeen) {
+		ser.firstSeen = ts
+	}
+	if name != "" && (ser.latest == nil || !ts.Before(ser.latest.TimeStamp)) {
+		ser.name = name
+	}
+}
+
+// fold adds the numeric reading v of p to its bucket in rollups at every
+// rollup resolution.
+func fold(rollups map[bucketKey]store.Aggregate, p store.Property, v float64) {
+	for _, res := range store.RollupResolutions {
+		start := store.BucketStart(p.TimeStamp, res)
+		key := bucketKey{resolution: res, start: start.UnixNano()}
+		a, ok := rollups[key]
+		if !ok {
+			rollups[key] = store.Aggregate{
+				BucketStart:       start,
+				UnitOfMeasurement: p.UnitOfMeasurement,
+				Min:               v,
+				Max:               v,
+				Sum:               v,
+				Count:             1,
+				Last:              v,
+				LastTimeStamp:     p.TimeStamp,
+			}
+			continue
+		}
+		a.Min = min(a.Min, v)
+		a.Max = max(a.Max, v)
+		a.Sum += v
+		a.Count++
+		if !p.TimeStamp.Before(a.LastTimeStamp) {
+			a.Last, a.LastTimeStamp = v, p.TimeStamp
+			a.UnitOfMeasurement = p.UnitOfMeasurement
+		}
+		rollups[key] = a
+	}
+}
+
+func (s *Store) RegisterDevice(_ context.Context, device *model.Device) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.devices[device.ID]; !ok {
+		s.devices[device.ID] = *device
+		s.changed()
+	}
+	return nil
+}
+
+func (s *Store) GetProperties(_ context.Context, identifier, slug string, from, to *time.Time) ([]store.Property, error) {
+	if from == nil || to == nil {
+		t := time.Now().AddDate(0, 0, -2)
+		from = &t
+		now := time.Now()
+		to = &now
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser, ok := s.series[seriesKey{identifier: identifier, slug: slug}]
+	if !ok {
+		return nil, nil
+	}
+	lo := sort.Search(len(ser.props), func(i int) bool { return !ser.props[i].TimeStamp.Before(*from) })
+	hi := sort.Search(len(ser.props), func(i int) bool { return ser.props[i].TimeStamp.After(*to) })
+	if lo >= hi {
+		return nil, nil
+	}
+	out := slices.Clone(ser.props[lo:hi])
+	slices.Reverse(out)
+	return out, nil
+}
+
+func (s *Store) GetPropertyRange(_ context.Context, identifier, slug string, from, to time.Time, limit int) ([]store.Property, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser, ok := s.series[seriesKey{identifier: identifier, slug: slug}]
+	if !ok {
+		return nil, nil
+	}
+	lo := sort.Search(len(ser.props), func(i int) bool { return !ser.props[i].TimeStamp.Before(from) })
+	hi := sort.Search(len(ser.props), func(i int) bool { return ser.props[i].TimeStamp.After(to) })
+	if lo >= hi {
+		return nil, nil
+	}
+	return slices.Clone(ser.props[lo:min(hi, lo+limit)]), nil
+}
+
+// GetSensors returns the catalog of every series with a latest reading,
+// ordered by identifier and slug.
+func (s *Store) GetSensors(_ context.Context) ([]store.Sensor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []store.Sensor
+	for key, ser := range s.series {
+		if ser.latest == nil {
+			continue
+		}
+		out = append(out, store.Sensor{
+			Identifier:        key.identifier,
+			Slug:              key.slug,
+			Name:              ser.name,
+			UnitOfMeasurement: ser.latest.UnitOfMeasurement,
+			FirstSeen:         ser.firstSeen,
+			LastSeen:          ser.latest.TimeStamp,
+			LastValue:         ser.latest.Value,
+		})
+	}
+	slices.SortFunc(out, func(a, b store.Sensor) int {
+		return cmp.Or(cmp.Compare(a.Identifier, b.Identifier), cmp.Compare(a.Slug, b.Slug))
+	})
+	return out, nil
+}
+
+// GetLatestProperties returns the most recent reading per identifier+slug,
+// ordered by identifier and slug.
+func (s *Store) GetLatestProperties(_ context.Context) (iter.Seq[store.Property], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []store.Property
+	for _, ser := range s.series {
+		if ser.latest != nil {
+			out = append(out, *ser.latest)
+		}
+	}
+	slices.SortFunc(out, func(a, b store.Property) int {
+		return cmp.Or(cmp.Compare(a.Identifier, b.Identifier), cmp.Compare(a.Slug, b.Slug))
+	})
+	return slices.Values(out), nil
+}
+
+func (s *Store) GetAggregates(_ context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser, ok := s.series[seriesKey{identifier: identifier, slug: slug}]
+	if !ok {
+		return nil, nil
+	}
+	var out []store.Aggregate
+	for key, a := range ser.rollups {
+		if key.resolution == resolution && !a.BucketStart.Before(from) && a.BucketStart.Before(to) {
+			out = append(out, a)
+		}
+	}
+	slices.SortFunc(out, func(a, b store.Aggregate) int { return a.BucketStart.Compare(b.BucketStart) })
+	return out, nil
+}
+
+// Cleanup applies policy. Raw readings are rolled up into any buckets that
+// are missing before they are deleted, matching the database stores.
+func (s *Store) Cleanup(_ context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	var res store.RetentionResult
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rule := range policy.RawRules(now) {
+		for key, ser := range s.series {
+			if rule.Slug != "" && key.slug != rule.Slug || rule.Slug == "" && slices.Contains(rule.Exclude, key.slug) {
+				continue
+			}
+			n := sort.Search(len(ser.props), func(i int) bool { return !ser.props[i].TimeStamp.Before(rule.Before) })
+			if n == 0 {
+				continue
+			}
+			ser.rollUpMissing(ser.props[:n])
+			ser.props = slices.Clone(ser.props[n:])
+			res.RowsDeleted += int64(n)
+		}
+	}
+	for _, rule := range policy.RollupRules(now) {
+		for _, ser := range s.series {
+			for key, a := range ser.rollups {
+				if key.resolution == rule.Resolution && a.BucketStart.Before(rule.Before) {
+					delete(ser.rollups, key)
+					res.RollupsDeleted++
+				}
+			}
+		}
+	}
+	if res.RowsDeleted > 0 || res.RollupsDeleted > 0 {
+		s.changed()
+	}
+	return res, nil
+}
+
+// rollUpMissing computes the rollup buckets of props that do not exist yet.
+// Buckets that exist were maintained on write and are left alone.
+func (ser *series) rollUpMissing(props []store.Property) {
+	missing := map[bucketKey]store.Aggregate{}
+	for _, p := range props {
+		v, ok := publisher.DataPoint{Slug: p.Slug, Value: p.Value}.Float()
+		if !ok {
+			continue
+		}
+		fold(missing, p, v)
+	}
+	for key, a := range missing {
+		if _, ok := ser.rollups[key]; !ok {
+			ser.rollups[key] = a
+		}
+	}
+}
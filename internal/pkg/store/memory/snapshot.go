@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// snapshot is the on-disk JSON form of a Store.
+type snapshot struct {
+	Properties     []store.Property      `json:"properties"`
+	Rollups        []snapshotRollup      `json:"rollups"`
+	Latest         []store.Property      `json:"latest"`
+	Sensors        []snapshotSensor      `json:"sensors"`
+	Devices        []model.Device        `json:"devices"`
+	Users          []store.User          `json:"users"`
+	RefreshTokens  []store.RefreshToken  `json:"refresh_tokens"`
+	Commands       []store.Command       `json:"commands"`
+	Inverters      []store.InverterState `json:"inverters"`
+	NextPropertyID int                   `json:"next_property_id"`
+	NextUserID     int                   `json:"next_user_id"`
+	NextCommandID  int                   `json:"next_command_id"`
+}
+
+type snapshotRollup struct {
+	Identifier string        `json:"identifier"`
+	Slug       string        `json:"slug"`
+	Resolution time.Duration `json:"resolution"`
+	store.Aggregate
+}
+
+// snapshotSensor holds the catalog details of a series.
+type snapshotSensor struct {
+	Identifier string    `json:"identifier"`
+	Slug       string    `json:"slug"`
+	Name       string    `json:"name"`
+	FirstSeen  time.Time `json:"first_seen"`
+}
+
+// Open creates a Store persisted to the JSON snapshot at path, loading the
+// snapshot if the file exists. Run rewrites it every interval.
+func Open(path string, interval time.Duration) (*Store, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("snapshot interval must be positive")
+	}
+	s := New()
+	s.snapshotPath = path
+	s.snapshotInterval = interval
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var snap snapshot
+	if err := json.NewDecoder(f).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	s.restore(&snap)
+	return s, nil
+}
+
+// Run saves the snapshot every interval while there are unsaved changes, and
+// once more when ctx is cancelled. Without a snapshot path it just waits for
+// ctx.
+func (s *Store) Run(ctx context.Context) error {
+	if s.snapshotPath == "" {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	ticker := time.NewTicker(s.snapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Save(); err != nil {
+				zap.L().Error("memory store: final snapshot failed", zap.Error(err))
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				zap.L().Error("memory store: snapshot failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Save writes the snapshot if anything changed since the last save. The file
+// is replaced atomically, so a crash mid-write leaves the previous snapshot.
+func (s *Store) Save() error {
+	if s.snapshotPath == "" {
+		return fmt.Errorf("memory store has no snapshot path")
+	}
+
+	s.mu.RLock()
+	version := s.version
+	if version == s.savedVersion {
+		s.mu.RUnlock()
+		return nil
+	}
+	data, err := json.Marshal(s.snapshot())
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.snapshotPath), filepath.Base(s.snapshotPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.snapshotPath); err != nil {
+		return fmt.Errorf("replace snapshot: %w", err)
+	}
+
+	s.mu.Lock()
+	s.savedVersion = max(s.savedVersion, version)
+	s.mu.Unlock()
+	return nil
+}
+
+// snapshot copies the store's state. Callers must hold s.mu.
+func (s *Store) snapshot() *snapshot {
+	snap := &snapshot{
+		Commands:       s.commands,
+		NextPropertyID: s.nextPropertyID,
+		NextUserID:     s.nextUserID,
+		NextCommandID:  s.nextCommandID,
+	}
+	for key, ser := range s.series {
+		snap.Properties = append(snap.Properties, ser.props...)
+		if ser.latest != nil {
+			snap.Latest = append(snap.Latest, *ser.latest)
+		}
+		if !ser.firstSeen.IsZero() {
+			snap.Sensors = append(snap.Sensors, snapshotSensor{
+				Identifier: key.identifier,
+				Slug:       key.slug,
+				Name:       ser.name,
+				FirstSeen:  ser.firstSeen,
+			})
+		}
+		for bk, a := range ser.rollups {
+			snap.Rollups = append(snap.Rollups, snapshotRollup{
+				Identifier: key.identifier,
+				Slug:       key.slug,
+				Resolution: bk.resolution,
+				Aggregate:  a,
+			})
+		}
+	}
+	for _, d := range s.devices {
+		snap.Devices = append(snap.Devices, d)
+	}
+	for _, u := range s.users {
+		snap.Users = append(snap.Users, u)
+	}
+	for _, t := range s.tokens {
+		snap.RefreshTokens = append(snap.RefreshTokens, t)
+	}
+	for _, st := range s.inverters {
+		snap.Inverters = append(snap.Inverters, st)
+	}
+	return snap
+}
+
+// restore replaces the store's state with snap.
+func (s *Store) restore(snap *snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range snap.Properties {
+		s.seriesFor(p.Identifier, p.Slug).insert(p)
+	}
+	for _, p := range snap.Latest {
+		s.seriesFor(p.Identifier, p.Slug).setLatest(p)
+	}
+	for _, sn := range snap.Sensors {
+		ser := s.seriesFor(sn.Identifier, sn.Slug)
+		ser.name, ser.firstSeen = sn.Name, sn.FirstSeen
+	}
+	for _, r := range snap.Rollups {
+		key := bucketKey{resolution: r.Resolution, start: r.BucketStart.UnixNano()}
+		s.seriesFor(r.Identifier, r.Slug).rollups[key] = r.Aggregate
+	}
+	for _, d := range snap.Devices {
+		s.devices[d.ID] = d
+	}
+	for _, u := range snap.Users {
+		s.users[u.Username] = u
+	}
+	for _, t := range snap.RefreshTokens {
+		s.tokens[t.TokenHash] = t
+	}
+	for _, st := range snap.Inverters {
+		s.inverters[st.ID] = st
+	}
+	s.commands = snap.Commands
+	s.nextPropertyID = snap.NextPropertyID
+	s.nextUserID = snap.NextUserID
+	s.nextCommandID = snap.NextCommandID
+}
@@ -16,6 +16,21 @@ type Property struct {
 	Slug              string    `json:"slug"`
 }
 
+// Sensor is a sensor catalog entry: one identifier+slug that has been
+// written, with its latest reading. Like the latest reading, it outlives
+// retention.
+type Sensor struct {
+	Identifier string `json:"identifier"`
+	Slug       string `json:"slug"`
+	// Name is the WiNet display name, empty until a reading carrying one
+	// has been written.
+	Name              string    `json:"name"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	FirstSeen         time.Time `json:"first_seen"`
+	LastSeen          time.Time `json:"last_seen"`
+	LastValue         string    `json:"last_value"`
+}
+
 // User is an application user account.
 type User struct {
 	ID           int       `json:"id"`
@@ -0,0 +1,230 @@
+// Package open connects to the database a config.DatabaseConfig describes and
+// returns the matching store.Store, so the service and the command-line tools
+// share one driver switch.
+package open
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	go_ora "github.com/sijms/go-ora/v2"
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	memstore "github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	orastore "github.com/anicoll/winet-integration/internal/pkg/store/oracle"
+	pgstore "github.com/anicoll/winet-integration/internal/pkg/store/postgres"
+	sqlitestore "github.com/anicoll/winet-integration/internal/pkg/store/sqlite"
+)
+
+// Options controls what Store does beyond connecting.
+type Options struct {
+	// Migrate applies pending migrations, including the TimescaleDB ones
+	// when TIMESCALE_ENABLED is set.
+	Migrate bool
+	// TimescalePolicies (re)installs the TimescaleDB compression and refresh
+	// policies from the TIMESCALE_* settings.
+	TimescalePolicies bool
+}
+
+// Conn is an open database connection: SQL for Oracle and SQLite, Pool for
+// Postgres.
+type Conn struct {
+	Driver string
+	SQL    *sql.DB
+	Pool   *pgxpool.Pool
+}
+
+// Close closes the connection.
+func (c *Conn) Close() {
+	if c.SQL != nil {
+		_ = c.SQL.Close()
+	}
+	if c.Pool != nil {
+		c.Pool.Close()
+	}
+}
+
+// MigrationsPath is MIGRATIONS_FOLDER, or migrations/<driver> when unset.
+func MigrationsPath(cfg *config.DatabaseConfig) string {
+	if cfg.MigrationsFolder != "" {
+		return cfg.MigrationsFolder
+	}
+	return "migrations/" + cfg.DBDriver
+}
+
+// TimescaleMigrationsPath is the TimescaleDB migrations folder, next to the
+// Postgres one.
+func TimescaleMigrationsPath(cfg *config.DatabaseConfig) string {
+	return filepath.Join(filepath.Dir(MigrationsPath(cfg)), "timescale")
+}
+
+// Connect opens and pings the database without touching its schema. The
+// memory driver has no connection.
+func Connect(ctx context.Context, cfg *config.DatabaseConfig) (*Conn, error) {
+	switch cfg.DBDriver {
+	case "memory":
+		return nil, errors.New("DB_DRIVER=memory has no database connection")
+
+	case "oracle":
+		o := &cfg.OracleCfg
+		if o.Host == "" || o.Service == "" || o.User == "" || o.Password == "" {
+			return nil, fmt.Errorf("ORACLE_HOST, ORACLE_SERVICE, ORACLE_USER and ORACLE_PASSWORD are required when DB_DRIVER=oracle")
+		}
+		urlOptions := map[string]string{"ssl": "true"}
+		if !o.SSLVerify {
+			urlOptions["ssl verify"] = "false"
+		}
+		if o.WalletPath != "" {
+			urlOptions["wallet"] = o.WalletPath
+		}
+		db, err := sql.Open("oracle", go_ora.BuildUrl(o.Host, o.Port, o.Service, o.User, o.Password, urlOptions))
+		if err != nil {
+			return nil, fmt.Errorf("connect to oracle: %w", err)
+		}
+		if err := db.PingContext(ctx); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("ping oracle: %w", err)
+		}
+		return &Conn{Driver: cfg.DBDriver, SQL: db}, nil
+
+	case "sqlite":
+		db, err := sqlitestore.Open(ctx, cfg.SQLiteCfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &Conn{Driver: cfg.DBDriver, SQL: db}, nil
+
+	default: // postgres
+		if cfg.DBDSN == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required when DB_DRIVER=postgres")
+		}
+		pool, err := pgxpool.New(ctx, cfg.DBDSN)
+		if err != nil {
+			return nil, fmt.Errorf("connect to database: %w", err)
+		}
+		if err := pool.Ping(ctx); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("ping database: %w", err)
+		}
+		return &Conn{Driver: cfg.DBDriver, Pool: pool}, nil
+	}
+}
+
+// Store opens the store cfg describes. The returned func closes it; the
+// connection is closed on every error path. DB_DRIVER=memory needs
+// MEMORY_SNAPSHOT_PATH here, since a store without one would start empty.
+func Store(ctx context.Context, cfg *config.DatabaseConfig, opts Options) (store.Store, func(), error) {
+	if cfg.DBDriver == "memory" {
+		if cfg.MemoryCfg.SnapshotPath == "" {
+			return nil, nil, fmt.Errorf("MEMORY_SNAPSHOT_PATH is required when DB_DRIVER=memory")
+		}
+		st, err := memstore.Open(cfg.MemoryCfg.SnapshotPath, cfg.MemoryCfg.SnapshotInterval)
+		if err != nil {
+			return nil, nil, err
+		}
+		return st, func() {}, nil
+	}
+
+	conn, err := Connect(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := fromConn(ctx, cfg, conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return st, conn.Close, nil
+}
+
+func fromConn(ctx context.Context, cfg *config.DatabaseConfig, conn *Conn, opts Options) (store.Store, error) {
+	migrationsPath := MigrationsPath(cfg)
+	switch cfg.DBDriver {
+	case "oracle":
+		if opts.Migrate {
+			if err := migration.MigrateOracle(conn.SQL, migrationsPath); err != nil {
+				return nil, fmt.Errorf("run oracle migrations: %w", err)
+			}
+		}
+		return orastore.New(conn.SQL), nil
+
+	case "sqlite":
+		if opts.Migrate {
+			if err := migration.MigrateSQLite(conn.SQL, migrationsPath); err != nil {
+				return nil, fmt.Errorf("run sqlite migrations: %w", err)
+			}
+		}
+		return sqlitestore.New(conn.SQL), nil
+
+	default: // postgres
+		if opts.Migrate {
+			if err := migration.MigratePostgres(conn.Pool, migrationsPath); err != nil {
+				return nil, fmt.Errorf("run migrations: %w", err)
+			}
+		}
+		if !cfg.TimescaleCfg.Enabled {
+			return pgstore.New(conn.Pool), nil
+		}
+		ok, err := timescaleReady(ctx, cfg, conn.Pool, opts.Migrate)
+		if err != nil || !ok {
+			return pgstore.New(conn.Pool), err
+		}
+		// In TimescaleDB mode continuous aggregates maintain the rollups, so
+		// the store must not write property_rollup itself.
+		ts := &cfg.TimescaleCfg
+		pg := pgstore.New(conn.Pool, pgstore.WithTimescale(pgstore.TimescaleOptions{
+			CompressAfter: ts.CompressAfter,
+			RefreshWindow: ts.RefreshWindow,
+		}))
+		if opts.TimescalePolicies {
+			if err := pg.ApplyTimescalePolicies(ctx); err != nil {
+				return nil, fmt.Errorf("apply timescale policies: %w", err)
+			}
+		}
+		zap.L().Info("TimescaleDB enabled", zap.Duration("compress_after", ts.CompressAfter), zap.Duration("refresh_window", ts.RefreshWindow))
+		return pg, nil
+	}
+}
+
+// timescaleReady reports whether the store can run in TimescaleDB mode:
+// the extension is available and its migrations are applied, by this call
+// when migrate is set. Otherwise a warning is logged and the store runs as
+// plain Postgres.
+func timescaleReady(ctx context.Context, cfg *config.DatabaseConfig, pool *pgxpool.Pool, migrate bool) (bool, error) {
+	tsPath := TimescaleMigrationsPath(cfg)
+	var (
+		available bool
+		err       error
+	)
+	if migrate {
+		available, err = migration.MigrateTimescale(ctx, pool, tsPath)
+	} else {
+		available, err = migration.TimescaleAvailable(ctx, pool)
+	}
+	if err != nil {
+		return false, fmt.Errorf("run timescale migrations: %w", err)
+	}
+	if !available {
+		zap.L().Warn("TIMESCALE_ENABLED is set but the timescaledb extension is not available; using plain Postgres")
+		return false, nil
+	}
+	if migrate {
+		return true, nil
+	}
+	// The continuous aggregates only exist once the migrations ran.
+	applied, err := migration.TimescaleApplied(ctx, pool, tsPath)
+	if err != nil {
+		return false, fmt.Errorf("check timescale migrations: %w", err)
+	}
+	if !applied {
+		zap.L().Warn("TIMESCALE_ENABLED is set but the timescale migrations are not applied; using plain Postgres until `dbadmin --timescale up` has run")
+	}
+	return applied, nil
+}
@@ -0,0 +1,75 @@
+package open
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	memstore "github.com/anicoll/winet-integration/internal/pkg/store/memory"
+	sqlitestore "github.com/anicoll/winet-integration/internal/pkg/store/sqlite"
+)
+
+func sqliteMigrations() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "../../../../migrations/sqlite")
+}
+
+func TestMigrationsPath(t *testing.T) {
+	cfg := &config.DatabaseConfig{DBDriver: "postgres"}
+	assert.Equal(t, "migrations/postgres", MigrationsPath(cfg))
+	assert.Equal(t, "migrations/timescale", TimescaleMigrationsPath(cfg))
+
+	cfg.MigrationsFolder = "/srv/winet/migrations/postgres"
+	assert.Equal(t, "/srv/winet/migrations/timescale", TimescaleMigrationsPath(cfg))
+}
+
+func TestStore_SQLiteMigrates(t *testing.T) {
+	cfg := &config.DatabaseConfig{DBDriver: "sqlite", MigrationsFolder: sqliteMigrations()}
+	cfg.SQLiteCfg.Path = filepath.Join(t.TempDir(), "winet.db")
+
+	st, cleanup, err := Store(context.Background(), cfg, Options{Migrate: true})
+	require.NoError(t, err)
+	defer cleanup()
+	assert.IsType(t, &sqlitestore.Store{}, st)
+
+	n, err := st.CountProperties(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestStore_MigrationFailureReportsError(t *testing.T) {
+	cfg := &config.DatabaseConfig{DBDriver: "sqlite", MigrationsFolder: filepath.Join(t.TempDir(), "missing")}
+	cfg.SQLiteCfg.Path = filepath.Join(t.TempDir(), "winet.db")
+
+	_, _, err := Store(context.Background(), cfg, Options{Migrate: true})
+	assert.ErrorContains(t, err, "run sqlite migrations")
+}
+
+func TestStore_Memory(t *testing.T) {
+	_, _, err := Store(context.Background(), &config.DatabaseConfig{DBDriver: "memory"}, Options{})
+	assert.ErrorContains(t, err, "MEMORY_SNAPSHOT_PATH")
+
+	cfg := &config.DatabaseConfig{DBDriver: "memory"}
+	cfg.MemoryCfg.SnapshotPath = filepath.Join(t.TempDir(), "winet.json")
+	cfg.MemoryCfg.SnapshotInterval = time.Minute
+	st, cleanup, err := Store(context.Background(), cfg, Options{})
+	require.NoError(t, err)
+	defer cleanup()
+	assert.IsType(t, &memstore.Store{}, st)
+}
+
+func TestConnect_RequiredSettings(t *testing.T) {
+	ctx := context.Background()
+	_, err := Connect(ctx, &config.DatabaseConfig{DBDriver: "postgres"})
+	assert.ErrorContains(t, err, "DATABASE_URL")
+	_, err = Connect(ctx, &config.DatabaseConfig{DBDriver: "oracle"})
+	assert.ErrorContains(t, err, "ORACLE_HOST")
+	_, err = Connect(ctx, &config.DatabaseConfig{DBDriver: "memory"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,262 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	go_ora "github.com/sijms/go-ora/v2"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) ListDevices(ctx context.Context) ([]model.Device, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, model, serial_number FROM Device ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.Device
+	for rows.Next() {
+		var (
+			d                   model.Device
+			deviceModel, serial sql.NullString
+		)
+		if err := rows.Scan(&d.ID, &deviceModel, &serial); err != nil {
+			return nil, err
+		}
+		d.Model, d.SerialNumber = deviceModel.String, serial.String
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ScanProperties(ctx context.Context, afterID, limit int) ([]store.Property, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+		FROM Property
+		WHERE id > :1
+		ORDER BY id
+		FETCH FIRST :2 ROWS ONLY`,
+		afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanProperties(rows)
+}
+
+func (s *Store) CountProperties(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM Property`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) ListUsers(ctx context.Context) ([]store.User, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, created_at, updated_at
+		FROM users
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.User
+	for rows.Next() {
+		var u store.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ListRefreshTokens(ctx context.Context) ([]store.RefreshToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT token_hash, user_id, username, expires_at, created_at
+		FROM refresh_tokens
+		ORDER BY created_at, token_hash`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.RefreshToken
+	for rows.Next() {
+		var r store.RefreshToken
+		if err := rows.Scan(&r.TokenHash, &r.UserID, &r.Username, &r.ExpiresAt, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ScanRollups pages through property_rollup. Oracle has no row value
+// comparison, so the keyset is spelled out; the zero Rollup binds a NULL
+// identifier, which compares false, so the first page has no keyset at all.
+func (s *Store) ScanRollups(ctx context.Context, after store.Rollup, limit int) ([]store.Rollup, error) {
+	const columns = `
+		SELECT r.identifier, r.slug, r.resolution, r.bucket_start, r.unit_of_measurement,
+		       r.min_value, r.max_value, r.sum_value, r.sample_count, r.last_value, r.last_time_stamp`
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if after.Identifier == "" {
+		rows, err = s.db.QueryContext(ctx, columns+`
+		FROM property_rollup r
+		ORDER BY r.identifier, r.slug, r.resolution, SYS_EXTRACT_UTC(r.bucket_start)
+		FETCH FIRST :1 ROWS ONLY`,
+			limit)
+	} else {
+		rows, err = s.db.QueryContext(ctx, columns+`
+		FROM property_rollup r,
+		     (SELECT :1 AS identifier, :2 AS slug, :3 AS resolution, SYS_EXTRACT_UTC(:4) AS bucket_start FROM dual) a
+		WHERE r.identifier > a.identifier
+		   OR (r.identifier = a.identifier AND (r.slug > a.slug
+		   OR (r.slug = a.slug AND (r.resolution > a.resolution
+		   OR (r.resolution = a.resolution AND SYS_EXTRACT_UTC(r.bucket_start) > a.bucket_start)))))
+		ORDER BY r.identifier, r.slug, r.resolution, SYS_EXTRACT_UTC(r.bucket_start)
+		FETCH FIRST :5 ROWS ONLY`,
+			after.Identifier, after.Slug, int(after.Resolution.Seconds()), go_ora.TimeStampTZ(after.BucketStart.UTC()), limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.Rollup
+	for rows.Next() {
+		var (
+			r          store.Rollup
+			resolution int64
+			unit       sql.NullString
+		)
+		if err := rows.Scan(&r.Identifier, &r.Slug, &resolution, &r.BucketStart, &unit,
+			&r.Min, &r.Max, &r.Sum, &r.Count, &r.Last, &r.LastTimeStamp); err != nil {
+			return nil, err
+		}
+		r.Resolution = time.Duration(resolution) * time.Second
+		r.UnitOfMeasurement = unit.String
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) CountRollups(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM property_rollup`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) ScanCommands(ctx context.Context, afterID, limit int) ([]store.Command, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, requested_at, source, username, command, params, success, result_code, error_message, latency_ms
+		FROM command_audit
+		WHERE id > :1
+		ORDER BY id
+		FETCH FIRST :2 ROWS ONLY`,
+		afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanCommands(rows)
+}
+
+func (s *Store) CountCommands(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM command_audit`).Scan(&n)
+	return n, err
+}
+
+// ImportRollups replaces whole buckets; bucket identity compares
+// SYS_EXTRACT_UTC(bucket_start) as upsertRollupSQL does.
+func (s *Store) ImportRollups(ctx context.Context, rollups []store.Rollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, `
+		MERGE INTO property_rollup r
+		USING (
+			SELECT :resolution AS resolution, :bucket_start AS bucket_start, :identifier AS identifier,
+			       :slug AS slug, :unit_of_measurement AS unit_of_measurement,
+			       TO_BINARY_DOUBLE(:min_value) AS min_value, TO_BINARY_DOUBLE(:max_value) AS max_value,
+			       TO_BINARY_DOUBLE(:sum_value) AS sum_value, :sample_count AS sample_count,
+			       TO_BINARY_DOUBLE(:last_value) AS last_value, :last_time_stamp AS last_time_stamp
+			FROM dual
+		) src
+		ON (r.identifier = src.identifier AND r.slug = src.slug AND r.resolution = src.resolution
+		    AND SYS_EXTRACT_UTC(r.bucket_start) = SYS_EXTRACT_UTC(src.bucket_start))
+		WHEN MATCHED THEN UPDATE SET
+			r.unit_of_measurement = src.unit_of_measurement,
+			r.min_value           = src.min_value,
+			r.max_value           = src.max_value,
+			r.sum_value           = src.sum_value,
+			r.sample_count        = src.sample_count,
+			r.last_value          = src.last_value,
+			r.last_time_stamp     = src.last_time_stamp
+		WHEN NOT MATCHED THEN
+			INSERT (resolution, bucket_start, identifier, slug, unit_of_measurement,
+			        min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+			VALUES (src.resolution, src.bucket_start, src.identifier, src.slug, src.unit_of_measurement,
+			        src.min_value, src.max_value, src.sum_value, src.sample_count, src.last_value, src.last_time_stamp)`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, r := range rollups {
+		if _, err := stmt.ExecContext(ctx,
+			sql.Named("resolution", int(r.Resolution.Seconds())),
+			sql.Named("bucket_start", go_ora.TimeStampTZ(r.BucketStart.UTC())),
+			sql.Named("identifier", r.Identifier),
+			sql.Named("slug", r.Slug),
+			sql.Named("unit_of_measurement", r.UnitOfMeasurement),
+			sql.Named("min_value", r.Min),
+			sql.Named("max_value", r.Max),
+			sql.Named("sum_value", r.Sum),
+			sql.Named("sample_count", r.Count),
+			sql.Named("last_value", r.Last),
+			sql.Named("last_time_stamp", go_ora.TimeStampTZ(r.LastTimeStamp.UTC())),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) ImportSensors(ctx context.Context, sensors []store.Sensor) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, upsertLatestSQL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, sn := range sensors {
+		if _, err := stmt.ExecContext(ctx,
+			sql.Named("identifier", sn.Identifier),
+			sql.Named("slug", sn.Slug),
+			sql.Named("time_stamp", go_ora.TimeStampTZ(sn.LastSeen.UTC())),
+			sql.Named("unit_of_measurement", sn.UnitOfMeasurement),
+			sql.Named("value", sn.LastValue),
+			sql.Named("name", sn.Name),
+			sql.Named("first_seen", go_ora.TimeStampTZ(sn.FirstSeen.UTC())),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,117 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	go_ora "github.com/sijms/go-ora/v2"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// rollupPropertiesSQL fills in rollup buckets that are missing for the
+// readings matched by the %s filter. Existing buckets were maintained on write
+// and are left alone.
+const rollupPropertiesSQL = `
+	INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+	                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+	SELECT src.resolution, src.bucket_start, src.identifier, src.slug, src.unit_of_measurement,
+	       src.min_value, src.max_value, src.sum_value, src.sample_count, src.last_value, src.last_time_stamp
+	FROM (
+		SELECT r.resolution,
+		       FROM_TZ(TIMESTAMP '1970-01-01 00:00:00', 'UTC') + NUMTODSINTERVAL(FLOOR(p.epoch / r.resolution) * r.resolution, 'SECOND') AS bucket_start,
+		       p.identifier,
+		       p.slug,
+		       MAX(p.unit_of_measurement) KEEP (DENSE_RANK LAST ORDER BY p.time_stamp) AS unit_of_measurement,
+		       MIN(p.v) AS min_value,
+		       MAX(p.v) AS max_value,
+		       SUM(p.v) AS sum_value,
+		       COUNT(*) AS sample_count,
+		       MAX(p.v) KEEP (DENSE_RANK LAST ORDER BY p.time_stamp) AS last_value,
+		       MAX(p.time_stamp) AS last_time_stamp
+		FROM (
+			SELECT time_stamp, identifier, slug, unit_of_measurement,
+			       TO_BINARY_DOUBLE(value DEFAULT NULL ON CONVERSION ERROR) AS v,
+			       (CAST(SYS_EXTRACT_UTC(time_stamp) AS DATE) - DATE '1970-01-01') * 86400 AS epoch
+			FROM Property
+			WHERE %s
+		) p
+		CROSS JOIN (
+			SELECT 60 AS resolution FROM dual UNION ALL
+			SELECT 900 FROM dual UNION ALL
+			SELECT 3600 FROM dual UNION ALL
+			SELECT 86400 FROM dual
+		) r
+		WHERE p.v IS NOT NULL
+		GROUP BY r.resolution, FLOOR(p.epoch / r.resolution), p.identifier, p.slug
+	) src
+	WHERE NOT EXISTS (
+		SELECT 1 FROM property_rollup x
+		WHERE x.identifier = src.identifier AND x.slug = src.slug AND x.resolution = src.resolution
+		  AND SYS_EXTRACT_UTC(x.bucket_start) = SYS_EXTRACT_UTC(src.bucket_start)
+	)`
+
+// Cleanup applies policy in a single transaction. Raw readings are rolled up
+// before they are deleted, so no reading is lost from the long-term rollups
+// even if it was written before rollups were maintained.
+func (s *Store) Cleanup(ctx context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	var res store.RetentionResult
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rule := range policy.RawRules(now) {
+		filter, args := rawRuleFilter(rule)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(rollupPropertiesSQL, filter), args...); err != nil {
+			return res, err
+		}
+		n, err := rowsAffected(tx.ExecContext(ctx, "DELETE FROM Property WHERE "+filter, args...))
+		if err != nil {
+			return res, err
+		}
+		res.RowsDeleted += n
+	}
+	for _, rule := range policy.RollupRules(now) {
+		n, err := rowsAffected(tx.ExecContext(ctx,
+			`DELETE FROM property_rollup WHERE resolution = :1 AND bucket_start < :2`,
+			int(rule.Resolution.Seconds()), go_ora.TimeStampTZ(rule.Before.UTC())))
+		if err != nil {
+			return res, err
+		}
+		res.RollupsDeleted += n
+	}
+	return res, tx.Commit()
+}
+
+// rawRuleFilter renders rule as a WHERE condition on Property with positional binds.
+func rawRuleFilter(rule store.RawRule) (string, []any) {
+	conds := []string{"time_stamp < :1"}
+	args := []any{go_ora.TimeStampTZ(rule.Before.UTC())}
+	if rule.Slug != "" {
+		args = append(args, rule.Slug)
+		conds = append(conds, fmt.Sprintf("slug = :%d", len(args)))
+	}
+	if len(rule.Exclude) > 0 {
+		binds := make([]string, len(rule.Exclude))
+		for i, slug := range rule.Exclude {
+			args = append(args, slug)
+			binds[i] = fmt.Sprintf(":%d", len(args))
+		}
+		conds = append(conds, "slug NOT IN ("+strings.Join(binds, ", ")+")")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func rowsAffected(r sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
@@ -0,0 +1,97 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	go_ora "github.com/sijms/go-ora/v2"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// RecordCommand appends cmd to command_audit. Oracle stores empty strings as
+// NULL; GetCommands maps them back to "".
+func (s *Store) RecordCommand(ctx context.Context, cmd store.Command) error {
+	params, err := json.Marshal(cmd.Params)
+	if err != nil {
+		return err
+	}
+	success := 0
+	if cmd.Success {
+		success = 1
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO command_audit (requested_at, source, username, command, params, success, result_code, error_message, latency_ms)
+		VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9)`,
+		go_ora.TimeStampTZ(cmd.RequestedAt.UTC()),
+		cmd.Source,
+		cmd.User,
+		cmd.Command,
+		string(params),
+		success,
+		cmd.ResultCode,
+		cmd.Error,
+		cmd.Latency.Milliseconds(),
+	)
+	return err
+}
+
+func (s *Store) GetCommands(ctx context.Context, filter store.CommandFilter) ([]store.Command, error) {
+	from, to := filter.Range()
+	conds := []string{"requested_at BETWEEN :1 AND :2"}
+	args := []any{go_ora.TimeStampTZ(from.UTC()), go_ora.TimeStampTZ(to.UTC())}
+	for _, f := range []struct{ column, value string }{
+		{"username", filter.User},
+		{"source", filter.Source},
+		{"command", filter.Command},
+	} {
+		if f.value != "" {
+			args = append(args, f.value)
+			conds = append(conds, fmt.Sprintf("%s = :%d", f.column, len(args)))
+		}
+	}
+	args = append(args, filter.LimitOrDefault())
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, requested_at, source, username, command, params, success, result_code, error_message, latency_ms
+		FROM command_audit
+		WHERE %s
+		ORDER BY requested_at DESC, id DESC
+		FETCH FIRST :%d ROWS ONLY`, strings.Join(conds, " AND "), len(args)),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanCommands(rows)
+}
+
+func scanCommands(rows *sql.Rows) ([]store.Command, error) {
+	var out []store.Command
+	for rows.Next() {
+		var (
+			c                     store.Command
+			user, params, errText sql.NullString
+			success               int
+			latencyMs             int64
+		)
+		if err := rows.Scan(&c.ID, &c.RequestedAt, &c.Source, &user, &c.Command, &params,
+			&success, &c.ResultCode, &errText, &latencyMs); err != nil {
+			return nil, err
+		}
+		if params.Valid {
+			if err := json.Unmarshal([]byte(params.String), &c.Params); err != nil {
+				return nil, err
+			}
+		}
+		c.User, c.Error = user.String, errText.String
+		c.Success = success == 1
+		c.Latency = time.Duration(latencyMs) * time.Millisecond
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
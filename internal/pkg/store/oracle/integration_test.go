@@ -22,10 +22,9 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
-	"github.com/anicoll/winet-integration/internal/pkg/model"
-	"github.com/anicoll/winet-integration/internal/pkg/publisher"
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 	orastore "github.com/anicoll/winet-integration/internal/pkg/store/oracle"
+	"github.com/anicoll/winet-integration/internal/pkg/store/storetest"
 )
 
 const (
@@ -87,6 +86,10 @@ func (s *OracleSuite) SetupSuite() {
 	s.Require().NoError(db.PingContext(ctx))
 
 	s.Require().NoError(migration.MigrateOracle(db, migrationsPath()))
+	migrator := migration.NewOracle(db, migrationsPath())
+	s.Require().NoError(migrator.Migrate(ctx, 0), "every migration must be reversible")
+	s.Require().NoError(migrator.Up(ctx))
+	s.Require().NoError(migration.MigrateOracle(db, migrationsPath()), "migrations must be idempotent")
 
 	s.store = orastore.New(db)
 }
@@ -97,63 +100,6 @@ func (s *OracleSuite) TearDownSuite() {
 	}
 }
 
-func (s *OracleSuite) TestWrite_and_GetLatestProperties() {
-	ctx := context.Background()
-
-	dp := publisher.DataPoint{
-		Timestamp:         time.Now().UTC().Truncate(time.Second),
-		UnitOfMeasurement: "W",
-		Value:             "42.5",
-		Identifier:        "SN-ORA-001",
-		Slug:              "battery-power",
-	}
-
-	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{dp}))
-
-	props, err := s.store.GetLatestProperties(ctx)
-	s.Require().NoError(err)
-
-	found := false
-	for p := range props {
-		if p.Identifier == dp.Identifier && p.Slug == dp.Slug {
-			s.Equal(dp.Value, p.Value)
-			found = true
-		}
-	}
-	s.True(found, "written data point not found in GetLatestProperties")
-}
-
-func (s *OracleSuite) TestRegisterDevice() {
-	ctx := context.Background()
-
-	dev := &model.Device{ID: "oracle-integration-device", Model: "TestModel", SerialNumber: "SN-ORA-IT-001"}
-	s.Require().NoError(s.store.RegisterDevice(ctx, dev))
-	s.Require().NoError(s.store.RegisterDevice(ctx, dev), "second upsert must be idempotent")
-}
-
-func (s *OracleSuite) TestUserAndRefreshToken() {
-	ctx := context.Background()
-
-	user, err := s.store.CreateUser(ctx, "oracle-integration-user", "$2a$10$placeholder")
-	s.Require().NoError(err)
-	s.Equal("oracle-integration-user", user.Username)
-
-	fetched, err := s.store.GetUserByUsername(ctx, user.Username)
-	s.Require().NoError(err)
-	s.Equal(user.ID, fetched.ID)
-
-	params := store.StoreRefreshTokenParams{
-		TokenHash: "ora-test-hash-integration",
-		UserID:    user.ID,
-		Username:  user.Username,
-		ExpiresAt: time.Now().Add(time.Hour),
-	}
-	s.Require().NoError(s.store.StoreRefreshToken(ctx, params))
-
-	tok, err := s.store.GetRefreshToken(ctx, params.TokenHash)
-	s.Require().NoError(err)
-	s.Equal(user.ID, tok.UserID)
-
-	s.Require().NoError(s.store.DeleteRefreshToken(ctx, params.TokenHash))
-	s.Require().NoError(s.store.DeleteExpiredRefreshTokens(ctx))
+func (s *OracleSuite) TestConformance() {
+	storetest.Run(s.T(), func(*testing.T) store.Store { return s.store })
 }
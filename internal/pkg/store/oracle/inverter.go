@@ -0,0 +1,73 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+
+	go_ora "github.com/sijms/go-ora/v2"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) SaveInverterState(ctx context.Context, st store.InverterState) error {
+	feedin := 0
+	if st.FeedinEnabled {
+		feedin = 1
+	}
+	var reconciledAt any
+	if !st.ReconciledAt.IsZero() {
+		reconciledAt = go_ora.TimeStampTZ(st.ReconciledAt.UTC())
+	}
+	_, err := s.db.ExecContext(ctx, `
+		MERGE INTO Inverter i
+		USING (SELECT :id AS id FROM dual) src
+		ON (i.id = src.id)
+		WHEN MATCHED THEN UPDATE SET
+			i.state          = :state,
+			i.battery_state  = :battery_state,
+			i.charge_rate    = :charge_rate,
+			i.feedin_enabled = :feedin_enabled,
+			i.updated_at     = :updated_at,
+			i.reconciled_at  = :reconciled_at
+		WHEN NOT MATCHED THEN
+			INSERT (id, state, battery_state, charge_rate, feedin_enabled, updated_at, reconciled_at)
+			VALUES (:id, :state, :battery_state, :charge_rate, :feedin_enabled, :updated_at, :reconciled_at)`,
+		sql.Named("id", st.ID),
+		sql.Named("state", st.State),
+		sql.Named("battery_state", st.BatteryState),
+		sql.Named("charge_rate", st.ChargeRate),
+		sql.Named("feedin_enabled", feedin),
+		sql.Named("updated_at", go_ora.TimeStampTZ(st.UpdatedAt.UTC())),
+		sql.Named("reconciled_at", reconciledAt),
+	)
+	return err
+}
+
+func (s *Store) GetInverterStates(ctx context.Context) ([]store.InverterState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, state, battery_state, charge_rate, feedin_enabled, updated_at, reconciled_at
+		FROM Inverter
+		ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []store.InverterState
+	for rows.Next() {
+		var (
+			st           store.InverterState
+			feedin       int
+			reconciledAt sql.NullTime
+		)
+		if err := rows.Scan(&st.ID, &st.State, &st.BatteryState, &st.ChargeRate, &feedin, &st.UpdatedAt, &reconciledAt); err != nil {
+			return nil, err
+		}
+		st.FeedinEnabled = feedin == 1
+		if reconciledAt.Valid {
+			st.ReconciledAt = reconciledAt.Time
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
@@ -29,3 +29,6 @@ type Store struct {
 func New(db *sql.DB) *Store {
 	return &Store{db: db}
 }
+
+// Name identifies the backend in logs and metrics.
+func (s *Store) Name() string { return "oracle" }
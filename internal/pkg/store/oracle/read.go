@@ -7,6 +7,8 @@ import (
 	"slices"
 	"time"
 
+	go_ora "github.com/sijms/go-ora/v2"
+
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 )
 
@@ -30,36 +32,115 @@ func (s *Store) GetProperties(ctx context.Context, identifier, slug string, from
 	return scanProperties(rows)
 }
 
-// GetLatestProperties returns the most recent reading per identifier+slug.
-// Oracle does not have DISTINCT ON; ROW_NUMBER() OVER (PARTITION BY ...) is used instead.
-func (s *Store) GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error) {
+func (s *Store) GetPropertyRange(ctx context.Context, identifier, slug string, from, to time.Time, limit int) ([]store.Property, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
-		FROM (
-			SELECT id, time_stamp, unit_of_measurement, value, identifier, slug,
-			       ROW_NUMBER() OVER (PARTITION BY identifier, slug ORDER BY time_stamp DESC) AS rn
-			FROM Property
-			WHERE time_stamp > SYSTIMESTAMP - INTERVAL '1' DAY
-		)
-		WHERE rn = 1`)
+		FROM Property
+		WHERE identifier = :1 AND slug = :2 AND time_stamp BETWEEN :3 AND :4
+		ORDER BY time_stamp, id
+		FETCH FIRST :5 ROWS ONLY`,
+		identifier, slug, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanProperties(rows)
+}
+
+func (s *Store) GetAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT bucket_start, unit_of_measurement, min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+		FROM property_rollup
+		WHERE identifier = :1 AND slug = :2 AND resolution = :3 AND bucket_start >= :4 AND bucket_start < :5
+		ORDER BY bucket_start`,
+		identifier, slug, int(resolution.Seconds()), go_ora.TimeStampTZ(from.UTC()), go_ora.TimeStampTZ(to.UTC()))
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	props, err := scanProperties(rows)
+	var out []store.Aggregate
+	for rows.Next() {
+		var (
+			a    store.Aggregate
+			unit sql.NullString
+		)
+		if err := rows.Scan(&a.BucketStart, &unit, &a.Min, &a.Max, &a.Sum, &a.Count, &a.Last, &a.LastTimeStamp); err != nil {
+			return nil, err
+		}
+		a.UnitOfMeasurement = unit.String
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetLatestProperties returns the most recent reading per identifier+slug
+// from property_latest, which Write keeps current.
+func (s *Store) GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT identifier, slug, time_stamp, unit_of_measurement, value
+		FROM property_latest
+		ORDER BY identifier, slug`)
 	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = rows.Close() }()
+
+	var props []store.Property
+	for rows.Next() {
+		var (
+			p    store.Property
+			unit sql.NullString
+		)
+		if err := rows.Scan(&p.Identifier, &p.Slug, &p.TimeStamp, &unit, &p.Value); err != nil {
+			return nil, err
+		}
+		p.UnitOfMeasurement = unit.String
+		props = append(props, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return slices.Values(props), nil
 }
 
+// GetSensors reads the catalog columns of property_latest. Oracle stores
+// an empty name or unit as NULL; both map back to "".
+func (s *Store) GetSensors(ctx context.Context) ([]store.Sensor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT identifier, slug, name, unit_of_measurement, first_seen, time_stamp, value
+		FROM property_latest
+		ORDER BY identifier, slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []store.Sensor
+	for rows.Next() {
+		var (
+			sn         store.Sensor
+			name, unit sql.NullString
+		)
+		if err := rows.Scan(&sn.Identifier, &sn.Slug, &name, &unit, &sn.FirstSeen, &sn.LastSeen, &sn.LastValue); err != nil {
+			return nil, err
+		}
+		sn.Name, sn.UnitOfMeasurement = name.String, unit.String
+		out = append(out, sn)
+	}
+	return out, rows.Err()
+}
+
 func scanProperties(rows *sql.Rows) ([]store.Property, error) {
 	var out []store.Property
 	for rows.Next() {
-		var p store.Property
-		if err := rows.Scan(&p.ID, &p.TimeStamp, &p.UnitOfMeasurement, &p.Value, &p.Identifier, &p.Slug); err != nil {
+		var (
+			p    store.Property
+			unit sql.NullString
+		)
+		if err := rows.Scan(&p.ID, &p.TimeStamp, &unit, &p.Value, &p.Identifier, &p.Slug); err != nil {
 			return nil, err
 		}
+		p.UnitOfMeasurement = unit.String
 		out = append(out, p)
 	}
 	return out, rows.Err()
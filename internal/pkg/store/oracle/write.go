@@ -5,11 +5,16 @@ import (
 	"database/sql"
 	"time"
 
+	go_ora "github.com/sijms/go-ora/v2"
+
 	"github.com/anicoll/winet-integration/internal/pkg/model"
 	"github.com/anicoll/winet-integration/internal/pkg/publisher"
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 )
 
+// Write stores each reading, records it in property_latest and folds it into
+// its rollups. Oracle stores an empty unit_of_measurement as NULL; the read
+// path maps it back to "".
 func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -19,12 +24,24 @@ func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
 
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO Property (time_stamp, unit_of_measurement, value, identifier, slug)
-		VALUES (:time_stamp, NVL(:unit_of_measurement, '-'), :value, :identifier, :slug)`)
+		VALUES (:time_stamp, :unit_of_measurement, :value, :identifier, :slug)`)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = stmt.Close() }()
 
+	latestStmt, err := tx.PrepareContext(ctx, upsertLatestSQL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = latestStmt.Close() }()
+
+	rollupStmt, err := tx.PrepareContext(ctx, upsertRollupSQL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rollupStmt.Close() }()
+
 	for _, dp := range data {
 		if _, err := stmt.ExecContext(ctx,
 			sql.Named("time_stamp", dp.Timestamp),
@@ -35,10 +52,100 @@ func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
 		); err != nil {
 			return err
 		}
+		if _, err := latestStmt.ExecContext(ctx,
+			sql.Named("identifier", dp.Identifier),
+			sql.Named("slug", dp.Slug),
+			sql.Named("time_stamp", go_ora.TimeStampTZ(dp.Timestamp.UTC())),
+			sql.Named("unit_of_measurement", dp.UnitOfMeasurement),
+			sql.Named("value", dp.Value),
+			sql.Named("name", dp.Name),
+			sql.Named("first_seen", go_ora.TimeStampTZ(dp.Timestamp.UTC())),
+		); err != nil {
+			return err
+		}
+		if err := upsertRollups(ctx, rollupStmt, dp); err != nil {
+			return err
+		}
 	}
 	return tx.Commit()
 }
 
+// upsertLatestSQL replaces the property_latest row for a reading's
+// identifier and slug unless it already holds a newer reading. A reading
+// without a name keeps the stored one: Oracle binds an empty name as NULL.
+// first_seen always moves back to the earliest reading. Write binds the
+// reading's time as first_seen; ImportSensors binds the catalog's.
+const upsertLatestSQL = `
+	MERGE INTO property_latest l
+	USING (
+		SELECT :identifier AS identifier, :slug AS slug, :time_stamp AS time_stamp,
+		       :unit_of_measurement AS unit_of_measurement, :value AS value, :name AS name,
+		       :first_seen AS first_seen
+		FROM dual
+	) src
+	ON (l.identifier = src.identifier AND l.slug = src.slug)
+	WHEN MATCHED THEN UPDATE SET
+		l.time_stamp          = GREATEST(l.time_stamp, src.time_stamp),
+		l.unit_of_measurement = CASE WHEN src.time_stamp >= l.time_stamp
+		                             THEN src.unit_of_measurement ELSE l.unit_of_measurement END,
+		l.value               = CASE WHEN src.time_stamp >= l.time_stamp
+		                             THEN src.value ELSE l.value END,
+		l.name                = CASE WHEN src.time_stamp >= l.time_stamp
+		                             THEN NVL(src.name, l.name) ELSE l.name END,
+		l.first_seen          = LEAST(l.first_seen, src.first_seen)
+	WHEN NOT MATCHED THEN
+		INSERT (identifier, slug, time_stamp, unit_of_measurement, value, name, first_seen)
+		VALUES (src.identifier, src.slug, src.time_stamp, src.unit_of_measurement, src.value, src.name, src.first_seen)`
+
+// upsertRollupSQL folds one reading into a property_rollup bucket. Bucket
+// identity compares SYS_EXTRACT_UTC(bucket_start) to match the unique index,
+// since Oracle cannot key on TIMESTAMP WITH TIME ZONE directly.
+const upsertRollupSQL = `
+	MERGE INTO property_rollup r
+	USING (
+		SELECT :resolution AS resolution, :bucket_start AS bucket_start, :identifier AS identifier,
+		       :slug AS slug, :unit_of_measurement AS unit_of_measurement,
+		       TO_BINARY_DOUBLE(:value) AS value, :time_stamp AS time_stamp
+		FROM dual
+	) src
+	ON (r.identifier = src.identifier AND r.slug = src.slug AND r.resolution = src.resolution
+	    AND SYS_EXTRACT_UTC(r.bucket_start) = SYS_EXTRACT_UTC(src.bucket_start))
+	WHEN MATCHED THEN UPDATE SET
+		r.min_value           = LEAST(r.min_value, src.value),
+		r.max_value           = GREATEST(r.max_value, src.value),
+		r.sum_value           = r.sum_value + src.value,
+		r.sample_count        = r.sample_count + 1,
+		r.last_value          = CASE WHEN src.time_stamp >= r.last_time_stamp THEN src.value ELSE r.last_value END,
+		r.unit_of_measurement = CASE WHEN src.time_stamp >= r.last_time_stamp THEN src.unit_of_measurement ELSE r.unit_of_measurement END,
+		r.last_time_stamp     = GREATEST(r.last_time_stamp, src.time_stamp)
+	WHEN NOT MATCHED THEN
+		INSERT (resolution, bucket_start, identifier, slug, unit_of_measurement,
+		        min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+		VALUES (src.resolution, src.bucket_start, src.identifier, src.slug, src.unit_of_measurement,
+		        src.value, src.value, src.value, 1, src.value, src.time_stamp)`
+
+// upsertRollups folds a numeric reading into its bucket at every rollup resolution.
+func upsertRollups(ctx context.Context, stmt *sql.Stmt, dp publisher.DataPoint) error {
+	v, ok := dp.Float()
+	if !ok {
+		return nil
+	}
+	for _, res := range store.RollupResolutions {
+		if _, err := stmt.ExecContext(ctx,
+			sql.Named("resolution", int(res.Seconds())),
+			sql.Named("bucket_start", go_ora.TimeStampTZ(store.BucketStart(dp.Timestamp, res))),
+			sql.Named("identifier", dp.Identifier),
+			sql.Named("slug", dp.Slug),
+			sql.Named("unit_of_measurement", dp.UnitOfMeasurement),
+			sql.Named("value", v),
+			sql.Named("time_stamp", go_ora.TimeStampTZ(dp.Timestamp.UTC())),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Store) RegisterDevice(ctx context.Context, device *model.Device) error {
 	_, err := s.db.ExecContext(ctx, `
 		MERGE INTO Device d
@@ -94,9 +201,3 @@ func (s *Store) DeleteExpiredRefreshTokens(ctx context.Context) error {
 		`DELETE FROM refresh_tokens WHERE expires_at < SYSTIMESTAMP`)
 	return err
 }
-
-func (s *Store) Cleanup(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx,
-		`DELETE FROM Property WHERE time_stamp < SYSTIMESTAMP - INTERVAL '8' DAY`)
-	return err
-}
@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	dbq "github.com/anicoll/winet-integration/internal/pkg/database/db"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) ListDevices(ctx context.Context) ([]model.Device, error) {
+	rows, err := s.queries.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Device, len(rows))
+	for i, r := range rows {
+		out[i] = model.Device{
+			ID:           r.ID,
+			Model:        r.Model.String,
+			SerialNumber: r.SerialNumber.String,
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) ScanProperties(ctx context.Context, afterID, limit int) ([]store.Property, error) {
+	rows, err := s.queries.ScanProperties(ctx, dbq.ScanPropertiesParams{
+		AfterID:  afterID,
+		RowLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProperties(rows), nil
+}
+
+func (s *Store) CountProperties(ctx context.Context) (int, error) {
+	n, err := s.queries.CountProperties(ctx)
+	return int(n), err
+}
+
+func (s *Store) ListUsers(ctx context.Context) ([]store.User, error) {
+	rows, err := s.queries.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.User, len(rows))
+	for i, u := range rows {
+		out[i] = store.User{
+			ID:           u.ID,
+			Username:     u.Username,
+			PasswordHash: u.PasswordHash,
+			CreatedAt:    u.CreatedAt.Time,
+			UpdatedAt:    u.UpdatedAt.Time,
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) ListRefreshTokens(ctx context.Context) ([]store.RefreshToken, error) {
+	rows, err := s.queries.ListRefreshTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.RefreshToken, len(rows))
+	for i, r := range rows {
+		out[i] = store.RefreshToken{
+			TokenHash: r.TokenHash,
+			UserID:    r.UserID,
+			Username:  r.Username,
+			ExpiresAt: r.ExpiresAt,
+			CreatedAt: r.CreatedAt.Time,
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) ScanRollups(ctx context.Context, after store.Rollup, limit int) ([]store.Rollup, error) {
+	if s.timescale != nil {
+		return s.scanTimescaleRollups(ctx, after, limit)
+	}
+	rows, err := s.queries.ScanPropertyRollups(ctx, dbq.ScanPropertyRollupsParams{
+		AfterIdentifier:  after.Identifier,
+		AfterSlug:        after.Slug,
+		AfterResolution:  int(after.Resolution.Seconds()),
+		AfterBucketStart: after.BucketStart,
+		RowLimit:         limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.Rollup, len(rows))
+	for i, r := range rows {
+		out[i] = store.Rollup{
+			Identifier: r.Identifier,
+			Slug:       r.Slug,
+			Resolution: time.Duration(r.Resolution) * time.Second,
+			Aggregate: store.Aggregate{
+				BucketStart:       r.BucketStart,
+				UnitOfMeasurement: r.UnitOfMeasurement,
+				Min:               r.MinValue,
+				Max:               r.MaxValue,
+				Sum:               r.SumValue,
+				Count:             r.SampleCount,
+				Last:              r.LastValue,
+				LastTimeStamp:     r.LastTimeStamp,
+			},
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) CountRollups(ctx context.Context) (int, error) {
+	if s.timescale != nil {
+		var n int
+		err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM (`+timescaleRollupsSQL()+`) a`).Scan(&n)
+		return n, err
+	}
+	n, err := s.queries.CountPropertyRollups(ctx)
+	return int(n), err
+}
+
+func (s *Store) ScanCommands(ctx context.Context, afterID, limit int) ([]store.Command, error) {
+	rows, err := s.queries.ScanCommands(ctx, dbq.ScanCommandsParams{
+		AfterID:  afterID,
+		RowLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toCommands(rows)
+}
+
+func (s *Store) CountCommands(ctx context.Context) (int, error) {
+	n, err := s.queries.CountCommands(ctx)
+	return int(n), err
+}
+
+// ImportRollups writes property_rollup in either mode. In TimescaleDB mode
+// the continuous aggregates take over from a series' first materialised
+// bucket, so imported buckets older than that are still read.
+func (s *Store) ImportRollups(ctx context.Context, rollups []store.Rollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	params := make([]dbq.ImportPropertyRollupsParams, len(rollups))
+	for i, r := range rollups {
+		params[i] = dbq.ImportPropertyRollupsParams{
+			Resolution:        int(r.Resolution.Seconds()),
+			BucketStart:       r.BucketStart,
+			Identifier:        r.Identifier,
+			Slug:              r.Slug,
+			UnitOfMeasurement: r.UnitOfMeasurement,
+			MinValue:          r.Min,
+			MaxValue:          r.Max,
+			SumValue:          r.Sum,
+			SampleCount:       r.Count,
+			LastValue:         r.Last,
+			LastTimeStamp:     r.LastTimeStamp,
+		}
+	}
+	return s.inTx(ctx, func(q *dbq.Queries) error {
+		var batchErr error
+		q.ImportPropertyRollups(ctx, params).Exec(func(_ int, err error) {
+			if err != nil && batchErr == nil {
+				batchErr = err
+			}
+		})
+		return batchErr
+	})
+}
+
+func (s *Store) ImportSensors(ctx context.Context, sensors []store.Sensor) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+	params := make([]dbq.UpsertPropertyLatestParams, len(sensors))
+	for i, sn := range sensors {
+		params[i] = dbq.UpsertPropertyLatestParams{
+			Identifier:        sn.Identifier,
+			Slug:              sn.Slug,
+			TimeStamp:         sn.LastSeen,
+			UnitOfMeasurement: sn.UnitOfMeasurement,
+			Value:             sn.LastValue,
+			Name:              sn.Name,
+			FirstSeen:         sn.FirstSeen,
+		}
+	}
+	return s.inTx(ctx, func(q *dbq.Queries) error {
+		var batchErr error
+		q.UpsertPropertyLatest(ctx, params).Exec(func(_ int, err error) {
+			if err != nil && batchErr == nil {
+				batchErr = err
+			}
+		})
+		return batchErr
+	})
+}
+
+// inTx runs fn in a transaction, so a failed batch leaves nothing behind.
+func (s *Store) inTx(ctx context.Context, fn func(q *dbq.Queries) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := fn(s.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -3,9 +3,55 @@ package postgres
 import (
 	"context"
 	"time"
+
+	dbq "github.com/anicoll/winet-integration/internal/pkg/database/db"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
 )
 
-// Cleanup removes Property rows older than 8 days.
-func (s *Store) Cleanup(ctx context.Context) error {
-	return s.queries.CleanupProperties(ctx, time.Now().AddDate(0, 0, -8))
+// Cleanup applies policy in a single transaction. Raw readings are rolled up
+// before they are deleted, so no reading is lost from the long-term rollups
+// even if it was written before rollups were maintained.
+func (s *Store) Cleanup(ctx context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	if s.timescale != nil {
+		return s.cleanupTimescale(ctx, policy)
+	}
+	var res store.RetentionResult
+	now := time.Now()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	qtx := s.queries.WithTx(tx)
+	for _, rule := range policy.RawRules(now) {
+		if err := qtx.RollupProperties(ctx, dbq.RollupPropertiesParams{
+			Before:  rule.Before,
+			Slug:    rule.Slug,
+			Exclude: rule.Exclude,
+		}); err != nil {
+			return res, err
+		}
+		n, err := qtx.DeleteProperties(ctx, dbq.DeletePropertiesParams{
+			Before:  rule.Before,
+			Slug:    rule.Slug,
+			Exclude: rule.Exclude,
+		})
+		if err != nil {
+			return res, err
+		}
+		res.RowsDeleted += n
+	}
+	for _, rule := range policy.RollupRules(now) {
+		n, err := qtx.DeletePropertyRollups(ctx, dbq.DeletePropertyRollupsParams{
+			Resolution:  int(rule.Resolution.Seconds()),
+			BucketStart: rule.Before,
+		})
+		if err != nil {
+			return res, err
+		}
+		res.RollupsDeleted += n
+	}
+	return res, tx.Commit(ctx)
 }
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dbq "github.com/anicoll/winet-integration/internal/pkg/database/db"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) RecordCommand(ctx context.Context, cmd store.Command) error {
+	params, err := json.Marshal(cmd.Params)
+	if err != nil {
+		return err
+	}
+	return s.queries.InsertCommand(ctx, dbq.InsertCommandParams{
+		RequestedAt:  cmd.RequestedAt,
+		Source:       cmd.Source,
+		Username:     cmd.User,
+		Command:      cmd.Command,
+		Params:       params,
+		Success:      cmd.Success,
+		ResultCode:   cmd.ResultCode,
+		ErrorMessage: cmd.Error,
+		LatencyMs:    cmd.Latency.Milliseconds(),
+	})
+}
+
+func (s *Store) GetCommands(ctx context.Context, filter store.CommandFilter) ([]store.Command, error) {
+	from, to := filter.Range()
+	rows, err := s.queries.GetCommands(ctx, dbq.GetCommandsParams{
+		From:     from,
+		To:       to,
+		Username: filter.User,
+		Source:   filter.Source,
+		Command:  filter.Command,
+		RowLimit: filter.LimitOrDefault(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toCommands(rows)
+}
+
+func toCommands(rows []dbq.CommandAudit) ([]store.Command, error) {
+	out := make([]store.Command, len(rows))
+	for i, r := range rows {
+		out[i] = store.Command{
+			ID:          r.ID,
+			RequestedAt: r.RequestedAt,
+			Source:      r.Source,
+			User:        r.Username,
+			Command:     r.Command,
+			Success:     r.Success,
+			ResultCode:  r.ResultCode,
+			Error:       r.ErrorMessage,
+			Latency:     time.Duration(r.LatencyMs) * time.Millisecond,
+		}
+		if err := json.Unmarshal(r.Params, &out[i].Params); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
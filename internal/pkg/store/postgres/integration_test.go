@@ -8,17 +8,15 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
-	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/suite"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 
 	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
-	"github.com/anicoll/winet-integration/internal/pkg/model"
-	"github.com/anicoll/winet-integration/internal/pkg/publisher"
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 	pgstore "github.com/anicoll/winet-integration/internal/pkg/store/postgres"
+	"github.com/anicoll/winet-integration/internal/pkg/store/storetest"
 )
 
 // migrationsPath resolves the postgres migrations directory relative to this
@@ -70,63 +68,20 @@ func (s *PostgresSuite) TearDownSuite() {
 	}
 }
 
-func (s *PostgresSuite) TestWrite_and_GetLatestProperties() {
-	ctx := context.Background()
-
-	dp := publisher.DataPoint{
-		Timestamp:         time.Now().UTC().Truncate(time.Millisecond),
-		UnitOfMeasurement: "W",
-		Value:             "42.5",
-		Identifier:        "SN-TEST-001",
-		Slug:              "battery-power",
-	}
-
-	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{dp}))
-
-	props, err := s.store.GetLatestProperties(ctx)
-	s.Require().NoError(err)
-
-	found := false
-	for p := range props {
-		if p.Identifier == dp.Identifier && p.Slug == dp.Slug {
-			s.Equal(dp.Value, p.Value)
-			found = true
-		}
-	}
-	s.True(found, "written data point not found in GetLatestProperties")
+func (s *PostgresSuite) TestConformance() {
+	storetest.Run(s.T(), func(*testing.T) store.Store { return s.store })
 }
 
-func (s *PostgresSuite) TestRegisterDevice() {
+func (s *PostgresSuite) TestMigrateTimescale_FallsBackWithoutExtension() {
 	ctx := context.Background()
 
-	dev := &model.Device{ID: "pg-integration-device", Model: "TestModel", SerialNumber: "SN-IT-001"}
-	s.Require().NoError(s.store.RegisterDevice(ctx, dev))
-	s.Require().NoError(s.store.RegisterDevice(ctx, dev), "second upsert must be idempotent")
-}
-
-func (s *PostgresSuite) TestUserAndRefreshToken() {
-	ctx := context.Background()
-
-	user, err := s.store.CreateUser(ctx, "pg-integration-user", "$2a$10$placeholder")
+	dsn, err := s.container.ConnectionString(ctx, "sslmode=disable")
 	s.Require().NoError(err)
-	s.Equal("pg-integration-user", user.Username)
-
-	fetched, err := s.store.GetUserByUsername(ctx, user.Username)
+	pool, err := pgxpool.New(ctx, dsn)
 	s.Require().NoError(err)
-	s.Equal(user.ID, fetched.ID)
-
-	params := store.StoreRefreshTokenParams{
-		TokenHash: "pg-test-hash-integration",
-		UserID:    user.ID,
-		Username:  user.Username,
-		ExpiresAt: time.Now().Add(time.Hour),
-	}
-	s.Require().NoError(s.store.StoreRefreshToken(ctx, params))
+	defer pool.Close()
 
-	tok, err := s.store.GetRefreshToken(ctx, params.TokenHash)
+	available, err := migration.MigrateTimescale(ctx, pool, filepath.Join(filepath.Dir(migrationsPath()), "timescale"))
 	s.Require().NoError(err)
-	s.Equal(user.ID, tok.UserID)
-
-	s.Require().NoError(s.store.DeleteRefreshToken(ctx, params.TokenHash))
-	s.Require().NoError(s.store.DeleteExpiredRefreshTokens(ctx))
+	s.False(available, "postgres:16-alpine does not ship timescaledb")
 }
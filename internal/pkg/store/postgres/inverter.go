@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbq "github.com/anicoll/winet-integration/internal/pkg/database/db"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) SaveInverterState(ctx context.Context, st store.InverterState) error {
+	return s.queries.UpsertInverter(ctx, dbq.UpsertInverterParams{
+		ID:            st.ID,
+		State:         st.State,
+		BatteryState:  st.BatteryState,
+		ChargeRate:    st.ChargeRate,
+		FeedinEnabled: st.FeedinEnabled,
+		UpdatedAt:     pgtype.Timestamptz{Time: st.UpdatedAt, Valid: true},
+		ReconciledAt:  pgtype.Timestamptz{Time: st.ReconciledAt, Valid: !st.ReconciledAt.IsZero()},
+	})
+}
+
+func (s *Store) GetInverterStates(ctx context.Context) ([]store.InverterState, error) {
+	rows, err := s.queries.GetInverters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.InverterState, 0, len(rows))
+	for _, r := range rows {
+		st := store.InverterState{
+			ID:            r.ID,
+			State:         r.State,
+			BatteryState:  r.BatteryState,
+			ChargeRate:    r.ChargeRate,
+			FeedinEnabled: r.FeedinEnabled,
+			UpdatedAt:     r.UpdatedAt.Time,
+		}
+		if r.ReconciledAt.Valid {
+			st.ReconciledAt = r.ReconciledAt.Time
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
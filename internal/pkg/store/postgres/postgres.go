@@ -17,12 +17,32 @@ var _ store.Store = (*Store)(nil)
 type Store struct {
 	pool    *pgxpool.Pool
 	queries *dbq.Queries
+
+	// timescale is set when Property is a TimescaleDB hypertable; rollups
+	// then come from continuous aggregates instead of property_rollup.
+	timescale *TimescaleOptions
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithTimescale switches the store to TimescaleDB mode. Only use it once
+// migration.MigrateTimescale has reported the extension available.
+func WithTimescale(opts TimescaleOptions) Option {
+	return func(s *Store) { s.timescale = &opts }
 }
 
 // New creates a Store from an existing connection pool.
-func New(pool *pgxpool.Pool) *Store {
-	return &Store{
+func New(pool *pgxpool.Pool, opts ...Option) *Store {
+	s := &Store{
 		pool:    pool,
 		queries: dbq.New(pool),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
+
+// Name identifies the backend in logs and metrics.
+func (s *Store) Name() string { return "postgres" }
@@ -29,12 +29,89 @@ func (s *Store) GetProperties(ctx context.Context, identifier, slug string, from
 	return toProperties(rows), nil
 }
 
+func (s *Store) GetPropertyRange(ctx context.Context, identifier, slug string, from, to time.Time, limit int) ([]store.Property, error) {
+	rows, err := s.queries.GetPropertyRange(ctx, dbq.GetPropertyRangeParams{
+		Identifier: identifier,
+		Slug:       slug,
+		FromTime:   from,
+		ToTime:     to,
+		RowLimit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProperties(rows), nil
+}
+
+// GetLatestProperties reads property_latest, which Write keeps current, so
+// it is a primary-key scan however much history Property holds.
 func (s *Store) GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error) {
 	rows, err := s.queries.GetLatestProperties(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return slices.Values(toProperties(rows)), nil
+	out := make([]store.Property, len(rows))
+	for i, r := range rows {
+		out[i] = store.Property{
+			TimeStamp:         r.TimeStamp,
+			UnitOfMeasurement: r.UnitOfMeasurement,
+			Value:             r.Value,
+			Identifier:        r.Identifier,
+			Slug:              r.Slug,
+		}
+	}
+	return slices.Values(out), nil
+}
+
+// GetSensors reads the catalog columns of property_latest.
+func (s *Store) GetSensors(ctx context.Context) ([]store.Sensor, error) {
+	rows, err := s.queries.GetSensors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.Sensor, len(rows))
+	for i, r := range rows {
+		out[i] = store.Sensor{
+			Identifier:        r.Identifier,
+			Slug:              r.Slug,
+			Name:              r.Name,
+			UnitOfMeasurement: r.UnitOfMeasurement,
+			FirstSeen:         r.FirstSeen,
+			LastSeen:          r.TimeStamp,
+			LastValue:         r.Value,
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) GetAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error) {
+	if s.timescale != nil {
+		return s.getTimescaleAggregates(ctx, identifier, slug, resolution, from, to)
+	}
+	rows, err := s.queries.GetPropertyRollups(ctx, dbq.GetPropertyRollupsParams{
+		Identifier:    identifier,
+		Slug:          slug,
+		Resolution:    int(resolution.Seconds()),
+		BucketStart:   from,
+		BucketStart_2: to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.Aggregate, len(rows))
+	for i, r := range rows {
+		out[i] = store.Aggregate{
+			BucketStart:       r.BucketStart,
+			UnitOfMeasurement: r.UnitOfMeasurement,
+			Min:               r.MinValue,
+			Max:               r.MaxValue,
+			Sum:               r.SumValue,
+			Count:             r.SampleCount,
+			Last:              r.LastValue,
+			LastTimeStamp:     r.LastTimeStamp,
+		}
+	}
+	return out, nil
 }
 
 func (s *Store) GetUserByUsername(ctx context.Context, username string) (store.User, error) {
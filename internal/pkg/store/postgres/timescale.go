@@ -0,0 +1,255 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	dbq "github.com/anicoll/winet-integration/internal/pkg/database/db"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// TimescaleOptions configures the policies installed on the hypertable and
+// continuous aggregates created by the migrations/timescale migrations.
+type TimescaleOptions struct {
+	// CompressAfter is the age at which Property chunks are compressed.
+	CompressAfter time.Duration
+	// RefreshWindow is how far back the continuous aggregate refresh policies
+	// recompute buckets. Raw readings must be kept longer than this, or a
+	// refresh would recompute buckets from readings that have been deleted.
+	RefreshWindow time.Duration
+}
+
+// timescaleAggregates names the continuous aggregate for each rollup resolution.
+var timescaleAggregates = map[time.Duration]string{
+	time.Minute:      "property_rollup_1m",
+	15 * time.Minute: "property_rollup_15m",
+	time.Hour:        "property_rollup_1h",
+	24 * time.Hour:   "property_rollup_1d",
+}
+
+// refreshOffsets returns the refresh policy offsets for the aggregate at
+// resolution. TimescaleDB requires the window to cover at least two buckets.
+func (o TimescaleOptions) refreshOffsets(resolution time.Duration) (start, end time.Duration) {
+	end = resolution
+	return max(o.RefreshWindow, end+2*resolution), end
+}
+
+// ApplyTimescalePolicies installs the compression policy on Property and a
+// refresh policy on each continuous aggregate, replacing any existing ones so
+// configuration changes take effect on restart. It is a no-op outside
+// TimescaleDB mode.
+func (s *Store) ApplyTimescalePolicies(ctx context.Context) error {
+	if s.timescale == nil {
+		return nil
+	}
+	if _, err := s.pool.Exec(ctx, `SELECT remove_compression_policy('property', if_exists => true)`); err != nil {
+		return fmt.Errorf("remove compression policy: %w", err)
+	}
+	if _, err := s.pool.Exec(ctx,
+		`SELECT add_compression_policy('property', compress_after => make_interval(secs => $1))`,
+		s.timescale.CompressAfter.Seconds()); err != nil {
+		return fmt.Errorf("add compression policy: %w", err)
+	}
+	for _, res := range store.RollupResolutions {
+		view := timescaleAggregates[res]
+		start, end := s.timescale.refreshOffsets(res)
+		if _, err := s.pool.Exec(ctx,
+			`SELECT remove_continuous_aggregate_policy($1::regclass, if_not_exists => true)`, view); err != nil {
+			return fmt.Errorf("remove refresh policy on %s: %w", view, err)
+		}
+		if _, err := s.pool.Exec(ctx, `
+			SELECT add_continuous_aggregate_policy($1::regclass,
+				start_offset      => make_interval(secs => $2),
+				end_offset        => make_interval(secs => $3),
+				schedule_interval => make_interval(secs => $4))`,
+			view, start.Seconds(), end.Seconds(), min(res, time.Hour).Seconds()); err != nil {
+			return fmt.Errorf("add refresh policy on %s: %w", view, err)
+		}
+	}
+	return nil
+}
+
+// getTimescaleAggregates reads rollups from the continuous aggregate for
+// resolution. Buckets older than the aggregate's first bucket for the series
+// come from property_rollup, which holds history rolled up before the switch
+// to TimescaleDB.
+func (s *Store) getTimescaleAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error) {
+	view, ok := timescaleAggregates[resolution]
+	if !ok {
+		return nil, fmt.Errorf("no rollups are kept at resolution %s", resolution)
+	}
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT bucket_start, unit_of_measurement, min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+		FROM (
+			SELECT bucket_start, unit_of_measurement, min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+			FROM %[1]s
+			WHERE identifier = $1 AND slug = $2 AND bucket_start >= $3 AND bucket_start < $4
+			UNION ALL
+			SELECT bucket_start, unit_of_measurement, min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+			FROM property_rollup
+			WHERE identifier = $1 AND slug = $2 AND resolution = $5 AND bucket_start >= $3 AND bucket_start < $4
+			  AND bucket_start < (SELECT COALESCE(MIN(bucket_start), 'infinity') FROM %[1]s WHERE identifier = $1 AND slug = $2)
+		) a
+		ORDER BY bucket_start`, view),
+		identifier, slug, from, to, int(resolution.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (store.Aggregate, error) {
+		var a store.Aggregate
+		err := row.Scan(&a.BucketStart, &a.UnitOfMeasurement, &a.Min, &a.Max, &a.Sum, &a.Count, &a.Last, &a.LastTimeStamp)
+		return a, err
+	})
+}
+
+// cleanupTimescale applies policy using chunk operations where it can. The
+// continuous aggregates are the rollups here, so rather than rolling readings
+// up itself it refuses to delete anything the refresh policies have not
+// materialised yet or could still recompute.
+func (s *Store) cleanupTimescale(ctx context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	var res store.RetentionResult
+	now := time.Now()
+
+	rules := policy.RawRules(now)
+	if len(rules) > 0 {
+		if err := s.checkMaterialised(ctx, now, rules); err != nil {
+			return res, err
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	qtx := s.queries.WithTx(tx)
+	for _, rule := range rules {
+		// Whole chunks can only be dropped when the rule covers every slug.
+		if rule.Slug == "" && len(rule.Exclude) == 0 {
+			var n int64
+			if err := tx.QueryRow(ctx,
+				`SELECT count(*) FROM drop_chunks('property', older_than => $1::timestamptz)`, rule.Before,
+			).Scan(&n); err != nil {
+				return res, err
+			}
+			res.ChunksDropped += n
+		}
+		n, err := qtx.DeleteProperties(ctx, dbq.DeletePropertiesParams{
+			Before:  rule.Before,
+			Slug:    rule.Slug,
+			Exclude: rule.Exclude,
+		})
+		if err != nil {
+			return res, err
+		}
+		res.RowsDeleted += n
+	}
+	for _, rule := range policy.RollupRules(now) {
+		var n int64
+		if err := tx.QueryRow(ctx,
+			`SELECT count(*) FROM drop_chunks($1::regclass, older_than => $2::timestamptz)`,
+			timescaleAggregates[rule.Resolution], rule.Before,
+		).Scan(&n); err != nil {
+			return res, err
+		}
+		res.ChunksDropped += n
+		// Rollups carried over from before the switch to TimescaleDB.
+		n, err := qtx.DeletePropertyRollups(ctx, dbq.DeletePropertyRollupsParams{
+			Resolution:  int(rule.Resolution.Seconds()),
+			BucketStart: rule.Before,
+		})
+		if err != nil {
+			return res, err
+		}
+		res.RollupsDeleted += n
+	}
+	return res, tx.Commit(ctx)
+}
+
+// checkMaterialised verifies that every continuous aggregate has materialised
+// the readings the rules delete, and that no refresh policy reaches back far
+// enough to recompute their buckets once they are gone.
+func (s *Store) checkMaterialised(ctx context.Context, now time.Time, rules []store.RawRule) error {
+	newest := rules[0].Before
+	for _, rule := range rules[1:] {
+		if rule.Before.After(newest) {
+			newest = rule.Before
+		}
+	}
+	for _, res := range store.RollupResolutions {
+		view := timescaleAggregates[res]
+		start, end := s.timescale.refreshOffsets(res)
+		if newest.After(now.Add(-start)) {
+			return fmt.Errorf("raw retention of %s is within the %s refresh window of %s", now.Sub(newest).Round(time.Second), start, view)
+		}
+		var finished *time.Time
+		if err := s.pool.QueryRow(ctx, `
+			SELECT js.last_successful_finish
+			FROM timescaledb_information.continuous_aggregates ca
+			JOIN timescaledb_information.jobs j
+			  ON j.hypertable_schema = ca.materialization_hypertable_schema
+			 AND j.hypertable_name = ca.materialization_hypertable_name
+			 AND j.proc_name = 'policy_refresh_continuous_aggregate'
+			LEFT JOIN timescaledb_information.job_stats js ON js.job_id = j.job_id
+			WHERE ca.view_name = $1`, view,
+		).Scan(&finished); err != nil {
+			return fmt.Errorf("check refresh of %s: %w", view, err)
+		}
+		if finished == nil || finished.Add(-end).Before(newest) {
+			return fmt.Errorf("%s has not been refreshed past %s yet; try again after its refresh policy runs", view, newest.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// timescaleRollupsSQL selects every rollup as getTimescaleAggregates reads
+// them: each continuous aggregate, plus the property_rollup buckets older than
+// the aggregate's first bucket for their series.
+func timescaleRollupsSQL() string {
+	var parts []string
+	for _, res := range store.RollupResolutions {
+		parts = append(parts, fmt.Sprintf(`
+			SELECT identifier, slug, %[2]d AS resolution, bucket_start, unit_of_measurement,
+			       min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+			FROM %[1]s
+			UNION ALL
+			SELECT identifier, slug, resolution, bucket_start, unit_of_measurement,
+			       min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+			FROM property_rollup r
+			WHERE resolution = %[2]d
+			  AND bucket_start < (SELECT COALESCE(MIN(bucket_start), 'infinity') FROM %[1]s v
+			                      WHERE v.identifier = r.identifier AND v.slug = r.slug)`,
+			timescaleAggregates[res], int(res.Seconds())))
+	}
+	return strings.Join(parts, "\n\t\t\tUNION ALL")
+}
+
+// scanTimescaleRollups pages through timescaleRollupsSQL.
+func (s *Store) scanTimescaleRollups(ctx context.Context, after store.Rollup, limit int) ([]store.Rollup, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT identifier, slug, resolution, bucket_start, unit_of_measurement,
+		       min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+		FROM (`+timescaleRollupsSQL()+`) a
+		WHERE (identifier, slug, resolution, bucket_start) > ($1::TEXT, $2::TEXT, $3::INTEGER, $4::TIMESTAMPTZ)
+		ORDER BY identifier, slug, resolution, bucket_start
+		LIMIT $5`,
+		after.Identifier, after.Slug, int(after.Resolution.Seconds()), after.BucketStart, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (store.Rollup, error) {
+		var (
+			r          store.Rollup
+			resolution int
+		)
+		err := row.Scan(&r.Identifier, &r.Slug, &resolution, &r.BucketStart, &r.UnitOfMeasurement,
+			&r.Min, &r.Max, &r.Sum, &r.Count, &r.Last, &r.LastTimeStamp)
+		r.Resolution = time.Duration(resolution) * time.Second
+		return r, err
+	})
+}
@@ -0,0 +1,171 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	pgstore "github.com/anicoll/winet-integration/internal/pkg/store/postgres"
+)
+
+// TimescaleSuite runs the store in TimescaleDB mode against a Timescale image.
+// It does not run the storetest conformance suite: continuous aggregates only
+// pick up backfilled readings once refreshed, and Cleanup refuses to delete
+// inside the refresh window, so those behaviours are tested here instead.
+type TimescaleSuite struct {
+	suite.Suite
+	container *postgres.PostgresContainer
+	pool      *pgxpool.Pool
+	store     *pgstore.Store
+}
+
+func TestTimescaleSuite(t *testing.T) {
+	suite.Run(t, new(TimescaleSuite))
+}
+
+func (s *TimescaleSuite) SetupSuite() {
+	ctx := context.Background()
+
+	ctr, err := postgres.Run(ctx,
+		"timescale/timescaledb:latest-pg16",
+		postgres.WithDatabase("winet_test"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.BasicWaitStrategies(),
+	)
+	s.Require().NoError(err)
+	s.container = ctr
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	s.Require().NoError(err)
+
+	pool, err := pgxpool.New(ctx, dsn)
+	s.Require().NoError(err)
+	s.Require().NoError(pool.Ping(ctx))
+	s.pool = pool
+
+	s.Require().NoError(migration.MigratePostgres(pool, migrationsPath()))
+	available, err := migration.MigrateTimescale(ctx, pool, filepath.Join(filepath.Dir(migrationsPath()), "timescale"))
+	s.Require().NoError(err)
+	s.Require().True(available)
+
+	s.store = pgstore.New(pool, pgstore.WithTimescale(pgstore.TimescaleOptions{
+		CompressAfter: 7 * 24 * time.Hour,
+		RefreshWindow: 48 * time.Hour,
+	}))
+	s.Require().NoError(s.store.ApplyTimescalePolicies(ctx))
+}
+
+func (s *TimescaleSuite) TearDownSuite() {
+	if s.container != nil {
+		_ = s.container.Terminate(context.Background())
+	}
+}
+
+// refreshAll materialises every continuous aggregate and runs the refresh
+// policies once, as the scheduler would.
+func (s *TimescaleSuite) refreshAll(ctx context.Context) {
+	for _, view := range []string{"property_rollup_1m", "property_rollup_15m", "property_rollup_1h", "property_rollup_1d"} {
+		_, err := s.pool.Exec(ctx, "CALL refresh_continuous_aggregate($1::regclass, NULL, NULL)", view)
+		s.Require().NoError(err)
+	}
+	rows, err := s.pool.Query(ctx,
+		`SELECT job_id FROM timescaledb_information.jobs WHERE proc_name = 'policy_refresh_continuous_aggregate'`)
+	s.Require().NoError(err)
+	var jobs []int
+	for rows.Next() {
+		var id int
+		s.Require().NoError(rows.Scan(&id))
+		jobs = append(jobs, id)
+	}
+	s.Require().NoError(rows.Err())
+	for _, id := range jobs {
+		_, err := s.pool.Exec(ctx, "CALL run_job($1)", id)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *TimescaleSuite) TestPropertyIsHypertable() {
+	var n int
+	s.Require().NoError(s.pool.QueryRow(context.Background(),
+		`SELECT count(*) FROM timescaledb_information.hypertables WHERE hypertable_name = 'property'`).Scan(&n))
+	s.Equal(1, n)
+}
+
+func (s *TimescaleSuite) TestTimescaleApplied() {
+	ctx := context.Background()
+	tsPath := filepath.Join(filepath.Dir(migrationsPath()), "timescale")
+	applied, err := migration.TimescaleApplied(ctx, s.pool, tsPath)
+	s.Require().NoError(err)
+	s.True(applied)
+
+	// A newer migration file that has not run yet.
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "000099_pending.up.sql"), []byte("SELECT 1;"), 0o644))
+	applied, err = migration.TimescaleApplied(ctx, s.pool, dir)
+	s.Require().NoError(err)
+	s.False(applied)
+}
+
+func (s *TimescaleSuite) TestGetAggregates_FromContinuousAggregates() {
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: base.Add(time.Minute), UnitOfMeasurement: "kW", Value: "1.5", Identifier: "SN-TS", Slug: "battery_power"},
+		{Timestamp: base.Add(2 * time.Minute), UnitOfMeasurement: "kW", Value: "3.5", Identifier: "SN-TS", Slug: "battery_power"},
+		{Timestamp: base.Add(3 * time.Minute), UnitOfMeasurement: "", Value: "Running", Identifier: "SN-TS", Slug: "running_status"},
+	}))
+
+	// Recent buckets come from real-time aggregation before any refresh.
+	hours, err := s.store.GetAggregates(ctx, "SN-TS", "battery_power", time.Hour, base, base.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(hours, 1)
+	s.Equal(2, hours[0].Count)
+	s.InDelta(1.5, hours[0].Min, 1e-9)
+	s.InDelta(3.5, hours[0].Last, 1e-9)
+
+	text, err := s.store.GetAggregates(ctx, "SN-TS", "running_status", time.Hour, base, base.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Empty(text, "non-numeric readings are not aggregated")
+}
+
+func (s *TimescaleSuite) TestCleanup_DropsChunksAndKeepsAggregates() {
+	ctx := context.Background()
+
+	old := time.Now().UTC().AddDate(0, 0, -10).Truncate(24 * time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: old.Add(time.Hour), UnitOfMeasurement: "W", Value: "10", Identifier: "SN-TS-RET", Slug: "load_power"},
+		{Timestamp: old.Add(2 * time.Hour), UnitOfMeasurement: "W", Value: "20", Identifier: "SN-TS-RET", Slug: "load_power"},
+	}))
+	s.refreshAll(ctx)
+
+	res, err := s.store.Cleanup(ctx, store.RetentionPolicy{Raw: 8 * 24 * time.Hour})
+	s.Require().NoError(err)
+	s.GreaterOrEqual(res.ChunksDropped, int64(1))
+
+	from, to := old, old.Add(24*time.Hour)
+	props, err := s.store.GetProperties(ctx, "SN-TS-RET", "load_power", &from, &to)
+	s.Require().NoError(err)
+	s.Empty(props)
+
+	days, err := s.store.GetAggregates(ctx, "SN-TS-RET", "load_power", 24*time.Hour, from, to)
+	s.Require().NoError(err)
+	s.Require().Len(days, 1)
+	s.Equal(2, days[0].Count)
+	s.InDelta(30.0, days[0].Sum, 1e-9)
+}
+
+func (s *TimescaleSuite) TestCleanup_RefusesRetentionInsideRefreshWindow() {
+	_, err := s.store.Cleanup(context.Background(), store.RetentionPolicy{Raw: 24 * time.Hour})
+	s.Error(err)
+}
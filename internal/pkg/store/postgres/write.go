@@ -12,7 +12,15 @@ import (
 	"github.com/anicoll/winet-integration/internal/pkg/store"
 )
 
+// Write stores a poll's readings in one transaction: a single COPY for the
+// raw rows, one pipelined batch of property_latest upserts and, outside
+// TimescaleDB mode, one of rollup upserts. The round trips no longer grow
+// with the number of readings.
 func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
+	if len(data) == 0 {
+		return nil
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -20,20 +28,91 @@ func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	qtx := s.queries.WithTx(tx)
-	for _, dp := range data {
-		if _, err := qtx.InsertProperty(ctx, dbq.InsertPropertyParams{
+	rows := make([]dbq.CopyPropertiesParams, len(data))
+	for i, dp := range data {
+		rows[i] = dbq.CopyPropertiesParams{
 			TimeStamp:         dp.Timestamp,
 			UnitOfMeasurement: dp.UnitOfMeasurement,
 			Value:             dp.Value,
 			Identifier:        dp.Identifier,
 			Slug:              dp.Slug,
-		}); err != nil {
+		}
+	}
+	if _, err := qtx.CopyProperties(ctx, rows); err != nil {
+		return err
+	}
+	if err := upsertLatest(ctx, qtx, data); err != nil {
+		return err
+	}
+	// In TimescaleDB mode continuous aggregates maintain the rollups.
+	if s.timescale == nil {
+		if err := upsertRollups(ctx, qtx, data); err != nil {
 			return err
 		}
 	}
 	return tx.Commit(ctx)
 }
 
+// upsertLatest records each reading in property_latest unless a newer one
+// is already there. A reading without a name keeps the stored one.
+func upsertLatest(ctx context.Context, q *dbq.Queries, data []publisher.DataPoint) error {
+	params := make([]dbq.UpsertPropertyLatestParams, len(data))
+	for i, dp := range data {
+		params[i] = dbq.UpsertPropertyLatestParams{
+			Identifier:        dp.Identifier,
+			Slug:              dp.Slug,
+			TimeStamp:         dp.Timestamp,
+			UnitOfMeasurement: dp.UnitOfMeasurement,
+			Value:             dp.Value,
+			Name:              dp.Name,
+			FirstSeen:         dp.Timestamp,
+		}
+	}
+
+	var batchErr error
+	q.UpsertPropertyLatest(ctx, params).Exec(func(_ int, err error) {
+		if err != nil && batchErr == nil {
+			batchErr = err
+		}
+	})
+	return batchErr
+}
+
+// upsertRollups folds the numeric readings into their buckets at every rollup
+// resolution. The upserts run as one batch, in order, so readings that share
+// a bucket are folded one after the other.
+func upsertRollups(ctx context.Context, q *dbq.Queries, data []publisher.DataPoint) error {
+	params := make([]dbq.UpsertPropertyRollupsParams, 0, len(data)*len(store.RollupResolutions))
+	for _, dp := range data {
+		v, ok := dp.Float()
+		if !ok {
+			continue
+		}
+		for _, res := range store.RollupResolutions {
+			params = append(params, dbq.UpsertPropertyRollupsParams{
+				Resolution:        int(res.Seconds()),
+				BucketStart:       store.BucketStart(dp.Timestamp, res),
+				Identifier:        dp.Identifier,
+				Slug:              dp.Slug,
+				UnitOfMeasurement: dp.UnitOfMeasurement,
+				Value:             v,
+				TimeStamp:         dp.Timestamp,
+			})
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+
+	var batchErr error
+	q.UpsertPropertyRollups(ctx, params).Exec(func(_ int, err error) {
+		if err != nil && batchErr == nil {
+			batchErr = err
+		}
+	})
+	return batchErr
+}
+
 func (s *Store) RegisterDevice(ctx context.Context, device *model.Device) error {
 	return s.queries.UpsertDevice(ctx, dbq.UpsertDeviceParams{
 		ID:           device.ID,
@@ -0,0 +1,151 @@
+// Run with: go test -run '^$' -bench Write ./internal/pkg/store/postgres/...
+//
+// Like the suites, the benchmark starts a PostgreSQL container via
+// testcontainers, so it needs Docker.
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	dbq "github.com/anicoll/winet-integration/internal/pkg/database/db"
+	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	pgstore "github.com/anicoll/winet-integration/internal/pkg/store/postgres"
+)
+
+// BenchmarkWrite compares Store.Write (COPY plus a batch of rollup upserts)
+// with the previous path of one round trip per statement, for poll sizes
+// from a single inverter up to several sites.
+func BenchmarkWrite(b *testing.B) {
+	ctx := context.Background()
+
+	ctr, err := postgres.Run(ctx,
+		"postgres:16-alpine",
+		postgres.WithDatabase("winet_bench"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = ctr.Terminate(context.Background()) })
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		b.Fatal(err)
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+	if err := migration.MigratePostgres(pool, migrationsPath()); err != nil {
+		b.Fatal(err)
+	}
+
+	st := pgstore.New(pool)
+	for _, size := range []int{50, 500, 5000} {
+		data := benchPoll(size)
+		b.Run(fmt.Sprintf("copy/%d", size), func(b *testing.B) {
+			for b.Loop() {
+				if err := st.Write(ctx, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "points/s")
+		})
+		b.Run(fmt.Sprintf("per_row/%d", size), func(b *testing.B) {
+			for b.Loop() {
+				if err := writePerRow(ctx, pool, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "points/s")
+		})
+	}
+}
+
+// writePerRow is the Write path before COPY: an INSERT ... RETURNING per
+// reading plus its property_latest upsert and one rollup upsert per
+// resolution, each its own round trip.
+func writePerRow(ctx context.Context, pool *pgxpool.Pool, data []publisher.DataPoint) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := dbq.New(tx)
+	for _, dp := range data {
+		if _, err := q.InsertProperty(ctx, dbq.InsertPropertyParams{
+			TimeStamp:         dp.Timestamp,
+			UnitOfMeasurement: dp.UnitOfMeasurement,
+			Value:             dp.Value,
+			Identifier:        dp.Identifier,
+			Slug:              dp.Slug,
+		}); err != nil {
+			return err
+		}
+		var latestErr error
+		q.UpsertPropertyLatest(ctx, []dbq.UpsertPropertyLatestParams{{
+			Identifier:        dp.Identifier,
+			Slug:              dp.Slug,
+			TimeStamp:         dp.Timestamp,
+			UnitOfMeasurement: dp.UnitOfMeasurement,
+			Value:             dp.Value,
+		}}).Exec(func(_ int, err error) { latestErr = err })
+		if latestErr != nil {
+			return latestErr
+		}
+		v, ok := dp.Float()
+		if !ok {
+			continue
+		}
+		for _, res := range store.RollupResolutions {
+			var batchErr error
+			q.UpsertPropertyRollups(ctx, []dbq.UpsertPropertyRollupsParams{{
+				Resolution:        int(res.Seconds()),
+				BucketStart:       store.BucketStart(dp.Timestamp, res),
+				Identifier:        dp.Identifier,
+				Slug:              dp.Slug,
+				UnitOfMeasurement: dp.UnitOfMeasurement,
+				Value:             v,
+				TimeStamp:         dp.Timestamp,
+			}}).Exec(func(_ int, err error) { batchErr = err })
+			if batchErr != nil {
+				return batchErr
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// benchPoll builds one poll's worth of readings spread over devices of 50
+// sensors each, with every tenth sensor a text reading.
+func benchPoll(size int) []publisher.DataPoint {
+	now := time.Now().UTC()
+	data := make([]publisher.DataPoint, size)
+	for i := range data {
+		dp := publisher.DataPoint{
+			Identifier:        fmt.Sprintf("SN-BENCH-%d", i/50),
+			Slug:              fmt.Sprintf("sensor_%d", i%50),
+			Value:             strconv.Itoa(i),
+			UnitOfMeasurement: "W",
+			Timestamp:         now,
+		}
+		if i%10 == 0 {
+			dp.Value, dp.UnitOfMeasurement = "Running", ""
+		}
+		data[i] = dp
+	}
+	return data
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RetentionPolicy says how long each kind of sensor data is kept. A zero
+// duration keeps data forever.
+type RetentionPolicy struct {
+	// Raw is how long raw readings are kept.
+	Raw time.Duration
+	// Slugs overrides Raw for individual slugs.
+	Slugs map[string]time.Duration
+	// Rollups is how long rollups of each resolution (one of
+	// RollupResolutions) are kept. Resolutions not listed are kept forever.
+	Rollups map[time.Duration]time.Duration
+}
+
+// RetentionResult reports what a Cleanup run removed.
+type RetentionResult struct {
+	RowsDeleted    int64 `json:"rows_deleted"`
+	RollupsDeleted int64 `json:"rollups_deleted"`
+	// ChunksDropped counts whole TimescaleDB chunks dropped; their rows are
+	// not included in RowsDeleted or RollupsDeleted.
+	ChunksDropped int64 `json:"chunks_dropped"`
+}
+
+// RawRule selects raw readings older than Before for deletion. A rule with a
+// Slug applies to that slug only; a rule without one applies to every slug
+// not listed in Exclude.
+type RawRule struct {
+	Slug    string
+	Exclude []string
+	Before  time.Time
+}
+
+// RollupRule selects rollups of Resolution whose bucket starts before Before.
+type RollupRule struct {
+	Resolution time.Duration
+	Before     time.Time
+}
+
+// Validate reports policies that cannot be applied.
+func (p RetentionPolicy) Validate() error {
+	if p.Raw < 0 {
+		return fmt.Errorf("raw retention must not be negative")
+	}
+	for slug, d := range p.Slugs {
+		if d < 0 {
+			return fmt.Errorf("retention for slug %q must not be negative", slug)
+		}
+	}
+	for res, d := range p.Rollups {
+		if !slices.Contains(RollupResolutions, res) {
+			return fmt.Errorf("no rollups are kept at resolution %s", res)
+		}
+		if d < 0 {
+			return fmt.Errorf("retention for %s rollups must not be negative", res)
+		}
+	}
+	return nil
+}
+
+// RawRules returns the raw deletion rules for a run at now, per-slug
+// overrides first (in slug order) and the default rule last.
+func (p RetentionPolicy) RawRules(now time.Time) []RawRule {
+	overrides := make([]string, 0, len(p.Slugs))
+	for slug := range p.Slugs {
+		overrides = append(overrides, slug)
+	}
+	slices.Sort(overrides)
+
+	var rules []RawRule
+	for _, slug := range overrides {
+		if d := p.Slugs[slug]; d > 0 {
+			rules = append(rules, RawRule{Slug: slug, Before: now.Add(-d)})
+		}
+	}
+	if p.Raw > 0 {
+		rules = append(rules, RawRule{Exclude: overrides, Before: now.Add(-p.Raw)})
+	}
+	return rules
+}
+
+// RollupRules returns the rollup deletion rules for a run at now, finest
+// resolution first.
+func (p RetentionPolicy) RollupRules(now time.Time) []RollupRule {
+	var rules []RollupRule
+	for _, res := range RollupResolutions {
+		if d := p.Rollups[res]; d > 0 {
+			rules = append(rules, RollupRule{Resolution: res, Before: now.Add(-d)})
+		}
+	}
+	return rules
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionPolicy_RawRules(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	p := RetentionPolicy{
+		Raw: 8 * 24 * time.Hour,
+		Slugs: map[string]time.Duration{
+			"load_power":    30 * 24 * time.Hour,
+			"battery_power": 0, // kept forever
+		},
+	}
+
+	assert.Equal(t, []RawRule{
+		{Slug: "load_power", Before: now.AddDate(0, 0, -30)},
+		{Exclude: []string{"battery_power", "load_power"}, Before: now.AddDate(0, 0, -8)},
+	}, p.RawRules(now))
+
+	assert.Empty(t, RetentionPolicy{}.RawRules(now), "zero policy keeps everything")
+}
+
+func TestRetentionPolicy_RollupRules(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	p := RetentionPolicy{Rollups: map[time.Duration]time.Duration{
+		time.Hour:      365 * 24 * time.Hour,
+		time.Minute:    8 * 24 * time.Hour,
+		24 * time.Hour: 0,
+	}}
+
+	assert.Equal(t, []RollupRule{
+		{Resolution: time.Minute, Before: now.AddDate(0, 0, -8)},
+		{Resolution: time.Hour, Before: now.AddDate(0, 0, -365)},
+	}, p.RollupRules(now))
+}
+
+func TestRetentionPolicy_Validate(t *testing.T) {
+	assert.NoError(t, RetentionPolicy{Raw: time.Hour, Rollups: map[time.Duration]time.Duration{15 * time.Minute: time.Hour}}.Validate())
+	assert.Error(t, RetentionPolicy{Raw: -time.Hour}.Validate())
+	assert.Error(t, RetentionPolicy{Slugs: map[string]time.Duration{"x": -1}}.Validate())
+	assert.Error(t, RetentionPolicy{Rollups: map[time.Duration]time.Duration{5 * time.Minute: time.Hour}}.Validate())
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+)
+
+// RollupResolutions are the bucket widths every store maintains for numeric
+// readings, finest first. Buckets are aligned to UTC.
+var RollupResolutions = []time.Duration{time.Minute, 15 * time.Minute, time.Hour, 24 * time.Hour}
+
+// Aggregate summarises the numeric readings of one identifier+slug within a
+// bucket starting at BucketStart.
+type Aggregate struct {
+	BucketStart       time.Time `json:"bucket_start"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Min               float64   `json:"min"`
+	Max               float64   `json:"max"`
+	Sum               float64   `json:"sum"`
+	Count             int       `json:"count"`
+	Last              float64   `json:"last"`
+	LastTimeStamp     time.Time `json:"last_time_stamp"`
+}
+
+// Rollup is a stored rollup bucket together with the series and resolution
+// it belongs to, as moved by the bulk export and import methods.
+type Rollup struct {
+	Identifier string        `json:"identifier"`
+	Slug       string        `json:"slug"`
+	Resolution time.Duration `json:"resolution"`
+	Aggregate
+}
+
+// CompareRollups orders rollups by identifier, slug, resolution and bucket
+// start, the order ScanRollups returns them in.
+func CompareRollups(a, b Rollup) int {
+	return cmp.Or(
+		cmp.Compare(a.Identifier, b.Identifier),
+		cmp.Compare(a.Slug, b.Slug),
+		cmp.Compare(a.Resolution, b.Resolution),
+		a.BucketStart.Compare(b.BucketStart),
+	)
+}
+
+// Avg returns the mean of the readings in the bucket.
+func (a Aggregate) Avg() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// BucketStart returns the start of the bucket of width resolution containing ts.
+func BucketStart(ts time.Time, resolution time.Duration) time.Time {
+	return ts.UTC().Truncate(resolution)
+}
+
+// PickResolution returns the coarsest stored resolution that evenly divides
+// bucket, so rollups at that resolution can be merged into buckets of that width.
+func PickResolution(bucket time.Duration) (time.Duration, error) {
+	for i := len(RollupResolutions) - 1; i >= 0; i-- {
+		r := RollupResolutions[i]
+		if bucket >= r && bucket%r == 0 {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("bucket %s must be a whole number of minutes", bucket)
+}
+
+// Rebucket merges aggregates (sorted by BucketStart) into buckets of width
+// bucket. bucket must be a multiple of the aggregates' resolution.
+func Rebucket(aggs []Aggregate, bucket time.Duration) []Aggregate {
+	var out []Aggregate
+	for _, a := range aggs {
+		start := BucketStart(a.BucketStart, bucket)
+		if n := len(out); n > 0 && out[n-1].BucketStart.Equal(start) {
+			out[n-1] = merge(out[n-1], a)
+			continue
+		}
+		a.BucketStart = start
+		out = append(out, a)
+	}
+	return out
+}
+
+func merge(a, b Aggregate) Aggregate {
+	a.Min = min(a.Min, b.Min)
+	a.Max = max(a.Max, b.Max)
+	a.Sum += b.Sum
+	a.Count += b.Count
+	if !b.LastTimeStamp.Before(a.LastTimeStamp) {
+		a.Last, a.LastTimeStamp = b.Last, b.LastTimeStamp
+		a.UnitOfMeasurement = b.UnitOfMeasurement
+	}
+	return a
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickResolution(t *testing.T) {
+	for _, tc := range []struct {
+		bucket, want time.Duration
+	}{
+		{time.Minute, time.Minute},
+		{5 * time.Minute, time.Minute},
+		{30 * time.Minute, 15 * time.Minute},
+		{90 * time.Minute, 15 * time.Minute},
+		{6 * time.Hour, time.Hour},
+		{24 * time.Hour, 24 * time.Hour},
+		{7 * 24 * time.Hour, 24 * time.Hour},
+	} {
+		got, err := PickResolution(tc.bucket)
+		require.NoError(t, err, tc.bucket)
+		assert.Equal(t, tc.want, got, tc.bucket)
+	}
+
+	_, err := PickResolution(30 * time.Second)
+	assert.Error(t, err)
+	_, err = PickResolution(90 * time.Second)
+	assert.Error(t, err)
+}
+
+func TestRebucket(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	aggs := []Aggregate{
+		{BucketStart: t0, Min: 1, Max: 3, Sum: 4, Count: 2, Last: 3, LastTimeStamp: t0.Add(10 * time.Minute), UnitOfMeasurement: "kW"},
+		{BucketStart: t0.Add(15 * time.Minute), Min: 0, Max: 2, Sum: 2, Count: 2, Last: 0, LastTimeStamp: t0.Add(20 * time.Minute), UnitOfMeasurement: "kW"},
+		{BucketStart: t0.Add(30 * time.Minute), Min: 5, Max: 5, Sum: 5, Count: 1, Last: 5, LastTimeStamp: t0.Add(31 * time.Minute), UnitOfMeasurement: "kW"},
+	}
+
+	out := Rebucket(aggs, 30*time.Minute)
+	require.Len(t, out, 2)
+	assert.Equal(t, Aggregate{BucketStart: t0, Min: 0, Max: 3, Sum: 6, Count: 4, Last: 0, LastTimeStamp: t0.Add(20 * time.Minute), UnitOfMeasurement: "kW"}, out[0])
+	assert.InDelta(t, 1.5, out[0].Avg(), 1e-9)
+	assert.Equal(t, t0.Add(30*time.Minute), out[1].BucketStart)
+	assert.Equal(t, 1, out[1].Count)
+}
@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) ListDevices(ctx context.Context) ([]model.Device, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, model, serial_number FROM Device ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.Device
+	for rows.Next() {
+		var (
+			d                   model.Device
+			deviceModel, serial sql.NullString
+		)
+		if err := rows.Scan(&d.ID, &deviceModel, &serial); err != nil {
+			return nil, err
+		}
+		d.Model, d.SerialNumber = deviceModel.String, serial.String
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ScanProperties(ctx context.Context, afterID, limit int) ([]store.Property, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+		FROM Property
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?`,
+		afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanProperties(rows)
+}
+
+func (s *Store) CountProperties(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM Property`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) ListUsers(ctx context.Context) ([]store.User, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, created_at, updated_at
+		FROM users
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.User
+	for rows.Next() {
+		var (
+			u                store.User
+			created, updated int64
+		)
+		if err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &created, &updated); err != nil {
+			return nil, err
+		}
+		u.CreatedAt, u.UpdatedAt = fromMicros(created), fromMicros(updated)
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ListRefreshTokens(ctx context.Context) ([]store.RefreshToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT token_hash, user_id, username, expires_at, created_at
+		FROM refresh_tokens
+		ORDER BY created_at, token_hash`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.RefreshToken
+	for rows.Next() {
+		var (
+			r                store.RefreshToken
+			expires, created int64
+		)
+		if err := rows.Scan(&r.TokenHash, &r.UserID, &r.Username, &expires, &created); err != nil {
+			return nil, err
+		}
+		r.ExpiresAt, r.CreatedAt = fromMicros(expires), fromMicros(created)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ScanRollups(ctx context.Context, after store.Rollup, limit int) ([]store.Rollup, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT identifier, slug, resolution, bucket_start, unit_of_measurement,
+		       min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+		FROM property_rollup
+		WHERE (identifier, slug, resolution, bucket_start) > (?, ?, ?, ?)
+		ORDER BY identifier, slug, resolution, bucket_start
+		LIMIT ?`,
+		after.Identifier, after.Slug, int(after.Resolution.Seconds()), micros(after.BucketStart), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.Rollup
+	for rows.Next() {
+		var (
+			r                             store.Rollup
+			resolution, bucket, lastStamp int64
+		)
+		if err := rows.Scan(&r.Identifier, &r.Slug, &resolution, &bucket, &r.UnitOfMeasurement,
+			&r.Min, &r.Max, &r.Sum, &r.Count, &r.Last, &lastStamp); err != nil {
+			return nil, err
+		}
+		r.Resolution = time.Duration(resolution) * time.Second
+		r.BucketStart, r.LastTimeStamp = fromMicros(bucket), fromMicros(lastStamp)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) CountRollups(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM property_rollup`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) ScanCommands(ctx context.Context, afterID, limit int) ([]store.Command, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, requested_at, source, username, command, params, success, result_code, error_message, latency_ms
+		FROM command_audit
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?`,
+		afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanCommands(rows)
+}
+
+func (s *Store) CountCommands(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM command_audit`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) ImportRollups(ctx context.Context, rollups []store.Rollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+		                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (identifier, slug, resolution, bucket_start) DO UPDATE SET
+			unit_of_measurement = excluded.unit_of_measurement,
+			min_value           = excluded.min_value,
+			max_value           = excluded.max_value,
+			sum_value           = excluded.sum_value,
+			sample_count        = excluded.sample_count,
+			last_value          = excluded.last_value,
+			last_time_stamp     = excluded.last_time_stamp`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, r := range rollups {
+		if _, err := stmt.ExecContext(ctx,
+			int(r.Resolution.Seconds()), micros(r.BucketStart), r.Identifier, r.Slug, r.UnitOfMeasurement,
+			r.Min, r.Max, r.Sum, r.Count, r.Last, micros(r.LastTimeStamp),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) ImportSensors(ctx context.Context, sensors []store.Sensor) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, upsertLatestSQL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, sn := range sensors {
+		if _, err := stmt.ExecContext(ctx,
+			sn.Identifier, sn.Slug, micros(sn.LastSeen), sn.UnitOfMeasurement, sn.LastValue, sn.Name, micros(sn.FirstSeen),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// rollupPropertiesSQL fills in rollup buckets that are missing for the
+// readings matched by the %s filter. Existing buckets were maintained on write
+// and are left alone.
+const rollupPropertiesSQL = `
+	WITH p AS (
+		SELECT r.resolution,
+		       (time_stamp / (r.resolution * 1000000)) * (r.resolution * 1000000) AS bucket_start,
+		       identifier, slug, unit_of_measurement, numeric_value AS v, time_stamp
+		FROM Property
+		CROSS JOIN (SELECT 60 AS resolution UNION ALL SELECT 900 UNION ALL SELECT 3600 UNION ALL SELECT 86400) r
+		WHERE numeric_value IS NOT NULL AND %s
+	), ranked AS (
+		SELECT p.*, ROW_NUMBER() OVER (
+			PARTITION BY resolution, bucket_start, identifier, slug ORDER BY time_stamp DESC) AS rn
+		FROM p
+	)
+	INSERT OR IGNORE INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+	                                       min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+	SELECT resolution, bucket_start, identifier, slug,
+	       MAX(CASE WHEN rn = 1 THEN unit_of_measurement END),
+	       MIN(v), MAX(v), SUM(v), COUNT(*),
+	       MAX(CASE WHEN rn = 1 THEN v END),
+	       MAX(time_stamp)
+	FROM ranked
+	GROUP BY resolution, bucket_start, identifier, slug`
+
+// Cleanup applies policy in a single transaction. Raw readings are rolled up
+// before they are deleted, so no reading is lost from the long-term rollups
+// even if it was written before rollups were maintained.
+func (s *Store) Cleanup(ctx context.Context, policy store.RetentionPolicy) (store.RetentionResult, error) {
+	var res store.RetentionResult
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rule := range policy.RawRules(now) {
+		filter, args := rawRuleFilter(rule)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(rollupPropertiesSQL, filter), args...); err != nil {
+			return res, err
+		}
+		n, err := rowsAffected(tx.ExecContext(ctx, "DELETE FROM Property WHERE "+filter, args...))
+		if err != nil {
+			return res, err
+		}
+		res.RowsDeleted += n
+	}
+	for _, rule := range policy.RollupRules(now) {
+		n, err := rowsAffected(tx.ExecContext(ctx,
+			`DELETE FROM property_rollup WHERE resolution = ? AND bucket_start < ?`,
+			int(rule.Resolution.Seconds()), micros(rule.Before)))
+		if err != nil {
+			return res, err
+		}
+		res.RollupsDeleted += n
+	}
+	return res, tx.Commit()
+}
+
+// rawRuleFilter renders rule as a WHERE condition on Property.
+func rawRuleFilter(rule store.RawRule) (string, []any) {
+	conds := []string{"time_stamp < ?"}
+	args := []any{micros(rule.Before)}
+	if rule.Slug != "" {
+		conds = append(conds, "slug = ?")
+		args = append(args, rule.Slug)
+	}
+	if len(rule.Exclude) > 0 {
+		conds = append(conds, "slug NOT IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(rule.Exclude)), ", ")+")")
+		for _, slug := range rule.Exclude {
+			args = append(args, slug)
+		}
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func rowsAffected(r sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) RecordCommand(ctx context.Context, cmd store.Command) error {
+	params, err := json.Marshal(cmd.Params)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO command_audit (requested_at, source, username, command, params, success, result_code, error_message, latency_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		micros(cmd.RequestedAt), cmd.Source, cmd.User, cmd.Command, string(params),
+		cmd.Success, cmd.ResultCode, cmd.Error, cmd.Latency.Milliseconds())
+	return err
+}
+
+func (s *Store) GetCommands(ctx context.Context, filter store.CommandFilter) ([]store.Command, error) {
+	from, to := filter.Range()
+	conds := []string{"requested_at BETWEEN ? AND ?"}
+	args := []any{micros(from), micros(to)}
+	for _, f := range []struct{ column, value string }{
+		{"username", filter.User},
+		{"source", filter.Source},
+		{"command", filter.Command},
+	} {
+		if f.value != "" {
+			conds = append(conds, f.column+" = ?")
+			args = append(args, f.value)
+		}
+	}
+	args = append(args, filter.LimitOrDefault())
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, requested_at, source, username, command, params, success, result_code, error_message, latency_ms
+		FROM command_audit
+		WHERE %s
+		ORDER BY requested_at DESC, id DESC
+		LIMIT ?`, strings.Join(conds, " AND ")),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanCommands(rows)
+}
+
+func scanCommands(rows *sql.Rows) ([]store.Command, error) {
+	var out []store.Command
+	for rows.Next() {
+		var (
+			c                    store.Command
+			requestedAt, latency int64
+			params               string
+		)
+		if err := rows.Scan(&c.ID, &requestedAt, &c.Source, &c.User, &c.Command, &params,
+			&c.Success, &c.ResultCode, &c.Error, &latency); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(params), &c.Params); err != nil {
+			return nil, err
+		}
+		c.RequestedAt = fromMicros(requestedAt)
+		c.Latency = time.Duration(latency) * time.Millisecond
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,88 @@
+// Run with: go test -v ./internal/pkg/store/sqlite/...
+//
+// The database is a file in a temporary directory, so the suite needs no
+// container and runs with the unit tests.
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/anicoll/winet-integration/internal/pkg/database/migration"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+	sqlitestore "github.com/anicoll/winet-integration/internal/pkg/store/sqlite"
+	"github.com/anicoll/winet-integration/internal/pkg/store/storetest"
+)
+
+// migrationsPath resolves the sqlite migrations directory relative to this
+// test file, regardless of where `go test` is invoked from.
+func migrationsPath() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "../../../../migrations/sqlite")
+}
+
+// SQLiteSuite shares a single database file across the entire suite.
+type SQLiteSuite struct {
+	suite.Suite
+	db    *sql.DB
+	store *sqlitestore.Store
+}
+
+func TestSQLiteSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteSuite))
+}
+
+func (s *SQLiteSuite) SetupSuite() {
+	ctx := context.Background()
+
+	db, err := sqlitestore.Open(ctx, filepath.Join(s.T().TempDir(), "winet.db"))
+	s.Require().NoError(err)
+	s.db = db
+
+	s.Require().NoError(migration.MigrateSQLite(db, migrationsPath()))
+	s.Require().NoError(migration.MigrateSQLite(db, migrationsPath()), "migrations must be idempotent")
+
+	s.store = sqlitestore.New(db)
+}
+
+func (s *SQLiteSuite) TearDownSuite() {
+	if s.db != nil {
+		_ = s.db.Close()
+	}
+}
+
+func (s *SQLiteSuite) TestConformance() {
+	storetest.Run(s.T(), func(*testing.T) store.Store { return s.store })
+}
+
+func (s *SQLiteSuite) TestCleanup_RollsUpReadingsWrittenWithoutRollups() {
+	ctx := context.Background()
+
+	old := time.Now().UTC().AddDate(0, 0, -10).Truncate(time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: old.Add(time.Minute), UnitOfMeasurement: "V", Value: "230", Identifier: "SN-BACKFILL", Slug: "grid_voltage"},
+		{Timestamp: old.Add(2 * time.Minute), UnitOfMeasurement: "V", Value: "240", Identifier: "SN-BACKFILL", Slug: "grid_voltage"},
+	}))
+	// Simulate readings stored before rollups were maintained.
+	_, err := s.db.ExecContext(ctx, `DELETE FROM property_rollup WHERE identifier = 'SN-BACKFILL'`)
+	s.Require().NoError(err)
+
+	_, err = s.store.Cleanup(ctx, store.RetentionPolicy{Raw: 8 * 24 * time.Hour})
+	s.Require().NoError(err)
+
+	hours, err := s.store.GetAggregates(ctx, "SN-BACKFILL", "grid_voltage", time.Hour, old, old.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(hours, 1)
+	s.Equal(2, hours[0].Count)
+	s.InDelta(230.0, hours[0].Min, 1e-9)
+	s.InDelta(240.0, hours[0].Last, 1e-9)
+	s.Equal("V", hours[0].UnitOfMeasurement)
+	s.True(old.Add(2 * time.Minute).Equal(hours[0].LastTimeStamp))
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) SaveInverterState(ctx context.Context, st store.InverterState) error {
+	var reconciledAt sql.NullInt64
+	if !st.ReconciledAt.IsZero() {
+		reconciledAt = sql.NullInt64{Int64: micros(st.ReconciledAt), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO Inverter (id, state, battery_state, charge_rate, feedin_enabled, created_at, updated_at, reconciled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			state          = excluded.state,
+			battery_state  = excluded.battery_state,
+			charge_rate    = excluded.charge_rate,
+			feedin_enabled = excluded.feedin_enabled,
+			updated_at     = excluded.updated_at,
+			reconciled_at  = excluded.reconciled_at`,
+		st.ID, st.State, st.BatteryState, st.ChargeRate, st.FeedinEnabled,
+		micros(time.Now()), micros(st.UpdatedAt), reconciledAt)
+	return err
+}
+
+func (s *Store) GetInverterStates(ctx context.Context) ([]store.InverterState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, state, battery_state, charge_rate, feedin_enabled, updated_at, reconciled_at
+		FROM Inverter
+		ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []store.InverterState
+	for rows.Next() {
+		var (
+			st           store.InverterState
+			updatedAt    int64
+			reconciledAt sql.NullInt64
+		)
+		if err := rows.Scan(&st.ID, &st.State, &st.BatteryState, &st.ChargeRate, &st.FeedinEnabled, &updatedAt, &reconciledAt); err != nil {
+			return nil, err
+		}
+		st.UpdatedAt = fromMicros(updatedAt)
+		if reconciledAt.Valid {
+			st.ReconciledAt = fromMicros(reconciledAt.Int64)
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
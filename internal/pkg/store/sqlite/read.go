@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) GetProperties(ctx context.Context, identifier, slug string, from, to *time.Time) ([]store.Property, error) {
+	if from == nil || to == nil {
+		t := time.Now().AddDate(0, 0, -2)
+		from = &t
+		now := time.Now()
+		to = &now
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+		FROM Property
+		WHERE identifier = ? AND slug = ? AND time_stamp BETWEEN ? AND ?
+		ORDER BY time_stamp DESC`,
+		identifier, slug, micros(*from), micros(*to))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanProperties(rows)
+}
+
+func (s *Store) GetPropertyRange(ctx context.Context, identifier, slug string, from, to time.Time, limit int) ([]store.Property, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, time_stamp, unit_of_measurement, value, identifier, slug
+		FROM Property
+		WHERE identifier = ? AND slug = ? AND time_stamp BETWEEN ? AND ?
+		ORDER BY time_stamp, id
+		LIMIT ?`,
+		identifier, slug, micros(from), micros(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanProperties(rows)
+}
+
+func (s *Store) GetAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]store.Aggregate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT bucket_start, unit_of_measurement, min_value, max_value, sum_value, sample_count, last_value, last_time_stamp
+		FROM property_rollup
+		WHERE identifier = ? AND slug = ? AND resolution = ? AND bucket_start >= ? AND bucket_start < ?
+		ORDER BY bucket_start`,
+		identifier, slug, int(resolution.Seconds()), micros(from), micros(to))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []store.Aggregate
+	for rows.Next() {
+		var (
+			a                 store.Aggregate
+			bucket, lastStamp int64
+		)
+		if err := rows.Scan(&bucket, &a.UnitOfMeasurement, &a.Min, &a.Max, &a.Sum, &a.Count, &a.Last, &lastStamp); err != nil {
+			return nil, err
+		}
+		a.BucketStart, a.LastTimeStamp = fromMicros(bucket), fromMicros(lastStamp)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetLatestProperties returns the most recent reading per identifier+slug
+// from property_latest, which Write keeps current.
+func (s *Store) GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT identifier, slug, time_stamp, unit_of_measurement, value
+		FROM property_latest
+		ORDER BY identifier, slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var props []store.Property
+	for rows.Next() {
+		var (
+			p  store.Property
+			ts int64
+		)
+		if err := rows.Scan(&p.Identifier, &p.Slug, &ts, &p.UnitOfMeasurement, &p.Value); err != nil {
+			return nil, err
+		}
+		p.TimeStamp = fromMicros(ts)
+		props = append(props, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return slices.Values(props), nil
+}
+
+// GetSensors reads the catalog columns of property_latest.
+func (s *Store) GetSensors(ctx context.Context) ([]store.Sensor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT identifier, slug, name, unit_of_measurement, first_seen, time_stamp, value
+		FROM property_latest
+		ORDER BY identifier, slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []store.Sensor
+	for rows.Next() {
+		var (
+			sn                  store.Sensor
+			firstSeen, lastSeen int64
+		)
+		if err := rows.Scan(&sn.Identifier, &sn.Slug, &sn.Name, &sn.UnitOfMeasurement, &firstSeen, &lastSeen, &sn.LastValue); err != nil {
+			return nil, err
+		}
+		sn.FirstSeen, sn.LastSeen = fromMicros(firstSeen), fromMicros(lastSeen)
+		out = append(out, sn)
+	}
+	return out, rows.Err()
+}
+
+func scanProperties(rows *sql.Rows) ([]store.Property, error) {
+	var out []store.Property
+	for rows.Next() {
+		var (
+			p  store.Property
+			ts int64
+		)
+		if err := rows.Scan(&p.ID, &ts, &p.UnitOfMeasurement, &p.Value, &p.Identifier, &p.Slug); err != nil {
+			return nil, err
+		}
+		p.TimeStamp = fromMicros(ts)
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (store.User, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, username, password_hash, created_at, updated_at
+		FROM users
+		WHERE username = ?
+		LIMIT 1`,
+		username)
+	return scanUser(row)
+}
+
+func scanUser(row *sql.Row) (store.User, error) {
+	var (
+		u                store.User
+		created, updated int64
+	)
+	if err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &created, &updated); err != nil {
+		return store.User{}, err
+	}
+	u.CreatedAt, u.UpdatedAt = fromMicros(created), fromMicros(updated)
+	return u, nil
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, tokenHash string) (store.RefreshToken, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT token_hash, user_id, username, expires_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+		LIMIT 1`,
+		tokenHash)
+	var (
+		r                store.RefreshToken
+		expires, created int64
+	)
+	if err := row.Scan(&r.TokenHash, &r.UserID, &r.Username, &expires, &created); err != nil {
+		return store.RefreshToken{}, err
+	}
+	r.ExpiresAt, r.CreatedAt = fromMicros(expires), fromMicros(created)
+	return r, nil
+}
@@ -0,0 +1,71 @@
+// Package sqlite is the SQLite implementation of store.Store, for single-board
+// deployments where running a database server is overkill. It uses the
+// standard database/sql interface with the modernc.org/sqlite pure-Go driver,
+// so the binary still cross-compiles without cgo.
+//
+// Open the database with Open, which enables WAL journaling so the HTTP
+// server can read while readings are written, then run
+// migration.MigrateSQLite before passing the *sql.DB to New.
+//
+// Timestamps are stored as INTEGER unix microseconds (UTC).
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// Register the "sqlite" driver with database/sql.
+	_ "modernc.org/sqlite"
+
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// compile-time check that *Store satisfies store.Store.
+var _ store.Store = (*Store)(nil)
+
+// Store is the SQLite-backed implementation of store.Store.
+type Store struct {
+	db *sql.DB
+}
+
+// New creates a Store from an existing *sql.DB opened with Open.
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Name identifies the backend in logs and metrics.
+func (s *Store) Name() string { return "sqlite" }
+
+// Open opens (creating if needed) the SQLite database file at path. Every
+// connection uses WAL journaling, enforces foreign keys and waits up to five
+// seconds for a lock, and transactions take the write lock up front so
+// concurrent writers queue instead of failing with SQLITE_BUSY.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := "file:" + path +
+		"?_pragma=journal_mode(WAL)" +
+		"&_pragma=busy_timeout(5000)" +
+		"&_pragma=foreign_keys(1)" +
+		"&_pragma=synchronous(NORMAL)" +
+		"&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	return db, nil
+}
+
+// micros converts t to the unix microseconds stored in timestamp columns.
+func micros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// fromMicros converts a timestamp column back to a time.Time in UTC.
+func fromMicros(us int64) time.Time {
+	return time.UnixMicro(us).UTC()
+}
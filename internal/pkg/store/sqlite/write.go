@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+func (s *Store) Write(ctx context.Context, data []publisher.DataPoint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO Property (time_stamp, unit_of_measurement, value, numeric_value, identifier, slug)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	latestStmt, err := tx.PrepareContext(ctx, upsertLatestSQL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = latestStmt.Close() }()
+
+	rollupStmt, err := tx.PrepareContext(ctx, upsertRollupSQL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rollupStmt.Close() }()
+
+	for _, dp := range data {
+		var numeric sql.NullFloat64
+		numeric.Float64, numeric.Valid = dp.Float()
+		if _, err := stmt.ExecContext(ctx,
+			micros(dp.Timestamp), dp.UnitOfMeasurement, dp.Value, numeric, dp.Identifier, dp.Slug,
+		); err != nil {
+			return err
+		}
+		if _, err := latestStmt.ExecContext(ctx,
+			dp.Identifier, dp.Slug, micros(dp.Timestamp), dp.UnitOfMeasurement, dp.Value, dp.Name, micros(dp.Timestamp),
+		); err != nil {
+			return err
+		}
+		if numeric.Valid {
+			if err := upsertRollups(ctx, rollupStmt, dp, numeric.Float64); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// upsertLatestSQL replaces the property_latest row for a reading's
+// identifier and slug unless it already holds a newer reading. A reading
+// without a name keeps the stored one. first_seen always moves back to the
+// earliest reading, so backfilled history is accounted for. Write binds the
+// reading's time as first_seen; ImportSensors binds the catalog's.
+const upsertLatestSQL = `
+	INSERT INTO property_latest (identifier, slug, time_stamp, unit_of_measurement, value, name, first_seen)
+	VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+	ON CONFLICT (identifier, slug) DO UPDATE SET
+		time_stamp          = MAX(time_stamp, excluded.time_stamp),
+		unit_of_measurement = CASE WHEN excluded.time_stamp >= time_stamp
+		                           THEN excluded.unit_of_measurement ELSE unit_of_measurement END,
+		value               = CASE WHEN excluded.time_stamp >= time_stamp
+		                           THEN excluded.value ELSE value END,
+		name                = CASE WHEN excluded.time_stamp >= time_stamp
+		                           THEN COALESCE(NULLIF(excluded.name, ''), name) ELSE name END,
+		first_seen          = MIN(first_seen, excluded.first_seen)`
+
+// upsertRollupSQL folds one reading into a property_rollup bucket.
+const upsertRollupSQL = `
+	INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+	                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+	VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6, ?6, 1, ?6, ?7)
+	ON CONFLICT (identifier, slug, resolution, bucket_start) DO UPDATE SET
+		min_value           = MIN(min_value, excluded.min_value),
+		max_value           = MAX(max_value, excluded.max_value),
+		sum_value           = sum_value + excluded.sum_value,
+		sample_count        = sample_count + 1,
+		last_value          = CASE WHEN excluded.last_time_stamp >= last_time_stamp THEN excluded.last_value ELSE last_value END,
+		unit_of_measurement = CASE WHEN excluded.last_time_stamp >= last_time_stamp THEN excluded.unit_of_measurement ELSE unit_of_measurement END,
+		last_time_stamp     = MAX(last_time_stamp, excluded.last_time_stamp)`
+
+// upsertRollups folds the numeric reading v into its bucket at every rollup resolution.
+func upsertRollups(ctx context.Context, stmt *sql.Stmt, dp publisher.DataPoint, v float64) error {
+	for _, res := range store.RollupResolutions {
+		if _, err := stmt.ExecContext(ctx,
+			int(res.Seconds()),
+			micros(store.BucketStart(dp.Timestamp, res)),
+			dp.Identifier,
+			dp.Slug,
+			dp.UnitOfMeasurement,
+			v,
+			micros(dp.Timestamp),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) RegisterDevice(ctx context.Context, device *model.Device) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO Device (id, model, serial_number, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		device.ID, device.Model, device.SerialNumber, micros(time.Now()))
+	return err
+}
+
+func (s *Store) CreateUser(ctx context.Context, username, passwordHash string) (store.User, error) {
+	now := micros(time.Now())
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO users (username, password_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, username, password_hash, created_at, updated_at`,
+		username, passwordHash, now, now)
+	return scanUser(row)
+}
+
+func (s *Store) StoreRefreshToken(ctx context.Context, arg store.StoreRefreshTokenParams) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, user_id, username, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (token_hash) DO UPDATE SET expires_at = excluded.expires_at`,
+		arg.TokenHash, arg.UserID, arg.Username, micros(arg.ExpiresAt), micros(time.Now()))
+	return err
+}
+
+func (s *Store) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM refresh_tokens WHERE token_hash = ?`, tokenHash)
+	return err
+}
+
+func (s *Store) DeleteExpiredRefreshTokens(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM refresh_tokens WHERE expires_at < ?`, micros(time.Now()))
+	return err
+}
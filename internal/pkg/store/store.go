@@ -11,8 +11,8 @@ import (
 
 // Store is the single interface that every database backend must satisfy.
 // Add a new implementation in its own sub-package (e.g. store/postgres,
-// store/mssql) and write an integration test there to prove behavioural
-// equivalence.
+// store/mssql) and run the storetest conformance suite against it to prove
+// behavioural equivalence.
 type Store interface {
 	// --- sensor data ---
 
@@ -23,11 +23,72 @@ type Store interface {
 	// GetProperties returns readings for identifier/slug, defaulting to the
 	// last 2 days when from/to are nil.
 	GetProperties(ctx context.Context, identifier, slug string, from, to *time.Time) ([]Property, error)
+	// GetPropertyRange returns up to limit readings for identifier/slug with
+	// time stamps in [from, to], oldest first.
+	GetPropertyRange(ctx context.Context, identifier, slug string, from, to time.Time, limit int) ([]Property, error)
 	// GetLatestProperties returns the most recent reading per identifier+slug.
 	GetLatestProperties(ctx context.Context) (iter.Seq[Property], error)
-	// Cleanup removes sensor readings older than the implementation's
-	// configured retention window.
-	Cleanup(ctx context.Context) error
+	// GetSensors returns the catalog of every identifier+slug written so
+	// far, ordered by identifier and slug.
+	GetSensors(ctx context.Context) ([]Sensor, error)
+	// GetAggregates returns the rollups for identifier/slug at resolution (one
+	// of RollupResolutions) whose buckets start in [from, to), oldest first.
+	// Rollups are maintained by Write for every numeric reading.
+	GetAggregates(ctx context.Context, identifier, slug string, resolution time.Duration, from, to time.Time) ([]Aggregate, error)
+	// Cleanup deletes sensor readings and rollups that have outlived policy.
+	// Raw readings are rolled up before they are deleted.
+	Cleanup(ctx context.Context, policy RetentionPolicy) (RetentionResult, error)
+
+	// --- command audit ---
+
+	// RecordCommand appends cmd to the command audit log; cmd.ID is ignored.
+	RecordCommand(ctx context.Context, cmd Command) error
+	// GetCommands returns the audit log entries matching filter, newest first.
+	GetCommands(ctx context.Context, filter CommandFilter) ([]Command, error)
+
+	// --- inverter control state ---
+
+	// SaveInverterState upserts the control state of inverter st.ID.
+	SaveInverterState(ctx context.Context, st InverterState) error
+	// GetInverterStates returns every inverter's control state, most recently
+	// updated first.
+	GetInverterStates(ctx context.Context) ([]InverterState, error)
+
+	// --- bulk export ---
+
+	// ListDevices returns every registered device, ordered by ID.
+	ListDevices(ctx context.Context) ([]model.Device, error)
+	// ScanProperties returns up to limit readings with an ID above afterID,
+	// in ascending ID order. Pass the last ID returned to fetch the next page.
+	ScanProperties(ctx context.Context, afterID, limit int) ([]Property, error)
+	// CountProperties returns the number of stored readings.
+	CountProperties(ctx context.Context) (int, error)
+	// ListUsers returns every user account, ordered by ID.
+	ListUsers(ctx context.Context) ([]User, error)
+	// ListRefreshTokens returns every stored refresh token, expired or not.
+	ListRefreshTokens(ctx context.Context) ([]RefreshToken, error)
+	// ScanRollups returns up to limit rollups ordered by identifier, slug,
+	// resolution and bucket start, beginning after the position of after.
+	// Pass the last rollup returned to fetch the next page, or the zero
+	// Rollup for the first.
+	ScanRollups(ctx context.Context, after Rollup, limit int) ([]Rollup, error)
+	// CountRollups returns the number of stored rollup buckets.
+	CountRollups(ctx context.Context) (int, error)
+	// ScanCommands returns up to limit audit log entries with an ID above
+	// afterID, in ascending ID order.
+	ScanCommands(ctx context.Context, afterID, limit int) ([]Command, error)
+	// CountCommands returns the number of audit log entries.
+	CountCommands(ctx context.Context) (int, error)
+
+	// --- bulk import ---
+
+	// ImportRollups stores rollups as they are, replacing any bucket already
+	// held for the same series, resolution and start.
+	ImportRollups(ctx context.Context, rollups []Rollup) error
+	// ImportSensors merges catalog entries into the latest readings as Write
+	// would: a newer last reading replaces the stored one, first seen moves
+	// back to the earlier time and an empty name keeps the stored one.
+	ImportSensors(ctx context.Context, sensors []Sensor) error
 
 	// --- auth ---
 
@@ -0,0 +1,772 @@
+// Package storetest is a conformance suite for store.Store implementations.
+// Every backend runs it from its own tests so that behaviour cannot drift
+// between databases:
+//
+//	func (s *PostgresSuite) TestConformance() {
+//		storetest.Run(s.T(), func(*testing.T) store.Store { return s.store })
+//	}
+package storetest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+	"github.com/anicoll/winet-integration/internal/pkg/store"
+)
+
+// Run runs the conformance tests against the stores returned by newStore,
+// which is called once per test. It may hand out the same store every time:
+// each test uses its own identifiers, usernames and tokens, and only reads
+// back what it wrote.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	suite.Run(t, &conformanceSuite{newStore: newStore})
+}
+
+type conformanceSuite struct {
+	suite.Suite
+	newStore func(t *testing.T) store.Store
+	store    store.Store
+}
+
+func (s *conformanceSuite) SetupTest() {
+	s.store = s.newStore(s.T())
+}
+
+// latest returns the GetLatestProperties rows for identifier, keyed by slug.
+func (s *conformanceSuite) latest(identifier string) map[string]store.Property {
+	props, err := s.store.GetLatestProperties(context.Background())
+	s.Require().NoError(err)
+	out := map[string]store.Property{}
+	for p := range props {
+		if p.Identifier == identifier {
+			out[p.Slug] = p
+		}
+	}
+	return out
+}
+
+func (s *conformanceSuite) TestWrite_and_GetLatestProperties() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now.Add(-time.Minute), UnitOfMeasurement: "W", Value: "40", Identifier: "SN-CT-LATEST", Slug: "battery_power"},
+		{Timestamp: now, UnitOfMeasurement: "W", Value: "42.5", Identifier: "SN-CT-LATEST", Slug: "battery_power"},
+		{Timestamp: now.Add(-time.Minute), UnitOfMeasurement: "", Value: "Running", Identifier: "SN-CT-LATEST", Slug: "running_status"},
+	}))
+
+	latest := s.latest("SN-CT-LATEST")
+	s.Require().Len(latest, 2, "one row per identifier+slug")
+	s.Equal("42.5", latest["battery_power"].Value)
+	s.Equal("W", latest["battery_power"].UnitOfMeasurement)
+	s.True(now.Equal(latest["battery_power"].TimeStamp))
+	s.Equal("Running", latest["running_status"].Value)
+}
+
+func (s *conformanceSuite) TestGetLatestProperties_KeepsReadingsOlderThanADay() {
+	ctx := context.Background()
+
+	// Unchanged values are deduplicated before Write, so a sensor that has
+	// not changed for days still has to report its last value.
+	old := time.Now().UTC().Add(-72 * time.Hour).Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: old, UnitOfMeasurement: "W", Value: "1", Identifier: "SN-CT-STALE", Slug: "load_power"},
+	}))
+
+	latest := s.latest("SN-CT-STALE")
+	s.Require().Contains(latest, "load_power")
+	s.Equal("1", latest["load_power"].Value)
+	s.True(old.Equal(latest["load_power"].TimeStamp))
+}
+
+func (s *conformanceSuite) TestGetLatestProperties_IgnoresOutOfOrderReadings() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now, UnitOfMeasurement: "%", Value: "80", Identifier: "SN-CT-ORDER", Slug: "battery_level"},
+	}))
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now.Add(-time.Minute), UnitOfMeasurement: "%", Value: "79", Identifier: "SN-CT-ORDER", Slug: "battery_level"},
+	}))
+
+	latest := s.latest("SN-CT-ORDER")
+	s.Equal("80", latest["battery_level"].Value, "a late reading must not replace a newer one")
+	s.True(now.Equal(latest["battery_level"].TimeStamp))
+}
+
+// sensors returns the GetSensors entries for identifier, keyed by slug.
+func (s *conformanceSuite) sensors(identifier string) map[string]store.Sensor {
+	all, err := s.store.GetSensors(context.Background())
+	s.Require().NoError(err)
+	out := map[string]store.Sensor{}
+	for _, sn := range all {
+		if sn.Identifier == identifier {
+			out[sn.Slug] = sn
+		}
+	}
+	return out
+}
+
+func (s *conformanceSuite) TestGetSensors() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now.Add(-2 * time.Minute), UnitOfMeasurement: "W", Value: "100", Identifier: "SN-CT-SENSORS", Slug: "load_power", Name: "Load Power"},
+		{Timestamp: now.Add(-time.Minute), UnitOfMeasurement: "", Value: "Running", Identifier: "SN-CT-SENSORS", Slug: "running_status", Name: "Running Status"},
+	}))
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		// No name: the stored one is kept.
+		{Timestamp: now, UnitOfMeasurement: "W", Value: "150", Identifier: "SN-CT-SENSORS", Slug: "load_power"},
+	}))
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		// Older than the latest reading, as in a backfill: only moves first
+		// seen back.
+		{Timestamp: now.Add(-time.Hour), UnitOfMeasurement: "kW", Value: "1", Identifier: "SN-CT-SENSORS", Slug: "load_power", Name: "Old Name"},
+	}))
+
+	sensors := s.sensors("SN-CT-SENSORS")
+	s.Require().Len(sensors, 2)
+	load := sensors["load_power"]
+	s.Equal("Load Power", load.Name)
+	s.Equal("W", load.UnitOfMeasurement)
+	s.Equal("150", load.LastValue)
+	s.True(now.Add(-time.Hour).Equal(load.FirstSeen), "first seen %s", load.FirstSeen)
+	s.True(now.Equal(load.LastSeen), "last seen %s", load.LastSeen)
+
+	status := sensors["running_status"]
+	s.Equal("Running Status", status.Name)
+	s.Empty(status.UnitOfMeasurement)
+	s.Equal("Running", status.LastValue)
+
+	all, err := s.store.GetSensors(ctx)
+	s.Require().NoError(err)
+	s.True(slices.IsSortedFunc(all, func(a, b store.Sensor) int {
+		return cmp.Or(cmp.Compare(a.Identifier, b.Identifier), cmp.Compare(a.Slug, b.Slug))
+	}), "ordered by identifier and slug")
+}
+
+func (s *conformanceSuite) TestWrite_EmptyUnitRoundTrips() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now, UnitOfMeasurement: "", Value: "3", Identifier: "SN-CT-UNIT", Slug: "battery_count"},
+	}))
+
+	from, to := now.Add(-time.Second), now.Add(time.Second)
+	props, err := s.store.GetProperties(ctx, "SN-CT-UNIT", "battery_count", &from, &to)
+	s.Require().NoError(err)
+	s.Require().Len(props, 1)
+	s.Empty(props[0].UnitOfMeasurement)
+
+	s.Empty(s.latest("SN-CT-UNIT")["battery_count"].UnitOfMeasurement)
+
+	bucket := store.BucketStart(now, time.Minute)
+	aggs, err := s.store.GetAggregates(ctx, "SN-CT-UNIT", "battery_count", time.Minute, bucket, bucket.Add(time.Minute))
+	s.Require().NoError(err)
+	s.Require().Len(aggs, 1)
+	s.Empty(aggs[0].UnitOfMeasurement)
+}
+
+func (s *conformanceSuite) TestGetProperties_DefaultsToLastTwoDays() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now.Add(-3 * 24 * time.Hour), UnitOfMeasurement: "V", Value: "228", Identifier: "SN-CT-RANGE", Slug: "grid_voltage"},
+		{Timestamp: now.Add(-2 * time.Hour), UnitOfMeasurement: "V", Value: "230", Identifier: "SN-CT-RANGE", Slug: "grid_voltage"},
+		{Timestamp: now.Add(-time.Hour), UnitOfMeasurement: "V", Value: "231", Identifier: "SN-CT-RANGE", Slug: "grid_voltage"},
+	}))
+
+	props, err := s.store.GetProperties(ctx, "SN-CT-RANGE", "grid_voltage", nil, nil)
+	s.Require().NoError(err)
+	s.Require().Len(props, 2)
+	s.Equal("231", props[0].Value, "newest first")
+	s.Equal("230", props[1].Value)
+}
+
+func (s *conformanceSuite) TestGetProperties_RangeIsInclusive() {
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: base, UnitOfMeasurement: "W", Value: "1", Identifier: "SN-CT-BETWEEN", Slug: "pv_power"},
+		{Timestamp: base.Add(time.Minute), UnitOfMeasurement: "W", Value: "2", Identifier: "SN-CT-BETWEEN", Slug: "pv_power"},
+		{Timestamp: base.Add(2 * time.Minute), UnitOfMeasurement: "W", Value: "3", Identifier: "SN-CT-BETWEEN", Slug: "pv_power"},
+		{Timestamp: base.Add(time.Minute), UnitOfMeasurement: "W", Value: "9", Identifier: "SN-CT-BETWEEN", Slug: "load_power"},
+	}))
+
+	from, to := base, base.Add(time.Minute)
+	props, err := s.store.GetProperties(ctx, "SN-CT-BETWEEN", "pv_power", &from, &to)
+	s.Require().NoError(err)
+	s.Require().Len(props, 2)
+	s.Equal("2", props[0].Value)
+	s.Equal("1", props[1].Value)
+}
+
+func (s *conformanceSuite) TestGetPropertyRange_OldestFirstUpToLimit() {
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: base.Add(2 * time.Minute), UnitOfMeasurement: "W", Value: "3", Identifier: "SN-CT-PAGE", Slug: "pv_power"},
+		{Timestamp: base, UnitOfMeasurement: "W", Value: "1", Identifier: "SN-CT-PAGE", Slug: "pv_power"},
+		{Timestamp: base.Add(time.Minute), UnitOfMeasurement: "W", Value: "2", Identifier: "SN-CT-PAGE", Slug: "pv_power"},
+		{Timestamp: base.Add(3 * time.Minute), UnitOfMeasurement: "W", Value: "4", Identifier: "SN-CT-PAGE", Slug: "pv_power"},
+		{Timestamp: base.Add(time.Minute), UnitOfMeasurement: "W", Value: "9", Identifier: "SN-CT-PAGE", Slug: "load_power"},
+	}))
+
+	props, err := s.store.GetPropertyRange(ctx, "SN-CT-PAGE", "pv_power", base.Add(time.Minute), base.Add(3*time.Minute), 2)
+	s.Require().NoError(err)
+	s.Require().Len(props, 2)
+	s.Equal("2", props[0].Value)
+	s.Equal("3", props[1].Value)
+
+	props, err = s.store.GetPropertyRange(ctx, "SN-CT-PAGE", "pv_power", base.Add(time.Minute), base.Add(3*time.Minute), 10)
+	s.Require().NoError(err)
+	s.Require().Len(props, 3, "to is inclusive")
+	s.Equal("4", props[2].Value)
+}
+
+func (s *conformanceSuite) TestWrite_MaintainsRollups() {
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: base.Add(5 * time.Second), UnitOfMeasurement: "kW", Value: "1.5", Identifier: "SN-CT-ROLLUP", Slug: "battery_power"},
+		{Timestamp: base.Add(20 * time.Second), UnitOfMeasurement: "kW", Value: "3.5", Identifier: "SN-CT-ROLLUP", Slug: "battery_power"},
+	}))
+	// Written in a later batch and out of order: Last must follow the timestamp.
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: base.Add(16 * time.Minute), UnitOfMeasurement: "kW", Value: "2.0", Identifier: "SN-CT-ROLLUP", Slug: "battery_power"},
+		{Timestamp: base.Add(10 * time.Second), UnitOfMeasurement: "kW", Value: "0.5", Identifier: "SN-CT-ROLLUP", Slug: "battery_power"},
+	}))
+
+	minutes, err := s.store.GetAggregates(ctx, "SN-CT-ROLLUP", "battery_power", time.Minute, base, base.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(minutes, 2)
+	s.True(base.Equal(minutes[0].BucketStart))
+	s.Equal(3, minutes[0].Count)
+	s.InDelta(0.5, minutes[0].Min, 1e-9)
+	s.InDelta(3.5, minutes[0].Max, 1e-9)
+	s.InDelta(5.5, minutes[0].Sum, 1e-9)
+	s.InDelta(3.5, minutes[0].Last, 1e-9)
+	s.True(base.Add(20 * time.Second).Equal(minutes[0].LastTimeStamp))
+	s.Equal("kW", minutes[0].UnitOfMeasurement)
+
+	hours, err := s.store.GetAggregates(ctx, "SN-CT-ROLLUP", "battery_power", time.Hour, base, base.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(hours, 1)
+	s.Equal(4, hours[0].Count)
+	s.InDelta(7.5, hours[0].Sum, 1e-9)
+	s.InDelta(2.0, hours[0].Last, 1e-9)
+
+	// The range is half-open: a bucket starting at `to` is excluded.
+	none, err := s.store.GetAggregates(ctx, "SN-CT-ROLLUP", "battery_power", 15*time.Minute, base.Add(-time.Hour), base)
+	s.Require().NoError(err)
+	s.Empty(none)
+}
+
+func (s *conformanceSuite) TestGetAggregates_SkipsTextReadings() {
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: base, UnitOfMeasurement: "", Value: "Running", Identifier: "SN-CT-TEXT", Slug: "running_status"},
+		{Timestamp: base, UnitOfMeasurement: "W", Value: "n/a", Identifier: "SN-CT-TEXT", Slug: "load_power"},
+	}))
+
+	for _, slug := range []string{"running_status", "load_power"} {
+		aggs, err := s.store.GetAggregates(ctx, "SN-CT-TEXT", slug, time.Hour, base, base.Add(time.Hour))
+		s.Require().NoError(err)
+		s.Empty(aggs, slug)
+	}
+}
+
+func (s *conformanceSuite) TestCleanup_KeepsRollupsOfDeletedReadings() {
+	ctx := context.Background()
+
+	old := time.Now().UTC().AddDate(0, 0, -10).Truncate(time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: old.Add(time.Minute), UnitOfMeasurement: "W", Value: "10", Identifier: "SN-CT-RETENTION", Slug: "load_power"},
+		{Timestamp: old.Add(2 * time.Minute), UnitOfMeasurement: "W", Value: "20", Identifier: "SN-CT-RETENTION", Slug: "load_power"},
+		{Timestamp: old.Add(time.Minute), UnitOfMeasurement: "W", Value: "5", Identifier: "SN-CT-RETENTION", Slug: "pv_power"},
+	}))
+
+	res, err := s.store.Cleanup(ctx, store.RetentionPolicy{
+		Raw:     8 * 24 * time.Hour,
+		Slugs:   map[string]time.Duration{"pv_power": 0},
+		Rollups: map[time.Duration]time.Duration{time.Minute: 8 * 24 * time.Hour},
+	})
+	s.Require().NoError(err)
+	s.GreaterOrEqual(res.RowsDeleted, int64(2))
+	s.GreaterOrEqual(res.RollupsDeleted, int64(2))
+
+	from, to := old, old.Add(time.Hour)
+	props, err := s.store.GetProperties(ctx, "SN-CT-RETENTION", "load_power", &from, &to)
+	s.Require().NoError(err)
+	s.Empty(props, "expired readings must be deleted")
+
+	kept, err := s.store.GetProperties(ctx, "SN-CT-RETENTION", "pv_power", &from, &to)
+	s.Require().NoError(err)
+	s.Len(kept, 1, "slugs with zero retention are kept forever")
+
+	minutes, err := s.store.GetAggregates(ctx, "SN-CT-RETENTION", "load_power", time.Minute, from, to)
+	s.Require().NoError(err)
+	s.Empty(minutes, "expired rollups must be deleted")
+
+	hours, err := s.store.GetAggregates(ctx, "SN-CT-RETENTION", "load_power", time.Hour, from, to)
+	s.Require().NoError(err)
+	s.Require().Len(hours, 1)
+	s.Equal(2, hours[0].Count)
+	s.InDelta(30.0, hours[0].Sum, 1e-9)
+
+	s.Equal("20", s.latest("SN-CT-RETENTION")["load_power"].Value, "retention must not drop the latest reading")
+}
+
+func (s *conformanceSuite) TestCleanup_EmptyPolicyKeepsEverything() {
+	ctx := context.Background()
+
+	old := time.Now().UTC().AddDate(-1, 0, 0).Truncate(time.Hour)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: old, UnitOfMeasurement: "W", Value: "7", Identifier: "SN-CT-FOREVER", Slug: "load_power"},
+	}))
+
+	_, err := s.store.Cleanup(ctx, store.RetentionPolicy{})
+	s.Require().NoError(err)
+
+	from, to := old, old
+	props, err := s.store.GetProperties(ctx, "SN-CT-FOREVER", "load_power", &from, &to)
+	s.Require().NoError(err)
+	s.Len(props, 1)
+}
+
+func (s *conformanceSuite) TestRegisterDevice() {
+	ctx := context.Background()
+
+	dev := &model.Device{ID: "conformance-device", Model: "TestModel", SerialNumber: "SN-CT-DEVICE"}
+	s.Require().NoError(s.store.RegisterDevice(ctx, dev))
+	s.Require().NoError(s.store.RegisterDevice(ctx, dev), "second upsert must be idempotent")
+}
+
+func (s *conformanceSuite) TestCreateUser() {
+	ctx := context.Background()
+
+	user, err := s.store.CreateUser(ctx, "conformance-user", "$2a$10$placeholder")
+	s.Require().NoError(err)
+	s.Equal("conformance-user", user.Username)
+	s.Equal("$2a$10$placeholder", user.PasswordHash)
+	s.NotZero(user.ID)
+	s.False(user.CreatedAt.IsZero())
+
+	fetched, err := s.store.GetUserByUsername(ctx, user.Username)
+	s.Require().NoError(err)
+	s.Equal(user.ID, fetched.ID)
+	s.Equal(user.PasswordHash, fetched.PasswordHash)
+
+	_, err = s.store.CreateUser(ctx, "conformance-user", "$2a$10$other")
+	s.Error(err, "usernames are unique")
+
+	_, err = s.store.GetUserByUsername(ctx, "conformance-nobody")
+	s.Error(err)
+}
+
+func (s *conformanceSuite) TestRefreshTokens() {
+	ctx := context.Background()
+
+	user, err := s.store.CreateUser(ctx, "conformance-token-user", "$2a$10$placeholder")
+	s.Require().NoError(err)
+
+	params := store.StoreRefreshTokenParams{
+		TokenHash: "conformance-token",
+		UserID:    user.ID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond),
+	}
+	s.Require().NoError(s.store.StoreRefreshToken(ctx, params))
+
+	tok, err := s.store.GetRefreshToken(ctx, params.TokenHash)
+	s.Require().NoError(err)
+	s.Equal(user.ID, tok.UserID)
+	s.Equal(user.Username, tok.Username)
+	s.True(params.ExpiresAt.Equal(tok.ExpiresAt))
+
+	// Storing the same hash again extends the expiry.
+	params.ExpiresAt = params.ExpiresAt.Add(time.Hour)
+	s.Require().NoError(s.store.StoreRefreshToken(ctx, params))
+	tok, err = s.store.GetRefreshToken(ctx, params.TokenHash)
+	s.Require().NoError(err)
+	s.True(params.ExpiresAt.Equal(tok.ExpiresAt))
+
+	s.Require().NoError(s.store.DeleteRefreshToken(ctx, params.TokenHash))
+	_, err = s.store.GetRefreshToken(ctx, params.TokenHash)
+	s.Error(err, "deleted tokens cannot be fetched")
+}
+
+func (s *conformanceSuite) TestDeleteExpiredRefreshTokens() {
+	ctx := context.Background()
+
+	user, err := s.store.CreateUser(ctx, "conformance-expiry-user", "$2a$10$placeholder")
+	s.Require().NoError(err)
+
+	for hash, expires := range map[string]time.Time{
+		"conformance-expired": time.Now().Add(-time.Hour),
+		"conformance-valid":   time.Now().Add(time.Hour),
+	} {
+		s.Require().NoError(s.store.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+			TokenHash: hash,
+			UserID:    user.ID,
+			Username:  user.Username,
+			ExpiresAt: expires,
+		}))
+	}
+
+	s.Require().NoError(s.store.DeleteExpiredRefreshTokens(ctx))
+
+	_, err = s.store.GetRefreshToken(ctx, "conformance-expired")
+	s.Error(err, "expired tokens must be deleted")
+	_, err = s.store.GetRefreshToken(ctx, "conformance-valid")
+	s.NoError(err, "unexpired tokens must be kept")
+}
+
+func (s *conformanceSuite) TestRecordCommand_and_GetCommands() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	cmds := []store.Command{
+		{RequestedAt: now.Add(-2 * time.Minute), Source: "api", User: "conformance-cmd-user", Command: "charge", Params: map[string]string{"power": "2.5"}, Success: true, Latency: 120 * time.Millisecond},
+		{RequestedAt: now.Add(-time.Minute), Source: "api", User: "conformance-cmd-user", Command: "self_consumption", Success: false, ResultCode: 2, Error: "timed out", Latency: 10 * time.Second},
+		{RequestedAt: now, Source: "automation", User: "conformance-cmd-user", Command: "feed_in_limitation", Params: map[string]string{"limited": "true"}, Success: true, Latency: 80 * time.Millisecond},
+	}
+	for _, c := range cmds {
+		s.Require().NoError(s.store.RecordCommand(ctx, c))
+	}
+
+	got, err := s.store.GetCommands(ctx, store.CommandFilter{User: "conformance-cmd-user"})
+	s.Require().NoError(err)
+	s.Require().Len(got, 3)
+	s.Equal("feed_in_limitation", got[0].Command, "newest first")
+	s.Equal("self_consumption", got[1].Command)
+	s.Equal("charge", got[2].Command)
+	s.NotZero(got[0].ID)
+
+	charge := got[2]
+	s.True(cmds[0].RequestedAt.Equal(charge.RequestedAt))
+	s.Equal("api", charge.Source)
+	s.Equal(map[string]string{"power": "2.5"}, charge.Params)
+	s.True(charge.Success)
+	s.Equal(120*time.Millisecond, charge.Latency)
+
+	failed := got[1]
+	s.False(failed.Success)
+	s.Equal(2, failed.ResultCode)
+	s.Equal("timed out", failed.Error)
+	s.Empty(failed.Params)
+
+	got, err = s.store.GetCommands(ctx, store.CommandFilter{User: "conformance-cmd-user", Source: "automation"})
+	s.Require().NoError(err)
+	s.Require().Len(got, 1)
+	s.Equal("feed_in_limitation", got[0].Command)
+
+	got, err = s.store.GetCommands(ctx, store.CommandFilter{User: "conformance-cmd-user", Command: "charge"})
+	s.Require().NoError(err)
+	s.Require().Len(got, 1)
+	s.Equal("charge", got[0].Command)
+
+	got, err = s.store.GetCommands(ctx, store.CommandFilter{User: "conformance-cmd-user", Limit: 2})
+	s.Require().NoError(err)
+	s.Require().Len(got, 2, "limit keeps the newest entries")
+	s.Equal("feed_in_limitation", got[0].Command)
+	s.Equal("self_consumption", got[1].Command)
+}
+
+func (s *conformanceSuite) TestGetCommands_RangeIsInclusive() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	from, to := now.Add(-2*time.Minute), now.Add(-time.Minute)
+	for _, at := range []time.Time{now.Add(-3 * time.Minute), from, to, now} {
+		s.Require().NoError(s.store.RecordCommand(ctx, store.Command{
+			RequestedAt: at,
+			Source:      "api",
+			User:        "conformance-cmd-range",
+			Command:     "battery_stop",
+			Success:     true,
+		}))
+	}
+
+	got, err := s.store.GetCommands(ctx, store.CommandFilter{User: "conformance-cmd-range", From: &from, To: &to})
+	s.Require().NoError(err)
+	s.Require().Len(got, 2)
+	s.True(to.Equal(got[0].RequestedAt))
+	s.True(from.Equal(got[1].RequestedAt))
+}
+
+func (s *conformanceSuite) TestSaveInverterState_and_GetInverterStates() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.SaveInverterState(ctx, store.InverterState{
+		ID: "SN-CT-INV-A", State: "ON", BatteryState: "CHARGING", ChargeRate: 6.6, UpdatedAt: now.Add(-time.Hour),
+	}))
+	s.Require().NoError(s.store.SaveInverterState(ctx, store.InverterState{
+		ID: "SN-CT-INV-B", State: "ON", BatteryState: "SELF_CONSUMPTION", FeedinEnabled: true, UpdatedAt: now.Add(-time.Minute),
+	}))
+	// Saving again replaces the row.
+	s.Require().NoError(s.store.SaveInverterState(ctx, store.InverterState{
+		ID: "SN-CT-INV-A", State: "OFF", BatteryState: "STOPPED", ChargeRate: 0, UpdatedAt: now, ReconciledAt: now,
+	}))
+
+	states, err := s.store.GetInverterStates(ctx)
+	s.Require().NoError(err)
+	var got []store.InverterState
+	for _, st := range states {
+		if st.ID == "SN-CT-INV-A" || st.ID == "SN-CT-INV-B" {
+			got = append(got, st)
+		}
+	}
+	s.Require().Len(got, 2, "one row per inverter")
+
+	a, b := got[0], got[1]
+	s.Equal("SN-CT-INV-A", a.ID, "most recently updated first")
+	s.Equal("OFF", a.State)
+	s.Equal("STOPPED", a.BatteryState)
+	s.Zero(a.ChargeRate)
+	s.False(a.FeedinEnabled)
+	s.True(now.Equal(a.UpdatedAt))
+	s.True(now.Equal(a.ReconciledAt))
+
+	s.Equal("SN-CT-INV-B", b.ID)
+	s.Equal("SELF_CONSUMPTION", b.BatteryState)
+	s.True(b.FeedinEnabled)
+	s.True(b.ReconciledAt.IsZero(), "never reconciled")
+}
+
+func (s *conformanceSuite) TestBulkExport() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.store.RegisterDevice(ctx, &model.Device{ID: "SN-CT-BULK", Model: "TestModel", SerialNumber: "SN-CT-BULK-SERIAL"}))
+	devices, err := s.store.ListDevices(ctx)
+	s.Require().NoError(err)
+	s.Contains(devices, model.Device{ID: "SN-CT-BULK", Model: "TestModel", SerialNumber: "SN-CT-BULK-SERIAL"})
+
+	before, err := s.store.CountProperties(ctx)
+	s.Require().NoError(err)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now.Add(-2 * time.Minute), UnitOfMeasurement: "W", Value: "1", Identifier: "SN-CT-BULK", Slug: "load_power"},
+		{Timestamp: now.Add(-time.Minute), UnitOfMeasurement: "W", Value: "2", Identifier: "SN-CT-BULK", Slug: "load_power"},
+		{Timestamp: now, UnitOfMeasurement: "", Value: "Running", Identifier: "SN-CT-BULK", Slug: "running_status"},
+	}))
+	after, err := s.store.CountProperties(ctx)
+	s.Require().NoError(err)
+	s.Equal(before+3, after)
+
+	// Page through everything; IDs must rise strictly across pages.
+	var got []store.Property
+	lastID := 0
+	for {
+		page, err := s.store.ScanProperties(ctx, lastID, 2)
+		s.Require().NoError(err)
+		s.LessOrEqual(len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		for _, p := range page {
+			s.Greater(p.ID, lastID)
+			lastID = p.ID
+			if p.Identifier == "SN-CT-BULK" {
+				got = append(got, p)
+			}
+		}
+	}
+	s.Require().Len(got, 3)
+	values := map[string]bool{}
+	for _, p := range got {
+		values[p.Value] = true
+	}
+	s.Equal(map[string]bool{"1": true, "2": true, "Running": true}, values)
+
+	user, err := s.store.CreateUser(ctx, "conformance-bulk-user", "$2a$10$placeholder")
+	s.Require().NoError(err)
+	expires := now.Add(time.Hour)
+	s.Require().NoError(s.store.StoreRefreshToken(ctx, store.StoreRefreshTokenParams{
+		TokenHash: "conformance-bulk-token", UserID: user.ID, Username: user.Username, ExpiresAt: expires,
+	}))
+
+	users, err := s.store.ListUsers(ctx)
+	s.Require().NoError(err)
+	var foundUser bool
+	for _, u := range users {
+		if u.Username == user.Username {
+			foundUser = true
+			s.Equal(user.ID, u.ID)
+			s.Equal(user.PasswordHash, u.PasswordHash)
+		}
+	}
+	s.True(foundUser)
+
+	tokens, err := s.store.ListRefreshTokens(ctx)
+	s.Require().NoError(err)
+	var foundToken bool
+	for _, tok := range tokens {
+		if tok.TokenHash == "conformance-bulk-token" {
+			foundToken = true
+			s.Equal(user.ID, tok.UserID)
+			s.True(expires.Equal(tok.ExpiresAt))
+		}
+	}
+	s.True(foundToken)
+}
+
+func (s *conformanceSuite) TestScanCommands() {
+	ctx := context.Background()
+
+	before, err := s.store.CountCommands(ctx)
+	s.Require().NoError(err)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for _, c := range []string{"charge", "discharge", "self_consumption"} {
+		s.Require().NoError(s.store.RecordCommand(ctx, store.Command{
+			RequestedAt: now, Source: "api", User: "conformance-scan-user", Command: c,
+			Params: map[string]string{"power": "1"}, Success: true, Latency: time.Second,
+		}))
+	}
+	after, err := s.store.CountCommands(ctx)
+	s.Require().NoError(err)
+	s.Equal(before+3, after)
+
+	var got []store.Command
+	lastID := 0
+	for {
+		page, err := s.store.ScanCommands(ctx, lastID, 2)
+		s.Require().NoError(err)
+		s.LessOrEqual(len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		for _, c := range page {
+			s.Greater(c.ID, lastID)
+			lastID = c.ID
+			if c.User == "conformance-scan-user" {
+				got = append(got, c)
+			}
+		}
+	}
+	s.Require().Len(got, 3)
+	s.Equal("charge", got[0].Command, "recorded order")
+	s.Equal("self_consumption", got[2].Command)
+	s.True(now.Equal(got[0].RequestedAt))
+	s.Equal(map[string]string{"power": "1"}, got[0].Params)
+	s.Equal(time.Second, got[0].Latency)
+}
+
+func (s *conformanceSuite) TestImportRollups_and_ScanRollups() {
+	ctx := context.Background()
+
+	before, err := s.store.CountRollups(ctx)
+	s.Require().NoError(err)
+	base := time.Now().UTC().Truncate(time.Hour).Add(-48 * time.Hour)
+	bucket := func(res time.Duration, start time.Time, sum float64) store.Rollup {
+		return store.Rollup{
+			Identifier: "SN-CT-IMPORT", Slug: "load_power", Resolution: res,
+			Aggregate: store.Aggregate{
+				BucketStart: start, UnitOfMeasurement: "W", Min: 1, Max: 3, Sum: sum, Count: 3, Last: 2,
+				LastTimeStamp: start.Add(30 * time.Second),
+			},
+		}
+	}
+	s.Require().NoError(s.store.ImportRollups(ctx, []store.Rollup{
+		bucket(time.Minute, base, 6),
+		bucket(time.Minute, base.Add(time.Minute), 6),
+		bucket(time.Hour, base, 12),
+	}))
+	// Importing a bucket again replaces it rather than folding into it.
+	s.Require().NoError(s.store.ImportRollups(ctx, []store.Rollup{bucket(time.Minute, base, 9)}))
+
+	after, err := s.store.CountRollups(ctx)
+	s.Require().NoError(err)
+	s.Equal(before+3, after)
+
+	minutes, err := s.store.GetAggregates(ctx, "SN-CT-IMPORT", "load_power", time.Minute, base, base.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(minutes, 2)
+	s.InDelta(9, minutes[0].Sum, 1e-9)
+	s.Equal(3, minutes[0].Count)
+	s.True(base.Add(30 * time.Second).Equal(minutes[0].LastTimeStamp))
+	s.Equal("W", minutes[0].UnitOfMeasurement)
+
+	// Page through everything; the order must rise strictly across pages.
+	var (
+		got  []store.Rollup
+		last store.Rollup
+	)
+	for {
+		page, err := s.store.ScanRollups(ctx, last, 2)
+		s.Require().NoError(err)
+		s.LessOrEqual(len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		for _, r := range page {
+			s.Positive(store.CompareRollups(r, last), "%+v after %+v", r, last)
+			last = r
+			if r.Identifier == "SN-CT-IMPORT" {
+				got = append(got, r)
+			}
+		}
+	}
+	s.Require().Len(got, 3)
+	s.Equal(time.Minute, got[0].Resolution)
+	s.True(base.Equal(got[0].BucketStart))
+	s.InDelta(9, got[0].Sum, 1e-9)
+	s.True(base.Add(time.Minute).Equal(got[1].BucketStart))
+	s.Equal(time.Hour, got[2].Resolution)
+	s.InDelta(12, got[2].Sum, 1e-9)
+}
+
+func (s *conformanceSuite) TestImportSensors() {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.store.Write(ctx, []publisher.DataPoint{
+		{Timestamp: now, UnitOfMeasurement: "W", Value: "150", Identifier: "SN-CT-IMPORT-SENSORS", Slug: "load_power", Name: "Load Power"},
+	}))
+	s.Require().NoError(s.store.ImportSensors(ctx, []store.Sensor{
+		{
+			Identifier: "SN-CT-IMPORT-SENSORS", Slug: "load_power", Name: "Old Name", UnitOfMeasurement: "kW",
+			FirstSeen: now.Add(-2 * time.Hour), LastSeen: now.Add(-time.Hour), LastValue: "1",
+		},
+		{
+			Identifier: "SN-CT-IMPORT-SENSORS", Slug: "running_status", Name: "Running Status",
+			FirstSeen: now.Add(-2 * time.Hour), LastSeen: now.Add(-time.Hour), LastValue: "Running",
+		},
+	}))
+
+	sensors := s.sensors("SN-CT-IMPORT-SENSORS")
+	s.Require().Len(sensors, 2)
+	load := sensors["load_power"]
+	s.Equal("Load Power", load.Name, "the newer reading wins")
+	s.Equal("W", load.UnitOfMeasurement)
+	s.Equal("150", load.LastValue)
+	s.True(now.Add(-2*time.Hour).Equal(load.FirstSeen), "first seen %s", load.FirstSeen)
+	s.True(now.Equal(load.LastSeen), "last seen %s", load.LastSeen)
+
+	status := sensors["running_status"]
+	s.Equal("Running Status", status.Name)
+	s.Empty(status.UnitOfMeasurement)
+	s.Equal("Running", status.LastValue)
+	s.True(now.Add(-2 * time.Hour).Equal(status.FirstSeen))
+	s.True(now.Add(-time.Hour).Equal(status.LastSeen))
+
+	latest := s.latest("SN-CT-IMPORT-SENSORS")
+	s.Equal("Running", latest["running_status"].Value)
+}
@@ -0,0 +1,162 @@
+// Package stream fans published readings out to live subscribers, such as
+// the HTTP server's Server-Sent Events and WebSocket endpoints. Hub is a
+// publisher.Publisher, so it receives each normalised, deduplicated batch
+// from the MultiPublisher as it is published, without touching the database.
+package stream
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+// bufferSize is how many batches a subscriber may fall behind before it is
+// disconnected. Readings arrive every poll interval, so this is minutes of
+// slack for a stalled client.
+const bufferSize = 16
+
+// ErrClosed is returned by Subscribe once the hub has shut down.
+var ErrClosed = errors.New("stream: hub closed")
+
+var _ publisher.Publisher = (*Hub)(nil)
+
+// Hub broadcasts every written batch to its subscribers.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+	logger *zap.Logger
+}
+
+// New returns an empty Hub. Call Run to close subscriptions on shutdown.
+func New() *Hub {
+	return &Hub{subs: map[*Subscription]struct{}{}, logger: zap.L()}
+}
+
+// Name implements the optional publisher name used in routes and metrics.
+func (h *Hub) Name() string { return "stream" }
+
+// Filter restricts a subscription to some identifiers and slugs. An empty
+// list matches everything.
+type Filter struct {
+	Identifiers []string
+	Slugs       []string
+}
+
+// Match reports whether dp passes the filter.
+func (f Filter) Match(dp publisher.DataPoint) bool {
+	return (len(f.Identifiers) == 0 || slices.Contains(f.Identifiers, dp.Identifier)) &&
+		(len(f.Slugs) == 0 || slices.Contains(f.Slugs, dp.Slug))
+}
+
+// Subscription receives the batches matching its filter.
+type Subscription struct {
+	hub    *Hub
+	filter Filter
+	c      chan []publisher.DataPoint
+	lagged bool // guarded by hub.mu
+}
+
+// C delivers matching batches. It is closed when the subscription ends:
+// after Close, when the hub shuts down, or when the subscriber fell too far
+// behind. Batches are shared between subscribers and must not be modified.
+func (s *Subscription) C() <-chan []publisher.DataPoint { return s.c }
+
+// Lagged reports whether the subscription was ended because the subscriber
+// did not keep up.
+func (s *Subscription) Lagged() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.lagged
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe starts a subscription to readings matching f.
+func (h *Hub) Subscribe(f Filter) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	sub := &Subscription{hub: h, filter: f, c: make(chan []publisher.DataPoint, bufferSize)}
+	h.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Subscribers returns the number of open subscriptions.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Write implements publisher.Publisher. It never blocks: a subscriber whose
+// buffer is full is disconnected, so it can reconnect and reload the latest
+// readings instead of silently missing some.
+func (h *Hub) Write(_ context.Context, data []publisher.DataPoint) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		batch := data
+		if len(sub.filter.Identifiers) > 0 || len(sub.filter.Slugs) > 0 {
+			batch = nil
+			for _, dp := range data {
+				if sub.filter.Match(dp) {
+					batch = append(batch, dp)
+				}
+			}
+		}
+		if len(batch) == 0 {
+			continue
+		}
+		select {
+		case sub.c <- batch:
+		default:
+			h.logger.Warn("stream: subscriber fell behind; disconnecting it")
+			sub.lagged = true
+			h.remove(sub)
+		}
+	}
+	return nil
+}
+
+// RegisterDevice implements publisher.Publisher. Subscribers only receive
+// readings.
+func (h *Hub) RegisterDevice(context.Context, *model.Device) error {
+	return nil
+}
+
+// Run waits for ctx to be cancelled, then ends every subscription and
+// refuses new ones, so long-lived HTTP streams finish and let the server
+// shut down.
+func (h *Hub) Run(ctx context.Context) error {
+	<-ctx.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+	return ctx.Err()
+}
+
+// remove ends sub. h.mu must be held.
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.c)
+}
@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+func point(identifier, slug, value string) publisher.DataPoint {
+	return publisher.DataPoint{
+		Timestamp: time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), Identifier: identifier, Slug: slug, Value: value, UnitOfMeasurement: "W",
+	}
+}
+
+func TestHub_BroadcastsToEverySubscriber(t *testing.T) {
+	h := New()
+	a, err := h.Subscribe(Filter{})
+	require.NoError(t, err)
+	b, err := h.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	batch := []publisher.DataPoint{point("SN-1", "load_power", "100")}
+	require.NoError(t, h.Write(context.Background(), batch))
+
+	assert.Equal(t, batch, <-a.C())
+	assert.Equal(t, batch, <-b.C())
+}
+
+func TestHub_Filters(t *testing.T) {
+	h := New()
+	sub, err := h.Subscribe(Filter{Identifiers: []string{"SN-1"}, Slugs: []string{"load_power", "pv_power"}})
+	require.NoError(t, err)
+
+	require.NoError(t, h.Write(context.Background(), []publisher.DataPoint{
+		point("SN-1", "load_power", "100"),
+		point("SN-1", "battery_level", "80"),
+		point("SN-2", "load_power", "200"),
+		point("SN-1", "pv_power", "3000"),
+	}))
+	// Nothing matches: no empty batch is sent.
+	require.NoError(t, h.Write(context.Background(), []publisher.DataPoint{point("SN-2", "pv_power", "1")}))
+
+	assert.Equal(t, []publisher.DataPoint{point("SN-1", "load_power", "100"), point("SN-1", "pv_power", "3000")}, <-sub.C())
+	assert.Empty(t, sub.C())
+}
+
+func TestHub_DisconnectsSlowSubscriber(t *testing.T) {
+	h := New()
+	slow, err := h.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	for range bufferSize + 1 {
+		require.NoError(t, h.Write(context.Background(), []publisher.DataPoint{point("SN-1", "load_power", "1")}))
+	}
+
+	n := 0
+	for range slow.C() {
+		n++
+	}
+	assert.Equal(t, bufferSize, n, "buffered batches are still delivered before the channel closes")
+	assert.True(t, slow.Lagged())
+	assert.Zero(t, h.Subscribers())
+}
+
+func TestSubscription_Close(t *testing.T) {
+	h := New()
+	sub, err := h.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	sub.Close()
+	sub.Close()
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+	assert.False(t, sub.Lagged())
+	require.NoError(t, h.Write(context.Background(), []publisher.DataPoint{point("SN-1", "load_power", "1")}))
+}
+
+func TestHub_RunClosesSubscriptionsOnShutdown(t *testing.T) {
+	h := New()
+	sub, err := h.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, h.Run(ctx), context.Canceled)
+
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+	_, err = h.Subscribe(Filter{})
+	assert.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,103 @@
+// Package tariff prices grid energy for the energy reports: a flat or
+// time-of-use import rate, a flat feed-in credit and a daily supply charge.
+package tariff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+)
+
+const minutesPerDay = 24 * 60
+
+// Tariff is a parsed config.TariffConfig. Rates are per kWh.
+type Tariff struct {
+	Currency    string
+	ExportRate  float64
+	DailyCharge float64
+
+	loc *time.Location
+	// importRates holds the import rate for each minute of the local day.
+	importRates [minutesPerDay]float64
+}
+
+// New returns the tariff described by cfg. Time-of-use windows are read in
+// loc.
+func New(cfg *config.TariffConfig, loc *time.Location) (*Tariff, error) {
+	if cfg.ImportRate < 0 || cfg.ExportRate < 0 || cfg.DailyCharge < 0 {
+		return nil, errors.New("TARIFF_IMPORT_RATE, TARIFF_EXPORT_RATE and TARIFF_DAILY_CHARGE must not be negative")
+	}
+	t := &Tariff{
+		Currency:    cfg.Currency,
+		ExportRate:  cfg.ExportRate,
+		DailyCharge: cfg.DailyCharge,
+		loc:         loc,
+	}
+	for m := range t.importRates {
+		t.importRates[m] = cfg.ImportRate
+	}
+	var taken [minutesPerDay]string
+	for window, rate := range cfg.ImportPeriods {
+		start, end, err := parseWindow(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q in TARIFF_IMPORT_PERIODS: %w", window, err)
+		}
+		if rate < 0 {
+			return nil, fmt.Errorf("rate for %q in TARIFF_IMPORT_PERIODS must not be negative", window)
+		}
+		length := end - start
+		if length <= 0 {
+			length += minutesPerDay
+		}
+		for i := range length {
+			m := (start + i) % minutesPerDay
+			if taken[m] != "" {
+				return nil, fmt.Errorf("windows %q and %q in TARIFF_IMPORT_PERIODS overlap", taken[m], window)
+			}
+			taken[m] = window
+			t.importRates[m] = rate
+		}
+	}
+	return t, nil
+}
+
+// ImportRate returns the price of energy imported at ts.
+func (t *Tariff) ImportRate(ts time.Time) float64 {
+	local := ts.In(t.loc)
+	return t.importRates[local.Hour()*60+local.Minute()]
+}
+
+// parseWindow parses "HH:MM-HH:MM" into minutes of the day. The end is
+// exclusive and may be earlier than the start for a window crossing midnight.
+func parseWindow(window string) (start, end int, err error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, errors.New("want HH:MM-HH:MM")
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, errors.New("window is empty")
+	}
+	return start, end, nil
+}
+
+// parseClock parses HH:MM, accepting 24:00 as the end of the day.
+func parseClock(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" {
+		return minutesPerDay, nil
+	}
+	c, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return c.Hour()*60 + c.Minute(), nil
+}
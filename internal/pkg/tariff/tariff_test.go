@@ -0,0 +1,57 @@
+package tariff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+)
+
+func TestImportRate_TimeOfUse(t *testing.T) {
+	loc, err := time.LoadLocation("Australia/Adelaide")
+	require.NoError(t, err)
+	tr, err := New(&config.TariffConfig{
+		ImportRate:    0.30,
+		ImportPeriods: map[string]float64{"16:00-21:00": 0.45, "22:00-07:00": 0.18},
+	}, loc)
+	require.NoError(t, err)
+
+	at := func(hour, minute int) time.Time {
+		// Readings arrive in UTC; windows are local.
+		return time.Date(2026, 1, 15, hour, minute, 0, 0, loc).UTC()
+	}
+	assert.InDelta(t, 0.30, tr.ImportRate(at(12, 0)), 1e-9)
+	assert.InDelta(t, 0.45, tr.ImportRate(at(16, 0)), 1e-9)
+	assert.InDelta(t, 0.45, tr.ImportRate(at(20, 59)), 1e-9)
+	assert.InDelta(t, 0.30, tr.ImportRate(at(21, 0)), 1e-9, "the end is exclusive")
+	assert.InDelta(t, 0.18, tr.ImportRate(at(23, 30)), 1e-9)
+	assert.InDelta(t, 0.18, tr.ImportRate(at(3, 0)), 1e-9, "windows cross midnight")
+	assert.InDelta(t, 0.30, tr.ImportRate(at(7, 0)), 1e-9)
+}
+
+func TestNew_WholeDayWindow(t *testing.T) {
+	tr, err := New(&config.TariffConfig{ImportPeriods: map[string]float64{"00:00-24:00": 0.25}}, time.UTC)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.25, tr.ImportRate(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)), 1e-9)
+	assert.InDelta(t, 0.25, tr.ImportRate(time.Date(2026, 1, 1, 23, 59, 0, 0, time.UTC)), 1e-9)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]config.TariffConfig{
+		"negative rate":   {ImportRate: -0.1},
+		"negative period": {ImportPeriods: map[string]float64{"16:00-21:00": -1}},
+		"malformed":       {ImportPeriods: map[string]float64{"16:00": 0.4}},
+		"bad time":        {ImportPeriods: map[string]float64{"16:00-25:00": 0.4}},
+		"empty window":    {ImportPeriods: map[string]float64{"16:00-16:00": 0.4}},
+		"overlap":         {ImportPeriods: map[string]float64{"16:00-21:00": 0.4, "20:00-23:00": 0.3}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(&cfg, time.UTC)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	retryBase      = time.Second
+	retryMax       = time.Minute
+
+	// HeaderEvent carries the envelope type.
+	HeaderEvent = "X-Winet-Event"
+	// HeaderTimestamp carries the Unix time (seconds) the request was signed.
+	HeaderTimestamp = "X-Winet-Timestamp"
+	// HeaderSignature carries "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+	HeaderSignature = "X-Winet-Signature"
+)
+
+var errPermanent = errors.New("webhook: permanent delivery failure")
+
+type sender struct {
+	client     *http.Client
+	secret     []byte
+	maxRetries int
+	retryBase  time.Duration
+	now        func() time.Time
+}
+
+func newSender(secret string, maxRetries int) *sender {
+	return &sender{
+		client:     &http.Client{Timeout: requestTimeout},
+		secret:     []byte(secret),
+		maxRetries: maxRetries,
+		retryBase:  retryBase,
+		now:        time.Now,
+	}
+}
+
+// Sign returns the X-Winet-Signature value for body signed at timestamp.
+// Receivers recompute it with the shared secret to authenticate the request.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send POSTs body to url, retrying network errors, 429 and 5xx responses with
+// exponential backoff. Each attempt is signed with a fresh timestamp.
+func (s *sender) send(ctx context.Context, url, event string, body []byte) error {
+	var err error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(min(s.retryBase*(1<<min(attempt-1, 10)), retryMax)):
+			}
+		}
+		if err = s.post(ctx, url, event, body); err == nil || errors.Is(err, errPermanent) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *sender) post(ctx context.Context, url, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	ts := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderTimestamp, ts)
+	if len(s.secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(s.secret, ts, body))
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	switch {
+	case res.StatusCode/100 == 2:
+		return nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode/100 == 5:
+		return fmt.Errorf("webhook: %s returned %s", url, res.Status)
+	default:
+		return fmt.Errorf("%w: %s returned %s", errPermanent, url, res.Status)
+	}
+}
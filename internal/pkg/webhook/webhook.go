@@ -0,0 +1,305 @@
+// Package webhook is an outbound HTTP publisher.Publisher backend.
+// Readings are batched over a configurable window and POSTed as JSON to every
+// configured URL; device registrations and inverter commands are delivered as
+// separate event types. Requests are optionally HMAC-signed and retried with
+// exponential backoff; a batch that still fails is requeued for the next
+// window a bounded number of times.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+// Event types, also accepted in WEBHOOK_EVENTS.
+const (
+	EventReadings = "readings"
+	EventDevice   = "device"
+	EventCommand  = "command"
+)
+
+const (
+	queueSize = 256
+	// maxPendingReadings bounds the batch buffer if deliveries fall behind.
+	maxPendingReadings = 10_000
+	// maxRequeues is how many later batch windows retry a delivery that
+	// failed every attempt; maxFailed bounds how many wait for it.
+	maxRequeues = 3
+	maxFailed   = queueSize
+)
+
+// Envelope is the JSON body of every webhook request.
+type Envelope struct {
+	Type      string              `json:"type"`
+	Timestamp time.Time           `json:"timestamp"`
+	Readings  []Reading           `json:"readings,omitempty"`
+	Device    *Device             `json:"device,omitempty"`
+	Command   *model.CommandEvent `json:"command,omitempty"`
+}
+
+// Reading is the wire form of a publisher.DataPoint.
+type Reading struct {
+	Identifier        string    `json:"identifier"`
+	Slug              string    `json:"slug"`
+	Value             string    `json:"value"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// Device is the wire form of a model.Device.
+type Device struct {
+	ID           string `json:"id"`
+	Model        string `json:"model"`
+	SerialNumber string `json:"serial_number"`
+}
+
+// delivery is an envelope bound for one URL.
+type delivery struct {
+	url      string
+	event    string
+	body     []byte
+	requeues int
+}
+
+type service struct {
+	cfg       *config.WebhookConfig
+	sender    *sender
+	logger    *zap.Logger
+	queue     chan Envelope
+	flushes   chan struct{} // batch window ticks, for the delivery worker
+	devicesMu sync.Mutex
+	devices   map[string]struct{} // device IDs already announced
+
+	mu      sync.Mutex
+	pending []Reading
+
+	failed []delivery // only touched by the delivery worker
+}
+
+// compile-time check that *service satisfies publisher.Publisher.
+var _ publisher.Publisher = (*service)(nil)
+
+// New returns a webhook publisher. Call Run to start batching and delivery.
+func New(cfg *config.WebhookConfig) (*service, error) {
+	if cfg.BatchWindow <= 0 {
+		return nil, fmt.Errorf("WEBHOOK_BATCH_WINDOW must be positive, got %s", cfg.BatchWindow)
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, fmt.Errorf("WEBHOOK_MAX_RETRIES must not be negative, got %d", cfg.MaxRetries)
+	}
+	return &service{
+		cfg:     cfg,
+		sender:  newSender(cfg.Secret, cfg.MaxRetries),
+		logger:  zap.L(),
+		queue:   make(chan Envelope, queueSize),
+		flushes: make(chan struct{}, 1),
+		devices: make(map[string]struct{}),
+	}, nil
+}
+
+// Name identifies the backend in logs and metrics.
+func (s *service) Name() string { return "webhook" }
+
+// Write adds matching readings to the current batch window.
+func (s *service) Write(_ context.Context, data []publisher.DataPoint) error {
+	if !s.wants(EventReadings) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dp := range data {
+		if !s.matchSlug(dp.Slug) {
+			continue
+		}
+		s.pending = append(s.pending, Reading{
+			Identifier:        dp.Identifier,
+			Slug:              dp.Slug,
+			Value:             dp.Value,
+			UnitOfMeasurement: dp.UnitOfMeasurement,
+			Timestamp:         dp.Timestamp,
+		})
+	}
+	if over := len(s.pending) - maxPendingReadings; over > 0 {
+		s.logger.Warn("webhook: reading buffer full, dropping oldest", zap.Int("dropped", over))
+		s.pending = s.pending[over:]
+	}
+	return nil
+}
+
+// RegisterDevice emits a device event the first time each device is seen.
+func (s *service) RegisterDevice(_ context.Context, device *model.Device) error {
+	if !s.wants(EventDevice) {
+		return nil
+	}
+	s.devicesMu.Lock()
+	_, seen := s.devices[device.ID]
+	s.devices[device.ID] = struct{}{}
+	s.devicesMu.Unlock()
+	if seen {
+		return nil
+	}
+	s.enqueue(Envelope{
+		Type:      EventDevice,
+		Timestamp: time.Now(),
+		Device:    &Device{ID: device.ID, Model: device.Model, SerialNumber: device.SerialNumber},
+	})
+	return nil
+}
+
+// CommandHook emits a command event. It is non-blocking and suitable for
+// winet's SetCommandHook.
+func (s *service) CommandHook(event model.CommandEvent) {
+	if !s.wants(EventCommand) {
+		return
+	}
+	s.enqueue(Envelope{Type: EventCommand, Timestamp: time.Now(), Command: &event})
+}
+
+// Run flushes the reading batch every BatchWindow and delivers queued events
+// until ctx is cancelled. Deliveries run on a worker, so a slow endpoint
+// delays later batches without holding up the window; readings keep
+// accumulating meanwhile. Pending readings, queued events and requeued
+// deliveries are given a final delivery attempt on shutdown.
+func (s *service) Run(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.work(ctx)
+	}()
+
+	ticker := time.NewTicker(s.cfg.BatchWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			s.drain()
+			return ctx.Err()
+		case <-ticker.C:
+			select {
+			case s.flushes <- struct{}{}:
+			default: // the worker has a flush pending already
+			}
+		}
+	}
+}
+
+// work delivers queued events as they arrive and, on each batch window, the
+// requeued deliveries and the reading batch.
+func (s *service) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env := <-s.queue:
+			s.deliver(ctx, env, true)
+		case <-s.flushes:
+			s.flush(ctx, true)
+		}
+	}
+}
+
+// flush retries the requeued deliveries, then sends the reading batch.
+func (s *service) flush(ctx context.Context, requeue bool) {
+	failed := s.failed
+	s.failed = nil
+	for _, d := range failed {
+		s.send(ctx, d, requeue)
+	}
+	if env, ok := s.takeReadings(); ok {
+		s.deliver(ctx, env, requeue)
+	}
+}
+
+func (s *service) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	for {
+		select {
+		case env := <-s.queue:
+			s.deliver(ctx, env, false)
+		default:
+			s.flush(ctx, false)
+			return
+		}
+	}
+}
+
+func (s *service) takeReadings() (Envelope, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return Envelope{}, false
+	}
+	env := Envelope{Type: EventReadings, Timestamp: time.Now(), Readings: s.pending}
+	s.pending = nil
+	return env, true
+}
+
+func (s *service) enqueue(env Envelope) {
+	select {
+	case s.queue <- env:
+	default:
+		s.logger.Warn("webhook: event queue full, dropping event", zap.String("type", env.Type))
+	}
+}
+
+// deliver sends the envelope to every URL independently; one failing
+// endpoint does not prevent delivery to the others.
+func (s *service) deliver(ctx context.Context, env Envelope, requeue bool) {
+	body, err := json.Marshal(env)
+	if err != nil {
+		s.logger.Error("webhook: marshal event", zap.String("type", env.Type), zap.Error(err))
+		return
+	}
+	for _, u := range s.cfg.URLs {
+		s.send(ctx, delivery{url: u, event: env.Type, body: body}, requeue)
+	}
+}
+
+// send delivers d. When every attempt fails with a retryable error and
+// requeue is set, d is kept for the next batch window, up to maxRequeues
+// times.
+func (s *service) send(ctx context.Context, d delivery, requeue bool) {
+	err := s.sender.send(ctx, d.url, d.event, d.body)
+	if err == nil {
+		return
+	}
+	if !requeue || errors.Is(err, errPermanent) || d.requeues >= maxRequeues || len(s.failed) >= maxFailed {
+		s.logger.Error("webhook: delivery failed", zap.String("url", d.url), zap.String("type", d.event), zap.Int("requeues", d.requeues), zap.Error(err))
+		return
+	}
+	s.logger.Warn("webhook: delivery failed, requeued for the next batch window", zap.String("url", d.url), zap.String("type", d.event), zap.Error(err))
+	d.requeues++
+	s.failed = append(s.failed, d)
+}
+
+func (s *service) wants(event string) bool {
+	return slices.Contains(s.cfg.Events, event)
+}
+
+// matchSlug reports whether slug passes WEBHOOK_SLUGS. An empty filter matches
+// everything; entries are path.Match globs such as "*_energy" or "battery_*".
+func (s *service) matchSlug(slug string) bool {
+	if len(s.cfg.Slugs) == 0 {
+		return true
+	}
+	for _, pattern := range s.cfg.Slugs {
+		if ok, _ := path.Match(pattern, slug); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anicoll/winet-integration/internal/pkg/config"
+	"github.com/anicoll/winet-integration/internal/pkg/model"
+	"github.com/anicoll/winet-integration/internal/pkg/publisher"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is an httptest handler that records requests and replies with the
+// queued status codes (200 once the queue is empty).
+type receiver struct {
+	mu       sync.Mutex
+	requests []received
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, received{header: req.Header.Clone(), body: body})
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) envelopes(t *testing.T) []Envelope {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Envelope, 0, len(r.requests))
+	for _, req := range r.requests {
+		var env Envelope
+		require.NoError(t, json.Unmarshal(req.body, &env))
+		out = append(out, env)
+	}
+	return out
+}
+
+func newTestService(t *testing.T, cfg config.WebhookConfig, handlers ...http.Handler) *service {
+	t.Helper()
+	for _, h := range handlers {
+		srv := httptest.NewServer(h)
+		t.Cleanup(srv.Close)
+		cfg.URLs = append(cfg.URLs, srv.URL)
+	}
+	if cfg.Events == nil {
+		cfg.Events = []string{EventReadings, EventDevice, EventCommand}
+	}
+	if cfg.BatchWindow == 0 {
+		cfg.BatchWindow = time.Hour
+	}
+	svc, err := New(&cfg)
+	require.NoError(t, err)
+	svc.sender.retryBase = time.Millisecond
+	return svc
+}
+
+func reading(slug, value string) publisher.DataPoint {
+	return publisher.DataPoint{Identifier: "SH10RT_SN1", Slug: slug, Value: value, UnitOfMeasurement: "kWh", Timestamp: time.Now()}
+}
+
+// runOnce performs the shutdown drain, delivering everything queued.
+func runOnce(t *testing.T, svc *service) {
+	t.Helper()
+	svc.drain()
+}
+
+func TestWrite_BatchesReadingsAndFiltersSlugs(t *testing.T) {
+	rcv := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{Slugs: []string{"*_energy"}}, rcv)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("daily_pv_energy", "1.2"), reading("battery_power", "3")}))
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("total_export_energy", "100")}))
+	runOnce(t, svc)
+
+	envs := rcv.envelopes(t)
+	require.Len(t, envs, 1, "both writes land in a single batch")
+	assert.Equal(t, EventReadings, envs[0].Type)
+	require.Len(t, envs[0].Readings, 2)
+	assert.Equal(t, "daily_pv_energy", envs[0].Readings[0].Slug)
+	assert.Equal(t, "total_export_energy", envs[0].Readings[1].Slug)
+	assert.Equal(t, EventReadings, rcv.requests[0].header.Get(HeaderEvent))
+}
+
+func TestRegisterDevice_SentOncePerDevice(t *testing.T) {
+	rcv := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{}, rcv)
+	dev := &model.Device{ID: "1", Model: "SH10RT", SerialNumber: "SN1"}
+
+	require.NoError(t, svc.RegisterDevice(context.Background(), dev))
+	require.NoError(t, svc.RegisterDevice(context.Background(), dev))
+	runOnce(t, svc)
+
+	envs := rcv.envelopes(t)
+	require.Len(t, envs, 1)
+	assert.Equal(t, EventDevice, envs[0].Type)
+	assert.Equal(t, &Device{ID: "1", Model: "SH10RT", SerialNumber: "SN1"}, envs[0].Device)
+}
+
+func TestCommandHook_DeliversCommandEvent(t *testing.T) {
+	rcv := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{}, rcv)
+
+	svc.CommandHook(model.CommandEvent{Command: "charge", Params: map[string]string{"power": "6.6"}, Success: true})
+	runOnce(t, svc)
+
+	envs := rcv.envelopes(t)
+	require.Len(t, envs, 1)
+	assert.Equal(t, EventCommand, envs[0].Type)
+	assert.Equal(t, "charge", envs[0].Command.Command)
+	assert.True(t, envs[0].Command.Success)
+}
+
+func TestRun_FlushesReadingsEveryBatchWindow(t *testing.T) {
+	rcv := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{BatchWindow: 10 * time.Millisecond}, rcv)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("daily_pv_energy", "1.2")}))
+	assert.Eventually(t, func() bool {
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+		return len(rcv.requests) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestEvents_DisabledTypesAreNotSent(t *testing.T) {
+	rcv := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{Events: []string{EventCommand}}, rcv)
+
+	require.NoError(t, svc.Write(context.Background(), []publisher.DataPoint{reading("daily_pv_energy", "1")}))
+	require.NoError(t, svc.RegisterDevice(context.Background(), &model.Device{ID: "1"}))
+	runOnce(t, svc)
+
+	assert.Empty(t, rcv.envelopes(t))
+}
+
+func TestDeliver_SignsRequests(t *testing.T) {
+	rcv := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{Secret: "s3cret"}, rcv)
+
+	svc.CommandHook(model.CommandEvent{Command: "battery_stop"})
+	runOnce(t, svc)
+
+	require.Len(t, rcv.requests, 1)
+	req := rcv.requests[0]
+	ts := req.header.Get(HeaderTimestamp)
+	require.NotEmpty(t, ts)
+	assert.Equal(t, Sign([]byte("s3cret"), ts, req.body), req.header.Get(HeaderSignature))
+}
+
+func TestDeliver_RetriesTransientFailures(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	svc := newTestService(t, config.WebhookConfig{MaxRetries: 3}, rcv)
+
+	svc.CommandHook(model.CommandEvent{Command: "charge"})
+	runOnce(t, svc)
+
+	assert.Len(t, rcv.requests, 3)
+}
+
+func TestDeliver_DoesNotRetryClientErrors(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusBadRequest}}
+	svc := newTestService(t, config.WebhookConfig{MaxRetries: 3}, rcv)
+
+	svc.CommandHook(model.CommandEvent{Command: "charge"})
+	runOnce(t, svc)
+
+	assert.Len(t, rcv.requests, 1)
+}
+
+func TestDeliver_FailingURLDoesNotBlockOthers(t *testing.T) {
+	bad := &receiver{statuses: []int{http.StatusNotFound}}
+	good := &receiver{}
+	svc := newTestService(t, config.WebhookConfig{}, bad, good)
+
+	svc.CommandHook(model.CommandEvent{Command: "charge"})
+	runOnce(t, svc)
+
+	assert.Len(t, bad.requests, 1)
+	assert.Len(t, good.requests, 1)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(&config.WebhookConfig{BatchWindow: 0})
+	assert.ErrorContains(t, err, "WEBHOOK_BATCH_WINDOW")
+	_, err = New(&config.WebhookConfig{BatchWindow: time.Second, MaxRetries: -1})
+	assert.ErrorContains(t, err, "WEBHOOK_MAX_RETRIES")
+}
+
+func TestFlush_RequeuesFailedBatchForNextWindow(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	svc := newTestService(t, config.WebhookConfig{}, rcv)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("daily_pv_energy", "1")}))
+	svc.flush(ctx, true)
+	require.Len(t, svc.failed, 1)
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("daily_pv_energy", "2")}))
+	svc.flush(ctx, true)
+
+	envs := rcv.envelopes(t)
+	require.Len(t, envs, 3)
+	assert.Equal(t, envs[0], envs[1], "the failed batch is resent first")
+	assert.Equal(t, "2", envs[2].Readings[0].Value)
+	assert.Empty(t, svc.failed)
+}
+
+func TestFlush_GivesUpAfterMaxRequeues(t *testing.T) {
+	rcv := &receiver{statuses: make([]int, 10)}
+	for i := range rcv.statuses {
+		rcv.statuses[i] = http.StatusBadGateway
+	}
+	svc := newTestService(t, config.WebhookConfig{}, rcv)
+	ctx := context.Background()
+
+	svc.CommandHook(model.CommandEvent{Command: "charge"})
+	svc.deliver(ctx, <-svc.queue, true)
+	for range maxRequeues + 1 {
+		svc.flush(ctx, true)
+	}
+
+	assert.Len(t, rcv.requests, 1+maxRequeues)
+	assert.Empty(t, svc.failed)
+}
+
+func TestRun_SlowEndpointDoesNotHoldUpWrites(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	slow := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		once.Do(func() { close(started) })
+		<-release
+	})
+	svc := newTestService(t, config.WebhookConfig{BatchWindow: 5 * time.Millisecond}, slow)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+
+	require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("daily_pv_energy", "1")}))
+	<-started // the first batch is in flight
+	for range 3 {
+		svc.CommandHook(model.CommandEvent{Command: "charge"})
+		require.NoError(t, svc.Write(ctx, []publisher.DataPoint{reading("daily_pv_energy", "2")}))
+	}
+	time.Sleep(20 * time.Millisecond) // several batch windows pass
+	svc.mu.Lock()
+	assert.Len(t, svc.pending, 3, "readings keep batching while delivery is blocked")
+	svc.mu.Unlock()
+
+	close(release)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
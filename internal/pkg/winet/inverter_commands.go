@@ -1,12 +1,15 @@
 package winet
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
 	"github.com/anicoll/winet-integration/internal/pkg/model"
 	ws "github.com/anicoll/winet-integration/pkg/sockets"
 )
@@ -17,7 +20,9 @@ import (
 
 // handle a message to force a charge at power.
 
-func (s *service) SendSelfConsumptionCommand() (bool, error) {
+func (s *service) SendSelfConsumptionCommand(ctx context.Context) (success bool, err error) {
+	start, resultCode := time.Now(), 0
+	defer func() { s.observeCommand(ctx, "self_consumption", nil, start, resultCode, success, err) }()
 	nowTime := fmt.Sprintf("%d", time.Now().UnixMilli())
 	data, err := json.Marshal(model.InverterUpdateRequest{
 		Request: model.Request{
@@ -63,10 +68,15 @@ func (s *service) SendSelfConsumptionCommand() (bool, error) {
 		return false, fmt.Errorf("unexpected response type: %T", res)
 	}
 	s.logger.Info("SendSelfConsumptionCommand", zap.Any("any", result))
+	resultCode = result.ResultCode
 	return result.ResultMessage == "success", nil
 }
 
-func (s *service) SendDischargeCommand(dischargePower string) (bool, error) {
+func (s *service) SendDischargeCommand(ctx context.Context, dischargePower string) (success bool, err error) {
+	start, resultCode := time.Now(), 0
+	defer func() {
+		s.observeCommand(ctx, "discharge", map[string]string{"power": dischargePower}, start, resultCode, success, err)
+	}()
 	nowTime := fmt.Sprintf("%d", time.Now().UnixMilli())
 	data, err := json.Marshal(model.InverterUpdateRequest{
 		Request: model.Request{
@@ -128,10 +138,15 @@ func (s *service) SendDischargeCommand(dischargePower string) (bool, error) {
 		return false, fmt.Errorf("unexpected response type: %T", res)
 	}
 	s.logger.Info("SendDischargeCommand", zap.Any("any", result))
+	resultCode = result.ResultCode
 	return result.ResultMessage == "success", nil
 }
 
-func (s *service) SendChargeCommand(chargePower string) (bool, error) {
+func (s *service) SendChargeCommand(ctx context.Context, chargePower string) (success bool, err error) {
+	start, resultCode := time.Now(), 0
+	defer func() {
+		s.observeCommand(ctx, "charge", map[string]string{"power": chargePower}, start, resultCode, success, err)
+	}()
 	nowTime := fmt.Sprintf("%d", time.Now().UnixMilli())
 	data, err := json.Marshal(model.InverterUpdateRequest{
 		Request: model.Request{
@@ -193,10 +208,13 @@ func (s *service) SendChargeCommand(chargePower string) (bool, error) {
 		return false, fmt.Errorf("unexpected response type: %T", res)
 	}
 	s.logger.Info("SendChargeCommand", zap.Any("any", result))
+	resultCode = result.ResultCode
 	return result.ResultMessage == "success", nil
 }
 
-func (s *service) SendBatteryStopCommand() (bool, error) {
+func (s *service) SendBatteryStopCommand(ctx context.Context) (success bool, err error) {
+	start, resultCode := time.Now(), 0
+	defer func() { s.observeCommand(ctx, "battery_stop", nil, start, resultCode, success, err) }()
 	nowTime := fmt.Sprintf("%d", time.Now().UnixMilli())
 	data, err := json.Marshal(model.InverterUpdateRequest{
 		Request: model.Request{
@@ -250,10 +268,15 @@ func (s *service) SendBatteryStopCommand() (bool, error) {
 		return false, fmt.Errorf("unexpected response type: %T", res)
 	}
 	s.logger.Info("SendBatteryStopCommand", zap.Any("any", result))
+	resultCode = result.ResultCode
 	return result.ResultMessage == "success", nil
 }
 
-func (s *service) SendInverterStateChangeCommand(disable bool) (bool, error) {
+func (s *service) SendInverterStateChangeCommand(ctx context.Context, disable bool) (success bool, err error) {
+	start, resultCode := time.Now(), 0
+	defer func() {
+		s.observeCommand(ctx, "inverter_state", map[string]string{"disable": strconv.FormatBool(disable)}, start, resultCode, success, err)
+	}()
 	data, err := json.Marshal(model.DisableInverterRequest{
 		Request: model.Request{
 			Lang:    EnglishLang,
@@ -296,10 +319,15 @@ func (s *service) SendInverterStateChangeCommand(disable bool) (bool, error) {
 		return false, fmt.Errorf("unexpected response type: %T", res)
 	}
 	s.logger.Info("SendInverterStateChangeCommand", zap.Any("any", result))
+	resultCode = result.ResultCode
 	return result.ResultMessage == "success", nil
 }
 
-func (s *service) SetFeedInLimitation(feedinLimited bool) (bool, error) {
+func (s *service) SetFeedInLimitation(ctx context.Context, feedinLimited bool) (success bool, err error) {
+	start, resultCode := time.Now(), 0
+	defer func() {
+		s.observeCommand(ctx, "feedin_limitation", map[string]string{"feedin_limited": strconv.FormatBool(feedinLimited)}, start, resultCode, success, err)
+	}()
 	paramRequests := []model.InverterParamRequest{{
 		Accuracy:   0,
 		ParamAddr:  31221,
@@ -364,9 +392,36 @@ func (s *service) SetFeedInLimitation(feedinLimited bool) (bool, error) {
 		return false, fmt.Errorf("unexpected response type: %T", res)
 	}
 	s.logger.Info("SetFeedInLimitation", zap.Any("any", result))
+	resultCode = result.ResultCode
 	return result.ResultMessage == "success", nil
 }
 
+// observeCommand records command metrics and notifies the command hook, if
+// any. resultCode is WiNet's result_code, zero when no reply arrived.
+func (s *service) observeCommand(ctx context.Context, command string, params map[string]string, start time.Time, resultCode int, success bool, err error) {
+	metrics.ObserveCommand(command, success, err)
+	if s.onCommand == nil {
+		return
+	}
+	origin, _ := model.CommandOriginFromContext(ctx)
+	now := time.Now()
+	event := model.CommandEvent{
+		Command:    command,
+		Device:     s.inverterDeviceID(),
+		Params:     params,
+		Source:     origin.Source,
+		User:       origin.User,
+		Success:    success,
+		ResultCode: resultCode,
+		Timestamp:  now,
+		LatencyMs:  now.Sub(start).Milliseconds(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.onCommand(event)
+}
+
 func (s *service) handleParamMessage(data []byte, _ ws.Connection) {
 	res := model.ParsedResult[model.GenericReponse[model.InverterParamResponse]]{}
 	if err := json.Unmarshal(data, &res); err != nil {
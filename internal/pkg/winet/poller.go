@@ -9,6 +9,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/anicoll/winet-integration/internal/pkg/metrics"
 	"github.com/anicoll/winet-integration/internal/pkg/model"
 	ws "github.com/anicoll/winet-integration/pkg/sockets"
 )
@@ -48,7 +49,9 @@ func (s *service) runPollLoop(ctx context.Context) {
 		if !ok {
 			s.logger.Warn("poll loop: unexpected device list response type", zap.String("type", fmt.Sprintf("%T", v)))
 		} else {
+			start := time.Now()
 			s.queryDevices(ctx, devices)
+			metrics.PollCycleDuration.Observe(time.Since(start).Seconds())
 		}
 
 		// Wait for the next poll interval.
@@ -76,6 +79,9 @@ func (s *service) queryDevices(ctx context.Context, devices []model.DeviceListOb
 
 		s.deviceMu.Lock()
 		s.currentDevice = dev
+		if device.DevType == model.DeviceTypeInverter {
+			s.inverterID = dev.ID
+		}
 		s.deviceMu.Unlock()
 
 		if err := s.publisher.RegisterDevice(ctx, dev); err != nil {
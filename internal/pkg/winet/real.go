@@ -44,7 +44,7 @@ func (s *service) handleRealMessage(data []byte) {
 	datapointsToPublish[*currentDevice] = datapoints
 
 	if s.onDeviceStatuses != nil {
-		s.onDeviceStatuses(datapoints)
+		s.onDeviceStatuses(currentDevice.ID, datapoints)
 	}
 
 	if err := s.publisher.PublishData(contxt.NewContext(time.Second*5), datapointsToPublish); err != nil {
@@ -81,22 +81,31 @@ type service struct {
 
 	deviceMu      sync.RWMutex
 	currentDevice *model.Device
+	inverterID    string // WiNet device ID of the inverter; see inverterDeviceID
 
 	publisher  publisher.DataPublisher
 	pending    pendingCmd
 	loginReady chan struct{} // closed by handleLoginMessage to start poll loop
 	cancelPoll context.CancelFunc
 
-	onDeviceStatuses func(statuses []model.DeviceStatus)
+	onDeviceStatuses func(deviceID string, statuses []model.DeviceStatus)
+	onCommand        func(event model.CommandEvent)
 }
 
 // SetDeviceStatusHook registers a callback that is invoked (from handleRealMessage) each time
-// a fresh batch of device statuses arrives. The callback must be non-blocking — it should only
-// update in-memory state and must not send inverter commands (which require the pending slot).
-func (s *service) SetDeviceStatusHook(fn func(statuses []model.DeviceStatus)) {
+// a fresh batch of device statuses arrives, with the ID of the device they belong to. The
+// callback must be non-blocking — it should only update in-memory state and must not send
+// inverter commands (which require the pending slot).
+func (s *service) SetDeviceStatusHook(fn func(deviceID string, statuses []model.DeviceStatus)) {
 	s.onDeviceStatuses = fn
 }
 
+// SetCommandHook registers a callback that is invoked after every inverter command
+// completes, successfully or not. The callback must be non-blocking.
+func (s *service) SetCommandHook(fn func(event model.CommandEvent)) {
+	s.onCommand = fn
+}
+
 func New(cfg *config.WinetConfig, pub publisher.DataPublisher) *service {
 	return &service{
 		cfg:        cfg,
@@ -107,6 +116,18 @@ func New(cfg *config.WinetConfig, pub publisher.DataPublisher) *service {
 	}
 }
 
+// inverterDeviceID returns the WiNet device ID of the inverter, which command
+// events are tagged with. Until a device list has named the inverter it is
+// "1", the ID WiNet gives its first device.
+func (s *service) inverterDeviceID() string {
+	s.deviceMu.RLock()
+	defer s.deviceMu.RUnlock()
+	if s.inverterID == "" {
+		return "1"
+	}
+	return s.inverterID
+}
+
 // getConn returns a snapshot of the current connection under a read lock.
 // All callers that need to use s.conn should go through this method so that
 // concurrent writes from reconnect() cannot produce a nil-pointer dereference.
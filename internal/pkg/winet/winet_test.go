@@ -471,6 +471,62 @@ func TestHandleParamMessage_InvalidJSON_SendsToEvents(t *testing.T) {
 	}
 }
 
+func TestCommandHook_CalledWithOutcome(t *testing.T) {
+	svc := newTestService()
+	var got []model.CommandEvent
+	svc.SetCommandHook(func(e model.CommandEvent) { got = append(got, e) })
+
+	// No connection: the command fails before anything is sent.
+	ok, err := svc.SendChargeCommand(context.Background(), "6.6")
+	require.Error(t, err)
+	assert.False(t, ok)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "charge", got[0].Command)
+	assert.Equal(t, map[string]string{"power": "6.6"}, got[0].Params)
+	assert.False(t, got[0].Success)
+	assert.NotEmpty(t, got[0].Error)
+	assert.Zero(t, got[0].ResultCode, "no reply, no result code")
+}
+
+func TestCommandHook_ReportsOriginDeviceAndResultCode(t *testing.T) {
+	svc := newTestService()
+	var got []model.CommandEvent
+	svc.SetCommandHook(func(e model.CommandEvent) { got = append(got, e) })
+
+	reply, err := json.Marshal(model.ParsedResult[model.GenericReponse[model.InverterParamResponse]]{
+		ResultCode: 1, ResultMessage: "success",
+	})
+	require.NoError(t, err)
+	var sent model.InverterUpdateRequest
+	conn := socketsmocks.NewConnection(t)
+	conn.EXPECT().Send(mock.Anything).RunAndReturn(func(msg ws.Msg) error {
+		require.NoError(t, json.Unmarshal(msg.Body, &sent))
+		// Reply once the command is waiting for it.
+		time.AfterFunc(10*time.Millisecond, func() { svc.handleParamMessage(reply, nil) })
+		return nil
+	})
+	svc.conn = conn
+	svc.inverterID = "3"
+	// The poll loop has moved on to the battery.
+	svc.currentDevice = &model.Device{ID: "4"}
+
+	ctx := model.WithCommandOrigin(context.Background(), model.CommandOrigin{Source: model.CommandSourceAPI, User: "alice"})
+	ok, err := svc.SendBatteryStopCommand(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "battery_stop", got[0].Command)
+	assert.Equal(t, []string{"1"}, sent.DevIDArray, "commands are still sent to device 1")
+	assert.Equal(t, "3", got[0].Device, "the inverter, not the device being polled")
+	assert.Equal(t, model.CommandSourceAPI, got[0].Source)
+	assert.Equal(t, "alice", got[0].User)
+	assert.True(t, got[0].Success)
+	assert.Equal(t, 1, got[0].ResultCode)
+	assert.GreaterOrEqual(t, got[0].LatencyMs, int64(10))
+}
+
 // --- handleRealMessage ---
 
 func TestHandleRealMessage_InvalidJSON_SendsToEvents(t *testing.T) {
@@ -687,6 +743,24 @@ func TestQueryDevices_SendsOneRequestPerStage(t *testing.T) {
 	svc.deviceMu.RUnlock()
 	require.NotNil(t, cd)
 	assert.Equal(t, "SN001", cd.SerialNumber)
+	assert.Equal(t, "1", svc.inverterDeviceID())
+}
+
+func TestQueryDevices_RecordsInverterID(t *testing.T) {
+	svc := newTestService()
+	conn := socketsmocks.NewConnection(t)
+	svc.conn = conn
+	conn.Mock.On("Send", mock.Anything).Return(nil).Run(func(_ mock.Arguments) {
+		time.AfterFunc(2*time.Millisecond, func() { svc.pending.deliver(struct{}{}) })
+	})
+
+	assert.Equal(t, "1", svc.inverterDeviceID(), "before any device list")
+	svc.queryDevices(context.Background(), []model.DeviceListObject{
+		{DeviceID: 3, DevModel: "SH10RT", DevSN: "SN-INV", DevType: model.DeviceTypeInverter},
+		{DeviceID: 4, DevModel: "SBR096", DevSN: "SN-BAT", DevType: model.DeviceTypeBattery},
+	})
+
+	assert.Equal(t, "3", svc.inverterDeviceID(), "the battery, polled last, does not replace it")
 }
 
 // --- publisher integration ---
@@ -0,0 +1,35 @@
+CREATE TABLE AmberPrice (
+    id           NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    per_kwh      NUMBER(10, 5) NOT NULL,
+    spot_per_kwh NUMBER(10, 5) NOT NULL,
+    start_time   TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time     TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration     NUMBER NOT NULL,
+    forecast     NUMBER(1) DEFAULT 0 NOT NULL,
+    channel_type VARCHAR2(64) NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+    updated_at   TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX uq_amberprice ON AmberPrice (SYS_EXTRACT_UTC(start_time), channel_type);
+CREATE INDEX idx_amber_price_start_time ON AmberPrice (start_time);
+CREATE INDEX idx_amber_price_end_time   ON AmberPrice (end_time);
+
+CREATE TABLE AmberUsage (
+    id                 NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    per_kwh            NUMBER(10, 5) NOT NULL,
+    spot_per_kwh       NUMBER(10, 5) NOT NULL,
+    start_time         TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time           TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration           NUMBER NOT NULL,
+    channel_type       VARCHAR2(64) NOT NULL,
+    channel_identifier VARCHAR2(64) NOT NULL,
+    kwh                NUMBER(10, 5) NOT NULL,
+    quality            VARCHAR2(64) NOT NULL,
+    cost               NUMBER(10, 5) NOT NULL,
+    created_at         TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+    updated_at         TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX uq_amberusage ON AmberUsage (SYS_EXTRACT_UTC(start_time), channel_identifier);
+CREATE INDEX idx_amber_usage_start_time ON AmberUsage (start_time);
@@ -0,0 +1,43 @@
+CREATE TABLE property_rollup (
+    resolution          NUMBER(10) NOT NULL,
+    bucket_start        TIMESTAMP WITH TIME ZONE NOT NULL,
+    identifier          VARCHAR2(256) NOT NULL,
+    slug                VARCHAR2(256) NOT NULL,
+    unit_of_measurement VARCHAR2(64) NOT NULL,
+    min_value           BINARY_DOUBLE NOT NULL,
+    max_value           BINARY_DOUBLE NOT NULL,
+    sum_value           BINARY_DOUBLE NOT NULL,
+    sample_count        NUMBER(10) NOT NULL,
+    last_value          BINARY_DOUBLE NOT NULL,
+    last_time_stamp     TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX uq_property_rollup ON property_rollup (identifier, slug, resolution, SYS_EXTRACT_UTC(bucket_start));
+
+INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+SELECT r.resolution,
+       FROM_TZ(TIMESTAMP '1970-01-01 00:00:00', 'UTC') + NUMTODSINTERVAL(FLOOR(p.epoch / r.resolution) * r.resolution, 'SECOND'),
+       p.identifier,
+       p.slug,
+       MAX(p.unit_of_measurement) KEEP (DENSE_RANK LAST ORDER BY p.time_stamp),
+       MIN(p.v),
+       MAX(p.v),
+       SUM(p.v),
+       COUNT(*),
+       MAX(p.v) KEEP (DENSE_RANK LAST ORDER BY p.time_stamp),
+       MAX(p.time_stamp)
+FROM (
+    SELECT time_stamp, identifier, slug, unit_of_measurement,
+           TO_BINARY_DOUBLE(value DEFAULT NULL ON CONVERSION ERROR) AS v,
+           (CAST(SYS_EXTRACT_UTC(time_stamp) AS DATE) - DATE '1970-01-01') * 86400 AS epoch
+    FROM Property
+) p
+CROSS JOIN (
+    SELECT 60 AS resolution FROM dual UNION ALL
+    SELECT 900 FROM dual UNION ALL
+    SELECT 3600 FROM dual UNION ALL
+    SELECT 86400 FROM dual
+) r
+WHERE p.v IS NOT NULL
+GROUP BY r.resolution, FLOOR(p.epoch / r.resolution), p.identifier, p.slug
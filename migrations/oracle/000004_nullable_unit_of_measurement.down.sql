@@ -0,0 +1,4 @@
+UPDATE Property SET unit_of_measurement = '-' WHERE unit_of_measurement IS NULL;
+UPDATE property_rollup SET unit_of_measurement = '-' WHERE unit_of_measurement IS NULL;
+ALTER TABLE Property MODIFY (unit_of_measurement NOT NULL);
+ALTER TABLE property_rollup MODIFY (unit_of_measurement NOT NULL);
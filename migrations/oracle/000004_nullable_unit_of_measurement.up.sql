@@ -0,0 +1,4 @@
+ALTER TABLE Property MODIFY (unit_of_measurement NULL);
+ALTER TABLE property_rollup MODIFY (unit_of_measurement NULL);
+UPDATE Property SET unit_of_measurement = NULL WHERE unit_of_measurement = '-';
+UPDATE property_rollup SET unit_of_measurement = NULL WHERE unit_of_measurement = '-';
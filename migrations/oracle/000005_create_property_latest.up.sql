@@ -0,0 +1,17 @@
+CREATE TABLE property_latest (
+    identifier          VARCHAR2(256) NOT NULL,
+    slug                VARCHAR2(256) NOT NULL,
+    time_stamp          TIMESTAMP WITH TIME ZONE NOT NULL,
+    unit_of_measurement VARCHAR2(64),
+    value               VARCHAR2(256) NOT NULL,
+    CONSTRAINT pk_property_latest PRIMARY KEY (identifier, slug)
+);
+
+INSERT INTO property_latest (identifier, slug, time_stamp, unit_of_measurement, value)
+SELECT identifier, slug, time_stamp, unit_of_measurement, value
+FROM (
+    SELECT identifier, slug, time_stamp, unit_of_measurement, value,
+           ROW_NUMBER() OVER (PARTITION BY identifier, slug ORDER BY time_stamp DESC, id DESC) AS rn
+    FROM Property
+)
+WHERE rn = 1
@@ -0,0 +1,14 @@
+CREATE TABLE command_audit (
+    id            NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    requested_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    source        VARCHAR2(64) NOT NULL,
+    username      VARCHAR2(256),
+    command       VARCHAR2(64) NOT NULL,
+    params        VARCHAR2(4000),
+    success       NUMBER(1) NOT NULL,
+    result_code   NUMBER(10) NOT NULL,
+    error_message VARCHAR2(4000),
+    latency_ms    NUMBER(19) NOT NULL
+);
+
+CREATE INDEX idx_command_audit_requested_at ON command_audit (requested_at)
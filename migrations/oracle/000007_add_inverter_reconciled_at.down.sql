@@ -0,0 +1 @@
+ALTER TABLE Inverter DROP COLUMN reconciled_at
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE Inverter ADD (reconciled_at TIMESTAMP WITH TIME ZONE)
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE property_latest DROP (name, first_seen)
\ No newline at end of file
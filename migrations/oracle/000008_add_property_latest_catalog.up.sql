@@ -0,0 +1,13 @@
+-- Sensor catalog columns: the WiNet display name of each series and when it
+-- was first written. Names fill in as new readings arrive.
+ALTER TABLE property_latest ADD (
+    name       VARCHAR2(256),
+    first_seen TIMESTAMP WITH TIME ZONE
+);
+
+UPDATE property_latest l
+SET first_seen = COALESCE(
+        (SELECT MIN(p.time_stamp) FROM Property p WHERE p.identifier = l.identifier AND p.slug = l.slug),
+        l.time_stamp);
+
+ALTER TABLE property_latest MODIFY (first_seen NOT NULL)
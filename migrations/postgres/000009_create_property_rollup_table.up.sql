@@ -0,0 +1,40 @@
+-- Rollups of numeric Property readings at 1 minute, 15 minutes, 1 hour and
+-- 1 day resolution (resolution is the bucket width in seconds; buckets are
+-- aligned to UTC). Maintained incrementally by the application on write.
+CREATE TABLE IF NOT EXISTS property_rollup (
+        resolution              INTEGER NOT NULL,
+        bucket_start            TIMESTAMP WITH TIME ZONE NOT NULL,
+        identifier              TEXT NOT NULL,
+        slug                    TEXT NOT NULL,
+        unit_of_measurement     TEXT NOT NULL,
+        min_value               DOUBLE PRECISION NOT NULL,
+        max_value               DOUBLE PRECISION NOT NULL,
+        sum_value               DOUBLE PRECISION NOT NULL,
+        sample_count            INTEGER NOT NULL,
+        last_value              DOUBLE PRECISION NOT NULL,
+        last_time_stamp         TIMESTAMP WITH TIME ZONE NOT NULL,
+        PRIMARY KEY (identifier, slug, resolution, bucket_start)
+);
+
+-- Backfill from the raw readings already stored.
+WITH numeric_property AS MATERIALIZED (
+        SELECT time_stamp, identifier, slug, unit_of_measurement, value::DOUBLE PRECISION AS v
+        FROM Property
+        WHERE value ~ '^-?[0-9]+(\.[0-9]+)?$'
+)
+INSERT INTO property_rollup (resolution, bucket_start, identifier, slug, unit_of_measurement,
+                             min_value, max_value, sum_value, sample_count, last_value, last_time_stamp)
+SELECT r.resolution,
+       to_timestamp(floor(extract(epoch FROM p.time_stamp) / r.resolution) * r.resolution) AS bucket_start,
+       p.identifier,
+       p.slug,
+       (array_agg(p.unit_of_measurement ORDER BY p.time_stamp DESC))[1],
+       MIN(p.v),
+       MAX(p.v),
+       SUM(p.v),
+       COUNT(*),
+       (array_agg(p.v ORDER BY p.time_stamp DESC))[1],
+       MAX(p.time_stamp)
+FROM numeric_property p
+CROSS JOIN (VALUES (60), (900), (3600), (86400)) AS r(resolution)
+GROUP BY r.resolution, bucket_start, p.identifier, p.slug;
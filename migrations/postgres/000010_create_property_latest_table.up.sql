@@ -0,0 +1,17 @@
+-- Most recent reading per identifier and slug, upserted by the application
+-- on every write. Retention never trims it, so sensors whose value has not
+-- changed for a long time (and so are not written again) still report it.
+CREATE TABLE IF NOT EXISTS property_latest (
+        identifier              TEXT NOT NULL,
+        slug                    TEXT NOT NULL,
+        time_stamp              TIMESTAMP WITH TIME ZONE NOT NULL,
+        unit_of_measurement     TEXT NOT NULL,
+        value                   TEXT NOT NULL,
+        PRIMARY KEY (identifier, slug)
+);
+
+-- Backfill from the raw readings already stored.
+INSERT INTO property_latest (identifier, slug, time_stamp, unit_of_measurement, value)
+SELECT DISTINCT ON (identifier, slug) identifier, slug, time_stamp, unit_of_measurement, value
+FROM Property
+ORDER BY identifier, slug, time_stamp DESC, id DESC;
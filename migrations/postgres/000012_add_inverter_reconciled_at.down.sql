@@ -0,0 +1 @@
+ALTER TABLE Inverter DROP COLUMN IF EXISTS reconciled_at;
@@ -0,0 +1,2 @@
+-- When readings last overrode the commanded inverter state; NULL if never.
+ALTER TABLE Inverter ADD COLUMN IF NOT EXISTS reconciled_at TIMESTAMP WITH TIME ZONE;
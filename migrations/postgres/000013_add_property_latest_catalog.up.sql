@@ -0,0 +1,13 @@
+-- Sensor catalog columns: the WiNet display name of each series and when it
+-- was first written. Names fill in as new readings arrive.
+ALTER TABLE property_latest ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE property_latest ADD COLUMN IF NOT EXISTS first_seen TIMESTAMP WITH TIME ZONE;
+
+-- Backfill from the raw readings already stored.
+UPDATE property_latest l
+SET first_seen = COALESCE(
+        (SELECT MIN(p.time_stamp) FROM Property p WHERE p.identifier = l.identifier AND p.slug = l.slug),
+        l.time_stamp)
+WHERE first_seen IS NULL;
+
+ALTER TABLE property_latest ALTER COLUMN first_seen SET NOT NULL;
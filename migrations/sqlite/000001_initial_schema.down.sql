@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS property_rollup;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS Device;
+DROP TABLE IF EXISTS Property;
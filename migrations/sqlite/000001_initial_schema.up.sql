@@ -0,0 +1,57 @@
+-- Timestamps are stored as INTEGER unix microseconds (UTC), which keeps the
+-- precision of the Postgres schema and lets rollup buckets be computed with
+-- integer arithmetic.
+CREATE TABLE IF NOT EXISTS Property (
+        id                      INTEGER PRIMARY KEY AUTOINCREMENT,
+        time_stamp              INTEGER NOT NULL,
+        unit_of_measurement     TEXT NOT NULL,
+        value                   TEXT NOT NULL,
+        -- value parsed as a number by the application, NULL for text readings.
+        -- SQLite has no safe numeric cast to derive it from value in SQL.
+        numeric_value           REAL,
+        identifier              TEXT NOT NULL,
+        slug                    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_properties_identifier_slug_timestamp ON Property (identifier, slug, time_stamp);
+CREATE INDEX IF NOT EXISTS idx_properties_timestamp ON Property (time_stamp);
+
+CREATE TABLE IF NOT EXISTS Device (
+        id                      TEXT PRIMARY KEY,
+        model                   TEXT,
+        serial_number           TEXT,
+        created_at              INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+        id                      INTEGER PRIMARY KEY AUTOINCREMENT,
+        username                TEXT NOT NULL UNIQUE,
+        password_hash           TEXT NOT NULL,
+        created_at              INTEGER NOT NULL,
+        updated_at              INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+        token_hash              TEXT PRIMARY KEY,
+        user_id                 INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        username                TEXT NOT NULL,
+        expires_at              INTEGER NOT NULL,
+        created_at              INTEGER NOT NULL
+);
+
+-- Rollups of numeric Property readings at 1 minute, 15 minutes, 1 hour and
+-- 1 day resolution (resolution is the bucket width in seconds; buckets are
+-- aligned to UTC). Maintained incrementally by the application on write.
+CREATE TABLE IF NOT EXISTS property_rollup (
+        resolution              INTEGER NOT NULL,
+        bucket_start            INTEGER NOT NULL,
+        identifier              TEXT NOT NULL,
+        slug                    TEXT NOT NULL,
+        unit_of_measurement     TEXT NOT NULL,
+        min_value               REAL NOT NULL,
+        max_value               REAL NOT NULL,
+        sum_value               REAL NOT NULL,
+        sample_count            INTEGER NOT NULL,
+        last_value              REAL NOT NULL,
+        last_time_stamp         INTEGER NOT NULL,
+        PRIMARY KEY (identifier, slug, resolution, bucket_start)
+) WITHOUT ROWID;
@@ -0,0 +1,20 @@
+-- Most recent reading per identifier and slug, upserted on every write and
+-- never trimmed by retention.
+CREATE TABLE IF NOT EXISTS property_latest (
+        identifier              TEXT NOT NULL,
+        slug                    TEXT NOT NULL,
+        time_stamp              INTEGER NOT NULL,
+        unit_of_measurement     TEXT NOT NULL,
+        value                   TEXT NOT NULL,
+        PRIMARY KEY (identifier, slug)
+) WITHOUT ROWID;
+
+-- Backfill from the raw readings already stored.
+INSERT INTO property_latest (identifier, slug, time_stamp, unit_of_measurement, value)
+SELECT identifier, slug, time_stamp, unit_of_measurement, value
+FROM (
+        SELECT identifier, slug, time_stamp, unit_of_measurement, value,
+               ROW_NUMBER() OVER (PARTITION BY identifier, slug ORDER BY time_stamp DESC, id DESC) AS rn
+        FROM Property
+)
+WHERE rn = 1;
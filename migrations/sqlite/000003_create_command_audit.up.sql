@@ -0,0 +1,15 @@
+-- Audit log of inverter and battery commands. params is the command's
+-- parameters as a JSON object; username is empty for automation.
+CREATE TABLE IF NOT EXISTS command_audit (
+        id                      INTEGER PRIMARY KEY AUTOINCREMENT,
+        requested_at            INTEGER NOT NULL,
+        source                  TEXT NOT NULL,
+        username                TEXT NOT NULL,
+        command                 TEXT NOT NULL,
+        params                  TEXT NOT NULL,
+        success                 INTEGER NOT NULL,
+        result_code             INTEGER NOT NULL,
+        error_message           TEXT NOT NULL,
+        latency_ms              INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_command_audit_requested_at ON command_audit (requested_at);
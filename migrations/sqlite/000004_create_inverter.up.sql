@@ -0,0 +1,12 @@
+-- Control state the service last put each inverter into. reconciled_at is
+-- when readings last overrode state, NULL if never.
+CREATE TABLE IF NOT EXISTS Inverter (
+        id                      TEXT PRIMARY KEY,
+        state                   TEXT NOT NULL,
+        battery_state           TEXT NOT NULL,
+        charge_rate             REAL NOT NULL,
+        feedin_enabled          INTEGER NOT NULL DEFAULT 0,
+        created_at              INTEGER NOT NULL,
+        updated_at              INTEGER NOT NULL,
+        reconciled_at           INTEGER
+);
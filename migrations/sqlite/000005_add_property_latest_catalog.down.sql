@@ -0,0 +1,2 @@
+ALTER TABLE property_latest DROP COLUMN first_seen;
+ALTER TABLE property_latest DROP COLUMN name;
@@ -0,0 +1,11 @@
+-- Sensor catalog columns: the WiNet display name of each series and when it
+-- was first written. Names fill in as new readings arrive.
+ALTER TABLE property_latest ADD COLUMN name TEXT NOT NULL DEFAULT '';
+ALTER TABLE property_latest ADD COLUMN first_seen INTEGER NOT NULL DEFAULT 0;
+
+-- Backfill from the raw readings already stored.
+UPDATE property_latest
+SET first_seen = COALESCE(
+        (SELECT MIN(p.time_stamp) FROM Property p
+         WHERE p.identifier = property_latest.identifier AND p.slug = property_latest.slug),
+        time_stamp);
@@ -0,0 +1,14 @@
+-- A hypertable cannot be converted back in place, so copy the readings into a
+-- plain table and swap it in. The id sequence is detached first so dropping
+-- the hypertable does not drop it.
+ALTER SEQUENCE property_id_seq OWNED BY NONE;
+
+CREATE TABLE property_plain (LIKE Property INCLUDING DEFAULTS);
+INSERT INTO property_plain SELECT * FROM Property;
+DROP TABLE Property;
+ALTER TABLE property_plain RENAME TO Property;
+
+ALTER TABLE Property ADD PRIMARY KEY (id);
+ALTER SEQUENCE property_id_seq OWNED BY Property.id;
+CREATE INDEX IF NOT EXISTS idx_properties_identifier ON Property (identifier);
+CREATE INDEX IF NOT EXISTS idx_properties_timestamp ON Property (time_stamp);
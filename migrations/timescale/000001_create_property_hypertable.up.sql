@@ -0,0 +1,20 @@
+-- Turns Property into a TimescaleDB hypertable partitioned by day, with
+-- native compression segmented by series. Applied only when TIMESCALE_ENABLED
+-- is set and the extension is available; the compression policy itself is
+-- configured at startup from TIMESCALE_COMPRESS_AFTER.
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+-- Unique constraints on a hypertable must include the partitioning column.
+ALTER TABLE Property DROP CONSTRAINT IF EXISTS property_pkey;
+ALTER TABLE Property ADD PRIMARY KEY (id, time_stamp);
+
+SELECT create_hypertable('property', 'time_stamp',
+        chunk_time_interval => INTERVAL '1 day',
+        migrate_data => true,
+        if_not_exists => true);
+
+ALTER TABLE Property SET (
+        timescaledb.compress,
+        timescaledb.compress_segmentby = 'identifier, slug',
+        timescaledb.compress_orderby = 'time_stamp DESC'
+);
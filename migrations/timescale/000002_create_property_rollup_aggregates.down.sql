@@ -0,0 +1,4 @@
+DROP MATERIALIZED VIEW IF EXISTS property_rollup_1d;
+DROP MATERIALIZED VIEW IF EXISTS property_rollup_1h;
+DROP MATERIALIZED VIEW IF EXISTS property_rollup_15m;
+DROP MATERIALIZED VIEW IF EXISTS property_rollup_1m;
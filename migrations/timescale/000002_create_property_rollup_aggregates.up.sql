@@ -0,0 +1,73 @@
+-- Continuous aggregates that replace the application-maintained
+-- property_rollup table in TimescaleDB mode, one per rollup resolution.
+-- Real-time aggregation is on, so the newest buckets include readings the
+-- refresh policy has not materialised yet. Refresh policies are configured at
+-- startup from TIMESCALE_REFRESH_WINDOW.
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS property_rollup_1m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT time_bucket(INTERVAL '1 minute', time_stamp) AS bucket_start,
+       identifier,
+       slug,
+       last(unit_of_measurement, time_stamp) AS unit_of_measurement,
+       MIN(value::DOUBLE PRECISION) AS min_value,
+       MAX(value::DOUBLE PRECISION) AS max_value,
+       SUM(value::DOUBLE PRECISION) AS sum_value,
+       COUNT(*) AS sample_count,
+       last(value::DOUBLE PRECISION, time_stamp) AS last_value,
+       MAX(time_stamp) AS last_time_stamp
+FROM Property
+WHERE value ~ '^-?[0-9]+(\.[0-9]+)?$'
+GROUP BY bucket_start, identifier, slug
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS property_rollup_15m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT time_bucket(INTERVAL '15 minutes', time_stamp) AS bucket_start,
+       identifier,
+       slug,
+       last(unit_of_measurement, time_stamp) AS unit_of_measurement,
+       MIN(value::DOUBLE PRECISION) AS min_value,
+       MAX(value::DOUBLE PRECISION) AS max_value,
+       SUM(value::DOUBLE PRECISION) AS sum_value,
+       COUNT(*) AS sample_count,
+       last(value::DOUBLE PRECISION, time_stamp) AS last_value,
+       MAX(time_stamp) AS last_time_stamp
+FROM Property
+WHERE value ~ '^-?[0-9]+(\.[0-9]+)?$'
+GROUP BY bucket_start, identifier, slug
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS property_rollup_1h
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT time_bucket(INTERVAL '1 hour', time_stamp) AS bucket_start,
+       identifier,
+       slug,
+       last(unit_of_measurement, time_stamp) AS unit_of_measurement,
+       MIN(value::DOUBLE PRECISION) AS min_value,
+       MAX(value::DOUBLE PRECISION) AS max_value,
+       SUM(value::DOUBLE PRECISION) AS sum_value,
+       COUNT(*) AS sample_count,
+       last(value::DOUBLE PRECISION, time_stamp) AS last_value,
+       MAX(time_stamp) AS last_time_stamp
+FROM Property
+WHERE value ~ '^-?[0-9]+(\.[0-9]+)?$'
+GROUP BY bucket_start, identifier, slug
+WITH DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS property_rollup_1d
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT time_bucket(INTERVAL '1 day', time_stamp) AS bucket_start,
+       identifier,
+       slug,
+       last(unit_of_measurement, time_stamp) AS unit_of_measurement,
+       MIN(value::DOUBLE PRECISION) AS min_value,
+       MAX(value::DOUBLE PRECISION) AS max_value,
+       SUM(value::DOUBLE PRECISION) AS sum_value,
+       COUNT(*) AS sample_count,
+       last(value::DOUBLE PRECISION, time_stamp) AS last_value,
+       MAX(time_stamp) AS last_time_stamp
+FROM Property
+WHERE value ~ '^-?[0-9]+(\.[0-9]+)?$'
+GROUP BY bucket_start, identifier, slug
+WITH DATA;
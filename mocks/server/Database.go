@@ -40,6 +40,166 @@ func (_m *Database) EXPECT() *Database_Expecter {
 	return &Database_Expecter{mock: &_m.Mock}
 }
 
+// GetAggregates provides a mock function for the type Database
+func (_mock *Database) GetAggregates(ctx context.Context, identifier string, slug string, resolution time.Duration, from time.Time, to time.Time) ([]store.Aggregate, error) {
+	ret := _mock.Called(ctx, identifier, slug, resolution, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAggregates")
+	}
+
+	var r0 []store.Aggregate
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, time.Duration, time.Time, time.Time) ([]store.Aggregate, error)); ok {
+		return returnFunc(ctx, identifier, slug, resolution, from, to)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, time.Duration, time.Time, time.Time) []store.Aggregate); ok {
+		r0 = returnFunc(ctx, identifier, slug, resolution, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]store.Aggregate)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, time.Duration, time.Time, time.Time) error); ok {
+		r1 = returnFunc(ctx, identifier, slug, resolution, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Database_GetAggregates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAggregates'
+type Database_GetAggregates_Call struct {
+	*mock.Call
+}
+
+// GetAggregates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - identifier string
+//   - slug string
+//   - resolution time.Duration
+//   - from time.Time
+//   - to time.Time
+func (_e *Database_Expecter) GetAggregates(ctx interface{}, identifier interface{}, slug interface{}, resolution interface{}, from interface{}, to interface{}) *Database_GetAggregates_Call {
+	return &Database_GetAggregates_Call{Call: _e.mock.On("GetAggregates", ctx, identifier, slug, resolution, from, to)}
+}
+
+func (_c *Database_GetAggregates_Call) Run(run func(ctx context.Context, identifier string, slug string, resolution time.Duration, from time.Time, to time.Time)) *Database_GetAggregates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 time.Duration
+		if args[3] != nil {
+			arg3 = args[3].(time.Duration)
+		}
+		var arg4 time.Time
+		if args[4] != nil {
+			arg4 = args[4].(time.Time)
+		}
+		var arg5 time.Time
+		if args[5] != nil {
+			arg5 = args[5].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *Database_GetAggregates_Call) Return(aggregates []store.Aggregate, err error) *Database_GetAggregates_Call {
+	_c.Call.Return(aggregates, err)
+	return _c
+}
+
+func (_c *Database_GetAggregates_Call) RunAndReturn(run func(ctx context.Context, identifier string, slug string, resolution time.Duration, from time.Time, to time.Time) ([]store.Aggregate, error)) *Database_GetAggregates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCommands provides a mock function for the type Database
+func (_mock *Database) GetCommands(ctx context.Context, filter store.CommandFilter) ([]store.Command, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommands")
+	}
+
+	var r0 []store.Command
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, store.CommandFilter) ([]store.Command, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, store.CommandFilter) []store.Command); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]store.Command)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, store.CommandFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Database_GetCommands_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCommands'
+type Database_GetCommands_Call struct {
+	*mock.Call
+}
+
+// GetCommands is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter store.CommandFilter
+func (_e *Database_Expecter) GetCommands(ctx interface{}, filter interface{}) *Database_GetCommands_Call {
+	return &Database_GetCommands_Call{Call: _e.mock.On("GetCommands", ctx, filter)}
+}
+
+func (_c *Database_GetCommands_Call) Run(run func(ctx context.Context, filter store.CommandFilter)) *Database_GetCommands_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 store.CommandFilter
+		if args[1] != nil {
+			arg1 = args[1].(store.CommandFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Database_GetCommands_Call) Return(commands []store.Command, err error) *Database_GetCommands_Call {
+	_c.Call.Return(commands, err)
+	return _c
+}
+
+func (_c *Database_GetCommands_Call) RunAndReturn(run func(ctx context.Context, filter store.CommandFilter) ([]store.Command, error)) *Database_GetCommands_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetLatestProperties provides a mock function for the type Database
 func (_mock *Database) GetLatestProperties(ctx context.Context) (iter.Seq[store.Property], error) {
 	ret := _mock.Called(ctx)
@@ -187,3 +347,157 @@ func (_c *Database_GetProperties_Call) RunAndReturn(run func(ctx context.Context
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetPropertyRange provides a mock function for the type Database
+func (_mock *Database) GetPropertyRange(ctx context.Context, identifier string, slug string, from time.Time, to time.Time, limit int) ([]store.Property, error) {
+	ret := _mock.Called(ctx, identifier, slug, from, to, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPropertyRange")
+	}
+
+	var r0 []store.Property
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time, int) ([]store.Property, error)); ok {
+		return returnFunc(ctx, identifier, slug, from, to, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time, int) []store.Property); ok {
+		r0 = returnFunc(ctx, identifier, slug, from, to, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]store.Property)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, time.Time, time.Time, int) error); ok {
+		r1 = returnFunc(ctx, identifier, slug, from, to, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Database_GetPropertyRange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPropertyRange'
+type Database_GetPropertyRange_Call struct {
+	*mock.Call
+}
+
+// GetPropertyRange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - identifier string
+//   - slug string
+//   - from time.Time
+//   - to time.Time
+//   - limit int
+func (_e *Database_Expecter) GetPropertyRange(ctx interface{}, identifier interface{}, slug interface{}, from interface{}, to interface{}, limit interface{}) *Database_GetPropertyRange_Call {
+	return &Database_GetPropertyRange_Call{Call: _e.mock.On("GetPropertyRange", ctx, identifier, slug, from, to, limit)}
+}
+
+func (_c *Database_GetPropertyRange_Call) Run(run func(ctx context.Context, identifier string, slug string, from time.Time, to time.Time, limit int)) *Database_GetPropertyRange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		var arg4 time.Time
+		if args[4] != nil {
+			arg4 = args[4].(time.Time)
+		}
+		var arg5 int
+		if args[5] != nil {
+			arg5 = args[5].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *Database_GetPropertyRange_Call) Return(propertys []store.Property, err error) *Database_GetPropertyRange_Call {
+	_c.Call.Return(propertys, err)
+	return _c
+}
+
+func (_c *Database_GetPropertyRange_Call) RunAndReturn(run func(ctx context.Context, identifier string, slug string, from time.Time, to time.Time, limit int) ([]store.Property, error)) *Database_GetPropertyRange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSensors provides a mock function for the type Database
+func (_mock *Database) GetSensors(ctx context.Context) ([]store.Sensor, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSensors")
+	}
+
+	var r0 []store.Sensor
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]store.Sensor, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []store.Sensor); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]store.Sensor)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Database_GetSensors_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSensors'
+type Database_GetSensors_Call struct {
+	*mock.Call
+}
+
+// GetSensors is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Database_Expecter) GetSensors(ctx interface{}) *Database_GetSensors_Call {
+	return &Database_GetSensors_Call{Call: _e.mock.On("GetSensors", ctx)}
+}
+
+func (_c *Database_GetSensors_Call) Run(run func(ctx context.Context)) *Database_GetSensors_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Database_GetSensors_Call) Return(sensors []store.Sensor, err error) *Database_GetSensors_Call {
+	_c.Call.Return(sensors, err)
+	return _c
+}
+
+func (_c *Database_GetSensors_Call) RunAndReturn(run func(ctx context.Context) ([]store.Sensor, error)) *Database_GetSensors_Call {
+	_c.Call.Return(run)
+	return _c
+}
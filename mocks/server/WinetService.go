@@ -5,6 +5,8 @@
 package mocks
 
 import (
+	"context"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -36,8 +38,8 @@ func (_m *WinetService) EXPECT() *WinetService_Expecter {
 }
 
 // SendBatteryStopCommand provides a mock function for the type WinetService
-func (_mock *WinetService) SendBatteryStopCommand() (bool, error) {
-	ret := _mock.Called()
+func (_mock *WinetService) SendBatteryStopCommand(ctx context.Context) (bool, error) {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendBatteryStopCommand")
@@ -45,16 +47,16 @@ func (_mock *WinetService) SendBatteryStopCommand() (bool, error) {
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() (bool, error)); ok {
-		return returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (bool, error)); ok {
+		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
-		r0 = returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = returnFunc(ctx)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func() error); ok {
-		r1 = returnFunc()
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -67,13 +69,20 @@ type WinetService_SendBatteryStopCommand_Call struct {
 }
 
 // SendBatteryStopCommand is a helper method to define mock.On call
-func (_e *WinetService_Expecter) SendBatteryStopCommand() *WinetService_SendBatteryStopCommand_Call {
-	return &WinetService_SendBatteryStopCommand_Call{Call: _e.mock.On("SendBatteryStopCommand")}
+//   - ctx context.Context
+func (_e *WinetService_Expecter) SendBatteryStopCommand(ctx interface{}) *WinetService_SendBatteryStopCommand_Call {
+	return &WinetService_SendBatteryStopCommand_Call{Call: _e.mock.On("SendBatteryStopCommand", ctx)}
 }
 
-func (_c *WinetService_SendBatteryStopCommand_Call) Run(run func()) *WinetService_SendBatteryStopCommand_Call {
+func (_c *WinetService_SendBatteryStopCommand_Call) Run(run func(ctx context.Context)) *WinetService_SendBatteryStopCommand_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
 	})
 	return _c
 }
@@ -83,14 +92,14 @@ func (_c *WinetService_SendBatteryStopCommand_Call) Return(b bool, err error) *W
 	return _c
 }
 
-func (_c *WinetService_SendBatteryStopCommand_Call) RunAndReturn(run func() (bool, error)) *WinetService_SendBatteryStopCommand_Call {
+func (_c *WinetService_SendBatteryStopCommand_Call) RunAndReturn(run func(ctx context.Context) (bool, error)) *WinetService_SendBatteryStopCommand_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SendChargeCommand provides a mock function for the type WinetService
-func (_mock *WinetService) SendChargeCommand(chargePower string) (bool, error) {
-	ret := _mock.Called(chargePower)
+func (_mock *WinetService) SendChargeCommand(ctx context.Context, chargePower string) (bool, error) {
+	ret := _mock.Called(ctx, chargePower)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendChargeCommand")
@@ -98,16 +107,16 @@ func (_mock *WinetService) SendChargeCommand(chargePower string) (bool, error) {
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (bool, error)); ok {
-		return returnFunc(chargePower)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, chargePower)
 	}
-	if returnFunc, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = returnFunc(chargePower)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, chargePower)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(chargePower)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, chargePower)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -120,19 +129,25 @@ type WinetService_SendChargeCommand_Call struct {
 }
 
 // SendChargeCommand is a helper method to define mock.On call
+//   - ctx context.Context
 //   - chargePower string
-func (_e *WinetService_Expecter) SendChargeCommand(chargePower interface{}) *WinetService_SendChargeCommand_Call {
-	return &WinetService_SendChargeCommand_Call{Call: _e.mock.On("SendChargeCommand", chargePower)}
+func (_e *WinetService_Expecter) SendChargeCommand(ctx interface{}, chargePower interface{}) *WinetService_SendChargeCommand_Call {
+	return &WinetService_SendChargeCommand_Call{Call: _e.mock.On("SendChargeCommand", ctx, chargePower)}
 }
 
-func (_c *WinetService_SendChargeCommand_Call) Run(run func(chargePower string)) *WinetService_SendChargeCommand_Call {
+func (_c *WinetService_SendChargeCommand_Call) Run(run func(ctx context.Context, chargePower string)) *WinetService_SendChargeCommand_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(string)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
@@ -143,14 +158,14 @@ func (_c *WinetService_SendChargeCommand_Call) Return(b bool, err error) *WinetS
 	return _c
 }
 
-func (_c *WinetService_SendChargeCommand_Call) RunAndReturn(run func(chargePower string) (bool, error)) *WinetService_SendChargeCommand_Call {
+func (_c *WinetService_SendChargeCommand_Call) RunAndReturn(run func(ctx context.Context, chargePower string) (bool, error)) *WinetService_SendChargeCommand_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SendDischargeCommand provides a mock function for the type WinetService
-func (_mock *WinetService) SendDischargeCommand(dischargePower string) (bool, error) {
-	ret := _mock.Called(dischargePower)
+func (_mock *WinetService) SendDischargeCommand(ctx context.Context, dischargePower string) (bool, error) {
+	ret := _mock.Called(ctx, dischargePower)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendDischargeCommand")
@@ -158,16 +173,16 @@ func (_mock *WinetService) SendDischargeCommand(dischargePower string) (bool, er
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (bool, error)); ok {
-		return returnFunc(dischargePower)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, dischargePower)
 	}
-	if returnFunc, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = returnFunc(dischargePower)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, dischargePower)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(dischargePower)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, dischargePower)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -180,19 +195,25 @@ type WinetService_SendDischargeCommand_Call struct {
 }
 
 // SendDischargeCommand is a helper method to define mock.On call
+//   - ctx context.Context
 //   - dischargePower string
-func (_e *WinetService_Expecter) SendDischargeCommand(dischargePower interface{}) *WinetService_SendDischargeCommand_Call {
-	return &WinetService_SendDischargeCommand_Call{Call: _e.mock.On("SendDischargeCommand", dischargePower)}
+func (_e *WinetService_Expecter) SendDischargeCommand(ctx interface{}, dischargePower interface{}) *WinetService_SendDischargeCommand_Call {
+	return &WinetService_SendDischargeCommand_Call{Call: _e.mock.On("SendDischargeCommand", ctx, dischargePower)}
 }
 
-func (_c *WinetService_SendDischargeCommand_Call) Run(run func(dischargePower string)) *WinetService_SendDischargeCommand_Call {
+func (_c *WinetService_SendDischargeCommand_Call) Run(run func(ctx context.Context, dischargePower string)) *WinetService_SendDischargeCommand_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(string)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
@@ -203,14 +224,14 @@ func (_c *WinetService_SendDischargeCommand_Call) Return(b bool, err error) *Win
 	return _c
 }
 
-func (_c *WinetService_SendDischargeCommand_Call) RunAndReturn(run func(dischargePower string) (bool, error)) *WinetService_SendDischargeCommand_Call {
+func (_c *WinetService_SendDischargeCommand_Call) RunAndReturn(run func(ctx context.Context, dischargePower string) (bool, error)) *WinetService_SendDischargeCommand_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SendInverterStateChangeCommand provides a mock function for the type WinetService
-func (_mock *WinetService) SendInverterStateChangeCommand(disable bool) (bool, error) {
-	ret := _mock.Called(disable)
+func (_mock *WinetService) SendInverterStateChangeCommand(ctx context.Context, disable bool) (bool, error) {
+	ret := _mock.Called(ctx, disable)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendInverterStateChangeCommand")
@@ -218,16 +239,16 @@ func (_mock *WinetService) SendInverterStateChangeCommand(disable bool) (bool, e
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(bool) (bool, error)); ok {
-		return returnFunc(disable)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) (bool, error)); ok {
+		return returnFunc(ctx, disable)
 	}
-	if returnFunc, ok := ret.Get(0).(func(bool) bool); ok {
-		r0 = returnFunc(disable)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) bool); ok {
+		r0 = returnFunc(ctx, disable)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(bool) error); ok {
-		r1 = returnFunc(disable)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, bool) error); ok {
+		r1 = returnFunc(ctx, disable)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -240,19 +261,25 @@ type WinetService_SendInverterStateChangeCommand_Call struct {
 }
 
 // SendInverterStateChangeCommand is a helper method to define mock.On call
+//   - ctx context.Context
 //   - disable bool
-func (_e *WinetService_Expecter) SendInverterStateChangeCommand(disable interface{}) *WinetService_SendInverterStateChangeCommand_Call {
-	return &WinetService_SendInverterStateChangeCommand_Call{Call: _e.mock.On("SendInverterStateChangeCommand", disable)}
+func (_e *WinetService_Expecter) SendInverterStateChangeCommand(ctx interface{}, disable interface{}) *WinetService_SendInverterStateChangeCommand_Call {
+	return &WinetService_SendInverterStateChangeCommand_Call{Call: _e.mock.On("SendInverterStateChangeCommand", ctx, disable)}
 }
 
-func (_c *WinetService_SendInverterStateChangeCommand_Call) Run(run func(disable bool)) *WinetService_SendInverterStateChangeCommand_Call {
+func (_c *WinetService_SendInverterStateChangeCommand_Call) Run(run func(ctx context.Context, disable bool)) *WinetService_SendInverterStateChangeCommand_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 bool
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(bool)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 bool
+		if args[1] != nil {
+			arg1 = args[1].(bool)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
@@ -263,14 +290,14 @@ func (_c *WinetService_SendInverterStateChangeCommand_Call) Return(b bool, err e
 	return _c
 }
 
-func (_c *WinetService_SendInverterStateChangeCommand_Call) RunAndReturn(run func(disable bool) (bool, error)) *WinetService_SendInverterStateChangeCommand_Call {
+func (_c *WinetService_SendInverterStateChangeCommand_Call) RunAndReturn(run func(ctx context.Context, disable bool) (bool, error)) *WinetService_SendInverterStateChangeCommand_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SendSelfConsumptionCommand provides a mock function for the type WinetService
-func (_mock *WinetService) SendSelfConsumptionCommand() (bool, error) {
-	ret := _mock.Called()
+func (_mock *WinetService) SendSelfConsumptionCommand(ctx context.Context) (bool, error) {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendSelfConsumptionCommand")
@@ -278,16 +305,16 @@ func (_mock *WinetService) SendSelfConsumptionCommand() (bool, error) {
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() (bool, error)); ok {
-		return returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (bool, error)); ok {
+		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
-		r0 = returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = returnFunc(ctx)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func() error); ok {
-		r1 = returnFunc()
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -300,13 +327,20 @@ type WinetService_SendSelfConsumptionCommand_Call struct {
 }
 
 // SendSelfConsumptionCommand is a helper method to define mock.On call
-func (_e *WinetService_Expecter) SendSelfConsumptionCommand() *WinetService_SendSelfConsumptionCommand_Call {
-	return &WinetService_SendSelfConsumptionCommand_Call{Call: _e.mock.On("SendSelfConsumptionCommand")}
+//   - ctx context.Context
+func (_e *WinetService_Expecter) SendSelfConsumptionCommand(ctx interface{}) *WinetService_SendSelfConsumptionCommand_Call {
+	return &WinetService_SendSelfConsumptionCommand_Call{Call: _e.mock.On("SendSelfConsumptionCommand", ctx)}
 }
 
-func (_c *WinetService_SendSelfConsumptionCommand_Call) Run(run func()) *WinetService_SendSelfConsumptionCommand_Call {
+func (_c *WinetService_SendSelfConsumptionCommand_Call) Run(run func(ctx context.Context)) *WinetService_SendSelfConsumptionCommand_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
 	})
 	return _c
 }
@@ -316,14 +350,14 @@ func (_c *WinetService_SendSelfConsumptionCommand_Call) Return(b bool, err error
 	return _c
 }
 
-func (_c *WinetService_SendSelfConsumptionCommand_Call) RunAndReturn(run func() (bool, error)) *WinetService_SendSelfConsumptionCommand_Call {
+func (_c *WinetService_SendSelfConsumptionCommand_Call) RunAndReturn(run func(ctx context.Context) (bool, error)) *WinetService_SendSelfConsumptionCommand_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SetFeedInLimitation provides a mock function for the type WinetService
-func (_mock *WinetService) SetFeedInLimitation(feedinLimited bool) (bool, error) {
-	ret := _mock.Called(feedinLimited)
+func (_mock *WinetService) SetFeedInLimitation(ctx context.Context, feedinLimited bool) (bool, error) {
+	ret := _mock.Called(ctx, feedinLimited)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SetFeedInLimitation")
@@ -331,16 +365,16 @@ func (_mock *WinetService) SetFeedInLimitation(feedinLimited bool) (bool, error)
 
 	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(bool) (bool, error)); ok {
-		return returnFunc(feedinLimited)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) (bool, error)); ok {
+		return returnFunc(ctx, feedinLimited)
 	}
-	if returnFunc, ok := ret.Get(0).(func(bool) bool); ok {
-		r0 = returnFunc(feedinLimited)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) bool); ok {
+		r0 = returnFunc(ctx, feedinLimited)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(bool) error); ok {
-		r1 = returnFunc(feedinLimited)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, bool) error); ok {
+		r1 = returnFunc(ctx, feedinLimited)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -353,19 +387,25 @@ type WinetService_SetFeedInLimitation_Call struct {
 }
 
 // SetFeedInLimitation is a helper method to define mock.On call
+//   - ctx context.Context
 //   - feedinLimited bool
-func (_e *WinetService_Expecter) SetFeedInLimitation(feedinLimited interface{}) *WinetService_SetFeedInLimitation_Call {
-	return &WinetService_SetFeedInLimitation_Call{Call: _e.mock.On("SetFeedInLimitation", feedinLimited)}
+func (_e *WinetService_Expecter) SetFeedInLimitation(ctx interface{}, feedinLimited interface{}) *WinetService_SetFeedInLimitation_Call {
+	return &WinetService_SetFeedInLimitation_Call{Call: _e.mock.On("SetFeedInLimitation", ctx, feedinLimited)}
 }
 
-func (_c *WinetService_SetFeedInLimitation_Call) Run(run func(feedinLimited bool)) *WinetService_SetFeedInLimitation_Call {
+func (_c *WinetService_SetFeedInLimitation_Call) Run(run func(ctx context.Context, feedinLimited bool)) *WinetService_SetFeedInLimitation_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 bool
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(bool)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 bool
+		if args[1] != nil {
+			arg1 = args[1].(bool)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
@@ -376,7 +416,7 @@ func (_c *WinetService_SetFeedInLimitation_Call) Return(b bool, err error) *Wine
 	return _c
 }
 
-func (_c *WinetService_SetFeedInLimitation_Call) RunAndReturn(run func(feedinLimited bool) (bool, error)) *WinetService_SetFeedInLimitation_Call {
+func (_c *WinetService_SetFeedInLimitation_Call) RunAndReturn(run func(ctx context.Context, feedinLimited bool) (bool, error)) *WinetService_SetFeedInLimitation_Call {
 	_c.Call.Return(run)
 	return _c
 }
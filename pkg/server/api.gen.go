@@ -18,6 +18,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // Defines values for ChangeBatteryStatePayloadState.
@@ -62,6 +63,152 @@ func (e ChangeInverterStatePayloadState) Valid() bool {
 	}
 }
 
+// Defines values for InverterStateBatteryState.
+const (
+	InverterStateBatteryStateCHARGING        InverterStateBatteryState = "CHARGING"
+	InverterStateBatteryStateDISCHARGING     InverterStateBatteryState = "DISCHARGING"
+	InverterStateBatteryStateSELFCONSUMPTION InverterStateBatteryState = "SELF_CONSUMPTION"
+	InverterStateBatteryStateSTOPPED         InverterStateBatteryState = "STOPPED"
+	InverterStateBatteryStateUNKNOWN         InverterStateBatteryState = "UNKNOWN"
+)
+
+// Valid indicates whether the value is a known member of the InverterStateBatteryState enum.
+func (e InverterStateBatteryState) Valid() bool {
+	switch e {
+	case InverterStateBatteryStateCHARGING:
+		return true
+	case InverterStateBatteryStateDISCHARGING:
+		return true
+	case InverterStateBatteryStateSELFCONSUMPTION:
+		return true
+	case InverterStateBatteryStateSTOPPED:
+		return true
+	case InverterStateBatteryStateUNKNOWN:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for InverterStateState.
+const (
+	InverterStateStateFAULT   InverterStateState = "FAULT"
+	InverterStateStateOFF     InverterStateState = "OFF"
+	InverterStateStateON      InverterStateState = "ON"
+	InverterStateStateUNKNOWN InverterStateState = "UNKNOWN"
+)
+
+// Valid indicates whether the value is a known member of the InverterStateState enum.
+func (e InverterStateState) Valid() bool {
+	switch e {
+	case InverterStateStateFAULT:
+		return true
+	case InverterStateStateOFF:
+		return true
+	case InverterStateStateON:
+		return true
+	case InverterStateStateUNKNOWN:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for GetCommandsParamsSource.
+const (
+	Api        GetCommandsParamsSource = "api"
+	Automation GetCommandsParamsSource = "automation"
+	Mqtt       GetCommandsParamsSource = "mqtt"
+)
+
+// Valid indicates whether the value is a known member of the GetCommandsParamsSource enum.
+func (e GetCommandsParamsSource) Valid() bool {
+	switch e {
+	case Api:
+		return true
+	case Automation:
+		return true
+	case Mqtt:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for GetPropertyIdentifierSlugAggregateParamsFn.
+const (
+	Avg   GetPropertyIdentifierSlugAggregateParamsFn = "avg"
+	Count GetPropertyIdentifierSlugAggregateParamsFn = "count"
+	Last  GetPropertyIdentifierSlugAggregateParamsFn = "last"
+	Max   GetPropertyIdentifierSlugAggregateParamsFn = "max"
+	Min   GetPropertyIdentifierSlugAggregateParamsFn = "min"
+	Sum   GetPropertyIdentifierSlugAggregateParamsFn = "sum"
+)
+
+// Valid indicates whether the value is a known member of the GetPropertyIdentifierSlugAggregateParamsFn enum.
+func (e GetPropertyIdentifierSlugAggregateParamsFn) Valid() bool {
+	switch e {
+	case Avg:
+		return true
+	case Count:
+		return true
+	case Last:
+		return true
+	case Max:
+		return true
+	case Min:
+		return true
+	case Sum:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for GetReportsEnergyParamsInterval.
+const (
+	Day   GetReportsEnergyParamsInterval = "day"
+	Month GetReportsEnergyParamsInterval = "month"
+)
+
+// Valid indicates whether the value is a known member of the GetReportsEnergyParamsInterval enum.
+func (e GetReportsEnergyParamsInterval) Valid() bool {
+	switch e {
+	case Day:
+		return true
+	case Month:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for GetSeriesParamsDownsample.
+const (
+	Bucket GetSeriesParamsDownsample = "bucket"
+	Lttb   GetSeriesParamsDownsample = "lttb"
+)
+
+// Valid indicates whether the value is a known member of the GetSeriesParamsDownsample enum.
+func (e GetSeriesParamsDownsample) Valid() bool {
+	switch e {
+	case Bucket:
+		return true
+	case Lttb:
+		return true
+	default:
+		return false
+	}
+}
+
+// AggregatePoint defines model for AggregatePoint.
+type AggregatePoint struct {
+	Count             int       `json:"count"`
+	Timestamp         time.Time `json:"timestamp"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Value             float64   `json:"value"`
+}
+
 // ChangeBatteryStatePayload defines model for ChangeBatteryStatePayload.
 type ChangeBatteryStatePayload struct {
 	Power *string                        `json:"power,omitempty"`
@@ -84,9 +231,84 @@ type ChangeInverterStatePayload struct {
 // ChangeInverterStatePayloadState defines model for ChangeInverterStatePayload.State.
 type ChangeInverterStatePayloadState string
 
+// CommandAudit defines model for CommandAudit.
+type CommandAudit struct {
+	Command     string             `json:"command"`
+	Error       *string            `json:"error,omitempty"`
+	Id          int                `json:"id"`
+	LatencyMs   int64              `json:"latency_ms"`
+	Params      *map[string]string `json:"params,omitempty"`
+	RequestedAt time.Time          `json:"requested_at"`
+
+	// ResultCode WiNet result_code; 0 when WiNet did not reply.
+	ResultCode int    `json:"result_code"`
+	Source     string `json:"source"`
+	Success    bool   `json:"success"`
+
+	// User Authenticated user; absent for automation.
+	User *string `json:"user,omitempty"`
+}
+
 // Empty defines model for Empty.
 type Empty = map[string]interface{}
 
+// EnergyPeriod Energy in kWh and money in the tariff currency over [start, end).
+type EnergyPeriod struct {
+	BatteryCharge    float64   `json:"battery_charge"`
+	BatteryDischarge float64   `json:"battery_discharge"`
+	Consumption      float64   `json:"consumption"`
+	End              time.Time `json:"end"`
+	ExportCredit     float64   `json:"export_credit"`
+	GridExport       float64   `json:"grid_export"`
+	GridImport       float64   `json:"grid_import"`
+	ImportCost       float64   `json:"import_cost"`
+
+	// NetCost import_cost plus supply_charge less export_credit.
+	NetCost      float64 `json:"net_cost"`
+	PvGeneration float64 `json:"pv_generation"`
+
+	// SelfSufficiency Share of consumption not drawn from the grid, 0 to 1. Absent without consumption.
+	SelfSufficiency *float64  `json:"self_sufficiency,omitempty"`
+	Start           time.Time `json:"start"`
+	SupplyCharge    float64   `json:"supply_charge"`
+}
+
+// EnergyReport defines model for EnergyReport.
+type EnergyReport struct {
+	Currency string         `json:"currency"`
+	Interval string         `json:"interval"`
+	Periods  []EnergyPeriod `json:"periods"`
+	Timezone string         `json:"timezone"`
+
+	// Total Energy in kWh and money in the tariff currency over [start, end).
+	Total EnergyPeriod `json:"total"`
+}
+
+// InverterState defines model for InverterState.
+type InverterState struct {
+	BatteryState InverterStateBatteryState `json:"battery_state"`
+
+	// ChargeRate Forced charge or discharge power in kW; 0 otherwise.
+	ChargeRate    float64 `json:"charge_rate"`
+	FeedinEnabled bool    `json:"feedin_enabled"`
+
+	// Id Inverter device identifier.
+	Id string `json:"id"`
+
+	// ReconciledAt When readings last overrode state, e.g. after a fault.
+	ReconciledAt *time.Time         `json:"reconciled_at,omitempty"`
+	State        InverterStateState `json:"state"`
+
+	// UpdatedAt When the last successful command was sent.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InverterStateBatteryState defines model for InverterState.BatteryState.
+type InverterStateBatteryState string
+
+// InverterStateState defines model for InverterState.State.
+type InverterStateState string
+
 // LoginRequest defines model for LoginRequest.
 type LoginRequest struct {
 	Password string `json:"password"`
@@ -108,12 +330,177 @@ type Property struct {
 	Value             *string    `json:"value,omitempty"`
 }
 
+// Reading defines model for Reading.
+type Reading struct {
+	Identifier        string    `json:"identifier"`
+	Slug              string    `json:"slug"`
+	Timestamp         time.Time `json:"timestamp"`
+	UnitOfMeasurement string    `json:"unit_of_measurement"`
+	Value             string    `json:"value"`
+}
+
+// RetentionRun defines model for RetentionRun.
+type RetentionRun struct {
+	// ChunksDropped Whole TimescaleDB chunks dropped; their rows are not counted above.
+	ChunksDropped *int64 `json:"chunks_dropped,omitempty"`
+
+	// Error Set when the run failed; nothing is deleted by a failed run.
+	Error          *string   `json:"error,omitempty"`
+	FinishedAt     time.Time `json:"finished_at"`
+	RollupsDeleted int64     `json:"rollups_deleted"`
+	RowsDeleted    int64     `json:"rows_deleted"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// RetentionStatus defines model for RetentionStatus.
+type RetentionStatus struct {
+	Enabled bool          `json:"enabled"`
+	LastRun *RetentionRun `json:"last_run,omitempty"`
+	NextRun *time.Time    `json:"next_run,omitempty"`
+
+	// RawRetention How long raw readings are kept; "0s" keeps them forever.
+	RawRetention *string `json:"raw_retention,omitempty"`
+
+	// RollupRetention How long rollups are kept, keyed by resolution. Resolutions not listed are kept forever.
+	RollupRetention *map[string]string `json:"rollup_retention,omitempty"`
+	Schedule        *string            `json:"schedule,omitempty"`
+
+	// SlugRetention Per-slug overrides of raw_retention.
+	SlugRetention *map[string]string `json:"slug_retention,omitempty"`
+}
+
+// Sensor defines model for Sensor.
+type Sensor struct {
+	FirstSeen  time.Time `json:"first_seen"`
+	Identifier string    `json:"identifier"`
+	LastSeen   time.Time `json:"last_seen"`
+	LastValue  string    `json:"last_value"`
+
+	// Name Display name from the WiNet i18n properties; empty until a reading carrying one is stored.
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+
+	// Text True for text sensors such as running status, whose values are not numbers.
+	Text              bool   `json:"text"`
+	UnitOfMeasurement string `json:"unit_of_measurement"`
+}
+
+// Series defines model for Series.
+type Series struct {
+	Identifier        string        `json:"identifier"`
+	Points            []SeriesPoint `json:"points"`
+	Slug              string        `json:"slug"`
+	UnitOfMeasurement string        `json:"unit_of_measurement"`
+}
+
+// SeriesPage defines model for SeriesPage.
+type SeriesPage struct {
+	// NextCursor Absent on the last page.
+	NextCursor *string  `json:"next_cursor,omitempty"`
+	Series     []Series `json:"series"`
+}
+
+// SeriesPoint defines model for SeriesPoint.
+type SeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// GetCommandsParams defines parameters for GetCommands.
+type GetCommandsParams struct {
+	From    *time.Time               `form:"from,omitempty" json:"from,omitempty"`
+	To      *time.Time               `form:"to,omitempty" json:"to,omitempty"`
+	User    *string                  `form:"user,omitempty" json:"user,omitempty"`
+	Source  *GetCommandsParamsSource `form:"source,omitempty" json:"source,omitempty"`
+	Command *string                  `form:"command,omitempty" json:"command,omitempty"`
+
+	// Limit Maximum number of entries to return, at most 1000.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetCommandsParamsSource defines parameters for GetCommands.
+type GetCommandsParamsSource string
+
 // GetPropertyIdentifierSlugParams defines parameters for GetPropertyIdentifierSlug.
 type GetPropertyIdentifierSlugParams struct {
 	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
 	To   *time.Time `form:"to,omitempty" json:"to,omitempty"`
 }
 
+// GetPropertyIdentifierSlugAggregateParams defines parameters for GetPropertyIdentifierSlugAggregate.
+type GetPropertyIdentifierSlugAggregateParams struct {
+	// Bucket Bucket width, e.g. 15m, 1h or 1d. Chosen from the time range when omitted.
+	Bucket *string                                     `form:"bucket,omitempty" json:"bucket,omitempty"`
+	Fn     *GetPropertyIdentifierSlugAggregateParamsFn `form:"fn,omitempty" json:"fn,omitempty"`
+	From   *time.Time                                  `form:"from,omitempty" json:"from,omitempty"`
+	To     *time.Time                                  `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetPropertyIdentifierSlugAggregateParamsFn defines parameters for GetPropertyIdentifierSlugAggregate.
+type GetPropertyIdentifierSlugAggregateParamsFn string
+
+// GetReportsEnergyParams defines parameters for GetReportsEnergy.
+type GetReportsEnergyParams struct {
+	// From First local date. Defaults to six days before to for days, or the start of the eleventh month before to for months.
+	From *openapi_types.Date `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Last local date, included. Defaults to today.
+	To       *openapi_types.Date             `form:"to,omitempty" json:"to,omitempty"`
+	Interval *GetReportsEnergyParamsInterval `form:"interval,omitempty" json:"interval,omitempty"`
+}
+
+// GetReportsEnergyParamsInterval defines parameters for GetReportsEnergy.
+type GetReportsEnergyParamsInterval string
+
+// GetSeriesParams defines parameters for GetSeries.
+type GetSeriesParams struct {
+	// Series identifier:slug of a sensor. Repeat for several.
+	Series []string `form:"series" json:"series"`
+
+	// From Defaults to two days before to.
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Defaults to now.
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// MaxPoints Points per series, between 3 and 5000. Raw readings are returned when omitted.
+	MaxPoints  *int                       `form:"max_points,omitempty" json:"max_points,omitempty"`
+	Downsample *GetSeriesParamsDownsample `form:"downsample,omitempty" json:"downsample,omitempty"`
+
+	// Limit Rows per page across all series; defaults to 10000, at most 100000.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor from the previous page. The page keeps the time range of the first one.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetSeriesParamsDownsample defines parameters for GetSeries.
+type GetSeriesParamsDownsample string
+
+// GetStreamParams defines parameters for GetStream.
+type GetStreamParams struct {
+	// Identifier Only readings from these devices. Repeat for several.
+	Identifier *[]string `form:"identifier,omitempty" json:"identifier,omitempty"`
+
+	// Slug Only these sensors. Repeat for several.
+	Slug *[]string `form:"slug,omitempty" json:"slug,omitempty"`
+
+	// AccessToken Access token, for clients that cannot send an Authorization header (EventSource, browser WebSocket).
+	AccessToken *string `form:"access_token,omitempty" json:"access_token,omitempty"`
+}
+
+// GetStreamWsParams defines parameters for GetStreamWs.
+type GetStreamWsParams struct {
+	// Identifier Only readings from these devices. Repeat for several.
+	Identifier *[]string `form:"identifier,omitempty" json:"identifier,omitempty"`
+
+	// Slug Only these sensors. Repeat for several.
+	Slug *[]string `form:"slug,omitempty" json:"slug,omitempty"`
+
+	// AccessToken Access token, for clients that cannot send an Authorization header (EventSource, browser WebSocket).
+	AccessToken *string `form:"access_token,omitempty" json:"access_token,omitempty"`
+}
+
 // PostAuthLoginJSONRequestBody defines body for PostAuthLogin for application/json ContentType.
 type PostAuthLoginJSONRequestBody = LoginRequest
 
@@ -140,9 +527,15 @@ type ServerInterface interface {
 
 	// (POST /battery/{state})
 	PostBatteryState(w http.ResponseWriter, r *http.Request, state string)
+	// Audit log of inverter and battery commands, newest first
+	// (GET /commands)
+	GetCommands(w http.ResponseWriter, r *http.Request, params GetCommandsParams)
 
 	// (POST /inverter/feedin)
 	PostInverterFeedin(w http.ResponseWriter, r *http.Request)
+	// Control state the service last put the inverter into
+	// (GET /inverter/state)
+	GetInverterState(w http.ResponseWriter, r *http.Request)
 
 	// (POST /inverter/{state})
 	PostInverterState(w http.ResponseWriter, r *http.Request, state string)
@@ -152,6 +545,27 @@ type ServerInterface interface {
 
 	// (GET /property/{identifier}/{slug})
 	GetPropertyIdentifierSlug(w http.ResponseWriter, r *http.Request, identifier string, slug string, params GetPropertyIdentifierSlugParams)
+	// Get aggregated readings for a numeric sensor
+	// (GET /property/{identifier}/{slug}/aggregate)
+	GetPropertyIdentifierSlugAggregate(w http.ResponseWriter, r *http.Request, identifier string, slug string, params GetPropertyIdentifierSlugAggregateParams)
+	// Energy totals and cost per day or month
+	// (GET /reports/energy)
+	GetReportsEnergy(w http.ResponseWriter, r *http.Request, params GetReportsEnergyParams)
+	// Get the data retention policy and the result of its last run
+	// (GET /retention)
+	GetRetention(w http.ResponseWriter, r *http.Request)
+	// Catalog of every sensor that has reported a reading
+	// (GET /sensors)
+	GetSensors(w http.ResponseWriter, r *http.Request)
+	// Numeric readings for several sensors, optionally downsampled
+	// (GET /series)
+	GetSeries(w http.ResponseWriter, r *http.Request, params GetSeriesParams)
+	// Live readings as Server-Sent Events
+	// (GET /stream)
+	GetStream(w http.ResponseWriter, r *http.Request, params GetStreamParams)
+	// Live readings over a WebSocket
+	// (GET /stream/ws)
+	GetStreamWs(w http.ResponseWriter, r *http.Request, params GetStreamWsParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -230,6 +644,73 @@ func (siw *ServerInterfaceWrapper) PostBatteryState(w http.ResponseWriter, r *ht
 	handler.ServeHTTP(w, r)
 }
 
+// GetCommands operation middleware
+func (siw *ServerInterfaceWrapper) GetCommands(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCommandsParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "from", r.URL.Query(), &params.From, runtime.BindQueryParameterOptions{Type: "string", Format: "date-time"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "to", r.URL.Query(), &params.To, runtime.BindQueryParameterOptions{Type: "string", Format: "date-time"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "user" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "user", r.URL.Query(), &params.User, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "source" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "source", r.URL.Query(), &params.Source, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "source", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "command" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "command", r.URL.Query(), &params.Command, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "command", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "limit", r.URL.Query(), &params.Limit, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCommands(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // PostInverterFeedin operation middleware
 func (siw *ServerInterfaceWrapper) PostInverterFeedin(w http.ResponseWriter, r *http.Request) {
 
@@ -244,6 +725,20 @@ func (siw *ServerInterfaceWrapper) PostInverterFeedin(w http.ResponseWriter, r *
 	handler.ServeHTTP(w, r)
 }
 
+// GetInverterState operation middleware
+func (siw *ServerInterfaceWrapper) GetInverterState(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetInverterState(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // PostInverterState operation middleware
 func (siw *ServerInterfaceWrapper) PostInverterState(w http.ResponseWriter, r *http.Request) {
 
@@ -336,6 +831,314 @@ func (siw *ServerInterfaceWrapper) GetPropertyIdentifierSlug(w http.ResponseWrit
 	handler.ServeHTTP(w, r)
 }
 
+// GetPropertyIdentifierSlugAggregate operation middleware
+func (siw *ServerInterfaceWrapper) GetPropertyIdentifierSlugAggregate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "identifier" -------------
+	var identifier string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "identifier", r.PathValue("identifier"), &identifier, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "identifier", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "slug" -------------
+	var slug string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "slug", r.PathValue("slug"), &slug, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "slug", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPropertyIdentifierSlugAggregateParams
+
+	// ------------- Optional query parameter "bucket" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "bucket", r.URL.Query(), &params.Bucket, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "bucket", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "fn" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "fn", r.URL.Query(), &params.Fn, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "fn", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "from", r.URL.Query(), &params.From, runtime.BindQueryParameterOptions{Type: "string", Format: "date-time"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "to", r.URL.Query(), &params.To, runtime.BindQueryParameterOptions{Type: "string", Format: "date-time"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPropertyIdentifierSlugAggregate(w, r, identifier, slug, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetReportsEnergy operation middleware
+func (siw *ServerInterfaceWrapper) GetReportsEnergy(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetReportsEnergyParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "from", r.URL.Query(), &params.From, runtime.BindQueryParameterOptions{Type: "string", Format: "date"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "to", r.URL.Query(), &params.To, runtime.BindQueryParameterOptions{Type: "string", Format: "date"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "interval" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "interval", r.URL.Query(), &params.Interval, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "interval", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetReportsEnergy(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRetention operation middleware
+func (siw *ServerInterfaceWrapper) GetRetention(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRetention(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSensors operation middleware
+func (siw *ServerInterfaceWrapper) GetSensors(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSensors(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSeries operation middleware
+func (siw *ServerInterfaceWrapper) GetSeries(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSeriesParams
+
+	// ------------- Required query parameter "series" -------------
+
+	if paramValue := r.URL.Query().Get("series"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "series"})
+		return
+	}
+
+	err = runtime.BindQueryParameterWithOptions("form", true, true, "series", r.URL.Query(), &params.Series, runtime.BindQueryParameterOptions{Type: "array", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "series", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "from", r.URL.Query(), &params.From, runtime.BindQueryParameterOptions{Type: "string", Format: "date-time"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "to", r.URL.Query(), &params.To, runtime.BindQueryParameterOptions{Type: "string", Format: "date-time"})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "max_points" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "max_points", r.URL.Query(), &params.MaxPoints, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "max_points", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "downsample" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "downsample", r.URL.Query(), &params.Downsample, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "downsample", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "limit", r.URL.Query(), &params.Limit, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "cursor", r.URL.Query(), &params.Cursor, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSeries(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetStream operation middleware
+func (siw *ServerInterfaceWrapper) GetStream(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetStreamParams
+
+	// ------------- Optional query parameter "identifier" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "identifier", r.URL.Query(), &params.Identifier, runtime.BindQueryParameterOptions{Type: "array", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "identifier", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "slug" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "slug", r.URL.Query(), &params.Slug, runtime.BindQueryParameterOptions{Type: "array", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "slug", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "access_token" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "access_token", r.URL.Query(), &params.AccessToken, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "access_token", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetStream(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetStreamWs operation middleware
+func (siw *ServerInterfaceWrapper) GetStreamWs(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetStreamWsParams
+
+	// ------------- Optional query parameter "identifier" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "identifier", r.URL.Query(), &params.Identifier, runtime.BindQueryParameterOptions{Type: "array", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "identifier", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "slug" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "slug", r.URL.Query(), &params.Slug, runtime.BindQueryParameterOptions{Type: "array", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "slug", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "access_token" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "access_token", r.URL.Query(), &params.AccessToken, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "access_token", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetStreamWs(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -460,10 +1263,19 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc("POST "+options.BaseURL+"/auth/logout", wrapper.PostAuthLogout)
 	m.HandleFunc("POST "+options.BaseURL+"/auth/refresh", wrapper.PostAuthRefresh)
 	m.HandleFunc("POST "+options.BaseURL+"/battery/{state}", wrapper.PostBatteryState)
+	m.HandleFunc("GET "+options.BaseURL+"/commands", wrapper.GetCommands)
 	m.HandleFunc("POST "+options.BaseURL+"/inverter/feedin", wrapper.PostInverterFeedin)
+	m.HandleFunc("GET "+options.BaseURL+"/inverter/state", wrapper.GetInverterState)
 	m.HandleFunc("POST "+options.BaseURL+"/inverter/{state}", wrapper.PostInverterState)
 	m.HandleFunc("GET "+options.BaseURL+"/properties", wrapper.GetProperties)
 	m.HandleFunc("GET "+options.BaseURL+"/property/{identifier}/{slug}", wrapper.GetPropertyIdentifierSlug)
+	m.HandleFunc("GET "+options.BaseURL+"/property/{identifier}/{slug}/aggregate", wrapper.GetPropertyIdentifierSlugAggregate)
+	m.HandleFunc("GET "+options.BaseURL+"/reports/energy", wrapper.GetReportsEnergy)
+	m.HandleFunc("GET "+options.BaseURL+"/retention", wrapper.GetRetention)
+	m.HandleFunc("GET "+options.BaseURL+"/sensors", wrapper.GetSensors)
+	m.HandleFunc("GET "+options.BaseURL+"/series", wrapper.GetSeries)
+	m.HandleFunc("GET "+options.BaseURL+"/stream", wrapper.GetStream)
+	m.HandleFunc("GET "+options.BaseURL+"/stream/ws", wrapper.GetStreamWs)
 
 	return m
 }
@@ -471,23 +1283,81 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/9RWTW/bRhD9K4tpj7RFOYEPvCVFmxoIWsMqEKCBIazIIbUxubuenZVDCPrvxS5libQo",
-	"f6AxktwIcj7fzBu+NeSmsUajZgfZGly+xEbGx9+WUlf4XjIjtTOWjJeyrY0swkdLxiKxwmhqzR1SeMCv",
-	"srE1QnZ+ep4AtxYhA8ekdAWbBFyIEu20byD7DA7rcp4b7XxjWRkNCeRLSRVCAoVyu2fHxsJ1so8/5qk0",
-	"ZGAlL+Eg9SYBwluvCIuYNtZxvTMziy+Yc6iwa/oPxELpo+0WyslFjYOGmTzu4i2MqVHqg7z3jsczX+gV",
-	"EiM9jvcBjrF/U5ZDjL4ZKr83ltuQ7+DLR1MpfYW3Hh2P7IV07s5QMUAKnLdIDnNChpEl8Q5Jy2YIL8ha",
-	"5fhkCzvfZJ97rKFt2c4a7fCwbpnn6NyczQ3qXttHkg6sx7JddsHbw0RqCM1056w0Y4UUvFWBmlWpHjAM",
-	"Zn+ep1ez+bvpGIiu9tXQfCHzG2/nZagcdd6OebFq0LFs7ND1LD17czJNT9LpP9OzLE2zNP0XEigNNZIh",
-	"g0IyngTf0XFqxXNTzhuUzhM2qHkY/ebTcsxvJWv/YAemafrkBhynWLBUujRxoIpjxE9KI5/MxAxphQQJ",
-	"rJBcuCYZTE/T0zQUYixqaRVk8Ca+SiKb4vgm0vNyUodl6s5gR4IwYRmO0kUBGVwax+88L+POQVctOn5v",
-	"irgQudG8xURaW6s8ek6+OKP3Bzk8/UpYQga/TPYXe7I915MBDTdDTMJlii+6bY+Fn6Xpt8695VJMXqDL",
-	"SXV3OevIJpyPNCl9HUB9m07jJR0YXuiVrFUhcsK487J2cb7ON42k9j5UfLeD3nh+FvbB7gCFt4dFfDRV",
-	"hYUI5ge5jWchdSEIV+YGBWFJ6JaiY/6+qu37p8u62hp+z+n8hXeiu2D3fRyZzlW/W6G2szIk8KuNmzaE",
-	"6968H1t4p3Qllsz2b123IjfmRmGH3KITGpN1/A1tHgevr0oiIUk2yEgOss/rwS+v+5Ns/20PaZH0QHx4",
-	"Vq5fh6nHVdXIbDpjEc3Ezu4Vt6X70Y9UkneVRBgF9dYpjE5tRcukjLrp8dHdK5xOY8FrojyUccfxDXYi",
-	"nChkVrpyPzLEz6LHQEX+jPwYlcE/L0GGoq/CkbF9QL7cW/3PDhRj455qZadKNzupJInkaHf7Bvq99c/9",
-	"B2TRa7PfdjtZ7zXsZrIO2nTzDCDai53XLMjZ5+xxTyy/ZJmTcVJ0WV8e5tYjtfs4JZkG+n7PUc7HgrF5",
-	"eajrH22fCNmTdsJZzFWp8t7qiEUrSlUz0mmkz2bzXwAAAP//4G4XyaQQAAA=",
+	"H4sIAAAAAAAC/+xbe3PbSHL/Kl1IUtlNIAqUvF6v9JdWfqwTr+wS5TgVr4s3AprEnIAZeGYgiufyd0/1",
+	"DN4ciJDPvtutu/I/FjmPnn7++sFPQSzzQgoURgcnnwIdp5gz+9+z9Vrhmhl8I7kw9EmhZIHKcLTfx7J0",
+	"H+Mdy4sMg5PHURiYbYHBScCFwTWq4HMYGJ6jNiwvemuDo+jo+GAeHUTzq/nRSRSdRNH/BWGwkipnJjgJ",
+	"EmbwgPYGzaHaKC7WdGYpuFnK1TJHpkuFOQ4oCW7e+bbdsqzE3sKj2fEP3UtleZ11bhRlfk2v+BwGCj+W",
+	"XGESnLzvPKk+M6zY4SftQ3OgvP4zxoZoOU+ZWOPPzBhU24UhPrNtJlmyy+lCblD1OT177HmeplPsOlHm",
+	"RKfGbLWMpdBlXhguBZGZMrUmehOum/9rIwsisuWfZycXwUlQMJPucnbAHkfH+KOfIyZcjD434ZpdZ+4h",
+	"NUFGldicdy1lhkzs3FtvHL/5pbhFZVDdz+8dPtr3y9Wqz6OvxxWZ50wkZ2XCvXZmv+3rd1d6O3qASkmr",
+	"Lzvf8P4xj458BpsxgyLeLnPdW/zkUdSxFC7M40eBb3vBFHNbWZJwUh6Wvem9aJfg+o5G14PHs8f0zQ6v",
+	"iKOoDSZLZkYdSnT8UIeiUJeZWcYysVQkqGPFneafBO/4BRroLDmFCDYpCnDfJDwBIWlFkW1nQec9cx+D",
+	"tCxV3FfvgBXcR5Yu4xi1nmAKYVBqVLu0n5UmRWF4zAwmQGtOgV1rFAZWUgErjcwZre3RHbCMx7hXpXkS",
+	"DCTSvC5s9LZ9RZ/NPUXzmcWzvDDbjr50vhGo1ts3qLhMdp/svgUu4OZdCkwkkEuB9gOTIhim+GoFcakU",
+	"3Q7yFhW814YpEwKK5HviRN8Er52XXlYm15XGT7PHXR0biR9hc0RruD3bmv046ZSuR+7unz+ZHU06AEUy",
+	"YjVHV9ZkTqLoP+fRyXE02XTwrpDKLGOFlftqDo9mPz6aRNVa8WTpzuk/69HsyfQDeL5zwNFsPmm/27qM",
+	"pd55wbQDBLa7+9rYORqKrNSgy6LIal2CDLWGHgv7DmQWTdOM4na5RoGK7ejG8dFsmhRszNflasVjTpax",
+	"+5ZFyhSCXEFHD63nSxTbCFgpmVsTI3GEEIGRMJ/BmXM3G25SWZru3t5To9mTacK2pjru+79Mi3tS6Svh",
+	"JB3aDfbKBM7ehsLpm3Ffefu2EA49j8+P9LV3aI/Dl3VU1etzre+8xNqSBlCk8pl95p+9ferjKBcG1S3L",
+	"+osTtvUtLqwvt5dwgw4+/KvCVXAS/Mthm6IcVvnJYS8CtDiBKcW2ddLxFykGUfas1EaxjLPDswQzxhOv",
+	"KhhpWPYwAoZxsX56h5Cw5V773Poynyh6WHVXFrUi7IDV81/OLl+8vHgRhMHTl4vOX4ur12/ePCNZLZ69",
+	"er48f32xePvrm6uXry+CMHh78d8Xr99dBB88DHGqs1TVRX2f8FyqGBNwa0AqaDQTLJZzcZgQkzQpqg3X",
+	"2DP7xxMD6MomDUsUBPGTDizoQCDuQQM1GyHBWx4j8ASF4SuOqkdGcHZ0dDw/On70w+Mf/QAxliLmWQM8",
+	"BxCR0KBClnCx1pAxbSyuUDJBsBIKAWfrGbAVkcJgxcrMevqJ3mkoZCuz18+fB2Hw/Oztq6s9EiwLOv4e",
+	"0slrW6orrLYqM6gAHGyYBo1iMrk+kOgeEA60tq9aOzLu0e0zkVdyzcWlw5+etJlpvZFqAHh0WaDSGCs0",
+	"3sqCRiVYPoTnk+Bwszds776HbF1IoT2WzawElkbeoPCkS4NLe6t9t1W513b3okE26E1WWnPprQ0WvzyO",
+	"LhfLs7lXXbNy3V9+zeKbsliuiPLKB+7s+jsVitJplaJgHkV7NeC+CsSl8w4+MXwVFlMtY+nS598Lc99N",
+	"Ze0PR/t52y26dVhWsaItxE0twF2ioUOkuCyFB+qkpbjRy0TJosDE5zVlhnBFNMUsw6c/g9sB1Y5Tcqlc",
+	"gZIbDUyhhcm2RogJsGt5i8NCwYTSSlPYGcByNLCpvbgqBawYBapTujPlYg1cQ4IZ0tXXW2DV97R05pPQ",
+	"iguu0yZcTKygyCwrC72sLhrkcRNrR8Qt/wk/Hj2ZdoRF3g+i3Qfd62pGlxUD8naffK+WEYwr9a6idQDN",
+	"3hIPReilKsU+cNpTbZud3jX7/Kn/8RclTYptlqq+a1cvf5EbyKRYg2KbFhyRMdxgYU7htyDSvwVwg1ho",
+	"0t2cSlJ4O0Rm85+O0iiP9LjW9YmYXHYco9aJtSE0hBvcOtNRqGVW2tQVLpv/a2vcGdfWtqtd3rd8Cub0",
+	"kM6bfDVOEmNSDirgQQTH8B/0bywcfBUuvEF1QIc5+MoT1JTv9wQ9eFEN6erK7Y9H0djTBpZWq77PbhYo",
+	"tFS75rLiSpulRvQpc/RTtwIwPZCNR+B5dHm13JMaZGycoi8NrfZMT6R8Es2iyAdEwqBGrX15PuW6yNgW",
+	"6Nu2QOPq1nz+REDL3lNAqrhCKQzPgNUWCzFTakv/kQIplGgjFSZ9G636WPAKbzGD7xavz7+fCgyd9mS0",
+	"0bfF4J0nablSJdoCNn0N2ioLldbiFJimwCaIYG19bgibVGoEy842GLvUUvfesWKZ9hfX90Gdf5uQB+1i",
+	"lipb8J1evTzsanxX13o64rcgVSdCk+DmHi0vJBdmeoHG3e5ax576zEPh65dAzQn89zO+euo4U9+wtSdv",
+	"s1E2LpX2YbSqCCo7qXbB1ujFX7qR3ANYvcvlIa5xy+55lb/P/y0yh13PRinA12jE7z7vs61FrqR7i3Ha",
+	"wgWagwUsUN1afbhFpZ2g5rNoFhGNskDBCh6cBMf2o9C2eS1LDllp0sOM0njXs3TlB1lUJd6XCUVSqQ11",
+	"32y23/bJfpbJ1rV1ham0mBVFRg06LsXhn7UL3064+0TfK4B87nPHqBLtB67OYAk/iqKvfbc73V3eV3m7",
+	"oFNQIqY+iubeEh3LeAJUsiYbZZm2ktZlnjO1rY+ynzWsl6WZxHtat8OFR7tEvJLrNSZAy3fuprYFVcIU",
+	"3sobBIUrhToFV3Npqao+30/WZbXw7ymdC9yAqx3V7xiRzmX3tcArWUlFXSuraX121cu7Z0OpKRynxhSv",
+	"RbaFWMobjo5zFQA4/GRLgp/vZ153XCaoRg3QoNLByftPvVkMh4aaumPfLMIOE4dB48O3sdTxcR+PbNxi",
+	"sMugWfcNtcW12j2UxI4Sy0ZQHXUi0VUFYkvNGj0ie4HmvF7jl9bHEtW2FRcB1KArnWn5u/8wI7/aUXa2",
+	"4j6tGdnXDEO0O+sKvpv4aOcvgjDIPxrjKd+PHd4ZsGhPnzIhRAf2xfwru+N5mVeYmDI+FEZxJPsFhaZU",
+	"IgRmIJfawDyKolkQemnKeM5Nj6IEba8jOJlHnvFAZ25/hVZPQki9AatdnLSj9TkzsS2cMdoBmVzX/HBe",
+	"MhqPYSueGVQDn3jWHCNXwOuGFEWUyvvVvRYdgsANagMW9TsPWW84dO2R+z1k3e5yM3bBt3Rm/TG+cTdG",
+	"66gLqNEYqgD9Dj1Zw+Km0Tbmz/pd2W/4kP5Fngc1ekTXKZm5pzkF9eCbC9k09FKm4RpR2K4eWTilJM1x",
+	"WxwioPPuBXaxRmWbqS6PKU3/BC6MHKjupOg+5O0fLrx7x0v/uPG9nweOWUSnvPi38OVNO3OCH28f0H1b",
+	"V7VfoOlUwILus7eHn9qSwefDT1Qy+DyBEduXza6FqzLs1+NebWK6Mod+o3C3PvyY3xsY+/B70yeHhDTo",
+	"AmO+4nFHdag74GL/bGA+Xj06ZPWvKx6uUc0PM/4YqtVn4c9lfEPtSp6YtJqJmf+QhzBPKaWcJzM4T6XG",
+	"zjAhKQco66Zsk1Pm3BhXf/Yp2rW9YAQUz9PpGrsSfhwbsFviQYPj7V+5xVo5u6vqsoF1Ms3PQqaD+n8M",
+	"oxv8tmiC6UmBYOuyUKCC61qLTNo0FkOQWdJA59lepF6dIVVHwzzRoTHUpLnJTc5TsoSKx1X3wcUOZScn",
+	"9SHa8cCObQ869mWue5jp3zUkjGdbcBurSQGl3XQ6sjiFTMYsg4RtieRcCpMSqH5PChOCkR/CesA9lmLF",
+	"16XCBOrpw9AmG4XiMWo7nVvfYzk43GQH5Gfw0o6VUsfHbkwoAaSlihkinLgQVzbJjZ0Ss2PAYY0oP5bM",
+	"wsFUlmr2m/0JzdDBuUFT7YYpd93ZYNKQ5NqwweAMnjqbtFmq5nfEHEK21Hmlj0hM9FFohZxamKMM5WD0",
+	"B1LLibjoeNnfZj/TYz7mfiOdknC/Yr2nkOzirEww6T/KyIRtx6gw8qE0+E7pzKr6nJ2b162dnfvLMif4",
+	"8PV9x/6BW6cwPgdRKbSqFuwx/vrVdmCVmcb8R5KnZ93Du/M8tdEMHEe13g73amt7bv4fVc+Aa5/RaduP",
+	"QYFmpONb5pzDYRU/BHJLquaqx2OScSXMMGjXFjLj8dYygr51PwOyxRBTTcuqsqqkV73cUdf5WqAtxmwt",
+	"N1t0Yw8nfELWnqByAxve763To5uTTnM8BGoGhnDDRWLXZsygNrXTH3Fgi4rav0XQXFRxZn+wdDyEmBmW",
+	"yfUwm3efEvfxFtW2il9gUmZsacBpOfn7+vG1ZFQ/FRz2CRw4JgHXobGJmHLlgljzYzFwpw1DWDeEn7rm",
+	"fm9wKMOVoR7NDN6REHN2t3TdWnd8fSg9IikpZhnZ1C3tA3Mmtg5I6JAUJGNqjdocGMWZWGd4YFKFeODA",
+	"QRV+XdDrjDB9lxlz/T2ZMY3T3aJia94ZHOLCSMCPJcugPuc795/vZ3BJA4EW3LIsqwm24R6M3DCVaLBF",
+	"1FMXWXMKSgpzxkUInQ4zfFcb0/8eXOCdOTh3H6fIElTfwwpNnGIlDbyrus2wQJHAWRxjYSx3D2N9W8GZ",
+	"88X/QCI3gooRo9quXJp/b5xube7ETRKtgFVKRlNTBTL320NN6seysfCm68vGs40G2L/vTi+cdEYJPoSt",
+	"be2Olgza5TuzM91IvJEDePFl4OAeBD9+uZCbLwEBk+9640yIHKrjegjXaDaIAo6tL/yBSv5wORzjc/kw",
+	"JpMSs9ZWfZ2UtiMwglNINbUTtx+pkE12oEr1Z5UOfpjABWuZxAMyFWCxklp3jPQUko5IqAkS9XsiD+mK",
+	"+F7dp6Zr600uXCi85bLUlTVfpeiIbUYou9lyhXOtLwUpcIw6d0mwt2r6jUBHZ6TmczXvRV6pf0Cbwjcj",
+	"H2HrZ0Ib1+0ASOgZ6flN+IZVwo7LCFuXEdpJlHfWAQ7Z4M9KmeN1bRlBGDgnbDnV8867QfO8EnCVoDSe",
+	"uvmRdndgqJLhuJg+74O9tXVbhQw74TPsKY6CSif6yOFiGNU7XryewQtBFm7sNNtCa7NJBSGMQpaPp8Uo",
+	"Eg0M/lTf8CewORqkMktsPw7+a/H6AqzHJp5Xv6mwhFgAUJTXmR3Yps5anNYxv42FrkanXULMbEuELnBI",
+	"6DgCjbEkIoy0RlXnxgJjC2RlgcLZnXsKWIqb4fve0IObj9Bh+7W240ag05LAp6RcWar26zjj9nf5LMvo",
+	"DAkrpuAaUy6SU1BYkVFNopDGwotnV9Ap04+Fbcf1PWHbTma0oq1cjsbqh3P6IbG7P2u3C3MfHIotcY6c",
+	"StEeQk5VtvwahJx1JBzau53UtFO1mAlKDDXaJAJo2Ecq/hcrjgqbwXfPSKcXdjQghGv6UQEqeIfXC+kw",
+	"4sgrer/0+uu8tXWy1rQOWpN8YDpyWecG+/MRZ8TVTYPpKn6LHVChq5G8gwXtsIzSXddxuBlPQN4Wa8US",
+	"N7PAWn66xK/yLDajyFFrctpf3avM4Fd3dGs/tVEzhcDXwk5OW//RcSpxJjXq2tVrlttv3fi+5Yk1c8eA",
+	"e038nf6nkf+jGvncN7unN7yaZKlqsq1VFEoaGctstFR2IU3PjFImEp2yG7zXgm3C3NlmUcn/DwD0MAE+",
+	"ZkwAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file